@@ -0,0 +1,94 @@
+// Package activation obtains the tokens that allow launched applications to take focus, as
+// defined by the [XDG activation protocol] on Wayland and the startup notification protocol on
+// X11.
+//
+// A token is requested for each launch and passed to the application using
+// $XDG_ACTIVATION_TOKEN and $DESKTOP_STARTUP_ID, or the platform data of D-Bus activation.
+//
+// [XDG activation protocol]: https://wayland.app/protocols/xdg-activation-v1
+package activation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// TokenProvider returns a new activation token for launching the application with the given
+// application ID, which is the desktop ID without the .desktop suffix. appId can be empty.
+type TokenProvider func(ctx context.Context, appId string) (string, error)
+
+// cancels holds, per token, the function that ends the startup sequence of the token.
+var (
+	cancelsMutex sync.Mutex
+	cancels      = make(map[string]func(ctx context.Context) error)
+)
+
+// Cancel ends the startup sequence of a token of the X11 provider that will not be used, e.g.
+// because starting the application failed. Otherwise, the launch feedback, such as a busy
+// cursor, remains until X11Options.RemoveAfter or the timeout of the window manager.
+// desktop.Entry.Launch calls Cancel when it fails to start the application.
+// Tokens of other providers, and tokens that were already canceled, are ignored.
+func Cancel(ctx context.Context, token string) error {
+	cancel := takeCancel(token)
+	if cancel == nil {
+		return nil
+	}
+
+	err := cancel(ctx)
+	if err != nil {
+		return fmt.Errorf("Cancel: %w", err)
+	}
+
+	return nil
+}
+
+func registerCancel(token string, cancel func(ctx context.Context) error) {
+	cancelsMutex.Lock()
+	defer cancelsMutex.Unlock()
+	cancels[token] = cancel
+}
+
+// takeCancel removes and returns the cancel function of the token, or nil if there is none.
+func takeCancel(token string) func(ctx context.Context) error {
+	cancelsMutex.Lock()
+	defer cancelsMutex.Unlock()
+	cancel := cancels[token]
+	delete(cancels, token)
+	return cancel
+}
+
+// FromEnvironment returns a TokenProvider that passes on the token this process was launched
+// with, $XDG_ACTIVATION_TOKEN or $DESKTOP_STARTUP_ID. Tokens can only be used once, the
+// returned provider returns the token only on the first call and an empty string afterward.
+// Use this when the process only launches on behalf of the user, e.g. xdg-open.
+func FromEnvironment() TokenProvider {
+	token := os.Getenv("XDG_ACTIVATION_TOKEN")
+	if token == "" {
+		token = os.Getenv("DESKTOP_STARTUP_ID")
+	}
+
+	used := false
+	return func(ctx context.Context, appId string) (string, error) {
+		if used {
+			return "", nil
+		}
+
+		used = true
+		return token, nil
+	}
+}
+
+// Default returns the TokenProvider appropriate for the session: the Wayland provider if
+// $WAYLAND_DISPLAY is set, the X11 provider if $DISPLAY is set, FromEnvironment otherwise.
+func Default() TokenProvider {
+	switch {
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		return Wayland(WaylandOptions{})
+	case os.Getenv("DISPLAY") != "":
+		return X11(X11Options{})
+	}
+
+	return FromEnvironment()
+}
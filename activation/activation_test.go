@@ -0,0 +1,153 @@
+package activation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// fakeCompositor serves a single Wayland client and answers activation token requests with
+// "token-<app id>". If supported is false, the activation global is not advertised.
+func fakeCompositor(t *testing.T, supported bool) string {
+	socket := filepath.Join(t.TempDir(), "wayland-test")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		server := &waylandConn{conn: conn}
+		var registry, activation uint32
+		tokens := make(map[uint32]string)
+		for {
+			sender, opcode, body, err := server.read()
+			if err != nil {
+				return
+			}
+
+			d := wireDecoder{buf: body}
+			switch {
+			case sender == displayId && opcode == displayGetRegistry:
+				registry = d.uint32()
+				if supported {
+					server.send(registry, registryGlobal, uint32(1), "wl_compositor", uint32(6))
+					server.send(registry, registryGlobal, uint32(7), activationIface, uint32(1))
+				}
+			case sender == displayId && opcode == displaySync:
+				server.send(d.uint32(), callbackDone, uint32(0))
+			case sender == registry && opcode == registryBind:
+				if name := d.uint32(); name != 7 || d.string() != activationIface {
+					server.send(displayId, displayError, registry, uint32(0), "invalid bind")
+					return
+				}
+				d.uint32()
+				activation = d.uint32()
+			case sender == activation && opcode == activationGetToken:
+				tokens[d.uint32()] = ""
+			case opcode == tokenSetAppId:
+				tokens[sender] = d.string()
+			case opcode == tokenCommit:
+				server.send(sender, tokenDone, "token-"+tokens[sender])
+			}
+		}
+	}()
+
+	return socket
+}
+
+func TestWayland(t *testing.T) {
+	socket := fakeCompositor(t, true)
+
+	token, err := Wayland(WaylandOptions{Display: socket})(context.Background(), "org.example.App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-org.example.App" {
+		t.Errorf("token = %s, expected: token-org.example.App", token)
+	}
+}
+
+func TestWaylandUnsupported(t *testing.T) {
+	socket := fakeCompositor(t, false)
+
+	_, err := Wayland(WaylandOptions{Display: socket})(context.Background(), "")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got: %v", err)
+	}
+}
+
+func TestWaylandDisplay(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-1")
+
+	socket, err := waylandSocket("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(runtimeDir, "wayland-1"); socket != expected {
+		t.Errorf("socket = %s, expected: %s", socket, expected)
+	}
+}
+
+func TestWireString(t *testing.T) {
+	for _, value := range []string{"", "a", "abc", "abcd"} {
+		var buf []byte
+		c := &waylandConn{conn: &writerConn{write: func(b []byte) { buf = append(buf, b...) }}}
+		err := c.send(1, 0, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buf)%4 != 0 {
+			t.Errorf("message for %q is not padded: %d bytes", value, len(buf))
+		}
+		if size := int(binary.NativeEndian.Uint32(buf[4:]) >> 16); size != len(buf) {
+			t.Errorf("size = %d, expected: %d", size, len(buf))
+		}
+
+		d := wireDecoder{buf: buf[8:]}
+		if actual := d.string(); actual != value || d.err != nil {
+			t.Errorf("decoded %q (%v), expected: %q", actual, d.err, value)
+		}
+	}
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("XDG_ACTIVATION_TOKEN", "")
+	t.Setenv("DESKTOP_STARTUP_ID", "startup-id")
+
+	provider := FromEnvironment()
+	token, _ := provider(context.Background(), "")
+	if token != "startup-id" {
+		t.Errorf("token = %s, expected: startup-id", token)
+	}
+	token, _ = provider(context.Background(), "")
+	if token != "" {
+		t.Errorf("token = %s on second use, expected it to be empty", token)
+	}
+}
+
+type writerConn struct {
+	net.Conn
+	write func([]byte)
+}
+
+func (c *writerConn) Write(b []byte) (int, error) {
+	c.write(b)
+	return len(b), nil
+}
+
+func (c *writerConn) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
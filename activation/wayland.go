@@ -0,0 +1,345 @@
+package activation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	displayId = 1
+
+	// wl_display
+	displaySync        = 0
+	displayGetRegistry = 1
+	displayError       = 0
+
+	// wl_registry
+	registryBind   = 0
+	registryGlobal = 0
+
+	// wl_callback
+	callbackDone = 0
+
+	// xdg_activation_v1
+	activationDestroy  = 0
+	activationGetToken = 1
+
+	// xdg_activation_token_v1
+	tokenSetSerial  = 0
+	tokenSetAppId   = 1
+	tokenCommit     = 3
+	tokenDestroy    = 4
+	tokenDone       = 0
+	activationIface = "xdg_activation_v1"
+)
+
+// ErrUnsupported is returned when the compositor does not support the XDG activation protocol.
+var ErrUnsupported = errors.New("compositor does not support xdg-activation-v1")
+
+// WaylandOptions configures the Wayland TokenProvider.
+type WaylandOptions struct {
+	// Display is the Wayland display socket, a name relative to $XDG_RUNTIME_DIR or an
+	// absolute path. If empty, $WAYLAND_DISPLAY is used, defaulting to wayland-0.
+	Display string
+
+	// Serial is the serial of the input event that triggered the launch, if known. Together
+	// with Seat, it lets the compositor verify that the launch was requested by the user.
+	Serial uint32
+
+	// Seat is the Wayland object ID of the seat that received the input event. It is only
+	// meaningful when the provider shares the connection of the application, which is not the
+	// case for this provider, and is ignored if zero.
+	Seat uint32
+
+	// Timeout limits the time waiting for the compositor if the context has no deadline.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// Wayland returns a TokenProvider that requests tokens from the compositor using the
+// xdg-activation-v1 protocol over a new connection for each token.
+//
+// As the connection has no surface, compositors may refuse to let the launched application take
+// focus. Applications with their own Wayland connection should request the token themselves,
+// passing their surface, and use ActivationToken of the launch options.
+func Wayland(opts WaylandOptions) TokenProvider {
+	return func(ctx context.Context, appId string) (string, error) {
+		return waylandToken(ctx, opts, appId)
+	}
+}
+
+func waylandSocket(display string) (string, error) {
+	if display == "" {
+		display = os.Getenv("WAYLAND_DISPLAY")
+	}
+	if display == "" {
+		display = "wayland-0"
+	}
+
+	if filepath.IsAbs(display) {
+		return display, nil
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return "", fmt.Errorf("$XDG_RUNTIME_DIR is not set")
+	}
+
+	return filepath.Join(runtimeDir, display), nil
+}
+
+func waylandToken(ctx context.Context, opts WaylandOptions, appId string) (string, error) {
+	socket, err := waylandSocket(opts.Display)
+	if err != nil {
+		return "", fmt.Errorf("Wayland: %w", err)
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := opts.Timeout
+		if timeout == 0 {
+			timeout = 5 * time.Second
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", socket)
+	if err != nil {
+		return "", fmt.Errorf("Wayland: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c := &waylandConn{conn: conn, nextId: displayId + 1}
+	token, err := c.requestToken(opts, appId)
+	if err != nil {
+		return "", fmt.Errorf("Wayland: %w", err)
+	}
+
+	return token, nil
+}
+
+// waylandConn is a minimal client of the Wayland wire protocol.
+type waylandConn struct {
+	conn   net.Conn
+	nextId uint32
+}
+
+func (c *waylandConn) newId() uint32 {
+	id := c.nextId
+	c.nextId++
+	return id
+}
+
+func (c *waylandConn) requestToken(opts WaylandOptions, appId string) (string, error) {
+	registry := c.newId()
+	err := c.send(displayId, displayGetRegistry, registry)
+	if err != nil {
+		return "", err
+	}
+
+	callback := c.newId()
+	err = c.send(displayId, displaySync, callback)
+	if err != nil {
+		return "", err
+	}
+
+	var activationName, activationVersion uint32
+	for done := false; !done; {
+		sender, opcode, body, err := c.receive()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case sender == registry && opcode == registryGlobal:
+			d := wireDecoder{buf: body}
+			name := d.uint32()
+			iface := d.string()
+			version := d.uint32()
+			if d.err == nil && iface == activationIface {
+				activationName = name
+				activationVersion = version
+			}
+		case sender == callback && opcode == callbackDone:
+			done = true
+		}
+	}
+
+	if activationName == 0 {
+		return "", ErrUnsupported
+	}
+
+	activation := c.newId()
+	err = c.send(registry, registryBind, activationName, activationIface, min(activationVersion, 1),
+		activation)
+	if err != nil {
+		return "", err
+	}
+
+	tokenId := c.newId()
+	err = c.send(activation, activationGetToken, tokenId)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Serial != 0 && opts.Seat != 0 {
+		err = c.send(tokenId, tokenSetSerial, opts.Serial, opts.Seat)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if appId != "" {
+		err = c.send(tokenId, tokenSetAppId, appId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = c.send(tokenId, tokenCommit)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		sender, opcode, body, err := c.receive()
+		if err != nil {
+			return "", err
+		}
+
+		if sender == tokenId && opcode == tokenDone {
+			d := wireDecoder{buf: body}
+			token := d.string()
+			if d.err != nil {
+				return "", d.err
+			}
+
+			// Destroying is a courtesy, the connection is closed right after
+			_ = c.send(tokenId, tokenDestroy)
+			_ = c.send(activation, activationDestroy)
+
+			return token, nil
+		}
+	}
+}
+
+// send sends a request. Arguments of type uint32 are sent as uint, int, object, or new_id
+// arguments, strings as string arguments.
+func (c *waylandConn) send(objectId uint32, opcode uint16, args ...any) error {
+	body := make([]byte, 0, 32)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case uint32:
+			body = binary.NativeEndian.AppendUint32(body, v)
+		case string:
+			body = binary.NativeEndian.AppendUint32(body, uint32(len(v)+1))
+			body = append(body, v...)
+			body = append(body, 0)
+			for len(body)%4 != 0 {
+				body = append(body, 0)
+			}
+		default:
+			return fmt.Errorf("unsupported argument type %T", arg)
+		}
+	}
+
+	message := make([]byte, 0, 8+len(body))
+	message = binary.NativeEndian.AppendUint32(message, objectId)
+	message = binary.NativeEndian.AppendUint32(message, uint32(8+len(body))<<16|uint32(opcode))
+	message = append(message, body...)
+
+	_, err := c.conn.Write(message)
+	return err
+}
+
+// receive reads an event. Protocol errors sent by the compositor are returned as error.
+func (c *waylandConn) receive() (uint32, uint16, []byte, error) {
+	sender, opcode, body, err := c.read()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	if sender == displayId && opcode == displayError {
+		d := wireDecoder{buf: body}
+		objectId := d.uint32()
+		code := d.uint32()
+		message := d.string()
+		return 0, 0, nil, fmt.Errorf("protocol error %d on object %d: %s", code, objectId, message)
+	}
+
+	return sender, opcode, body, nil
+}
+
+// read reads a message.
+func (c *waylandConn) read() (uint32, uint16, []byte, error) {
+	header := make([]byte, 8)
+	_, err := io.ReadFull(c.conn, header)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	sender := binary.NativeEndian.Uint32(header)
+	sizeOpcode := binary.NativeEndian.Uint32(header[4:])
+	size := int(sizeOpcode >> 16)
+	opcode := uint16(sizeOpcode)
+	if size < 8 {
+		return 0, 0, nil, fmt.Errorf("invalid message size %d", size)
+	}
+
+	body := make([]byte, size-8)
+	_, err = io.ReadFull(c.conn, body)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return sender, opcode, body, nil
+}
+
+type wireDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *wireDecoder) uint32() uint32 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) < 4 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+
+	v := binary.NativeEndian.Uint32(d.buf)
+	d.buf = d.buf[4:]
+	return v
+}
+
+func (d *wireDecoder) string() string {
+	length := int(d.uint32())
+	if d.err != nil || length == 0 {
+		return ""
+	}
+
+	padded := (length + 3) &^ 3
+	if len(d.buf) < padded {
+		d.err = io.ErrUnexpectedEOF
+		return ""
+	}
+
+	s := string(d.buf[:length-1])
+	d.buf = d.buf[padded:]
+	return s
+}
@@ -0,0 +1,560 @@
+package activation
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Requests
+	x11CreateWindow   = 1
+	x11DestroyWindow  = 4
+	x11InternAtom     = 16
+	x11ChangeProperty = 18
+	x11SendEvent      = 25
+	x11GetInputFocus  = 43
+
+	// Events
+	x11PropertyNotify = 28
+	x11ClientMessage  = 33
+
+	x11InputOnly          = 2
+	x11EventMaskAttribute = 1 << 11
+	x11PropertyChangeMask = 1 << 22
+	x11AtomString         = 31
+
+	// startupInfoChunk is the amount of message bytes sent per ClientMessage event.
+	startupInfoChunk = 20
+)
+
+// X11Options configures the X11 TokenProvider.
+type X11Options struct {
+	// Display is the X11 display, e.g. :0, or the absolute path of the display socket. If empty,
+	// $DISPLAY is used.
+	Display string
+
+	// Timestamp is the X server time of the input event that triggered the launch, if known. It
+	// allows the window manager to prevent focus stealing. If zero, the current server time is
+	// used.
+	Timestamp uint32
+
+	// RemoveAfter is the duration after which the startup sequence is ended by sending a remove
+	// message, in case the application does not do so itself. Defaults to 30 seconds, a negative
+	// duration disables the fallback. The process must keep running for the fallback to be sent,
+	// otherwise the window manager ends the sequence after its own timeout.
+	// Short-lived processes, such as xdg-open, exit before the fallback is sent and must end the
+	// sequence themselves using Cancel if the token ends up unused.
+	RemoveAfter time.Duration
+
+	// Timeout limits the time waiting for the X server if the context has no deadline.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// X11 returns a TokenProvider that starts a startup sequence of the [Startup Notification
+// Protocol] on the X server. The returned token is the startup ID, which the application passes
+// to the window manager when it maps its window or sends a remove message.
+//
+// [Startup Notification Protocol]: https://specifications.freedesktop.org/startup-notification-spec/latest/
+func X11(opts X11Options) TokenProvider {
+	return func(ctx context.Context, appId string) (string, error) {
+		token, err := x11Startup(ctx, opts, appId)
+		if err != nil {
+			return "", fmt.Errorf("X11: %w", err)
+		}
+
+		return token, nil
+	}
+}
+
+// startupSequence makes the startup IDs created by this process unique.
+var startupSequence atomic.Uint64
+
+func x11Startup(ctx context.Context, opts X11Options, appId string) (string, error) {
+	c, err := x11Dial(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	defer c.conn.Close()
+
+	window, err := c.createWindow()
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := opts.Timestamp
+	if timestamp == 0 {
+		timestamp, err = c.serverTime(window)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name := appId
+	if name == "" {
+		name = "application"
+	}
+
+	hostname, _ := os.Hostname()
+	id := fmt.Sprintf(
+		"%s-%d-%s-%s-%d_TIME%d",
+		sanitizeStartupId(filepath.Base(os.Args[0])),
+		os.Getpid(),
+		sanitizeStartupId(hostname),
+		sanitizeStartupId(name),
+		startupSequence.Add(1),
+		timestamp,
+	)
+
+	message := fmt.Sprintf(
+		"new: ID=%s NAME=%s SCREEN=%d",
+		quoteStartupValue(id),
+		quoteStartupValue(name),
+		c.screen,
+	)
+	err = c.sendStartupMessage(window, message)
+	if err != nil {
+		return "", err
+	}
+
+	registerCancel(id, func(ctx context.Context) error {
+		return x11Remove(ctx, opts, id)
+	})
+
+	removeAfter := opts.RemoveAfter
+	if removeAfter == 0 {
+		removeAfter = 30 * time.Second
+	}
+	if removeAfter > 0 {
+		time.AfterFunc(removeAfter, func() {
+			_ = Cancel(context.Background(), id)
+		})
+	}
+
+	return id, nil
+}
+
+// x11Remove ends the startup sequence with the given ID.
+func x11Remove(ctx context.Context, opts X11Options, id string) error {
+	c, err := x11Dial(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+
+	window, err := c.createWindow()
+	if err != nil {
+		return err
+	}
+
+	return c.sendStartupMessage(window, "remove: ID="+quoteStartupValue(id))
+}
+
+// x11Conn is a minimal client of the X11 protocol.
+type x11Conn struct {
+	conn   net.Conn
+	root   uint32
+	screen int
+	idBase uint32
+	nextId uint32
+}
+
+func x11Dial(ctx context.Context, opts X11Options) (*x11Conn, error) {
+	display := opts.Display
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+	if display == "" {
+		return nil, fmt.Errorf("$DISPLAY is not set")
+	}
+
+	network, address, displayNumber, screen, err := x11Address(display)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		timeout := opts.Timeout
+		if timeout == 0 {
+			timeout = 5 * time.Second
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c := &x11Conn{conn: conn, screen: screen}
+	authName, authData := xauthCookie(displayNumber)
+	err = c.setup(authName, authData)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// x11Address parses a display such as :0, :0.1, unix:0, or host:0 into the address of its
+// socket, the display number, and the screen.
+func x11Address(display string) (string, string, string, int, error) {
+	if filepath.IsAbs(display) {
+		return "unix", display, "", 0, nil
+	}
+
+	index := strings.LastIndex(display, ":")
+	if index == -1 {
+		return "", "", "", 0, fmt.Errorf("invalid display %s", display)
+	}
+	host := display[:index]
+	number, screenNumber, _ := strings.Cut(display[index+1:], ".")
+
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return "", "", "", 0, fmt.Errorf("invalid display %s", display)
+	}
+
+	screen := 0
+	if screenNumber != "" {
+		screen, err = strconv.Atoi(screenNumber)
+		if err != nil {
+			return "", "", "", 0, fmt.Errorf("invalid display %s", display)
+		}
+	}
+
+	if host == "" || host == "unix" {
+		return "unix", "/tmp/.X11-unix/X" + number, number, screen, nil
+	}
+
+	return "tcp", net.JoinHostPort(host, strconv.Itoa(6000+n)), number, screen, nil
+}
+
+// xauthCookie returns the MIT-MAGIC-COOKIE-1 of the display in the Xauthority file, if any.
+func xauthCookie(displayNumber string) (string, []byte) {
+	const cookieName = "MIT-MAGIC-COOKIE-1"
+	const familyLocal = 256
+	const familyWild = 65535
+
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil
+	}
+
+	hostname, _ := os.Hostname()
+	field := func() []byte {
+		if len(content) < 2 {
+			content = nil
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(content))
+		if len(content) < 2+length {
+			content = nil
+			return nil
+		}
+		value := content[2 : 2+length]
+		content = content[2+length:]
+		return value
+	}
+
+	for len(content) >= 2 {
+		family := binary.BigEndian.Uint16(content)
+		content = content[2:]
+		address := string(field())
+		number := string(field())
+		name := string(field())
+		data := field()
+		if content == nil {
+			break
+		}
+
+		if family != familyWild && (family != familyLocal || address != hostname) {
+			continue
+		}
+		if number != "" && number != displayNumber {
+			continue
+		}
+		if name == cookieName {
+			return name, data
+		}
+	}
+
+	return "", nil
+}
+
+func (c *x11Conn) setup(authName string, authData []byte) error {
+	request := []byte{'l', 0}
+	request = binary.LittleEndian.AppendUint16(request, 11)
+	request = binary.LittleEndian.AppendUint16(request, 0)
+	request = binary.LittleEndian.AppendUint16(request, uint16(len(authName)))
+	request = binary.LittleEndian.AppendUint16(request, uint16(len(authData)))
+	request = append(request, 0, 0)
+	request = appendPadded(request, []byte(authName))
+	request = appendPadded(request, authData)
+	_, err := c.conn.Write(request)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	_, err = io.ReadFull(c.conn, header)
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, int(binary.LittleEndian.Uint16(header[6:]))*4)
+	_, err = io.ReadFull(c.conn, data)
+	if err != nil {
+		return err
+	}
+
+	switch header[0] {
+	case 0:
+		reason := data[:min(int(header[1]), len(data))]
+		return fmt.Errorf("connection refused by X server: %s", reason)
+	case 1:
+	default:
+		return fmt.Errorf("X server requires additional authentication")
+	}
+
+	if len(data) < 32 {
+		return io.ErrUnexpectedEOF
+	}
+
+	c.idBase = binary.LittleEndian.Uint32(data[4:])
+	vendorLength := int(binary.LittleEndian.Uint16(data[16:]))
+	screens := int(data[20])
+	formats := int(data[21])
+	if c.screen >= screens {
+		return fmt.Errorf("screen %d does not exist", c.screen)
+	}
+
+	offset := 32 + (vendorLength+3)&^3 + 8*formats
+	for i := 0; ; i++ {
+		if len(data) < offset+40 {
+			return io.ErrUnexpectedEOF
+		}
+
+		if i == c.screen {
+			c.root = binary.LittleEndian.Uint32(data[offset:])
+			return nil
+		}
+
+		depths := int(data[offset+39])
+		offset += 40
+		for range depths {
+			if len(data) < offset+8 {
+				return io.ErrUnexpectedEOF
+			}
+			offset += 8 + 24*int(binary.LittleEndian.Uint16(data[offset+2:]))
+		}
+	}
+}
+
+// request sends a request. The body is padded to a multiple of 4 bytes.
+func (c *x11Conn) request(opcode byte, data byte, body []byte) error {
+	message := []byte{opcode, data, 0, 0}
+	message = appendPadded(message, body)
+	binary.LittleEndian.PutUint16(message[2:], uint16(len(message)/4))
+
+	_, err := c.conn.Write(message)
+	return err
+}
+
+// next reads the next reply or event. Errors sent by the server are returned as error.
+func (c *x11Conn) next() ([]byte, error) {
+	packet := make([]byte, 32)
+	_, err := io.ReadFull(c.conn, packet)
+	if err != nil {
+		return nil, err
+	}
+
+	switch packet[0] {
+	case 0:
+		return nil, fmt.Errorf("X11 error %d for request %d", packet[1], packet[10])
+	case 1:
+		extra := make([]byte, int(binary.LittleEndian.Uint32(packet[4:]))*4)
+		_, err = io.ReadFull(c.conn, extra)
+		if err != nil {
+			return nil, err
+		}
+		packet = append(packet, extra...)
+	}
+
+	return packet, nil
+}
+
+// reply reads the next reply, skipping events.
+func (c *x11Conn) reply() ([]byte, error) {
+	for {
+		packet, err := c.next()
+		if err != nil || packet[0] == 1 {
+			return packet, err
+		}
+	}
+}
+
+func (c *x11Conn) internAtom(name string) (uint32, error) {
+	body := binary.LittleEndian.AppendUint16(nil, uint16(len(name)))
+	body = append(body, 0, 0)
+	body = append(body, name...)
+	err := c.request(x11InternAtom, 0, body)
+	if err != nil {
+		return 0, err
+	}
+
+	reply, err := c.reply()
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(reply[8:]), nil
+}
+
+// createWindow creates an unmapped window that receives PropertyNotify events. It is used as
+// the source window of startup messages.
+func (c *x11Conn) createWindow() (uint32, error) {
+	window := c.idBase | c.nextId
+	c.nextId++
+
+	body := binary.LittleEndian.AppendUint32(nil, window)
+	body = binary.LittleEndian.AppendUint32(body, c.root)
+	body = binary.LittleEndian.AppendUint32(body, 0)                // x, y
+	body = binary.LittleEndian.AppendUint32(body, 1|1<<16)          // width, height
+	body = binary.LittleEndian.AppendUint32(body, x11InputOnly<<16) // border, class
+	body = binary.LittleEndian.AppendUint32(body, 0)                // visual
+	body = binary.LittleEndian.AppendUint32(body, x11EventMaskAttribute)
+	body = binary.LittleEndian.AppendUint32(body, x11PropertyChangeMask)
+
+	return window, c.request(x11CreateWindow, 0, body)
+}
+
+// serverTime returns the current time of the X server by appending nothing to a property of
+// the window and waiting for the resulting PropertyNotify event.
+func (c *x11Conn) serverTime(window uint32) (uint32, error) {
+	body := binary.LittleEndian.AppendUint32(nil, window)
+	body = binary.LittleEndian.AppendUint32(body, x11AtomString) // property
+	body = binary.LittleEndian.AppendUint32(body, x11AtomString) // type
+	body = append(body, 8, 0, 0, 0)                              // format
+	body = binary.LittleEndian.AppendUint32(body, 0)             // length
+	err := c.request(x11ChangeProperty, 2, body)
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		packet, err := c.next()
+		if err != nil {
+			return 0, err
+		}
+
+		if packet[0]&0x7f == x11PropertyNotify {
+			return binary.LittleEndian.Uint32(packet[12:]), nil
+		}
+	}
+}
+
+// sendStartupMessage sends the message to the root window, split into ClientMessage events,
+// and waits until the server has processed them.
+func (c *x11Conn) sendStartupMessage(window uint32, message string) error {
+	begin, err := c.internAtom("_NET_STARTUP_INFO_BEGIN")
+	if err != nil {
+		return err
+	}
+
+	info, err := c.internAtom("_NET_STARTUP_INFO")
+	if err != nil {
+		return err
+	}
+
+	data := append([]byte(message), 0)
+	for offset := 0; offset < len(data); offset += startupInfoChunk {
+		messageType := info
+		if offset == 0 {
+			messageType = begin
+		}
+
+		event := []byte{x11ClientMessage, 8, 0, 0}
+		event = binary.LittleEndian.AppendUint32(event, window)
+		event = binary.LittleEndian.AppendUint32(event, messageType)
+		chunk := make([]byte, startupInfoChunk)
+		copy(chunk, data[offset:])
+		event = append(event, chunk...)
+
+		body := binary.LittleEndian.AppendUint32(nil, c.root)
+		body = binary.LittleEndian.AppendUint32(body, x11PropertyChangeMask)
+		body = append(body, event...)
+		err = c.request(x11SendEvent, 0, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.request(x11DestroyWindow, 0, binary.LittleEndian.AppendUint32(nil, window))
+	if err != nil {
+		return err
+	}
+
+	// The reply is only sent after the previous requests have been processed.
+	err = c.request(x11GetInputFocus, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.reply()
+	return err
+}
+
+func appendPadded(b []byte, data []byte) []byte {
+	b = append(b, data...)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// quoteStartupValue quotes a value of a startup notification message.
+func quoteStartupValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// sanitizeStartupId replaces the characters that are unsuitable for a startup ID, which is also
+// passed in the environment.
+func sanitizeStartupId(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
@@ -0,0 +1,193 @@
+package activation
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeXServer accepts X11 clients and sends the startup notification messages they send to the
+// root window on the returned channel. The server time is 1234.
+func fakeXServer(t *testing.T) (string, <-chan string) {
+	socket := filepath.Join(t.TempDir(), "X0")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveX11(conn, messages)
+		}
+	}()
+
+	return socket, messages
+}
+
+func serveX11(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	le := binary.LittleEndian
+
+	setup := make([]byte, 12)
+	if _, err := io.ReadFull(conn, setup); err != nil {
+		return
+	}
+	authLength := (int(le.Uint16(setup[6:]))+3)&^3 + (int(le.Uint16(setup[8:]))+3)&^3
+	if _, err := io.ReadFull(conn, make([]byte, authLength)); err != nil {
+		return
+	}
+
+	// One screen with root window 0x100 and no formats, vendor, or depths.
+	data := make([]byte, 32+40)
+	le.PutUint32(data[4:], 0x200000)
+	data[20] = 1
+	le.PutUint32(data[32:], 0x100)
+	reply := []byte{1, 0, 11, 0, 0, 0}
+	reply = le.AppendUint16(reply, uint16(len(data)/4))
+	conn.Write(append(reply, data...))
+
+	atoms := make(map[string]uint32)
+	var message []byte
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		body := make([]byte, int(le.Uint16(header[2:]))*4-4)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		response := make([]byte, 32)
+		switch header[0] {
+		case x11InternAtom:
+			name := string(body[4 : 4+le.Uint16(body)])
+			if atoms[name] == 0 {
+				atoms[name] = uint32(100 + len(atoms))
+			}
+			response[0] = 1
+			le.PutUint32(response[8:], atoms[name])
+		case x11ChangeProperty:
+			response[0] = x11PropertyNotify
+			le.PutUint32(response[12:], 1234)
+		case x11SendEvent:
+			event := body[8:]
+			if le.Uint32(event[8:]) == atoms["_NET_STARTUP_INFO_BEGIN"] {
+				message = nil
+			}
+			message = append(message, event[12:32]...)
+			if end := bytes.IndexByte(message, 0); end != -1 {
+				messages <- string(message[:end])
+			}
+			continue
+		case x11GetInputFocus:
+			response[0] = 1
+		default:
+			continue
+		}
+		conn.Write(response)
+	}
+}
+
+func TestX11(t *testing.T) {
+	socket, messages := fakeXServer(t)
+
+	provider := X11(X11Options{Display: socket, RemoveAfter: 10 * time.Millisecond})
+	token, err := provider(context.Background(), "org.example.App")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(token, "-org.example.App-") || !strings.HasSuffix(token, "_TIME1234") {
+		t.Errorf("token = %s, expected it to contain the app ID and server time", token)
+	}
+
+	expected := []string{
+		`new: ID="` + token + `" NAME="org.example.App" SCREEN=0`,
+		`remove: ID="` + token + `"`,
+	}
+	for _, expectedMessage := range expected {
+		select {
+		case message := <-messages:
+			if message != expectedMessage {
+				t.Errorf("message = %s, expected: %s", message, expectedMessage)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no message received, expected: %s", expectedMessage)
+		}
+	}
+}
+
+func TestX11Cancel(t *testing.T) {
+	socket, messages := fakeXServer(t)
+
+	provider := X11(X11Options{Display: socket, RemoveAfter: -1})
+	token, err := provider(context.Background(), "org.example.App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-messages
+
+	for range 2 {
+		// Canceling twice only sends one remove message
+		err = Cancel(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `remove: ID="` + token + `"`
+	select {
+	case message := <-messages:
+		if message != expected {
+			t.Errorf("message = %s, expected: %s", message, expected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no message received, expected: %s", expected)
+	}
+
+	select {
+	case message := <-messages:
+		t.Errorf("unexpected message: %s", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestX11Address(t *testing.T) {
+	tests := map[string]string{
+		":0":          "unix /tmp/.X11-unix/X0 0 0",
+		"unix:1.2":    "unix /tmp/.X11-unix/X1 1 2",
+		"host:10":     "tcp host:6010 10 0",
+		"/tmp/socket": "unix /tmp/socket  0",
+	}
+
+	for display, expected := range tests {
+		network, address, number, screen, err := x11Address(display)
+		if err != nil {
+			t.Errorf("x11Address(%s) error: %v", display, err)
+			continue
+		}
+
+		actual := strings.Join([]string{network, address, number, fmt.Sprint(screen)}, " ")
+		if actual != expected {
+			t.Errorf("x11Address(%s) = %s, expected: %s", display, actual, expected)
+		}
+	}
+
+	if _, _, _, _, err := x11Address("invalid"); err == nil {
+		t.Errorf("x11Address(invalid) returned no error")
+	}
+}
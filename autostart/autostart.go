@@ -0,0 +1,129 @@
+// Package autostart implements the [Desktop Application Autostart Specification] which
+// describes the applications that are started when the user logs in.
+//
+// [Desktop Application Autostart Specification]: https://specifications.freedesktop.org/autostart-spec/0.5/
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const dirName = "autostart"
+
+// Entry is an autostart desktop entry.
+type Entry struct {
+	// ID is the file name of the desktop entry, e.g. nm-applet.desktop.
+	ID string
+
+	// Path of the desktop file.
+	Path string
+
+	// Desktop is the parsed desktop entry.
+	Desktop *desktop.Entry
+}
+
+// GetDirs returns the autostart directories in order of precedence:
+// $XDG_CONFIG_HOME/autostart followed by autostart in each $XDG_CONFIG_DIRS.
+func GetDirs() []string {
+	result := []string{filepath.Join(basedir.ConfigHome, dirName)}
+
+	for _, dir := range basedir.ConfigDirs {
+		result = append(result, filepath.Join(dir, dirName))
+	}
+
+	return result
+}
+
+// UserDir returns the autostart directory of the user: $XDG_CONFIG_HOME/autostart.
+func UserDir() string {
+	return filepath.Join(basedir.ConfigHome, dirName)
+}
+
+// Load returns all autostart entries found in the given directories. If dirs is nil, GetDirs is
+// used.
+// A desktop file in a directory of higher precedence hides desktop files with the same name in
+// directories of lower precedence, also if it cannot be parsed. Files that cannot be parsed are
+// skipped, except for files with Hidden=true which disable the entry even if they lack required
+// keys such as Name and Exec.
+// The entries are returned unfiltered, use Filter to determine which ones should be started.
+func Load(dirs []string) ([]Entry, error) {
+	if dirs == nil {
+		dirs = GetDirs()
+	}
+
+	var result []Entry
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			logging.Logger().Warn(
+				"Failed to read autostart directory",
+				logging.Path, dir,
+				logging.Error, err,
+			)
+			continue
+		}
+
+		for _, file := range files {
+			id := file.Name()
+			if file.IsDir() || !strings.HasSuffix(id, ".desktop") || seen[id] {
+				continue
+			}
+
+			// An invalid file still hides the files of lower precedence
+			seen[id] = true
+
+			path := filepath.Join(dir, id)
+			parsed, err := loadEntry(path)
+			if err != nil {
+				logging.Logger().Warn(
+					"Skipping invalid autostart entry",
+					logging.Path, path,
+					logging.Error, err,
+				)
+				continue
+			}
+
+			result = append(result, Entry{
+				ID:      id,
+				Path:    path,
+				Desktop: parsed,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// loadEntry parses the autostart desktop file at path. A file with Hidden=true is returned even
+// if it is otherwise invalid, e.g. a minimal override containing only Hidden=true, since it
+// disables the entry regardless.
+func loadEntry(path string) (*desktop.Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	parsed, issues := desktop.ParseLenient(file)
+	if parsed.Hidden {
+		return parsed, nil
+	}
+
+	if len(issues) > 0 {
+		return nil, fmt.Errorf("failed to parse desktop file %s: %w", path, issues[0])
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,144 @@
+package autostart
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func createEntry(t *testing.T, dir string, id string, extra string) {
+	testenv.WriteFile(t, filepath.Join(dir, id), "[Desktop Entry]\nType=Application\nName="+id+
+		"\nExec=/bin/true\n"+extra)
+}
+
+func entryIDs(entries []Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.ID)
+	}
+
+	return result
+}
+
+func TestLoad(t *testing.T) {
+	home := testenv.Setup(t)
+	systemDir := filepath.Join(home, "etc/xdg/autostart")
+	createEntry(t, systemDir, "a.desktop", "")
+	createEntry(t, systemDir, "b.desktop", "")
+	createEntry(t, UserDir(), "b.desktop", "Hidden=true\n")
+	testenv.WriteFile(t, filepath.Join(systemDir, "invalid.desktop"), "garbage")
+	testenv.WriteFile(t, filepath.Join(systemDir, "readme.txt"), "not a desktop file")
+
+	entries, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := entryIDs(entries)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"a.desktop", "b.desktop"}) {
+		t.Fatalf("Load = %v, expected: [a.desktop b.desktop]", ids)
+	}
+
+	for _, entry := range entries {
+		if entry.ID == "b.desktop" && !entry.Desktop.Hidden {
+			t.Errorf("b.desktop of the user did not hide the system entry")
+		}
+	}
+}
+
+func TestLoadHiddenOnlyOverride(t *testing.T) {
+	home := testenv.Setup(t)
+	systemDir := filepath.Join(home, "etc/xdg/autostart")
+	createEntry(t, systemDir, "a.desktop", "")
+	createEntry(t, systemDir, "b.desktop", "")
+	testenv.WriteFile(t, filepath.Join(UserDir(), "a.desktop"), "[Desktop Entry]\nHidden=true\n")
+	testenv.WriteFile(t, filepath.Join(UserDir(), "b.desktop"), "garbage")
+
+	entries, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].ID != "a.desktop" || !entries[0].Desktop.Hidden {
+		t.Fatalf("Load = %v, expected only the hidden a.desktop of the user", entryIDs(entries))
+	}
+	if entries[0].Path != filepath.Join(UserDir(), "a.desktop") {
+		t.Errorf("Path = %s, expected the user override", entries[0].Path)
+	}
+
+	state, err := GetState("a.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateDisabled {
+		t.Errorf("GetState(a.desktop) = %v, expected: %v", state, StateDisabled)
+	}
+
+	state, err = Enable("a.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateEnabled {
+		t.Errorf("Enable(a.desktop) = %v, expected: %v", state, StateEnabled)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := filepath.Join(home, "autostart")
+	createEntry(t, dir, "plain.desktop", "")
+	createEntry(t, dir, "hidden.desktop", "Hidden=true\n")
+	createEntry(t, dir, "gnome.desktop", "OnlyShowIn=GNOME;\n")
+	createEntry(t, dir, "not-kde.desktop", "NotShowIn=KDE;\n")
+	createEntry(t, dir, "tryexec.desktop", "TryExec=/nonexistent/program\n")
+	testenv.WriteFile(t, filepath.Join(dir, "link.desktop"),
+		"[Desktop Entry]\nType=Link\nName=Link\nURL=https://example.com\n")
+	testenv.WriteFile(t, filepath.Join(dir, "override.desktop"), "[Desktop Entry]\nHidden=true\n")
+
+	entries, err := Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eligible, excluded := Filter(entries, []string{"KDE"})
+	ids := entryIDs(eligible)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"plain.desktop"}) {
+		t.Errorf("eligible = %v, expected: [plain.desktop]", ids)
+	}
+
+	expected := map[string]ExclusionReason{
+		"hidden.desktop":   ExcludedHidden,
+		"override.desktop": ExcludedHidden,
+		"gnome.desktop":    ExcludedByDesktop,
+		"not-kde.desktop":  ExcludedByDesktop,
+		"tryexec.desktop":  ExcludedTryExec,
+		"link.desktop":     ExcludedNotApplication,
+	}
+	if len(excluded) != len(expected) {
+		t.Errorf("excluded %d entries, expected: %d", len(excluded), len(expected))
+	}
+	for _, e := range excluded {
+		if expected[e.Entry.ID] != e.Reason {
+			t.Errorf("%s excluded for %s, expected: %s", e.Entry.ID, e.Reason, expected[e.Entry.ID])
+		}
+	}
+
+	eligible, _ = Filter(entries, []string{"ubuntu", "GNOME"})
+	ids = entryIDs(eligible)
+	slices.Sort(ids)
+	expectedIDs := []string{"gnome.desktop", "not-kde.desktop", "plain.desktop"}
+	if !slices.Equal(ids, expectedIDs) {
+		t.Errorf("eligible in GNOME = %v, expected: %v", ids, expectedIDs)
+	}
+}
+
+func TestCurrentDesktops(t *testing.T) {
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+	actual := CurrentDesktops()
+	if !slices.Equal(actual, []string{"ubuntu", "GNOME"}) {
+		t.Errorf("CurrentDesktops = %v, expected: [ubuntu GNOME]", actual)
+	}
+}
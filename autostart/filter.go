@@ -0,0 +1,152 @@
+package autostart
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"os"
+	"strings"
+)
+
+// ExclusionReason is the reason an autostart entry must not be started.
+type ExclusionReason int
+
+const (
+	// NotExcluded means the entry should be started.
+	NotExcluded ExclusionReason = iota
+
+	// ExcludedNotApplication means the entry is not of type Application.
+	ExcludedNotApplication
+
+	// ExcludedHidden means the entry has Hidden=true, i.e. it was disabled.
+	ExcludedHidden
+
+	// ExcludedByDesktop means OnlyShowIn or NotShowIn exclude the current desktop.
+	ExcludedByDesktop
+
+	// ExcludedTryExec means the TryExec executable is not installed.
+	ExcludedTryExec
+
+	// ExcludedByCondition means a ConditionEvaluator rejected the entry.
+	ExcludedByCondition
+)
+
+func (r ExclusionReason) String() string {
+	switch r {
+	case NotExcluded:
+		return "not excluded"
+	case ExcludedNotApplication:
+		return "not an application"
+	case ExcludedHidden:
+		return "hidden"
+	case ExcludedByDesktop:
+		return "not shown in current desktop"
+	case ExcludedTryExec:
+		return "TryExec not found"
+	case ExcludedByCondition:
+		return "condition not met"
+	default:
+		return fmt.Sprintf("ExclusionReason(%d)", int(r))
+	}
+}
+
+// Excluded is an autostart entry that must not be started.
+type Excluded struct {
+	Entry  Entry
+	Reason ExclusionReason
+}
+
+// CurrentDesktops returns the desktop environments in $XDG_CURRENT_DESKTOP, e.g. [ubuntu GNOME].
+func CurrentDesktops() []string {
+	var result []string
+	for _, name := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// FilterOptions configure FilterWithOptions.
+type FilterOptions struct {
+	// Desktops are the current desktop environments, see CurrentDesktops.
+	Desktops []string
+
+	// Conditions are evaluated, in order, for the entries that meet the conditions of the
+	// specification. See DefaultConditions.
+	Conditions []ConditionEvaluator
+}
+
+// Eligible returns the autostart entries of the standard directories that should be started in
+// the desktop environments of $XDG_CURRENT_DESKTOP. Besides the conditions of the
+// specification, the DefaultConditions are applied.
+func Eligible() ([]Entry, error) {
+	entries, err := Load(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Eligible: %w", err)
+	}
+
+	eligible, _ := FilterWithOptions(entries, FilterOptions{
+		Desktops:   CurrentDesktops(),
+		Conditions: DefaultConditions,
+	})
+	return eligible, nil
+}
+
+// Filter splits the entries in those that should be started in the given desktop environments
+// and those that should not, together with the reason.
+// Only the conditions of the specification are applied.
+func Filter(entries []Entry, desktops []string) ([]Entry, []Excluded) {
+	return FilterWithOptions(entries, FilterOptions{Desktops: desktops})
+}
+
+// FilterWithOptions is like Filter but additionally consults the given condition evaluators.
+func FilterWithOptions(entries []Entry, opts FilterOptions) ([]Entry, []Excluded) {
+	var eligible []Entry
+	var excluded []Excluded
+
+	for _, entry := range entries {
+		reason := entry.ExclusionReason(opts.Desktops)
+		if reason == NotExcluded && !meetsConditions(entry, opts.Conditions) {
+			reason = ExcludedByCondition
+		}
+
+		if reason == NotExcluded {
+			eligible = append(eligible, entry)
+		} else {
+			excluded = append(excluded, Excluded{Entry: entry, Reason: reason})
+		}
+	}
+
+	return eligible, excluded
+}
+
+// ExclusionReason returns why the entry must not be started in the given desktop environments
+// or NotExcluded if it should be started.
+// The conditions are checked in the following order: Hidden, Type, OnlyShowIn/NotShowIn,
+// TryExec. Hidden comes first because an override that disables an entry only needs to contain
+// Hidden=true.
+func (e Entry) ExclusionReason(desktops []string) ExclusionReason {
+	switch {
+	case e.Desktop.Hidden:
+		return ExcludedHidden
+	case e.Desktop.Type != desktop.TypeApplication:
+		return ExcludedNotApplication
+	case !e.Desktop.ShouldShowIn(strings.Join(desktops, ":")):
+		return ExcludedByDesktop
+	case !e.Desktop.IsInstalled():
+		return ExcludedTryExec
+	}
+
+	return NotExcluded
+}
+
+func meetsConditions(entry Entry, conditions []ConditionEvaluator) bool {
+	for _, condition := range conditions {
+		if !condition(entry) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,170 @@
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrEntryExists is returned by Install when the user autostart directory already contains an
+// entry with the ID and ConflictFail is used.
+var ErrEntryExists = errors.New("autostart entry already exists")
+
+// ConflictStrategy determines what happens when the user autostart directory already contains
+// an entry with the ID being installed.
+type ConflictStrategy int
+
+const (
+	// ConflictFail makes the install fail with ErrEntryExists.
+	ConflictFail ConflictStrategy = iota
+
+	// ConflictRename installs the entry using a new ID such as app-2.desktop.
+	ConflictRename
+
+	// ConflictOverwrite replaces the existing entry.
+	ConflictOverwrite
+)
+
+// NewEntry describes an autostart entry to install.
+type NewEntry struct {
+	// Name of the application, e.g. Syncthing. Required.
+	Name string
+
+	// Comment is an optional description of the application.
+	Comment string
+
+	// Icon name or absolute path.
+	Icon string
+
+	// Exec is the command to run, the first element being the program. Required.
+	// Arguments are quoted as needed.
+	Exec []string
+
+	// TryExec is an optional executable that must be installed for the entry to be started.
+	TryExec string
+
+	// Terminal runs the program in a terminal.
+	Terminal bool
+
+	// OnlyShowIn and NotShowIn limit the desktop environments in which the entry is started.
+	OnlyShowIn []string
+	NotShowIn  []string
+
+	// OtherKeys are additional keys written to the Desktop Entry group, e.g.
+	// X-GNOME-Autostart-Delay.
+	OtherKeys map[string]string
+}
+
+// Install writes the entry to the user autostart directory and returns the path of the created
+// file. If id does not end with .desktop, the extension is added.
+// The file is written atomically.
+func Install(id string, entry NewEntry, strategy ConflictStrategy) (string, error) {
+	id = normalizeID(id)
+	if id == ".desktop" || strings.ContainsRune(id, filepath.Separator) {
+		return "", fmt.Errorf("Install: invalid autostart ID: %s", id)
+	}
+
+	content, err := entry.encode()
+	if err != nil {
+		return "", fmt.Errorf("Install: %w", err)
+	}
+
+	dir := UserDir()
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("Install: failed to create %s: %w", dir, err)
+	}
+
+	path, err := reservePath(dir, id, strategy)
+	if err != nil {
+		return "", fmt.Errorf("Install: %w", err)
+	}
+
+	err = fileutil.WriteFileAtomic(path, content, 0644)
+	if err != nil {
+		if strategy != ConflictOverwrite {
+			// The empty placeholder would mask a system entry with the same ID
+			os.Remove(path)
+		}
+		return "", fmt.Errorf("Install: %w", err)
+	}
+
+	return path, nil
+}
+
+// reservePath returns the path to write the entry to, creating an empty file to claim the name
+// unless an existing file is overwritten.
+func reservePath(dir string, id string, strategy ConflictStrategy) (string, error) {
+	base := strings.TrimSuffix(id, ".desktop")
+
+	for i := 1; ; i++ {
+		name := id
+		if i > 1 {
+			name = base + "-" + strconv.Itoa(i) + ".desktop"
+		}
+		path := filepath.Join(dir, name)
+
+		if strategy == ConflictOverwrite {
+			return path, nil
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		switch {
+		case err == nil:
+			return path, file.Close()
+		case !errors.Is(err, os.ErrExist):
+			return "", err
+		case strategy == ConflictFail:
+			return "", fmt.Errorf("%w: %s", ErrEntryExists, path)
+		}
+	}
+}
+
+// Uninstall removes the entry with the given ID from the user autostart directory.
+// Removing an entry that does not exist is not an error.
+// To disable an entry of a system directory, use Disable.
+func Uninstall(id string) error {
+	err := os.Remove(filepath.Join(UserDir(), normalizeID(id)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Uninstall: %w", err)
+	}
+
+	return nil
+}
+
+func (e NewEntry) encode() ([]byte, error) {
+	if len(e.Exec) == 0 || e.Exec[0] == "" {
+		return nil, fmt.Errorf("Exec must not be empty")
+	}
+
+	execValue, err := desktop.NewExecFromArgs(e.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	// OtherKeys of desktop.Entry are written as is
+	otherKeys := make(map[string]string, len(e.OtherKeys))
+	for key, value := range e.OtherKeys {
+		otherKeys[key] = desktop.EscapeString(value)
+	}
+
+	entry := desktop.Entry{
+		Type:       desktop.TypeApplication,
+		Name:       desktop.LocaleString{Default: e.Name},
+		Comment:    desktop.LocaleString{Default: e.Comment},
+		Icon:       desktop.IconString{Default: e.Icon},
+		TryExec:    e.TryExec,
+		Exec:       execValue,
+		Terminal:   e.Terminal,
+		OnlyShowIn: e.OnlyShowIn,
+		NotShowIn:  e.NotShowIn,
+		OtherKeys:  otherKeys,
+	}
+
+	return entry.Encode()
+}
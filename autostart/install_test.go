@@ -0,0 +1,113 @@
+package autostart
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	testenv.Setup(t)
+
+	entry := NewEntry{
+		Name:      "Sync tool",
+		Comment:   "Synchronizes files",
+		Exec:      []string{"/usr/bin/sync-tool", "--config", "/home/user/my config", "100%"},
+		OtherKeys: map[string]string{"X-GNOME-Autostart-Delay": "5"},
+	}
+
+	path, err := Install("sync-tool", entry, ConflictFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPath := filepath.Join(UserDir(), "sync-tool.desktop")
+	if path != expectedPath {
+		t.Errorf("path = %s, expected: %s", path, expectedPath)
+	}
+
+	entries, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got: %d", len(entries))
+	}
+
+	parsed := entries[0].Desktop
+	if parsed.Name.Default != entry.Name || parsed.Comment.Default != entry.Comment {
+		t.Errorf("unexpected Name or Comment: %v, %v", parsed.Name, parsed.Comment)
+	}
+	if parsed.OtherKeys["X-GNOME-Autostart-Delay"] != "5" {
+		t.Errorf("OtherKeys = %v", parsed.OtherKeys)
+	}
+
+	args := parsed.Exec.ToArguments(desktop.FieldCodeProvider{})
+	if len(args) != len(entry.Exec) {
+		t.Fatalf("Exec arguments = %q, expected: %q", args, entry.Exec)
+	}
+	for i := range args {
+		if args[i] != entry.Exec[i] {
+			t.Errorf("Exec arguments = %q, expected: %q", args, entry.Exec)
+		}
+	}
+
+	_, err = Install("sync-tool.desktop", entry, ConflictFail)
+	if !errors.Is(err, ErrEntryExists) {
+		t.Errorf("expected ErrEntryExists, got: %v", err)
+	}
+
+	path, err = Install("sync-tool", entry, ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "sync-tool-2.desktop" {
+		t.Errorf("renamed path = %s, expected: sync-tool-2.desktop", path)
+	}
+
+	entry.Name = "Replaced"
+	_, err = Install("sync-tool", entry, ConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Uninstall("sync-tool-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Uninstall did not remove %s", path)
+	}
+}
+
+func TestInstallInvalid(t *testing.T) {
+	testenv.Setup(t)
+
+	_, err := Install("app", NewEntry{Name: "App"}, ConflictFail)
+	if err == nil {
+		t.Errorf("expected an error for an entry without Exec")
+	}
+
+	_, err = Install("../app", NewEntry{Name: "App", Exec: []string{"app"}}, ConflictFail)
+	if err == nil {
+		t.Errorf("expected an error for an ID containing a separator")
+	}
+
+	for _, key := range []string{"X-Key\nHidden", "X-Key=true\nExec"} {
+		_, err = Install("app", NewEntry{
+			Name:      "App",
+			Exec:      []string{"app"},
+			OtherKeys: map[string]string{key: "true"},
+		}, ConflictFail)
+		if err == nil {
+			t.Errorf("expected an error for the key %q", key)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(UserDir(), "app.desktop")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("failed Install created app.desktop")
+	}
+}
@@ -0,0 +1,178 @@
+package autostart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrNotFound is returned when no autostart entry exists with the given ID.
+var ErrNotFound = errors.New("autostart entry not found")
+
+// State is the effective state of an autostart entry.
+type State int
+
+const (
+	// StateNotFound means there is no autostart entry with the ID.
+	StateNotFound State = iota
+
+	// StateEnabled means the entry is not hidden.
+	StateEnabled
+
+	// StateDisabled means the entry is hidden using Hidden=true.
+	StateDisabled
+)
+
+func (s State) String() string {
+	switch s {
+	case StateNotFound:
+		return "not found"
+	case StateEnabled:
+		return "enabled"
+	case StateDisabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// GetState returns the effective state of the autostart entry with the given ID, determined by
+// the entry with the highest precedence.
+func GetState(id string) (State, error) {
+	id = normalizeID(id)
+	for _, dir := range GetDirs() {
+		path := filepath.Join(dir, id)
+		parsed, err := loadEntry(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			return StateNotFound, fmt.Errorf("GetState: %w", err)
+		case parsed.Hidden:
+			return StateDisabled, nil
+		default:
+			return StateEnabled, nil
+		}
+	}
+
+	return StateNotFound, nil
+}
+
+// Disable disables the autostart entry with the given ID by setting Hidden=true in the user
+// autostart directory, which is the mechanism of the specification.
+// If the entry only exists in a system directory, it is copied to the user directory with
+// Hidden=true added, the system file is left untouched.
+// The effective state after the change is returned.
+func Disable(id string) (State, error) {
+	err := setHidden(normalizeID(id), true)
+	if err != nil {
+		return StateNotFound, fmt.Errorf("Disable: %w", err)
+	}
+
+	return GetState(id)
+}
+
+// Enable re-enables an autostart entry that was disabled using Disable.
+// If the user autostart directory contains a hidden override of a system entry, the override is
+// removed. If the user entry has no system counterpart, Hidden is removed from it.
+// The effective state after the change is returned. This can still be StateDisabled if the
+// system entry itself is hidden.
+func Enable(id string) (State, error) {
+	id = normalizeID(id)
+	userPath := filepath.Join(UserDir(), id)
+	userEntry, err := loadEntry(userPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return GetState(id)
+	case err != nil:
+		return StateNotFound, fmt.Errorf("Enable: %w", err)
+	case !userEntry.Hidden:
+		return StateEnabled, nil
+	}
+
+	if findSystemPath(id) != "" {
+		err = os.Remove(userPath)
+	} else {
+		err = setHidden(id, false)
+	}
+	if err != nil {
+		return StateNotFound, fmt.Errorf("Enable: %w", err)
+	}
+
+	return GetState(id)
+}
+
+// setHidden sets or removes Hidden=true in the user entry with the given ID. If there is no user
+// entry, the system entry is copied first.
+func setHidden(id string, hidden bool) error {
+	userPath := filepath.Join(UserDir(), id)
+	content, err := os.ReadFile(userPath)
+	if errors.Is(err, os.ErrNotExist) {
+		systemPath := findSystemPath(id)
+		if systemPath == "" {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		content, err = os.ReadFile(systemPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := withHidden(content, hidden)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", id, err)
+	}
+
+	return fileutil.WriteFileAtomic(userPath, updated, 0644)
+}
+
+// findSystemPath returns the path of the entry in the system autostart directories with the
+// highest precedence or an empty string if there is none.
+func findSystemPath(id string) string {
+	for _, dir := range GetDirs()[1:] {
+		path := filepath.Join(dir, id)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// withHidden returns the desktop file content with the Hidden key of the Desktop Entry group
+// replaced. If hidden is false, the key is removed. Other lines are preserved.
+func withHidden(content []byte, hidden bool) ([]byte, error) {
+	doc, err := desktop.ParseDocument(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(doc.Groups(), "Desktop Entry") {
+		return nil, fmt.Errorf("missing [Desktop Entry] group")
+	}
+
+	if hidden {
+		err = doc.SetBoolean("Desktop Entry", "Hidden", true)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		doc.Remove("Desktop Entry", "Hidden")
+	}
+
+	return doc.Bytes(), nil
+}
+
+func normalizeID(id string) string {
+	if !strings.HasSuffix(id, ".desktop") {
+		return id + ".desktop"
+	}
+
+	return id
+}
@@ -0,0 +1,88 @@
+package autostart
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisableEnableSystemEntry(t *testing.T) {
+	home := testenv.Setup(t)
+	systemDir := filepath.Join(home, "etc/xdg/autostart")
+	createEntry(t, systemDir, "applet.desktop", "# keep this comment\nX-Custom=1\n")
+
+	state, err := Disable("applet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateDisabled {
+		t.Errorf("state after Disable = %s, expected: %s", state, StateDisabled)
+	}
+
+	content, err := os.ReadFile(filepath.Join(UserDir(), "applet.desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "# keep this comment\nX-Custom=1\n") {
+		t.Errorf("override lost content of the system entry:\n%s", content)
+	}
+
+	state, err = Enable("applet.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateEnabled {
+		t.Errorf("state after Enable = %s, expected: %s", state, StateEnabled)
+	}
+
+	_, err = os.Stat(filepath.Join(UserDir(), "applet.desktop"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Enable did not remove the override")
+	}
+}
+
+func TestDisableEnableUserEntry(t *testing.T) {
+	testenv.Setup(t)
+	createEntry(t, UserDir(), "mine.desktop", "Hidden=false\n")
+
+	state, err := Disable("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateDisabled {
+		t.Errorf("state after Disable = %s, expected: %s", state, StateDisabled)
+	}
+
+	state, err = Enable("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != StateEnabled {
+		t.Errorf("state after Enable = %s, expected: %s", state, StateEnabled)
+	}
+
+	content, err := os.ReadFile(filepath.Join(UserDir(), "mine.desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "Hidden") {
+		t.Errorf("Hidden was not removed:\n%s", content)
+	}
+}
+
+func TestDisableNotFound(t *testing.T) {
+	testenv.Setup(t)
+
+	_, err := Disable("missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+
+	state, err := GetState("missing")
+	if err != nil || state != StateNotFound {
+		t.Errorf("GetState = %s, %v, expected: %s", state, err, StateNotFound)
+	}
+}
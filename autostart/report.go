@@ -0,0 +1,91 @@
+package autostart
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Report describes an autostart ID as needed by a "Startup Applications" settings UI.
+type Report struct {
+	ID string
+
+	// System is the entry with the highest precedence in the system autostart directories,
+	// nil if there is none.
+	System *Entry
+
+	// User is the entry in the user autostart directory, nil if there is none.
+	User *Entry
+
+	// State is the effective state, determined by User if present and System otherwise.
+	State State
+
+	// Reason is why the effective entry would not be started, NotExcluded if it would be.
+	Reason ExclusionReason
+}
+
+// Effective returns the entry that applies: User if present, System otherwise.
+func (r Report) Effective() *Entry {
+	if r.User != nil {
+		return r.User
+	}
+
+	return r.System
+}
+
+// IsOverride returns true if the user entry overrides a system entry.
+func (r Report) IsOverride() bool {
+	return r.User != nil && r.System != nil
+}
+
+// GetReport returns a report for every autostart ID in the user and system autostart
+// directories, sorted by ID. The exclusion reasons are determined using opts.
+func GetReport(opts FilterOptions) ([]Report, error) {
+	dirs := GetDirs()
+	userEntries, err := Load(dirs[:1])
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	systemEntries, err := Load(dirs[1:])
+	if err != nil {
+		return nil, fmt.Errorf("GetReport: %w", err)
+	}
+
+	reports := make(map[string]*Report)
+	get := func(id string) *Report {
+		if reports[id] == nil {
+			reports[id] = &Report{ID: id}
+		}
+		return reports[id]
+	}
+
+	for i := range systemEntries {
+		get(systemEntries[i].ID).System = &systemEntries[i]
+	}
+	for i := range userEntries {
+		get(userEntries[i].ID).User = &userEntries[i]
+	}
+
+	result := make([]Report, 0, len(reports))
+	for _, report := range reports {
+		effective := report.Effective()
+		report.State = StateEnabled
+		if effective.Desktop.Hidden {
+			report.State = StateDisabled
+		}
+
+		report.Reason = effective.ExclusionReason(opts.Desktops)
+		if report.Reason == NotExcluded && !meetsConditions(*effective, opts.Conditions) {
+			report.Reason = ExcludedByCondition
+		}
+
+		result = append(result, *report)
+	}
+
+	slices.SortFunc(result, func(a, b Report) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package autostart
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetReport(t *testing.T) {
+	home := testenv.Setup(t)
+	systemDir := filepath.Join(home, "etc/xdg/autostart")
+	createEntry(t, systemDir, "applet.desktop", "")
+	createEntry(t, systemDir, "tracker.desktop", "")
+	createEntry(t, UserDir(), "tracker.desktop", "Hidden=true\n")
+	createEntry(t, UserDir(), "mine.desktop", "X-GNOME-Autostart-enabled=false\n")
+	createEntry(t, systemDir, "updater.desktop", "")
+	testenv.WriteFile(
+		t,
+		filepath.Join(UserDir(), "updater.desktop"),
+		"[Desktop Entry]\nHidden=true\n",
+	)
+
+	reports, err := GetReport(FilterOptions{Conditions: DefaultConditions})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		id         string
+		hasSystem  bool
+		hasUser    bool
+		isOverride bool
+		state      State
+		reason     ExclusionReason
+	}{
+		{"applet.desktop", true, false, false, StateEnabled, NotExcluded},
+		{"mine.desktop", false, true, false, StateEnabled, ExcludedByCondition},
+		{"tracker.desktop", true, true, true, StateDisabled, ExcludedHidden},
+		{"updater.desktop", true, true, true, StateDisabled, ExcludedHidden},
+	}
+
+	if len(reports) != len(expected) {
+		t.Fatalf("got %d reports, expected: %d", len(reports), len(expected))
+	}
+
+	for i, e := range expected {
+		r := reports[i]
+		if r.ID != e.id ||
+			(r.System != nil) != e.hasSystem ||
+			(r.User != nil) != e.hasUser ||
+			r.IsOverride() != e.isOverride ||
+			r.State != e.state ||
+			r.Reason != e.reason {
+			t.Errorf("report %d = %+v, expected: %+v", i, r, e)
+		}
+	}
+
+	if reports[2].Effective() != reports[2].User {
+		t.Errorf("Effective did not return the user entry")
+	}
+}
@@ -0,0 +1,124 @@
+package autostart
+
+import (
+	"context"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/activation"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/terminal"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoTerminal is returned for entries with Terminal=true when no terminal emulator is found.
+var ErrNoTerminal = desktop.ErrNoTerminal
+
+// RunOptions configure Run.
+type RunOptions struct {
+	// Terminal is the command used to run entries with Terminal=true, the command of the entry is
+	// appended, e.g. []string{"xterm", "-e"}. If empty, the preferred terminal emulator is used,
+	// see terminal.Preferred.
+	Terminal []string
+
+	// TokenProvider obtains an activation token for entries with StartupNotify=true, e.g.
+	// activation.Default(). If nil, no token is passed.
+	TokenProvider activation.TokenProvider
+
+	// Activate is used to start entries with DBusActivatable=true. If nil, these entries are
+	// started using Entry.Launch, which falls back to their Exec key if D-Bus activation fails.
+	Activate func(ctx context.Context, entry Entry) error
+}
+
+// Result is the outcome of starting an autostart entry.
+type Result struct {
+	Entry Entry
+
+	// Process is the started process. It is nil if the entry was activated using D-Bus or
+	// failed to start.
+	Process *os.Process
+
+	// Err is the reason the entry could not be started.
+	Err error
+}
+
+// RunEligible starts the Eligible entries using Run.
+func RunEligible(ctx context.Context, opts RunOptions) ([]Result, error) {
+	entries, err := Eligible()
+	if err != nil {
+		return nil, fmt.Errorf("RunEligible: %w", err)
+	}
+
+	return Run(ctx, entries, opts), nil
+}
+
+// Run starts the entries, waiting for the delay of X-GNOME-Autostart-Delay where set, and
+// returns the result of every entry once all entries have been started. The entries are not
+// filtered, see Eligible. Each entry is started using desktop.Entry.Launch.
+//
+// When ctx is done, entries that have not been started yet fail with the error of ctx and the
+// started processes are killed. The processes are reaped in the background.
+func Run(ctx context.Context, entries []Entry, opts RunOptions) []Result {
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		results[i].Entry = entry
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if delay := entry.Delay(); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					results[i].Err = ctx.Err()
+					return
+				case <-timer.C:
+				}
+			}
+
+			results[i].Process, results[i].Err = start(ctx, entry, opts)
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func start(ctx context.Context, entry Entry, opts RunOptions) (*os.Process, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if entry.Desktop.DBusActivatable && opts.Activate != nil {
+		err := opts.Activate(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate %s: %w", entry.ID, err)
+		}
+		return nil, nil
+	}
+
+	cmd, err := entry.Desktop.Launch(ctx, desktop.LaunchOptions{
+		DesktopId:           entry.ID,
+		DesktopFileLocation: entry.Path,
+		Terminal:            opts.Terminal,
+		ResolveTerminal:     terminal.Resolver(terminal.Options{}),
+		TokenProvider:       opts.TokenProvider,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", entry.ID, err)
+	}
+
+	if cmd == nil {
+		return nil, nil
+	}
+
+	context.AfterFunc(ctx, func() {
+		cmd.Process.Kill()
+	})
+
+	return cmd.Process, nil
+}
@@ -0,0 +1,111 @@
+package autostart
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	home := testenv.Setup(t)
+	script := filepath.Join(home, "touch")
+	testenv.WriteExecutable(t, script, "#!/bin/sh\ntouch \"$1\"\n")
+
+	dir := filepath.Join(home, "autostart")
+	testenv.WriteFile(t, filepath.Join(dir, "now.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Now\nExec="+script+" "+home+"/now\n"+
+			"StartupNotify=true\n")
+	testenv.WriteFile(t, filepath.Join(dir, "delayed.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Delayed\nExec="+script+" "+home+"/delayed\n"+
+			"X-GNOME-Autostart-Delay=0.05\n")
+	testenv.WriteFile(t, filepath.Join(dir, "terminal.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Term\nExec=top\nTerminal=true\n")
+	testenv.WriteFile(t, filepath.Join(dir, "dbus.desktop"),
+		"[Desktop Entry]\nType=Application\nName=DBus\nDBusActivatable=true\n")
+
+	entries, err := Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var activated []string
+	tokenRequests := make(chan string, len(entries))
+	results := Run(context.Background(), entries, RunOptions{
+		Terminal: []string{script, filepath.Join(home, "terminal")},
+		TokenProvider: func(ctx context.Context, appId string) (string, error) {
+			tokenRequests <- appId
+			return "token", nil
+		},
+		Activate: func(ctx context.Context, entry Entry) error {
+			activated = append(activated, entry.ID)
+			return nil
+		},
+	})
+
+	for _, result := range results {
+		switch result.Entry.ID {
+		case "now.desktop", "delayed.desktop", "terminal.desktop":
+			if result.Err != nil || result.Process == nil {
+				t.Errorf("%s: Process = %v, Err = %v", result.Entry.ID, result.Process, result.Err)
+			}
+		case "dbus.desktop":
+			if result.Err != nil || result.Process != nil {
+				t.Errorf("dbus.desktop: Process = %v, Err = %v", result.Process, result.Err)
+			}
+		}
+	}
+
+	if len(activated) != 1 || activated[0] != "dbus.desktop" {
+		t.Errorf("activated = %v, expected: [dbus.desktop]", activated)
+	}
+
+	close(tokenRequests)
+	var requested []string
+	for appId := range tokenRequests {
+		requested = append(requested, appId)
+	}
+	if len(requested) != 1 || requested[0] != "now" {
+		t.Errorf("requested activation tokens for %v, expected: [now]", requested)
+	}
+
+	for _, name := range []string{"now", "delayed", "terminal"} {
+		path := filepath.Join(home, name)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := os.Stat(path); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("%s was not started", name)
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := filepath.Join(home, "autostart")
+	createEntry(t, dir, "delayed.desktop", "X-GNOME-Autostart-Delay=60\n")
+
+	entries, err := Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	results := Run(ctx, entries, RunOptions{})
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", results[0].Err)
+	}
+}
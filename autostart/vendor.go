@@ -0,0 +1,210 @@
+package autostart
+
+import (
+	"bufio"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Vendor keys that extend the autostart specification.
+const (
+	KeyGNOMEDelay         = "X-GNOME-Autostart-Delay"
+	KeyGNOMEEnabled       = "X-GNOME-Autostart-enabled"
+	KeyKDECondition       = "X-KDE-autostart-condition"
+	KeyAutostartCondition = "AutostartCondition"
+)
+
+// KDECondition is the value of X-KDE-autostart-condition: the entry is only started if the
+// boolean Key of Group in the KConfig file File is true.
+type KDECondition struct {
+	// File is the name of the configuration file, relative to the XDG config dirs, e.g.
+	// kdedrc.
+	File    string
+	Group   string
+	Key     string
+	Default bool
+}
+
+// Condition is the value of the AutostartCondition key as used by GNOME, e.g.
+// "unless-exists gnome-initial-setup-done" results in
+// Condition{Kind: "unless-exists", Args: ["gnome-initial-setup-done"]}.
+type Condition struct {
+	Kind string
+	Args []string
+}
+
+// Delay returns the delay of X-GNOME-Autostart-Delay, in seconds in the file, or zero if the
+// key is absent or invalid.
+func (e Entry) Delay() time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(e.Desktop.OtherKeys[KeyGNOMEDelay]), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// GNOMEEnabled returns the value of X-GNOME-Autostart-enabled and whether the key is present
+// with a valid boolean.
+func (e Entry) GNOMEEnabled() (bool, bool) {
+	switch e.Desktop.OtherKeys[KeyGNOMEEnabled] {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// KDECondition returns the parsed X-KDE-autostart-condition key, which has the format
+// file:group:key:default.
+func (e Entry) KDECondition() (KDECondition, bool) {
+	value, found := e.Desktop.OtherKeys[KeyKDECondition]
+	if !found {
+		return KDECondition{}, false
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 || parts[0] == "" || parts[2] == "" {
+		return KDECondition{}, false
+	}
+
+	return KDECondition{
+		File:    parts[0],
+		Group:   parts[1],
+		Key:     parts[2],
+		Default: parseKDEBool(parts[3], false),
+	}, true
+}
+
+// AutostartCondition returns the parsed AutostartCondition key.
+func (e Entry) AutostartCondition() (Condition, bool) {
+	fields := strings.Fields(e.Desktop.OtherKeys[KeyAutostartCondition])
+	if len(fields) == 0 {
+		return Condition{}, false
+	}
+
+	return Condition{
+		Kind: fields[0],
+		Args: fields[1:],
+	}, true
+}
+
+// ConditionEvaluator decides whether an entry may be started. Returning false excludes the
+// entry with ExcludedByCondition.
+type ConditionEvaluator func(entry Entry) bool
+
+// DefaultConditions are the condition evaluators used by Eligible.
+var DefaultConditions = []ConditionEvaluator{
+	EvaluateGNOMEEnabled,
+	EvaluateAutostartCondition,
+	EvaluateKDECondition,
+}
+
+// EvaluateGNOMEEnabled excludes entries with X-GNOME-Autostart-enabled=false.
+func EvaluateGNOMEEnabled(entry Entry) bool {
+	enabled, set := entry.GNOMEEnabled()
+	return enabled || !set
+}
+
+// EvaluateAutostartCondition evaluates the if-exists and unless-exists conditions of the
+// AutostartCondition key. Their argument is a path relative to $XDG_CONFIG_HOME.
+// Other conditions, such as those based on GSettings, cannot be evaluated and do not exclude
+// the entry.
+func EvaluateAutostartCondition(entry Entry) bool {
+	condition, found := entry.AutostartCondition()
+	if !found || len(condition.Args) != 1 {
+		return true
+	}
+
+	switch condition.Kind {
+	case "if-exists":
+		return configFileExists(condition.Args[0])
+	case "unless-exists":
+		return !configFileExists(condition.Args[0])
+	default:
+		return true
+	}
+}
+
+func configFileExists(name string) bool {
+	_, err := os.Stat(filepath.Join(basedir.ConfigHome, name))
+	return err == nil
+}
+
+// EvaluateKDECondition evaluates X-KDE-autostart-condition by reading the boolean from the
+// KConfig file, which is searched for in $XDG_CONFIG_HOME and $XDG_CONFIG_DIRS. If the key is
+// not set, the default of the condition is used.
+func EvaluateKDECondition(entry Entry) bool {
+	condition, found := entry.KDECondition()
+	if !found {
+		return true
+	}
+
+	dirs := append([]string{basedir.ConfigHome}, basedir.ConfigDirs...)
+	for _, dir := range dirs {
+		value, found, err := readKConfigValue(
+			filepath.Join(dir, condition.File),
+			condition.Group,
+			condition.Key,
+		)
+		if err != nil || !found {
+			continue
+		}
+
+		return parseKDEBool(value, condition.Default)
+	}
+
+	return condition.Default
+}
+
+// readKConfigValue reads the value of key in group of the KConfig file at path.
+func readKConfigValue(path string, group string, key string) (string, bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	currentGroup := ""
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			currentGroup = line[1 : len(line)-1]
+			continue
+		case currentGroup != group:
+			continue
+		}
+
+		k, v, found := strings.Cut(line, "=")
+		if found && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v), true, nil
+		}
+	}
+
+	return "", false, sc.Err()
+}
+
+func parseKDEBool(value string, fallback bool) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "on", "yes", "1":
+		return true
+	case "false", "off", "no", "0":
+		return false
+	default:
+		return fallback
+	}
+}
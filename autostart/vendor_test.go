@@ -0,0 +1,79 @@
+package autostart
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVendorAccessors(t *testing.T) {
+	testenv.Setup(t)
+	createEntry(t, UserDir(), "a.desktop", "X-GNOME-Autostart-Delay=2.5\n"+
+		"X-GNOME-Autostart-enabled=false\n"+
+		"X-KDE-autostart-condition=testrc:General:Enabled:true\n"+
+		"AutostartCondition=unless-exists some-app/done\n")
+
+	entries, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := entries[0]
+
+	if delay := entry.Delay(); delay != 2500*time.Millisecond {
+		t.Errorf("Delay = %v, expected: 2.5s", delay)
+	}
+
+	if enabled, set := entry.GNOMEEnabled(); enabled || !set {
+		t.Errorf("GNOMEEnabled = %v, %v, expected: false, true", enabled, set)
+	}
+
+	kde, found := entry.KDECondition()
+	expectedKDE := KDECondition{File: "testrc", Group: "General", Key: "Enabled", Default: true}
+	if !found || kde != expectedKDE {
+		t.Errorf("KDECondition = %+v, expected: %+v", kde, expectedKDE)
+	}
+
+	condition, found := entry.AutostartCondition()
+	if !found || condition.Kind != "unless-exists" ||
+		!slices.Equal(condition.Args, []string{"some-app/done"}) {
+		t.Errorf("AutostartCondition = %+v", condition)
+	}
+}
+
+func TestFilterWithConditions(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := filepath.Join(home, "autostart")
+	createEntry(t, dir, "plain.desktop", "")
+	createEntry(t, dir, "gnome-disabled.desktop", "X-GNOME-Autostart-enabled=false\n")
+	createEntry(t, dir, "setup.desktop", "AutostartCondition=unless-exists setup-done\n")
+	createEntry(t, dir, "kde-off.desktop", "X-KDE-autostart-condition=testrc:General:Run:true\n")
+	createEntry(t, dir, "kde-default.desktop", "X-KDE-autostart-condition=otherrc:General:Run:true\n")
+	testenv.WriteFile(t, filepath.Join(home, ".config/setup-done"), "")
+	testenv.WriteFile(t, filepath.Join(home, "etc/xdg/testrc"), "[General]\nRun=false\n")
+
+	entries, err := Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eligible, _ := Filter(entries, nil)
+	if len(eligible) != len(entries) {
+		t.Errorf("Filter applied vendor conditions")
+	}
+
+	eligible, excluded := FilterWithOptions(entries, FilterOptions{Conditions: DefaultConditions})
+	ids := entryIDs(eligible)
+	slices.Sort(ids)
+	expected := []string{"kde-default.desktop", "plain.desktop"}
+	if !slices.Equal(ids, expected) {
+		t.Errorf("eligible = %v, expected: %v", ids, expected)
+	}
+
+	for _, e := range excluded {
+		if e.Reason != ExcludedByCondition {
+			t.Errorf("%s excluded for %s, expected: %s", e.Entry.ID, e.Reason, ExcludedByCondition)
+		}
+	}
+}
@@ -70,6 +70,7 @@ func Reinit() {
 	Home = home
 	LocalBin = filepath.Join(home, ".local/bin")
 	RuntimeDir = singleVar("XDG_RUNTIME_DIR", "")
+	Sandbox = detectSandbox()
 	StateHome = singleVar("XDG_STATE_HOME", filepath.Join(home, ".local/state"))
 }
 
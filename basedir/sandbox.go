@@ -0,0 +1,59 @@
+package basedir
+
+import (
+	"os"
+)
+
+// SandboxKind identifies the application sandbox the process runs in.
+type SandboxKind int
+
+const (
+	// SandboxNone means the process does not run in a known sandbox.
+	SandboxNone SandboxKind = iota
+
+	// SandboxFlatpak means the process runs in a Flatpak sandbox.
+	SandboxFlatpak
+
+	// SandboxSnap means the process runs in a Snap sandbox.
+	SandboxSnap
+)
+
+func (s SandboxKind) String() string {
+	switch s {
+	case SandboxFlatpak:
+		return "flatpak"
+	case SandboxSnap:
+		return "snap"
+	default:
+		return "none"
+	}
+}
+
+// Sandbox is the sandbox the process runs in. Sandboxed processes should use portals to
+// interact with the host, e.g. to open files, rather than spawning host applications.
+// Flatpak is detected using /.flatpak-info or $FLATPAK_ID, Snap using $SNAP_NAME.
+var Sandbox SandboxKind
+
+// flatpakInfoPath is the file that Flatpak creates in the root of the sandbox.
+const flatpakInfoPath = "/.flatpak-info"
+
+func detectSandbox() SandboxKind {
+	if os.Getenv("FLATPAK_ID") != "" {
+		return SandboxFlatpak
+	}
+
+	if _, err := os.Stat(flatpakInfoPath); err == nil {
+		return SandboxFlatpak
+	}
+
+	if os.Getenv("SNAP_NAME") != "" {
+		return SandboxSnap
+	}
+
+	return SandboxNone
+}
+
+// InSandbox reports whether the process runs in a sandbox such as Flatpak or Snap.
+func InSandbox() bool {
+	return Sandbox != SandboxNone
+}
@@ -0,0 +1,53 @@
+package basedir
+
+import (
+	"context"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/watch"
+	"os"
+)
+
+// Watch reports the paths of entries that are created, removed, renamed, or modified in the
+// given directories, e.g. the applications subdirectory of DataHome and DataDirs. Directories
+// that do not exist are skipped, directories are not watched recursively.
+//
+// The returned channel is closed when ctx is done.
+func Watch(ctx context.Context, dirs []string) (<-chan string, error) {
+	var existing []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		}
+	}
+
+	watcher, err := watch.New(existing)
+	if err != nil {
+		return nil, fmt.Errorf("Watch: %w", err)
+	}
+
+	result := make(chan string)
+
+	go func() {
+		defer close(result)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case changed, ok := <-watcher.C:
+				if !ok {
+					return
+				}
+
+				select {
+				case result <- changed:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return result, nil
+}
@@ -0,0 +1,198 @@
+// Command xdg-mime queries file types and default applications, and sets the default
+// application of MIME types. It implements the query and default modes of xdg-mime of
+// xdg-utils, with the same arguments and exit codes.
+//
+// Usage:
+//
+//	xdg-mime query filetype FILE
+//	xdg-mime query default mimetype
+//	xdg-mime default application mimetype(s)
+//	xdg-mime { --help | --manual | --version }
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/mimeapps"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"io"
+	"io/fs"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// Exit codes of xdg-utils.
+const (
+	exitSuccess         = 0
+	exitSyntax          = 1
+	exitFileNotFound    = 2
+	exitOperationFailed = 4
+)
+
+const usage = `xdg-mime - command line tool for querying information about file type handling
+and adding descriptions for new file types
+
+Synopsis
+
+xdg-mime query { filetype | default } ...
+
+xdg-mime default application mimetype(s)
+
+xdg-mime { --help | --manual | --version }
+
+Use 'man xdg-mime' or 'xdg-mime --manual' for additional info.
+`
+
+const manual = `xdg-mime queries information about file type handling and sets the default
+application of file types.
+
+Commands
+
+query filetype FILE
+    Returns the file type of FILE in the form of a MIME type.
+query default mimetype
+    Returns the default application that the desktop environment uses for the specified
+    mimetype. The default application is identified by its *.desktop file.
+default application mimetype(s)
+    Ask the desktop environment to make application the default application for opening files
+    of type mimetype. An application can be made the default for several file types by
+    specifying multiple mimetypes. application is the desktop file id of the application.
+
+Exit Codes
+
+An exit code of 0 indicates success while a non-zero exit code indicates failure. The following
+failure codes can be returned:
+
+1   Error in command line syntax.
+2   One of the files passed on the command line did not exist.
+4   The action failed.
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		return syntaxError(stderr, "mode argument missing")
+	}
+
+	switch args[0] {
+	case "--help":
+		fmt.Fprint(stdout, usage)
+		return exitSuccess
+	case "--manual":
+		fmt.Fprint(stdout, manual)
+		return exitSuccess
+	case "--version":
+		fmt.Fprintf(stdout, "xdg-mime %s\n", version())
+		return exitSuccess
+	case "query":
+		if len(args) < 2 {
+			return syntaxError(stderr, "query type argument missing")
+		}
+
+		switch args[1] {
+		case "filetype":
+			if len(args) != 3 {
+				return syntaxError(stderr, "FILE argument missing")
+			}
+			return queryFiletype(ctx, args[2], stdout, stderr)
+		case "default":
+			if len(args) != 3 {
+				return syntaxError(stderr, "mimetype argument missing")
+			}
+			return queryDefault(ctx, args[2], stdout, stderr)
+		default:
+			return syntaxError(stderr, fmt.Sprintf("unknown query type '%s'", args[1]))
+		}
+	case "default":
+		if len(args) < 3 {
+			return syntaxError(stderr, "application or mimetype argument missing")
+		}
+		if !strings.HasSuffix(args[1], ".desktop") {
+			return syntaxError(stderr, "malformed argument '"+args[1]+"', expecting a *.desktop")
+		}
+		return setDefault(args[1], args[2:], stderr)
+	default:
+		return syntaxError(stderr, fmt.Sprintf("unknown mode '%s'", args[0]))
+	}
+}
+
+func queryFiletype(ctx context.Context, path string, stdout io.Writer, stderr io.Writer) int {
+	db, err := sharedmimeinfo.LoadContext(ctx, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "xdg-mime: %v\n", err)
+		return exitOperationFailed
+	}
+
+	mimeType, err := db.TypeByFileContext(ctx, path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		fmt.Fprintf(stderr, "xdg-mime: file '%s' does not exist\n", path)
+		return exitFileNotFound
+	case err != nil:
+		fmt.Fprintf(stderr, "xdg-mime: %v\n", err)
+		return exitOperationFailed
+	}
+
+	fmt.Fprintln(stdout, mimeType)
+	return exitSuccess
+}
+
+// queryDefault prints the preferred application of the MIME type, or nothing if there is none.
+func queryDefault(ctx context.Context, mimeType string, stdout io.Writer, stderr io.Writer) int {
+	idPathMap, err := desktop.GetDesktopFilesContext(ctx, desktop.GetDesktopFileLocations())
+	if err != nil {
+		fmt.Fprintf(stderr, "xdg-mime: %v\n", err)
+		return exitOperationFailed
+	}
+
+	currentDesktop, _, _ := strings.Cut(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	preferred, err := mimeapps.GetPreferredApplicationsContext(
+		ctx,
+		mimeapps.GetLists(currentDesktop),
+		idPathMap,
+	)
+	if err != nil {
+		fmt.Fprintf(stderr, "xdg-mime: %v\n", err)
+		return exitOperationFailed
+	}
+
+	if apps := preferred[mimeType]; len(apps) > 0 {
+		fmt.Fprintln(stdout, apps[0])
+	}
+
+	return exitSuccess
+}
+
+// setDefault makes the desktop ID the default application of the MIME types, see
+// mimeapps.SetDefault.
+func setDefault(desktopId string, mimeTypes []string, stderr io.Writer) int {
+	for _, mimeType := range mimeTypes {
+		err := mimeapps.SetDefault(mimeType, desktopId)
+		if err != nil {
+			fmt.Fprintf(stderr, "xdg-mime: %v\n", err)
+			return exitOperationFailed
+		}
+	}
+
+	return exitSuccess
+}
+
+func syntaxError(stderr io.Writer, message string) int {
+	fmt.Fprintf(stderr, "xdg-mime: %s\nTry 'xdg-mime --help' for more information.\n", message)
+	return exitSyntax
+}
+
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(devel)"
+	}
+
+	return info.Main.Version
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/MatthiasKunnen/xdg/mimeapps"
+	"github.com/MatthiasKunnen/xdg/xdgtest"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	env := xdgtest.New(t, xdgtest.Fixture{
+		Applications: map[string]xdgtest.Application{
+			"editor.desktop": {MimeTypes: []string{"text/plain"}},
+			"viewer.desktop": {MimeTypes: []string{"text/plain"}},
+		},
+		MimeTypes: []xdgtest.MimeType{{Type: "text/x-csrc", Globs: []string{"*.c"}}},
+		Files:     map[string]string{"home/main.c": "int main() {}\n"},
+	})
+	ctx := context.Background()
+	file := filepath.Join(env.Home, "main.c")
+
+	err := mimeapps.RemoveAssociation("text/plain", "viewer.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args     []string
+		code     int
+		expected string
+	}{
+		{[]string{"query", "filetype", file}, exitSuccess, "text/x-csrc\n"},
+		{[]string{"query", "filetype", filepath.Join(env.Home, "missing")}, exitFileNotFound, ""},
+		{[]string{"query", "default", "text/plain"}, exitSuccess, "editor.desktop\n"},
+		{[]string{"query", "default", "image/png"}, exitSuccess, ""},
+		{[]string{"default", "viewer.desktop", "text/plain", "text/x-csrc"}, exitSuccess, ""},
+		{[]string{"query", "default", "text/plain"}, exitSuccess, "viewer.desktop\n"},
+		{[]string{"query", "default", "text/x-csrc"}, exitSuccess, "viewer.desktop\n"},
+		{[]string{"default", "viewer", "text/plain"}, exitSyntax, ""},
+		{[]string{"default", "viewer.desktop", "x]y"}, exitOperationFailed, ""},
+		{[]string{"install", "foo.xml"}, exitSyntax, ""},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(ctx, test.args, &stdout, &stderr)
+		if code != test.code || stdout.String() != test.expected {
+			t.Errorf(
+				"run(%v) = %d, %q, expected: %d, %q. Stderr: %s",
+				test.args,
+				code,
+				stdout.String(),
+				test.code,
+				test.expected,
+				&stderr,
+			)
+		}
+	}
+
+	list, err := mimeapps.ParseFile(filepath.Join(env.ConfigHome, "mimeapps.list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults := list.Default["text/x-csrc"]; !slices.Equal(defaults, []string{"viewer.desktop"}) {
+		t.Errorf("default of text/x-csrc = %v, expected: [viewer.desktop]", defaults)
+	}
+	if removed := list.Removed["text/plain"]; len(removed) != 0 {
+		t.Errorf("removed associations of text/plain = %v, expected: []", removed)
+	}
+	if _, found := list.Default["x]y"]; found {
+		t.Errorf("default of invalid MIME type x]y was written")
+	}
+}
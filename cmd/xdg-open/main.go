@@ -0,0 +1,148 @@
+// Command xdg-open opens a file or URL in the preferred application of the user. It is a
+// drop-in replacement for xdg-open of xdg-utils, supporting the same arguments and exit codes.
+//
+// Usage:
+//
+//	xdg-open { file | URL }
+//	xdg-open { --help | --manual | --version }
+//
+// Diagnostics are printed to stderr if $XDG_UTILS_DEBUG_LEVEL is set to a positive number.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg"
+	"github.com/MatthiasKunnen/xdg/activation"
+	"github.com/MatthiasKunnen/xdg/open"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of xdg-utils.
+const (
+	exitSuccess         = 0
+	exitSyntax          = 1
+	exitFileNotFound    = 2
+	exitToolNotFound    = 3
+	exitOperationFailed = 4
+)
+
+const usage = `xdg-open - opens a file or URL in the user's preferred application
+
+Synopsis
+
+xdg-open { file | URL }
+
+xdg-open { --help | --manual | --version }
+
+Use 'man xdg-open' or 'xdg-open --manual' for additional info.
+`
+
+const manual = `xdg-open opens a file or URL in the user's preferred application. If a URL is
+provided the URL will be opened in the user's preferred web browser. If a file is provided the
+file will be opened in the preferred application for files of that type. xdg-open supports file,
+ftp, http and https URLs, as well as any other scheme with a registered
+x-scheme-handler/<scheme> application.
+
+xdg-open is for use inside a desktop session only.
+
+Options
+
+--help
+    Show command synopsis.
+--manual
+    Show this manual page.
+--version
+    Show the xdg-utils version information.
+
+Exit Codes
+
+An exit code of 0 indicates success while a non-zero exit code indicates failure. The following
+failure codes can be returned:
+
+1   Error in command line syntax.
+2   One of the files passed on the command line did not exist.
+3   A required tool could not be found.
+4   The action failed.
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	var target string
+	for _, arg := range args {
+		switch {
+		case arg == "--help":
+			fmt.Fprint(stdout, usage)
+			return exitSuccess
+		case arg == "--manual":
+			fmt.Fprint(stdout, manual)
+			return exitSuccess
+		case arg == "--version":
+			fmt.Fprintf(stdout, "xdg-open %s\n", version())
+			return exitSuccess
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			return syntaxError(stderr, fmt.Sprintf("unexpected option '%s'", arg))
+		case target != "":
+			return syntaxError(stderr, fmt.Sprintf("unexpected argument '%s'", arg))
+		default:
+			target = arg
+		}
+	}
+
+	if target == "" {
+		return syntaxError(stderr, "file or URL argument missing")
+	}
+
+	if level, _ := strconv.Atoi(os.Getenv("XDG_UTILS_DEBUG_LEVEL")); level > 0 {
+		xdg.SetLogger(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})))
+	}
+
+	err := open.Open(ctx, target, open.Options{
+		TokenProvider: activation.FromEnvironment(),
+	})
+
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.Is(err, fs.ErrNotExist):
+		fmt.Fprintf(stderr, "xdg-open: file '%s' does not exist\n", target)
+		return exitFileNotFound
+	case errors.Is(err, open.ErrNoHandler):
+		var noHandler *open.NoHandlerError
+		if errors.As(err, &noHandler) && len(noHandler.LaunchErrors) > 0 {
+			fmt.Fprintf(stderr, "xdg-open: %v\n", err)
+			return exitOperationFailed
+		}
+		fmt.Fprintf(stderr, "xdg-open: no method available for opening '%s'\n", target)
+		return exitToolNotFound
+	default:
+		fmt.Fprintf(stderr, "xdg-open: %v\n", err)
+		return exitOperationFailed
+	}
+}
+
+func syntaxError(stderr io.Writer, message string) int {
+	fmt.Fprintf(stderr, "xdg-open: %s\nTry 'xdg-open --help' for more information.\n", message)
+	return exitSyntax
+}
+
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(devel)"
+	}
+
+	return info.Main.Version
+}
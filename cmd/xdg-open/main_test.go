@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/MatthiasKunnen/xdg/xdgtest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSyntax(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{nil, exitSyntax},
+		{[]string{"--help"}, exitSuccess},
+		{[]string{"--unknown"}, exitSyntax},
+		{[]string{"a", "b"}, exitSyntax},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), test.args, &stdout, &stderr)
+		if code != test.expected {
+			t.Errorf("run(%v) = %d, expected: %d. Stderr: %s", test.args, code, test.expected, &stderr)
+		}
+	}
+}
+
+func TestRunOpen(t *testing.T) {
+	env := xdgtest.New(t, xdgtest.Fixture{})
+	output := filepath.Join(env.Root, "browser.out")
+	script := filepath.Join(env.Root, "browser.sh")
+	env.WriteFile(
+		t,
+		script,
+		"#!/bin/sh\necho \"$@\" > "+output+".tmp && mv "+output+".tmp "+output+"\n",
+	)
+	err := os.Chmod(script, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.WriteFile(
+		t,
+		filepath.Join(env.DataHome, "applications/browser.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Browser\nExec="+script+" %u\n"+
+			"MimeType=x-scheme-handler/https;\n",
+	)
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"https://example.com"}, &stdout, &stderr)
+	if code != exitSuccess {
+		t.Fatalf("run = %d, expected: %d. Stderr: %s", code, exitSuccess, &stderr)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		content, err := os.ReadFile(output)
+		if err == nil {
+			if string(content) != "https://example.com\n" {
+				t.Errorf("browser received %q", content)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("browser was not started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	code = run(context.Background(), []string{"myapp://action"}, &stdout, &stderr)
+	if code != exitToolNotFound {
+		t.Errorf("run = %d for an unhandled scheme, expected: %d", code, exitToolNotFound)
+	}
+
+	stderr.Reset()
+	code = run(context.Background(), []string{filepath.Join(env.Root, "missing")}, &stdout, &stderr)
+	if code != exitFileNotFound || !strings.Contains(stderr.String(), "does not exist") {
+		t.Errorf("run = %d for a missing file, expected: %d. Stderr: %s", code, exitFileNotFound,
+			&stderr)
+	}
+}
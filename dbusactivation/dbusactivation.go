@@ -0,0 +1,191 @@
+// Package dbusactivation launches applications using the org.freedesktop.Application D-Bus
+// interface, as used for desktop entries with DBusActivatable=true.
+// See https://specifications.freedesktop.org/desktop-entry-spec/latest/dbus.html.
+package dbusactivation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"strings"
+)
+
+// Interface is the D-Bus interface implemented by D-Bus activatable applications.
+const Interface = "org.freedesktop.Application"
+
+// ErrInvalidName is returned when a desktop ID is not a valid D-Bus well-known name.
+var ErrInvalidName = errors.New("desktop ID is not a valid D-Bus name")
+
+// PlatformData is passed to the application with every call. It allows the application to
+// take focus.
+type PlatformData struct {
+	// StartupID is the startup notification ID, passed as desktop-startup-id. Used on X11.
+	StartupID string
+
+	// ActivationToken is the XDG activation token, passed as activation-token. Used on
+	// Wayland.
+	ActivationToken string
+}
+
+func (p PlatformData) toMap() map[string]dbus.Variant {
+	result := make(map[string]dbus.Variant)
+	if p.StartupID != "" {
+		result["desktop-startup-id"] = dbus.Variant{Signature: "s", Value: p.StartupID}
+	}
+	if p.ActivationToken != "" {
+		result["activation-token"] = dbus.Variant{Signature: "s", Value: p.ActivationToken}
+	}
+
+	return result
+}
+
+// BusName returns the well-known bus name of the application with the given desktop ID, which
+// is the desktop ID without the .desktop suffix, e.g. org.gnome.Maps.
+func BusName(desktopId string) (string, error) {
+	name := strings.TrimSuffix(desktopId, ".desktop")
+	if !isValidBusName(name) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidName, desktopId)
+	}
+
+	return name, nil
+}
+
+// ObjectPath returns the object path of the application with the given bus name. This is the
+// bus name with . replaced by / and - replaced by _, e.g. /org/gnome/Maps.
+func ObjectPath(busName string) string {
+	path := strings.ReplaceAll(busName, ".", "/")
+	return "/" + strings.ReplaceAll(path, "-", "_")
+}
+
+// isValidBusName reports whether name is a valid well-known bus name: at least two elements
+// separated by dots, consisting of [A-Za-z0-9_-] and not starting with a digit.
+func isValidBusName(name string) bool {
+	if len(name) > 255 {
+		return false
+	}
+
+	elements := strings.Split(name, ".")
+	if len(elements) < 2 {
+		return false
+	}
+
+	for _, element := range elements {
+		if element == "" || (element[0] >= '0' && element[0] <= '9') {
+			return false
+		}
+
+		for _, char := range element {
+			switch {
+			case char >= 'a' && char <= 'z',
+				char >= 'A' && char <= 'Z',
+				char >= '0' && char <= '9',
+				char == '_',
+				char == '-':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Client calls D-Bus activatable applications on the session bus.
+type Client struct {
+	conn *dbus.Conn
+}
+
+// Connect connects to the session bus.
+func Connect(ctx context.Context) (*Client, error) {
+	conn, err := dbus.SessionBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Connect: %w", err)
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the connection to the session bus.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Activate activates the application with the given desktop ID, which is what happens when
+// it is started without files.
+func (c *Client) Activate(ctx context.Context, desktopId string, platformData PlatformData) error {
+	err := c.call(ctx, desktopId, "Activate", "a{sv}", platformData.toMap())
+	if err != nil {
+		return fmt.Errorf("Activate: %w", err)
+	}
+
+	return nil
+}
+
+// Open opens the URIs with the application with the given desktop ID. Local files must be given
+// as file:// URIs.
+func (c *Client) Open(
+	ctx context.Context,
+	desktopId string,
+	uris []string,
+	platformData PlatformData,
+) error {
+	err := c.call(ctx, desktopId, "Open", "asa{sv}", uris, platformData.toMap())
+	if err != nil {
+		return fmt.Errorf("Open: %w", err)
+	}
+
+	return nil
+}
+
+// ActivateAction activates the action, as listed in the Actions key of the desktop entry, of
+// the application with the given desktop ID. Parameters must be of basic Go types such as
+// string, bool, int32, or []string. Actions of desktop entries do not take parameters.
+func (c *Client) ActivateAction(
+	ctx context.Context,
+	desktopId string,
+	action string,
+	parameters []any,
+	platformData PlatformData,
+) error {
+	variants := make([]dbus.Variant, 0, len(parameters))
+	for _, parameter := range parameters {
+		variant, err := dbus.MakeVariant(parameter)
+		if err != nil {
+			return fmt.Errorf("ActivateAction: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	err := c.call(ctx, desktopId, "ActivateAction", "sava{sv}", action, variants, platformData.toMap())
+	if err != nil {
+		return fmt.Errorf("ActivateAction: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) call(
+	ctx context.Context,
+	desktopId string,
+	method string,
+	signature string,
+	args ...any,
+) error {
+	name, err := BusName(desktopId)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.conn.Call(
+		ctx,
+		name,
+		dbus.ObjectPath(ObjectPath(name)),
+		Interface,
+		method,
+		signature,
+		args...,
+	)
+
+	return err
+}
@@ -0,0 +1,148 @@
+package dbusactivation
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"github.com/MatthiasKunnen/xdg/internal/dbus/dbustest"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestBusName(t *testing.T) {
+	tests := []struct {
+		desktopId string
+		expected  string
+		valid     bool
+	}{
+		{"org.gnome.Maps.desktop", "org.gnome.Maps", true},
+		{"org.example.my-app.desktop", "org.example.my-app", true},
+		{"org.example.App", "org.example.App", true},
+		{"firefox.desktop", "", false},
+		{"org.2example.App.desktop", "", false},
+		{"org..App.desktop", "", false},
+		{"org.example.App+.desktop", "", false},
+	}
+
+	for _, test := range tests {
+		name, err := BusName(test.desktopId)
+		if !test.valid {
+			if !errors.Is(err, ErrInvalidName) {
+				t.Errorf("BusName(%s) error = %v, expected: %v", test.desktopId, err, ErrInvalidName)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("BusName(%s) failed: %v", test.desktopId, err)
+		} else if name != test.expected {
+			t.Errorf("BusName(%s) = %s, expected: %s", test.desktopId, name, test.expected)
+		}
+	}
+}
+
+func TestObjectPath(t *testing.T) {
+	path := ObjectPath("org.example.my-app")
+	if path != "/org/example/my_app" {
+		t.Errorf("ObjectPath = %s, expected: /org/example/my_app", path)
+	}
+}
+
+func connect(t *testing.T) (*Client, *dbustest.Bus) {
+	bus := dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		if call.Destination == "org.example.Missing" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+		}
+		return "", nil, nil
+	})
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address)
+
+	client, err := Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return client, bus
+}
+
+func TestClientCalls(t *testing.T) {
+	client, bus := connect(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	platformData := PlatformData{StartupID: "startup", ActivationToken: "token"}
+	err := client.Activate(ctx, "org.example.App.desktop", PlatformData{})
+	if err != nil {
+		t.Fatalf("Activate failed: %v", err)
+	}
+
+	err = client.Open(ctx, "org.example.App.desktop", []string{"file:///tmp/a.txt"}, platformData)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	err = client.ActivateAction(ctx, "org.example.App.desktop", "new-window", []any{"x"}, platformData)
+	if err != nil {
+		t.Fatalf("ActivateAction failed: %v", err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Received %d calls, expected: 3", len(calls))
+	}
+
+	expectedPlatformData := map[string]any{
+		"desktop-startup-id": dbus.Variant{Signature: "s", Value: "startup"},
+		"activation-token":   dbus.Variant{Signature: "s", Value: "token"},
+	}
+	expected := []struct {
+		member string
+		body   []any
+	}{
+		{"Activate", []any{map[string]any{}}},
+		{"Open", []any{[]any{"file:///tmp/a.txt"}, expectedPlatformData}},
+		{"ActivateAction", []any{
+			"new-window",
+			[]any{dbus.Variant{Signature: "s", Value: "x"}},
+			expectedPlatformData,
+		}},
+	}
+
+	for i, call := range calls {
+		if call.Destination != "org.example.App" {
+			t.Errorf("Destination = %s, expected: org.example.App", call.Destination)
+		}
+		if call.Path != "/org/example/App" {
+			t.Errorf("Path = %s, expected: /org/example/App", call.Path)
+		}
+		if call.Interface != Interface || call.Member != expected[i].member {
+			t.Errorf(
+				"Method = %s.%s, expected: %s.%s",
+				call.Interface,
+				call.Member,
+				Interface,
+				expected[i].member,
+			)
+		}
+		if diff := cmp.Diff(expected[i].body, call.Body); diff != "" {
+			t.Errorf("%s body mismatch (-expected +got):\n%s", call.Member, diff)
+		}
+	}
+}
+
+func TestClientError(t *testing.T) {
+	client, _ := connect(t)
+	err := client.Activate(context.Background(), "org.example.Missing.desktop", PlatformData{})
+	if err == nil {
+		t.Errorf("Activate of missing application succeeded, expected error")
+	}
+
+	err = client.Activate(context.Background(), "firefox.desktop", PlatformData{})
+	if !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Activate error = %v, expected: %v", err, ErrInvalidName)
+	}
+}
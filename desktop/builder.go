@@ -0,0 +1,391 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Builder constructs an Entry using fluent setters, e.g.:
+//
+//	entry, err := desktop.NewApplication("Editor", "editor %F").
+//		Icon("editor").
+//		Categories("Utility", "TextEditor").
+//		MimeTypes("text/plain").
+//		Build()
+//
+// Each setter validates its value, errors are collected and returned by Build so that setters
+// can be chained. The resulting entry can be written using Entry.Encode.
+type Builder struct {
+	entry Entry
+	errs  []error
+}
+
+// NewApplication returns a builder of an entry of type Application. exec is the value of the Exec
+// key as written in a desktop file, see NewExec. Use Builder.ExecArgs to set Exec from a list of
+// arguments instead.
+func NewApplication(name string, exec string) *Builder {
+	b := newBuilder(TypeApplication, name)
+	b.Exec(exec)
+	return b
+}
+
+// NewLink returns a builder of an entry of type Link that points to url.
+func NewLink(name string, url string) *Builder {
+	b := newBuilder(TypeLink, name)
+	b.URL(url)
+	return b
+}
+
+// NewDirectory returns a builder of an entry of type Directory, as used by .directory files, see
+// DirectoryEntry.
+func NewDirectory(name string) *Builder {
+	return newBuilder(TypeDirectory, name)
+}
+
+func newBuilder(entryType string, name string) *Builder {
+	b := &Builder{entry: Entry{Type: entryType, Version: "1.5"}}
+	if name == "" {
+		b.fail("Name", errors.New("must not be empty"))
+	}
+	b.entry.Name.Default = name
+
+	return b
+}
+
+// Build returns the entry. An error matching ErrInvalidEntry is returned if any of the values
+// were invalid, it contains the errors of every invalid value.
+func (b *Builder) Build() (*Entry, error) {
+	if len(b.errs) > 0 {
+		return nil, fmt.Errorf("Build: %w: %w", ErrInvalidEntry, errors.Join(b.errs...))
+	}
+
+	entry := b.entry
+	return &entry, nil
+}
+
+func (b *Builder) fail(key string, err error) *Builder {
+	b.errs = append(b.errs, fmt.Errorf("%s: %w", key, err))
+	return b
+}
+
+// requireType records an error if the entry is not of the given type.
+func (b *Builder) requireType(key string, entryType string) bool {
+	if b.entry.Type != entryType {
+		b.fail(key, fmt.Errorf("only allowed for Type=%s, not %s", entryType, b.entry.Type))
+		return false
+	}
+
+	return true
+}
+
+// checkString records an error if value cannot be used as a value of type string.
+func (b *Builder) checkString(key string, value string) bool {
+	if !isAsciiNoControl(value) {
+		b.fail(key, fmt.Errorf("value of type string must be ASCII: %q", value))
+		return false
+	}
+
+	return true
+}
+
+// Localized sets the translation of the Name, GenericName, Comment, or Icon key for the locale,
+// e.g. nl_BE.
+func (b *Builder) Localized(key string, locale string, value string) *Builder {
+	if !localeRegex.MatchString(locale) {
+		return b.fail(key, fmt.Errorf("invalid locale %q", locale))
+	}
+
+	var target *LocaleString
+	switch key {
+	case "Name":
+		target = &b.entry.Name
+	case "GenericName":
+		target = &b.entry.GenericName
+	case "Comment":
+		target = &b.entry.Comment
+	case "Icon":
+		target = (*LocaleString)(&b.entry.Icon)
+	default:
+		return b.fail(key, errors.New("not a localestring key"))
+	}
+
+	if target.Localized == nil {
+		target.Localized = make(map[string]string)
+	}
+	target.Localized[locale] = value
+
+	return b
+}
+
+// GenericName sets the generic name, e.g. Web Browser.
+func (b *Builder) GenericName(genericName string) *Builder {
+	b.entry.GenericName.Default = genericName
+	return b
+}
+
+// Comment sets the tooltip.
+func (b *Builder) Comment(comment string) *Builder {
+	b.entry.Comment.Default = comment
+	return b
+}
+
+// Icon sets the icon name or absolute path of the icon.
+func (b *Builder) Icon(icon string) *Builder {
+	b.entry.Icon.Default = icon
+	return b
+}
+
+// NoDisplay sets whether the entry is hidden from menus.
+func (b *Builder) NoDisplay(noDisplay bool) *Builder {
+	b.entry.NoDisplay = noDisplay
+	return b
+}
+
+// OnlyShowIn sets the desktop environments in which the entry is shown. A desktop environment
+// may not be in both OnlyShowIn and NotShowIn.
+func (b *Builder) OnlyShowIn(desktops ...string) *Builder {
+	for _, desktop := range desktops {
+		if slices.Contains(b.entry.NotShowIn, desktop) {
+			return b.fail("OnlyShowIn", fmt.Errorf("%s is also in NotShowIn", desktop))
+		}
+	}
+
+	b.entry.OnlyShowIn = desktops
+	return b
+}
+
+// NotShowIn sets the desktop environments in which the entry is not shown, see OnlyShowIn.
+func (b *Builder) NotShowIn(desktops ...string) *Builder {
+	for _, desktop := range desktops {
+		if slices.Contains(b.entry.OnlyShowIn, desktop) {
+			return b.fail("NotShowIn", fmt.Errorf("%s is also in OnlyShowIn", desktop))
+		}
+	}
+
+	b.entry.NotShowIn = desktops
+	return b
+}
+
+// DBusActivatable sets whether the application supports D-Bus activation.
+func (b *Builder) DBusActivatable(dBusActivatable bool) *Builder {
+	if b.requireType("DBusActivatable", TypeApplication) {
+		b.entry.DBusActivatable = dBusActivatable
+	}
+	return b
+}
+
+// TryExec sets the executable used to determine whether the application is installed.
+func (b *Builder) TryExec(tryExec string) *Builder {
+	if b.requireType("TryExec", TypeApplication) && b.checkString("TryExec", tryExec) {
+		b.entry.TryExec = tryExec
+	}
+	return b
+}
+
+// Exec sets the Exec key using its value as written in a desktop file, see NewExec.
+func (b *Builder) Exec(exec string) *Builder {
+	if !b.requireType("Exec", TypeApplication) {
+		return b
+	}
+
+	value, err := NewExec(exec)
+	if err != nil {
+		return b.fail("Exec", err)
+	}
+
+	b.entry.Exec = value
+	return b
+}
+
+// ExecArgs sets the Exec key from the program and its arguments followed by field codes, see
+// NewExecFromArgs.
+func (b *Builder) ExecArgs(args []string, fieldCodes ...rune) *Builder {
+	if !b.requireType("Exec", TypeApplication) {
+		return b
+	}
+
+	value, err := NewExecFromArgs(args, fieldCodes...)
+	if err != nil {
+		return b.fail("Exec", err)
+	}
+
+	b.entry.Exec = value
+	return b
+}
+
+// Path sets the working directory of the application.
+func (b *Builder) Path(path string) *Builder {
+	if b.requireType("Path", TypeApplication) && b.checkString("Path", path) {
+		b.entry.Path = path
+	}
+	return b
+}
+
+// Terminal sets whether the application runs in a terminal.
+func (b *Builder) Terminal(terminal bool) *Builder {
+	if b.requireType("Terminal", TypeApplication) {
+		b.entry.Terminal = terminal
+	}
+	return b
+}
+
+// Action adds an application action, exec is parsed like Builder.Exec.
+func (b *Builder) Action(id string, name string, exec string) *Builder {
+	key := "Desktop Action " + id
+	if !b.requireType(key, TypeApplication) {
+		return b
+	}
+
+	switch {
+	case !isValidKey(id) || strings.ContainsAny(id, "[]"):
+		return b.fail(key, errors.New("invalid action ID"))
+	case name == "":
+		return b.fail(key, errors.New("Name must not be empty"))
+	case slices.ContainsFunc(b.entry.Actions, func(a Action) bool { return a.ID == id }):
+		return b.fail(key, errors.New("duplicate action"))
+	}
+
+	action := Action{ID: id}
+	action.Name.Default = name
+	if exec != "" {
+		value, err := NewExec(exec)
+		if err != nil {
+			return b.fail(key, err)
+		}
+		action.Exec = value
+	}
+
+	b.entry.Actions = append(b.entry.Actions, action)
+	return b
+}
+
+// MimeTypes sets the MIME types supported by the application, e.g. text/plain.
+func (b *Builder) MimeTypes(mimeTypes ...string) *Builder {
+	if !b.requireType("MimeType", TypeApplication) {
+		return b
+	}
+
+	for _, mimeType := range mimeTypes {
+		if !mimeTypeRegex.MatchString(mimeType) {
+			return b.fail("MimeType", fmt.Errorf("invalid MIME type %q", mimeType))
+		}
+	}
+
+	b.entry.MimeType = mimeTypes
+	return b
+}
+
+// Categories sets the categories of the application. Every category must be registered in the
+// Desktop Menu Specification or start with X-, and at least one must be a main category, e.g.
+// Development.
+func (b *Builder) Categories(categories ...string) *Builder {
+	if !b.requireType("Categories", TypeApplication) {
+		return b
+	}
+
+	hasMain := false
+	for _, category := range categories {
+		switch {
+		case slices.Contains(mainCategories, category):
+			hasMain = true
+		case slices.Contains(additionalCategories, category), strings.HasPrefix(category, "X-"):
+		default:
+			return b.fail("Categories", fmt.Errorf("unregistered category %q", category))
+		}
+	}
+
+	if !hasMain {
+		return b.fail("Categories", errors.New("no main category"))
+	}
+
+	b.entry.Categories = categories
+	return b
+}
+
+// Implements sets the interfaces that the application implements.
+func (b *Builder) Implements(interfaces ...string) *Builder {
+	if b.requireType("Implements", TypeApplication) {
+		b.entry.Implements = interfaces
+	}
+	return b
+}
+
+// Keywords sets the untranslated keywords used to search for the application.
+func (b *Builder) Keywords(keywords ...string) *Builder {
+	if b.requireType("Keywords", TypeApplication) {
+		b.entry.Keywords.Default = keywords
+	}
+	return b
+}
+
+// StartupNotify sets whether the application sends a startup notification when launched.
+func (b *Builder) StartupNotify(startupNotify bool) *Builder {
+	if !b.requireType("StartupNotify", TypeApplication) {
+		return b
+	}
+
+	if startupNotify {
+		b.entry.StartupNotify = StartupNotifyTrue
+	} else {
+		b.entry.StartupNotify = StartupNotifyFalse
+	}
+	return b
+}
+
+// StartupWMClass sets the WM class or name hint that the application maps at least one window
+// with.
+func (b *Builder) StartupWMClass(class string) *Builder {
+	if b.requireType("StartupWMClass", TypeApplication) &&
+		b.checkString("StartupWMClass", class) {
+		b.entry.StartupWMClass = class
+	}
+	return b
+}
+
+// URL sets the URL that is accessed when the entry of type Link is activated.
+func (b *Builder) URL(url string) *Builder {
+	if !b.requireType("URL", TypeLink) {
+		return b
+	}
+
+	if url == "" {
+		return b.fail("URL", errors.New("must not be empty"))
+	}
+
+	if b.checkString("URL", url) {
+		b.entry.URL = url
+	}
+	return b
+}
+
+// PrefersNonDefaultGPU sets whether the application prefers to run on a more powerful GPU.
+func (b *Builder) PrefersNonDefaultGPU(prefers bool) *Builder {
+	if b.requireType("PrefersNonDefaultGPU", TypeApplication) {
+		b.entry.PrefersNonDefaultGPU = prefers
+	}
+	return b
+}
+
+// SingleMainWindow sets whether the application has a single main window.
+func (b *Builder) SingleMainWindow(single bool) *Builder {
+	if b.requireType("SingleMainWindow", TypeApplication) {
+		b.entry.SingleMainWindow = single
+	}
+	return b
+}
+
+// Extension sets an extension key of the "Desktop Entry" group, which must start with X-. The
+// value is stored as written in the desktop file, see Entry.OtherKeys.
+func (b *Builder) Extension(key string, value string) *Builder {
+	if !strings.HasPrefix(key, "X-") || !isValidKeyName(key) {
+		return b.fail(key, errors.New("invalid extension key, it must start with X-"))
+	}
+
+	if b.entry.OtherKeys == nil {
+		b.entry.OtherKeys = make(map[string]string)
+	}
+	b.entry.OtherKeys[key] = value
+	return b
+}
@@ -0,0 +1,87 @@
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	entry, err := NewApplication("Editor", "editor --new-window %F").
+		Localized("Name", "nl", "Tekstverwerker").
+		Comment("Edit text files").
+		Icon("accessories-text-editor").
+		Categories("Utility", "TextEditor").
+		MimeTypes("text/plain").
+		Keywords("text", "editor").
+		StartupNotify(true).
+		Action("new", "New Window", "editor --new-window").
+		Extension("X-GNOME-UsesNotifications", "true").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := entry.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("built entry is invalid: %v\n%s", err, data)
+	}
+	if parsed.Name.ToLocale("nl") != "Tekstverwerker" {
+		t.Errorf("Name[nl] = %s, expected: Tekstverwerker", parsed.Name.ToLocale("nl"))
+	}
+	if parsed.Exec.String() != "editor --new-window %F" {
+		t.Errorf("Exec = %s, expected: editor --new-window %%F", parsed.Exec.String())
+	}
+	if len(parsed.Actions) != 1 || parsed.Actions[0].Name.Default != "New Window" {
+		t.Errorf("Actions = %v, expected the new action", parsed.Actions)
+	}
+
+	link, err := NewLink("Docs", "https://example.com").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Type != TypeLink || link.URL != "https://example.com" {
+		t.Errorf("link = %s %s, expected: Link https://example.com", link.Type, link.URL)
+	}
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		message string
+	}{
+		{"empty name", NewApplication("", "app"), "Name"},
+		{"exec syntax", NewApplication("App", `app "unterminated`), "Exec"},
+		{"exec args", NewApplication("App", "app").ExecArgs([]string{"app"}, 'f', 'U'), "Exec"},
+		{"link exec", NewLink("Docs", "https://example.com").Exec("app"), "Type=Application"},
+		{"application URL", NewApplication("App", "app").URL("https://example.com"), "Type=Link"},
+		{"empty URL", NewLink("Docs", ""), "URL"},
+		{"unknown category", NewApplication("App", "app").Categories("Development", "Foo"), "Foo"},
+		{"no main category", NewApplication("App", "app").Categories("IDE"), "main category"},
+		{"MIME type", NewApplication("App", "app").MimeTypes("text"), "MIME"},
+		{"locale", NewApplication("App", "app").Localized("Name", "dutch", "A"), "locale"},
+		{"show in", NewApplication("App", "app").OnlyShowIn("KDE").NotShowIn("KDE"), "KDE"},
+		{"action", NewApplication("App", "app").Action("a[b]", "A", "app"), "action ID"},
+		{"extension", NewApplication("App", "app").Extension("Foo", "bar"), "X-"},
+		{"path", NewApplication("App", "app").Path("/home/ünicode"), "ASCII"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.builder.Build()
+			if !errors.Is(err, ErrInvalidEntry) {
+				t.Fatalf("expected ErrInvalidEntry, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), test.message) {
+				t.Errorf("error %q does not contain %q", err, test.message)
+			}
+		})
+	}
+}
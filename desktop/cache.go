@@ -0,0 +1,213 @@
+package desktop
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// entryCacheVersion is incremented whenever the format of the cache or of Entry changes, caches
+// of other versions are discarded.
+const entryCacheVersion = 1
+
+// EntryCache is an on-disk cache of parsed desktop entries. It allows launchers to avoid parsing
+// every desktop file on each start. Entries are keyed by the path of the desktop file and are
+// invalidated automatically when the modification time or size of the file changes.
+//
+// Use OpenEntryCache to load the cache, EntryCache.Load to get entries, and EntryCache.Store to
+// write the cache back to disk. An EntryCache is safe for concurrent use.
+type EntryCache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]cachedEntry
+	dirty   bool
+}
+
+type entryCacheFile struct {
+	Version int
+	Entries map[string]cachedEntry
+}
+
+type cachedEntry struct {
+	ModTime time.Time
+	Size    int64
+	Entry   *Entry
+}
+
+// DefaultEntryCachePath returns the path of the cache in $XDG_CACHE_HOME that is used when
+// OpenEntryCache is called with an empty path.
+func DefaultEntryCachePath() string {
+	return filepath.Join(basedir.CacheHome, "xdg-go", "desktop-entries.gob")
+}
+
+// OpenEntryCache reads the cache at path. If path is empty, DefaultEntryCachePath is used.
+// A cache that does not exist, is corrupt, or was written by an incompatible version results in
+// an empty cache rather than an error, since it is rebuilt by using it.
+func OpenEntryCache(path string) *EntryCache {
+	if path == "" {
+		path = DefaultEntryCachePath()
+	}
+
+	cache := &EntryCache{
+		path:    path,
+		entries: make(map[string]cachedEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Logger().Warn(
+				"Failed to read entry cache",
+				logging.Path, path,
+				logging.Error, err,
+			)
+		}
+		return cache
+	}
+
+	var file entryCacheFile
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&file)
+	switch {
+	case err != nil:
+		logging.Logger().Debug(
+			"Discarding corrupt entry cache",
+			logging.Path, path,
+			logging.Error, err,
+		)
+	case file.Version != entryCacheVersion:
+		logging.Logger().Debug("Discarding outdated entry cache", logging.Path, path)
+	case file.Entries != nil:
+		cache.entries = file.Entries
+	}
+
+	return cache
+}
+
+// Load returns the parsed desktop file at path, see LoadFile. The cached entry is returned if
+// the file has not changed since it was cached, otherwise the file is parsed and the cache is
+// updated. Invalid desktop files are not cached.
+//
+// The returned entry is shared with the cache and must not be modified.
+func (c *EntryCache) Load(path string) (*Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.remove(path)
+		return nil, fmt.Errorf("EntryCache.Load: %w", err)
+	}
+
+	c.mu.Lock()
+	cached, found := c.entries[path]
+	c.mu.Unlock()
+	if found && cached.ModTime.Equal(info.ModTime()) && cached.Size == info.Size() {
+		return cached.Entry, nil
+	}
+
+	entry, err := LoadFile(path)
+	if err != nil {
+		c.remove(path)
+		return nil, fmt.Errorf("EntryCache.Load: %w", err)
+	}
+
+	c.mu.Lock()
+	c.entries[path] = cachedEntry{ModTime: info.ModTime(), Size: info.Size(), Entry: entry}
+	c.dirty = true
+	c.mu.Unlock()
+
+	return entry, nil
+}
+
+// LoadById is like IdPathMap.LoadById but uses the cache to load the desktop files.
+func (c *EntryCache) LoadById(m IdPathMap, desktopId string) (*Entry, string, error) {
+	for _, path := range m[desktopId] {
+		entry, err := c.Load(path)
+		if err != nil {
+			logging.Logger().Warn(
+				"Skipping invalid desktop file",
+				logging.DesktopId, desktopId,
+				logging.Path, path,
+				logging.Error, err,
+			)
+			continue
+		}
+
+		return entry, path, nil
+	}
+
+	return nil, "", nil
+}
+
+// Store writes the cache to disk, atomically replacing the previous cache. Entries of desktop
+// files that no longer exist are dropped. Nothing is written if the cache did not change since it
+// was opened or last stored.
+func (c *EntryCache) Store() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(c.entries, path)
+			c.dirty = true
+		}
+	}
+
+	if !c.dirty {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(entryCacheFile{
+		Version: entryCacheVersion,
+		Entries: c.entries,
+	})
+	if err != nil {
+		return fmt.Errorf("EntryCache.Store: failed to encode cache: %w", err)
+	}
+
+	err = fileutil.WriteFileAtomic(c.path, buffer.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("EntryCache.Store: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+func (c *EntryCache) remove(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[path]; found {
+		delete(c.entries, path)
+		c.dirty = true
+	}
+}
+
+// GobEncode encodes the Exec value using its desktop file representation, see ExecValue.String.
+func (e ExecValue) GobEncode() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// GobDecode decodes an Exec value encoded by GobEncode.
+func (e *ExecValue) GobDecode(data []byte) error {
+	if len(data) == 0 {
+		*e = nil
+		return nil
+	}
+
+	decoded, err := NewExec(string(data))
+	if err != nil {
+		return err
+	}
+
+	*e = decoded
+	return nil
+}
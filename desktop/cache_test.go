@@ -0,0 +1,91 @@
+package desktop
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEntryCache(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "cache", "entries.gob")
+	writeFiles(t, dir, map[string]string{
+		"app.desktop": "[Desktop Entry]\nType=Application\nName=App\nName[nl]=Toep\n" +
+			"Exec=app --flag \"a b\" %U\nActions=new;\n\n" +
+			"[Desktop Action new]\nName=New\nExec=app --new\n",
+		"gone.desktop": "[Desktop Entry]\nType=Application\nName=Gone\nExec=gone\n",
+	})
+	appPath := filepath.Join(dir, "app.desktop")
+	gonePath := filepath.Join(dir, "gone.desktop")
+
+	cache := OpenEntryCache(cachePath)
+	original, err := cache.Load(appPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Load(gonePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(gonePath); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = OpenEntryCache(cachePath)
+	if len(cache.entries) != 2 {
+		t.Errorf("len(entries) = %d, expected: 2", len(cache.entries))
+	}
+	cached, err := cache.Load(appPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(original, cached, cmp.Comparer(func(a ExecValue, b ExecValue) bool {
+		return a.String() == b.String()
+	}))
+	if diff != "" {
+		t.Errorf("cached entry mismatch (-expected +got):\n%s", diff)
+	}
+	if err := cache.Store(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = OpenEntryCache(cachePath)
+	if _, found := cache.entries[gonePath]; found {
+		t.Errorf("entry of removed file was stored")
+	}
+
+	// Modifying the file invalidates the cached entry.
+	changedFile := "[Desktop Entry]\nType=Application\nName=Changed\nExec=app\n"
+	err = os.WriteFile(appPath, []byte(changedFile), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(appPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := cache.Load(appPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Name.Default != "Changed" {
+		t.Errorf("Name = %s, expected: Changed", changed.Name.Default)
+	}
+}
+
+func TestOpenEntryCacheCorrupt(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "entries.gob")
+	if err := os.WriteFile(cachePath, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := OpenEntryCache(cachePath)
+	if len(cache.entries) != 0 {
+		t.Errorf("len(entries) = %d, expected: 0", len(cache.entries))
+	}
+}
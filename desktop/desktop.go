@@ -1,14 +1,20 @@
 package desktop
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io"
 	"io/fs"
-	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // GetDirs returns all directories containing .desktop files in accordance with
@@ -37,19 +43,101 @@ func GetDirs() []string {
 // [Desktop ID]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/file-naming.html#desktop-file-id
 type IdPathMap map[string][]string
 
+// ErrDeleted is returned when the desktop file with the highest precedence for a desktop ID has
+// Hidden=true. Such a file hides the desktop files with lower precedence, e.g. to remove an entry
+// installed by the system for a single user.
+var ErrDeleted = errors.New("desktop entry is deleted")
+
+// ErrNotFound is returned when no valid desktop file exists for a desktop ID.
+var ErrNotFound = errors.New("desktop entry not found")
+
+// ErrInvalidDesktopId is returned when a desktop ID cannot be determined for a path.
+var ErrInvalidDesktopId = errors.New("invalid desktop ID")
+
 // LoadById loads the first valid desktop file in the list of paths for the given desktop ID and
 // returns the parsed result and the path to the file.
 // If no valid desktop file could be found, error will be nil and path will be an empty string.
+// Hidden=true is not treated specially, use LoadEffective to respect deleted entries.
+// See LoadByIdStrict for a variant that reports why no entry was found.
 // Example of desktopId: vim.desktop
 func (m IdPathMap) LoadById(desktopId string) (*Entry, string, error) {
-	if m[desktopId] == nil {
-		return nil, "", nil
+	parsed, path := loadFirst(m[desktopId], func(path string, err error) {
+		logging.Logger().Warn(
+			"Skipping invalid desktop file",
+			logging.DesktopId, desktopId,
+			logging.Path, path,
+			logging.Error, err,
+		)
+	})
+
+	return parsed, path, nil
+}
+
+// LoadByIdStrict is like LoadById but returns an error matching ErrNotFound if no valid desktop
+// file could be found. The error also contains the errors of the desktop files that could not be
+// loaded.
+func (m IdPathMap) LoadByIdStrict(desktopId string) (*Entry, string, error) {
+	var errs []error
+	parsed, path := loadFirst(m[desktopId], func(path string, err error) {
+		errs = append(errs, err)
+	})
+	if parsed == nil {
+		return nil, "", notFound("IdPathMap.LoadByIdStrict", desktopId, errs)
+	}
+
+	return parsed, path, nil
+}
+
+// LoadedEntry is a desktop file loaded by IdPathMap.LoadAllById.
+type LoadedEntry struct {
+	Path string
+
+	// Entry is the parsed desktop file. If the file is invalid, it contains what could be parsed
+	// and Err is set. Entry is nil if the file could not be read.
+	Entry *Entry
+
+	Err error
+}
+
+// LoadAllById parses every desktop file of the desktop ID and returns them in order of
+// precedence, including the files that are invalid or overridden by files with a higher
+// precedence. See LoadEffective for the entry that is in effect.
+func (m IdPathMap) LoadAllById(desktopId string) []LoadedEntry {
+	result := make([]LoadedEntry, 0, len(m[desktopId]))
+	for _, path := range m[desktopId] {
+		parsed, err := loadFileLenient(path)
+		result = append(result, LoadedEntry{
+			Path:  path,
+			Entry: parsed,
+			Err:   err,
+		})
 	}
 
+	return result
+}
+
+// LoadEffective returns the entry that is in effect for the desktop ID and the path to its file.
+// Unlike LoadById, it applies the override rules of the [Desktop Entry Specification]: the valid
+// desktop file with the highest precedence is used as is, files with lower precedence are
+// ignored. A file with Hidden=true deletes the entry, even if it is otherwise incomplete. In that
+// case, an error matching ErrDeleted is returned together with the path of the file.
+// If no valid desktop file could be found, error will be nil and path will be an empty string.
+//
+// [Desktop Entry Specification]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/recognized-keys.html
+func (m IdPathMap) LoadEffective(desktopId string) (*Entry, string, error) {
 	for _, path := range m[desktopId] {
-		parsed, err := LoadFile(path)
+		parsed, err := loadFileLenient(path)
+		if parsed != nil && parsed.Hidden {
+			return nil, path, fmt.Errorf("LoadEffective: %w: %s", ErrDeleted, desktopId)
+		}
+
 		if err != nil {
-			log.Printf("Failed to load desktop ID %s: %v. Skipping\n", desktopId, err)
+			logging.Logger().Warn(
+				"Skipping invalid desktop file",
+				logging.DesktopId, desktopId,
+				logging.Path, path,
+				logging.Error, err,
+			)
 			continue
 		}
 
@@ -63,60 +151,364 @@ func (m IdPathMap) LoadById(desktopId string) (*Entry, string, error) {
 // could be found in the given locations.
 // To get the standard locations, use GetDesktopFileLocations.
 // The slice of desktop file paths is in order of highest to lowest precedence.
-func GetDesktopFiles(locations []string) (IdPathMap, error) {
+func GetDesktopFiles(locations []string, opts ...ScanOption) (IdPathMap, error) {
+	return GetDesktopFilesContext(context.Background(), locations, opts...)
+}
+
+// GetDesktopFilesContext is like GetDesktopFiles but stops scanning when ctx is done, returning
+// the error of ctx.
+//
+// The locations are scanned concurrently, see ScanParallelism. The result does not depend on the
+// parallelism.
+func GetDesktopFilesContext(
+	ctx context.Context,
+	locations []string,
+	opts ...ScanOption,
+) (IdPathMap, error) {
+	config := newScanConfig(opts)
 	result := make(IdPathMap)
+	err := scanLocations(ctx, locations, config, func(i int, found IdPathMap) {
+		for desktopId, paths := range found {
+			result[desktopId] = append(result[desktopId], paths...)
+		}
+	})
+	if err == nil {
+		err = config.filter(ctx, result, func(desktopId string) {
+			delete(result, desktopId)
+		})
+	}
+	if err != nil {
+		return result, fmt.Errorf("GetDesktopFilesContext: %w", err)
+	}
 
-	for _, dir := range locations {
-		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
+	return result, nil
+}
 
-			if entry.IsDir() {
-				return nil
-			}
+// IdEntry is a desktop file found for a desktop ID, including the location it was found in.
+type IdEntry struct {
+	// Path of the desktop file.
+	Path string
 
-			add := false
-
-			switch filepath.Ext(path) {
-			case ".desktop":
-				add = true
-			case ".directory":
-			default:
-				isDesktopFile, magicError := MagicIsDesktopFilePath(path)
-				if isDesktopFile && magicError == nil {
-					add = true
-				}
-			}
+	// BaseDir is the location in which the desktop file was found, e.g.
+	// /usr/share/applications.
+	BaseDir string
 
-			if add {
-				desktopId := strings.ReplaceAll(
-					strings.TrimPrefix(path, dir)[1:],
-					string(filepath.Separator),
-					"-",
-				)
-				if result[desktopId] == nil {
-					result[desktopId] = []string{path}
-				} else {
-					result[desktopId] = append(result[desktopId], path)
-				}
-			}
+	// Precedence is the index of BaseDir in the scanned locations, 0 is the highest precedence.
+	// For GetDesktopFileLocations, 0 is the user location in $XDG_DATA_HOME.
+	Precedence int
+}
 
-			return nil
+// IdEntryMap maps a desktop ID to its desktop files in order of highest to lowest precedence,
+// like IdPathMap.
+type IdEntryMap map[string][]IdEntry
+
+// GetDesktopFileEntries is like GetDesktopFiles but also returns the location each desktop file
+// was found in. This allows telling apart, for example, a user override from the system desktop
+// file it masks.
+func GetDesktopFileEntries(locations []string, opts ...ScanOption) (IdEntryMap, error) {
+	return GetDesktopFileEntriesContext(context.Background(), locations, opts...)
+}
+
+// GetDesktopFileEntriesContext is like GetDesktopFileEntries but stops scanning when ctx is done,
+// returning the error of ctx.
+func GetDesktopFileEntriesContext(
+	ctx context.Context,
+	locations []string,
+	opts ...ScanOption,
+) (IdEntryMap, error) {
+	config := newScanConfig(opts)
+	result := make(IdEntryMap)
+	err := scanLocations(ctx, locations, config, func(i int, found IdPathMap) {
+		for desktopId, paths := range found {
+			for _, path := range paths {
+				result[desktopId] = append(result[desktopId], IdEntry{
+					Path:       path,
+					BaseDir:    locations[i],
+					Precedence: i,
+				})
+			}
+		}
+	})
+	if err == nil {
+		err = config.filter(ctx, result.Paths(), func(desktopId string) {
+			delete(result, desktopId)
 		})
+	}
+	if err != nil {
+		return result, fmt.Errorf("GetDesktopFileEntriesContext: %w", err)
+	}
+
+	return result, nil
+}
+
+// Paths returns the IdPathMap of the entries.
+func (m IdEntryMap) Paths() IdPathMap {
+	result := make(IdPathMap, len(m))
+	for desktopId, entries := range m {
+		for _, entry := range entries {
+			result[desktopId] = append(result[desktopId], entry.Path)
+		}
+	}
+
+	return result
+}
+
+// scanLocations finds the desktop files of the locations concurrently, see ScanParallelism, and
+// calls add with the result of each location in order. Locations that do not exist are skipped.
+func scanLocations(
+	ctx context.Context,
+	locations []string,
+	config scanConfig,
+	add func(i int, found IdPathMap),
+) error {
+	found := make([]IdPathMap, len(locations))
+	errs := make([]error, len(locations))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(config.parallelism, 1), len(locations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				found[i] = make(IdPathMap)
+				errs[i] = addDesktopFiles(ctx, locations[i], found[i])
+			}
+		}()
+	}
+	for i := range locations {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
+	for i, dir := range locations {
+		err := errs[i]
 		switch {
 		case errors.Is(err, os.ErrNotExist):
+		case ctx.Err() != nil:
+			return ctx.Err()
 		case err != nil:
-			return result, fmt.Errorf(
-				"getDesktopFiles, failed to walk dir %s for desktop files: %w",
-				dir,
-				err,
+			return fmt.Errorf("failed to walk dir %s for desktop files: %w", dir, err)
+		}
+
+		add(i, found[i])
+	}
+
+	return nil
+}
+
+// ScanOption configures GetDesktopFiles.
+type ScanOption func(c *scanConfig)
+
+type scanConfig struct {
+	parallelism int
+	filters     []func(desktopId string, entry *Entry) bool
+}
+
+func newScanConfig(opts []ScanOption) scanConfig {
+	config := scanConfig{parallelism: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// ScanParallelism sets the maximum number of locations that are scanned at the same time.
+// The default is GOMAXPROCS, 1 scans the locations one after the other.
+func ScanParallelism(n int) ScanOption {
+	return func(c *scanConfig) {
+		c.parallelism = n
+	}
+}
+
+// ScanFilter only keeps the desktop IDs for which keep returns true.
+// keep receives the entry that is in effect for the desktop ID, following the rules of
+// IdPathMap.LoadEffective, except that an entry with Hidden=true is passed to keep instead of
+// deleting the desktop ID. Only the files up to and including the effective one are parsed.
+// Desktop IDs without a valid desktop file are removed without calling keep.
+//
+// Multiple filters can be given, a desktop ID is kept if all of them return true.
+func ScanFilter(keep func(desktopId string, entry *Entry) bool) ScanOption {
+	return func(c *scanConfig) {
+		c.filters = append(c.filters, keep)
+	}
+}
+
+// SkipNoDisplay removes the desktop IDs whose entry has NoDisplay=true, see ScanFilter.
+func SkipNoDisplay() ScanOption {
+	return ScanFilter(func(desktopId string, entry *Entry) bool {
+		return !entry.NoDisplay
+	})
+}
+
+// SkipHidden removes the desktop IDs whose entry has Hidden=true, i.e. that are deleted, see
+// ScanFilter.
+func SkipHidden() ScanOption {
+	return ScanFilter(func(desktopId string, entry *Entry) bool {
+		return !entry.Hidden
+	})
+}
+
+// OnlyType removes the desktop IDs whose entry is not of the given type, e.g. TypeApplication,
+// see ScanFilter.
+func OnlyType(entryType string) ScanOption {
+	return ScanFilter(func(desktopId string, entry *Entry) bool {
+		return entry.Type == entryType
+	})
+}
+
+// RequireMimeType removes the desktop IDs whose entry has no MimeType key, i.e. that cannot open
+// any file, see ScanFilter.
+func RequireMimeType() ScanOption {
+	return ScanFilter(func(desktopId string, entry *Entry) bool {
+		return len(entry.MimeType) > 0
+	})
+}
+
+// filter calls remove for every desktop ID that is rejected by the filters.
+func (c scanConfig) filter(
+	ctx context.Context,
+	found IdPathMap,
+	remove func(desktopId string),
+) error {
+	if len(c.filters) == 0 {
+		return nil
+	}
+
+	for desktopId, paths := range found {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry := loadFilterEntry(desktopId, paths)
+		keep := entry != nil
+		for _, filter := range c.filters {
+			if !keep {
+				break
+			}
+			keep = filter(desktopId, entry)
+		}
+
+		if !keep {
+			remove(desktopId)
+		}
+	}
+
+	return nil
+}
+
+// loadFilterEntry returns the entry in effect for the desktop ID like IdPathMap.LoadEffective,
+// but returns hidden entries instead of treating them as deleted.
+func loadFilterEntry(desktopId string, paths []string) *Entry {
+	for _, path := range paths {
+		parsed, err := loadFileLenient(path)
+		if parsed != nil && parsed.Hidden {
+			return parsed
+		}
+
+		if err != nil {
+			logging.Logger().Warn(
+				"Skipping invalid desktop file",
+				logging.DesktopId, desktopId,
+				logging.Path, path,
+				logging.Error, err,
 			)
+			continue
 		}
+
+		return parsed
 	}
 
-	return result, nil
+	return nil
+}
+
+// addDesktopFiles adds the desktop files found in dir to result.
+func addDesktopFiles(ctx context.Context, dir string, result IdPathMap) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		add := isDesktopFile(path, func() (io.ReadCloser, error) {
+			return os.Open(path)
+		})
+
+		if add {
+			desktopId, err := DesktopIDForPath(dir, path)
+			if err != nil {
+				return nil
+			}
+
+			if result[desktopId] == nil {
+				result[desktopId] = []string{path}
+			} else {
+				result[desktopId] = append(result[desktopId], path)
+			}
+		}
+
+		return nil
+	})
+}
+
+// isDesktopFile returns whether the file at path is a desktop file based on its extension. The
+// content of files without the .desktop or .directory extension is checked using
+// MagicIsDesktopFile.
+func isDesktopFile(path string, open func() (io.ReadCloser, error)) bool {
+	switch filepath.Ext(path) {
+	case ".desktop":
+		return true
+	case ".directory":
+		return false
+	}
+
+	file, err := open()
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	isDesktopFile, err := MagicIsDesktopFile(file)
+	return isDesktopFile && err == nil
+}
+
+// DesktopIDForPath returns the [Desktop ID] of the desktop file at path, which must be inside
+// baseDir, e.g. the applications subdirectory of a data directory.
+// The ID is the path relative to baseDir with the directory separators replaced by dashes, e.g.
+// /usr/share/applications/kde/okular.desktop has the ID kde-okular.desktop for base directory
+// /usr/share/applications.
+// An error matching ErrInvalidDesktopId is returned if path is not inside baseDir.
+//
+// [Desktop ID]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/file-naming.html#desktop-file-id
+func DesktopIDForPath(baseDir string, path string) (string, error) {
+	relative, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return "", fmt.Errorf("DesktopIDForPath: %w: %w", ErrInvalidDesktopId, err)
+	}
+
+	if relative == "." || !filepath.IsLocal(relative) {
+		return "", fmt.Errorf(
+			"DesktopIDForPath: %w: %s is not inside %s",
+			ErrInvalidDesktopId,
+			path,
+			baseDir,
+		)
+	}
+
+	desktopId := strings.ReplaceAll(filepath.ToSlash(relative), "/", "-")
+	if !utf8.ValidString(desktopId) || strings.HasPrefix(desktopId, "-") {
+		return "", fmt.Errorf("DesktopIDForPath: %w: %q", ErrInvalidDesktopId, desktopId)
+	}
+
+	return desktopId, nil
 }
 
 // GetDesktopFileLocations returns the directories where desktop files can be found.
@@ -134,44 +526,115 @@ func GetDesktopFileLocations() []string {
 	return locations
 }
 
+// GetDesktopFilesById is like GetDesktopFiles but only finds the desktop files of the given
+// desktop IDs, which avoids scanning the locations. The result contains no key for desktop IDs
+// without desktop files.
+// If locations is nil, GetDesktopFileLocations will be used.
+func GetDesktopFilesById(locations []string, desktopIds ...string) IdPathMap {
+	result := make(IdPathMap, len(desktopIds))
+	for _, desktopId := range desktopIds {
+		paths := candidatePaths(desktopId, locations)
+		if len(paths) > 0 {
+			result[desktopId] = paths
+		}
+	}
+
+	return result
+}
+
 // LoadById finds the first valid desktop file with the given ID, parses it and returns the result
 // and the path of the file.
 // If locations is nil, GetDesktopFileLocations will be used.
 // If no valid desktop file could be found, error will be nil and path will be an empty string.
+// See LoadByIdStrict for a variant that reports why no entry was found.
 // Example of desktopId: vim.desktop
 func LoadById(desktopId string, locations []string) (*Entry, string, error) {
+	parsed, path := loadFirst(candidatePaths(desktopId, locations), func(path string, err error) {
+		logging.Logger().Warn(
+			"Skipping invalid desktop file",
+			logging.DesktopId, desktopId,
+			logging.Path, path,
+			logging.Error, err,
+		)
+	})
+
+	return parsed, path, nil
+}
+
+// LoadByIdStrict is like LoadById but returns an error matching ErrNotFound if no valid desktop
+// file could be found. The error also contains the errors of the desktop files that could not be
+// loaded.
+func LoadByIdStrict(desktopId string, locations []string) (*Entry, string, error) {
+	var errs []error
+	parsed, path := loadFirst(candidatePaths(desktopId, locations), func(path string, err error) {
+		errs = append(errs, err)
+	})
+	if parsed == nil {
+		return nil, "", notFound("LoadByIdStrict", desktopId, errs)
+	}
+
+	return parsed, path, nil
+}
+
+// candidatePaths returns the existing files that can have the given desktop ID in the locations,
+// in order of precedence.
+func candidatePaths(desktopId string, locations []string) []string {
 	if locations == nil {
 		locations = GetDesktopFileLocations()
 	}
 
+	var result []string
 	for _, dir := range locations {
-		attempts := map[string]bool{
-			filepath.Join(dir, desktopId): true,
+		attempts := []string{
+			filepath.Join(dir, desktopId),
 			// Desktop IDs with hyphens such as foo-bar.desktop can mean foo/bar.desktop
-			filepath.Join(dir, strings.Replace(desktopId, "-", "/", 1)): true,
+			filepath.Join(dir, strings.Replace(desktopId, "-", "/", 1)),
 		}
 
-		for path, _ := range attempts {
+		for _, path := range slices.Compact(attempts) {
 			_, err := os.Stat(path)
 			switch {
 			case errors.Is(err, os.ErrNotExist):
 				continue
 			case err != nil:
-				log.Printf("Failed to stat desktop file '%s': %v\n", path, err)
+				logging.Logger().Warn(
+					"Failed to stat desktop file",
+					logging.Path, path,
+					logging.Error, err,
+				)
 				continue
 			}
 
-			parsed, err := LoadFile(path)
-			if err != nil {
-				log.Printf("Failed to load desktop file '%s': %v. Skipping\n", desktopId, err)
-				continue
-			}
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
 
-			return parsed, path, nil
+// loadFirst loads the paths in order and returns the first valid entry and its path. onError is
+// called for every file that could not be loaded.
+func loadFirst(paths []string, onError func(path string, err error)) (*Entry, string) {
+	for _, path := range paths {
+		parsed, err := LoadFile(path)
+		if err != nil {
+			onError(path, err)
+			continue
 		}
+
+		return parsed, path
 	}
 
-	return nil, "", nil
+	return nil, ""
+}
+
+// notFound returns the error of the LoadByIdStrict functions.
+func notFound(funcName string, desktopId string, errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("%s: %w: %s", funcName, ErrNotFound, desktopId)
+	}
+
+	return fmt.Errorf("%s: %w: %s: %w", funcName, ErrNotFound, desktopId, errors.Join(errs...))
 }
 
 func LoadFile(path string) (*Entry, error) {
@@ -197,3 +660,20 @@ func LoadFile(path string) (*Entry, error) {
 
 	return parsed, nil
 }
+
+// loadFileLenient is like LoadFile but also returns the partially parsed entry if the file is
+// invalid.
+func loadFileLenient(path string) (*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open desktop file '%s'. %w", path, err)
+	}
+	defer file.Close()
+
+	parsed, issues := ParseLenient(file)
+	if len(issues) > 0 {
+		return parsed, fmt.Errorf("failed to parse desktop file '%s'. %w", path, issues[0])
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,373 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGetDesktopFilesContext(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.desktop", "vendor/b.desktop"} {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte("[Desktop Entry]\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := GetDesktopFilesContext(context.Background(), []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "vendor/b.desktop")}
+	if !slices.Equal(result["vendor-b.desktop"], expected) {
+		t.Errorf("vendor-b.desktop = %v, expected: %v", result["vendor-b.desktop"], expected)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GetDesktopFilesContext(ctx, []string{dir})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestIdPathMapLoadEffective(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"user/app.desktop":      "[Desktop Entry]\nType=Application\nName=User\nExec=user\n",
+		"system/app.desktop":    "[Desktop Entry]\nType=Application\nName=System\nExec=system\n",
+		"user/gone.desktop":     "[Desktop Entry]\nType=Application\nName=Gone\nHidden=true\n",
+		"system/gone.desktop":   "[Desktop Entry]\nType=Application\nName=System\nExec=system\n",
+		"user/broken.desktop":   "not a desktop file\n",
+		"system/broken.desktop": "[Desktop Entry]\nType=Application\nName=System\nExec=system\n",
+	}
+	writeFiles(t, dir, files)
+
+	m, err := GetDesktopFiles([]string{filepath.Join(dir, "user"), filepath.Join(dir, "system")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, path, err := m.LoadEffective("app.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name.Default != "User" || path != filepath.Join(dir, "user/app.desktop") {
+		t.Errorf(
+			"LoadEffective(app.desktop) = %s, %s, expected the user entry",
+			entry.Name.Default,
+			path,
+		)
+	}
+
+	entry, path, err = m.LoadEffective("gone.desktop")
+	if !errors.Is(err, ErrDeleted) || entry != nil {
+		t.Errorf("LoadEffective(gone.desktop) = %v, %v, expected: nil, %v", entry, err, ErrDeleted)
+	}
+	if path != filepath.Join(dir, "user/gone.desktop") {
+		t.Errorf("LoadEffective(gone.desktop) path = %s, expected the user file", path)
+	}
+
+	entry, _, err = m.LoadEffective("broken.desktop")
+	if err != nil || entry == nil || entry.Name.Default != "System" {
+		t.Errorf("LoadEffective(broken.desktop) = %v, %v, expected the system entry", entry, err)
+	}
+
+	entry, path, err = m.LoadEffective("missing.desktop")
+	if entry != nil || path != "" || err != nil {
+		t.Errorf("LoadEffective(missing.desktop) = %v, %q, %v, expected nothing", entry, path, err)
+	}
+}
+
+// writeFiles writes the files, given as relative path and content, to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetDesktopFilesParallelism(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]string)
+	var locations []string
+	for i := range 8 {
+		location := fmt.Sprintf("location%d", i)
+		locations = append(locations, filepath.Join(dir, location))
+		files[location+"/app.desktop"] = "[Desktop Entry]\n"
+		files[location+"/vendor/app.desktop"] = "[Desktop Entry]\n"
+		files[location+"/vendor-app.desktop"] = "[Desktop Entry]\n"
+	}
+	writeFiles(t, dir, files)
+
+	expected, err := GetDesktopFiles(locations, ScanParallelism(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expected["vendor-app.desktop"]) != 16 {
+		t.Errorf("vendor-app.desktop = %v, expected 16 paths", expected["vendor-app.desktop"])
+	}
+
+	for _, parallelism := range []int{0, 3, 100} {
+		result, err := GetDesktopFiles(locations, ScanParallelism(parallelism))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Errorf("ScanParallelism(%d) mismatch (-want +got):\n%s", parallelism, diff)
+		}
+	}
+}
+
+func TestGetDesktopFileEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user/app.desktop":          "[Desktop Entry]\n",
+		"system/app.desktop":        "[Desktop Entry]\n",
+		"system/vendor/app.desktop": "[Desktop Entry]\n",
+	})
+	user := filepath.Join(dir, "user")
+	system := filepath.Join(dir, "system")
+	locations := []string{user, filepath.Join(dir, "missing"), system}
+
+	result, err := GetDesktopFileEntries(locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := IdEntryMap{
+		"app.desktop": {
+			{Path: filepath.Join(user, "app.desktop"), BaseDir: user, Precedence: 0},
+			{Path: filepath.Join(system, "app.desktop"), BaseDir: system, Precedence: 2},
+		},
+		"vendor-app.desktop": {
+			{Path: filepath.Join(system, "vendor/app.desktop"), BaseDir: system, Precedence: 2},
+		},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("GetDesktopFileEntries mismatch (-want +got):\n%s", diff)
+	}
+
+	paths, err := GetDesktopFiles(locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(paths, result.Paths()); diff != "" {
+		t.Errorf("Paths mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetDesktopFilesFilter(t *testing.T) {
+	dir := t.TempDir()
+	app := "[Desktop Entry]\nType=Application\nName=App\nExec=app\n"
+	writeFiles(t, dir, map[string]string{
+		"user/hidden.desktop":      "[Desktop Entry]\nHidden=true\n",
+		"system/hidden.desktop":    app + "MimeType=text/plain;\n",
+		"system/nodisplay.desktop": app + "NoDisplay=true\nMimeType=text/plain;\n",
+		"system/editor.desktop":    app + "MimeType=text/plain;\n",
+		"system/tool.desktop":      app,
+		"system/link.desktop":      "[Desktop Entry]\nType=Link\nName=Link\nURL=https://a.b\n",
+		"system/invalid.desktop":   "[Desktop Entry]\nType=Application\n",
+	})
+	locations := []string{filepath.Join(dir, "user"), filepath.Join(dir, "system")}
+
+	tests := []struct {
+		name     string
+		opts     []ScanOption
+		expected []string
+	}{
+		{
+			name: "SkipHidden",
+			opts: []ScanOption{SkipHidden()},
+			expected: []string{
+				"editor.desktop",
+				"link.desktop",
+				"nodisplay.desktop",
+				"tool.desktop",
+			},
+		},
+		{
+			name:     "OnlyType",
+			opts:     []ScanOption{OnlyType(TypeLink)},
+			expected: []string{"link.desktop"},
+		},
+		{
+			name: "SkipNoDisplay and RequireMimeType",
+			opts: []ScanOption{SkipNoDisplay(), RequireMimeType()},
+			// The hidden user file has no MimeType, the system file is not in effect.
+			expected: []string{"editor.desktop"},
+		},
+		{
+			name: "ScanFilter",
+			opts: []ScanOption{ScanFilter(func(desktopId string, entry *Entry) bool {
+				return desktopId == "tool.desktop"
+			})},
+			expected: []string{"tool.desktop"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := GetDesktopFiles(locations, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.expected, slices.Sorted(maps.Keys(result))); diff != "" {
+				t.Errorf("GetDesktopFiles mismatch (-want +got):\n%s", diff)
+			}
+
+			entries, err := GetDesktopFileEntries(locations, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(result, entries.Paths()); diff != "" {
+				t.Errorf("GetDesktopFileEntries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDesktopIDForPath(t *testing.T) {
+	base := filepath.FromSlash("/usr/share/applications")
+	tests := map[string]string{
+		"/usr/share/applications/vim.desktop":          "vim.desktop",
+		"/usr/share/applications/kde/okular.desktop":   "kde-okular.desktop",
+		"/usr/share/applications/a/b/c.desktop":        "a-b-c.desktop",
+		"/usr/share/applications/../other/vim.desktop": "",
+		"/usr/share/applications":                      "",
+		"/usr/share/applications/\xff.desktop":         "",
+	}
+
+	for path, expected := range tests {
+		desktopId, err := DesktopIDForPath(base, filepath.FromSlash(path))
+		switch {
+		case expected == "" && !errors.Is(err, ErrInvalidDesktopId):
+			t.Errorf(
+				"DesktopIDForPath(%q) error = %v, expected: %v",
+				path,
+				err,
+				ErrInvalidDesktopId,
+			)
+		case expected != "" && (err != nil || desktopId != expected):
+			t.Errorf("DesktopIDForPath(%q) = %q, %v, expected: %q", path, desktopId, err, expected)
+		}
+	}
+}
+
+func TestGetDesktopFilesById(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user/app.desktop":          "[Desktop Entry]\n",
+		"system/vendor/app.desktop": "[Desktop Entry]\n",
+		"system/app.desktop":        "[Desktop Entry]\n",
+	})
+	locations := []string{filepath.Join(dir, "user"), filepath.Join(dir, "system")}
+
+	all, err := GetDesktopFiles(locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := GetDesktopFilesById(locations, "app.desktop", "vendor-app.desktop", "missing.desktop")
+	if diff := cmp.Diff(all, result); diff != "" {
+		t.Errorf("GetDesktopFilesById mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadByIdStrict(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"vendor/app.desktop": "[Desktop Entry]\nType=Application\nName=App\nExec=app\n",
+		"broken.desktop":     "[Desktop Entry]\nType=Application\n",
+	})
+
+	entry, path, err := LoadByIdStrict("vendor-app.desktop", []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name.Default != "App" || path != filepath.Join(dir, "vendor", "app.desktop") {
+		t.Errorf(
+			"LoadByIdStrict(vendor-app.desktop) = %s, %s, expected: App",
+			entry.Name.Default,
+			path,
+		)
+	}
+
+	_, _, err = LoadByIdStrict("missing.desktop", []string{dir})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadByIdStrict(missing.desktop) error = %v, expected: %v", err, ErrNotFound)
+	}
+
+	m, err := GetDesktopFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = m.LoadByIdStrict("broken.desktop")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrMissingKey) {
+		t.Errorf(
+			"LoadByIdStrict(broken.desktop) error = %v, expected: %v and %v",
+			err,
+			ErrNotFound,
+			ErrMissingKey,
+		)
+	}
+
+	entry, path, err = m.LoadById("broken.desktop")
+	if entry != nil || path != "" || err != nil {
+		t.Errorf("LoadById(broken.desktop) = %v, %q, %v, expected nothing", entry, path, err)
+	}
+}
+
+func TestIdPathMapLoadAllById(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"user/app.desktop":   "[Desktop Entry]\nType=Application\nName=User\nHidden=true\n",
+		"system/app.desktop": "[Desktop Entry]\nType=Application\nName=System\nExec=system\n",
+	})
+
+	m, err := GetDesktopFiles([]string{filepath.Join(dir, "user"), filepath.Join(dir, "system")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := m.LoadAllById("app.desktop")
+	if len(loaded) != 2 {
+		t.Fatalf("LoadAllById() returned %d entries, expected: 2", len(loaded))
+	}
+
+	user, system := loaded[0], loaded[1]
+	userPath := filepath.Join(dir, "user/app.desktop")
+	if user.Path != userPath || !user.Entry.Hidden || user.Err == nil {
+		t.Errorf("LoadAllById()[0] = %+v, expected the invalid, hidden, user entry", user)
+	}
+	if system.Entry.Name.Default != "System" || system.Err != nil {
+		t.Errorf("LoadAllById()[1] = %+v, expected the system entry", system)
+	}
+
+	if loaded := m.LoadAllById("missing.desktop"); len(loaded) != 0 {
+		t.Errorf("LoadAllById(missing.desktop) = %v, expected no entries", loaded)
+	}
+}
@@ -0,0 +1,94 @@
+package desktop
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// KeyChange is a key whose value differs between two entries, see Diff.
+type KeyChange struct {
+	// Group of the key, e.g. Desktop Entry or Desktop Action new-window.
+	Group string
+
+	// Key including its locale, e.g. Name[nl].
+	Key string
+
+	// Old is the value in the first entry, as written in a desktop file. It is empty if the key
+	// was added.
+	Old string
+
+	// New is the value in the second entry, as written in a desktop file. It is empty if the key
+	// was removed.
+	New string
+}
+
+// Diff returns the keys that differ between the entries, e.g. to show what a user override
+// changes compared to the system desktop file. The entries are compared as they would be written
+// by Encode, which means that keys with their default value are the same as absent keys, e.g.
+// NoDisplay=false, and that localized values and actions are compared key by key.
+//
+// The changes of the Desktop Entry group come first, followed by the other groups sorted by
+// name. The keys of a group are sorted. A nil entry has no keys.
+func Diff(a *Entry, b *Entry) []KeyChange {
+	oldValues := a.keyValues()
+	newValues := b.keyValues()
+
+	var changes []KeyChange
+	groups := slices.Concat(
+		slices.Collect(maps.Keys(oldValues)),
+		slices.Collect(maps.Keys(newValues)),
+	)
+	slices.SortFunc(groups, compareGroups)
+	for _, group := range slices.Compact(groups) {
+		keys := slices.Concat(
+			slices.Collect(maps.Keys(oldValues[group])),
+			slices.Collect(maps.Keys(newValues[group])),
+		)
+		slices.Sort(keys)
+
+		for _, key := range slices.Compact(keys) {
+			oldValue := oldValues[group][key]
+			newValue := newValues[group][key]
+			if oldValue != newValue {
+				changes = append(changes, KeyChange{
+					Group: group,
+					Key:   key,
+					Old:   oldValue,
+					New:   newValue,
+				})
+			}
+		}
+	}
+
+	return changes
+}
+
+// Equal returns whether the entries have the same keys and values, see Diff.
+func (e *Entry) Equal(other *Entry) bool {
+	return len(Diff(e, other)) == 0
+}
+
+// keyValues returns the values of the entry by group and key, as written by Encode.
+func (e *Entry) keyValues() map[string]map[string]string {
+	enc := encoder{values: make(map[string]map[string]string)}
+	if e != nil {
+		e.encode(&enc)
+	}
+
+	return enc.values
+}
+
+// compareGroups orders the Desktop Entry group before the other groups.
+func compareGroups(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == requiredGroupName:
+		return -1
+	case b == requiredGroupName:
+		return 1
+	default:
+		return cmp.Compare(a, b)
+	}
+}
@@ -0,0 +1,79 @@
+package desktop
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Entry{
+		Type: TypeApplication,
+		Name: LocaleString{
+			Default:   "Firefox",
+			Localized: map[string]string{"nl": "Vuurvos"},
+		},
+		Exec: mustExec(t, "firefox %u"),
+		Actions: []Action{{
+			ID:   "new-window",
+			Name: LocaleString{Default: "New Window"},
+			Exec: mustExec(t, "firefox --new-window"),
+		}},
+		NoDisplay: true,
+	}
+	b := &Entry{
+		Type: TypeApplication,
+		Name: LocaleString{
+			Default:   "Firefox",
+			Localized: map[string]string{"nl": "Vuurvos!", "de": "Feuerfuchs"},
+		},
+		Exec: mustExec(t, "firefox %u"),
+		Actions: []Action{{
+			ID:   "new-window",
+			Name: LocaleString{Default: "New Window"},
+			Exec: mustExec(t, "firefox --new-window %u"),
+		}},
+		OtherGroups: map[string]map[string]string{"A": {"X-Key": "1"}},
+	}
+
+	expected := []KeyChange{
+		{Group: "Desktop Entry", Key: "Name[de]", New: "Feuerfuchs"},
+		{Group: "Desktop Entry", Key: "Name[nl]", Old: "Vuurvos", New: "Vuurvos!"},
+		{Group: "Desktop Entry", Key: "NoDisplay", Old: "true"},
+		{Group: "A", Key: "X-Key", New: "1"},
+		{
+			Group: "Desktop Action new-window",
+			Key:   "Exec",
+			Old:   "firefox --new-window",
+			New:   "firefox --new-window %u",
+		},
+	}
+	if diff := cmp.Diff(expected, Diff(a, b)); diff != "" {
+		t.Errorf("Diff() mismatch (-expected +actual):\n%s", diff)
+	}
+
+	if a.Equal(b) {
+		t.Errorf("Equal() = true, expected: false")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := &Entry{
+		Type: TypeLink,
+		Name: LocaleString{Default: "Docs"},
+		URL:  "https://example.com",
+	}
+	b := &Entry{
+		Type:      TypeLink,
+		Name:      LocaleString{Default: "Docs", Localized: map[string]string{}},
+		URL:       "https://example.com",
+		NoDisplay: false,
+	}
+
+	if !a.Equal(b) {
+		t.Errorf("Equal() = false, expected: true, diff: %v", Diff(a, b))
+	}
+
+	if a.Equal(nil) {
+		t.Errorf("Equal(nil) = true, expected: false")
+	}
+}
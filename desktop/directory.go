@@ -0,0 +1,221 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotDirectory is returned when a .directory file does not have Type=Directory.
+var ErrNotDirectory = errors.New("desktop entry is not of type Directory")
+
+// DirectoryEntry presents a .directory file, a desktop entry of type Directory that describes a
+// menu or folder. Directory entries are referenced by the Directory element of menu files and
+// are stored in the desktop-directories subdirectory of the data directories, see
+// GetDirectoryLocations.
+type DirectoryEntry struct {
+	// Version of the Desktop Entry Specification that the entry conforms with.
+	Version string
+
+	// Name is the name of the menu, for example "Development".
+	Name LocaleString
+
+	// Comment is the tooltip of the menu.
+	Comment LocaleString
+
+	// Icon of the menu, see Entry.Icon.
+	Icon IconString
+
+	// NoDisplay means the menu exists but should not be displayed.
+	NoDisplay bool
+
+	// Hidden means the user deleted the directory entry, see Entry.Hidden.
+	Hidden bool
+
+	// OnlyShowIn and NotShowIn identify the desktop environments that should display/not display
+	// the menu, see Entry.OnlyShowIn.
+	OnlyShowIn []string
+	NotShowIn  []string
+
+	// OtherKeys contains the keys of the "Desktop Entry" group that are not part of the
+	// specification, e.g. X-GNOME-Settings-Panel.
+	OtherKeys map[string]string
+}
+
+// ShouldShowIn returns whether the menu should be shown in the current desktop environments, see
+// Entry.ShouldShowIn.
+func (d *DirectoryEntry) ShouldShowIn(currentDesktop string) bool {
+	entry := Entry{OnlyShowIn: d.OnlyShowIn, NotShowIn: d.NotShowIn}
+	return entry.ShouldShowIn(currentDesktop)
+}
+
+// ParseDirectory parses a .directory file. An error matching ErrNotDirectory is returned if the
+// entry is valid but its Type is not Directory.
+func ParseDirectory(reader io.Reader, opts ...ParseOption) (*DirectoryEntry, error) {
+	entry, err := Parse(reader, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Type != TypeDirectory {
+		return nil, fmt.Errorf("ParseDirectory: %w: Type=%s", ErrNotDirectory, entry.Type)
+	}
+
+	return &DirectoryEntry{
+		Version:    entry.Version,
+		Name:       entry.Name,
+		Comment:    entry.Comment,
+		Icon:       entry.Icon,
+		NoDisplay:  entry.NoDisplay,
+		Hidden:     entry.Hidden,
+		OnlyShowIn: entry.OnlyShowIn,
+		NotShowIn:  entry.NotShowIn,
+		OtherKeys:  entry.OtherKeys,
+	}, nil
+}
+
+// LoadDirectoryFile parses the .directory file at path, see ParseDirectory.
+func LoadDirectoryFile(path string) (*DirectoryEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"LoadDirectoryFile: failed to open directory file '%s'. %w",
+			path,
+			err,
+		)
+	}
+	defer file.Close()
+
+	parsed, err := ParseDirectory(file)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"LoadDirectoryFile: failed to parse directory file '%s'. %w",
+			path,
+			err,
+		)
+	}
+
+	return parsed, nil
+}
+
+// GetDirectoryLocations returns the directories where .directory files can be found, in order of
+// priority. These are the desktop-directories subdirectories of the data directories as defined
+// in the [Desktop Menu Specification].
+//
+// [Desktop Menu Specification]: https://specifications.freedesktop.org/menu-spec/latest/paths.html
+func GetDirectoryLocations() []string {
+	locations := make([]string, 0, len(basedir.DataDirs)+1)
+	locations = append(locations, filepath.Join(basedir.DataHome, "desktop-directories"))
+
+	for _, baseDir := range basedir.DataDirs {
+		locations = append(locations, filepath.Join(baseDir, "desktop-directories"))
+	}
+
+	return locations
+}
+
+// GetDirectoryFiles finds the .directory files in the locations and their subdirectories, e.g.
+// GetDirectoryLocations. The returned map is keyed by the path of the file relative to its
+// location, using forward slashes, which is how the Directory element of a menu file refers to
+// it, e.g. Development.directory or kde/kf5-games.directory. The paths of each key are in order
+// of the locations. Locations that do not exist are skipped.
+func GetDirectoryFiles(locations []string) (IdPathMap, error) {
+	return GetDirectoryFilesContext(context.Background(), locations)
+}
+
+// GetDirectoryFilesContext is like GetDirectoryFiles but stops when ctx is done, returning the
+// error of ctx.
+func GetDirectoryFilesContext(ctx context.Context, locations []string) (IdPathMap, error) {
+	result := make(IdPathMap)
+
+	for _, dir := range locations {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if entry.IsDir() || filepath.Ext(path) != ".directory" {
+				return nil
+			}
+
+			relative, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+
+			name := filepath.ToSlash(relative)
+			result[name] = append(result[name], path)
+
+			return nil
+		})
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case ctx.Err() != nil:
+			return result, fmt.Errorf("GetDirectoryFilesContext: %w", ctx.Err())
+		case err != nil:
+			return result, fmt.Errorf(
+				"GetDirectoryFilesContext, failed to walk dir %s for directory files: %w",
+				dir,
+				err,
+			)
+		}
+	}
+
+	return result, nil
+}
+
+// LoadDirectory finds the first valid .directory file with the given name, as used by the
+// Directory element of menu files, parses it and returns the result and the path of the file.
+// If locations is nil, GetDirectoryLocations is used.
+// An error matching ErrNotFound is returned if no valid file could be found, it also contains
+// the errors of the files that could not be loaded.
+// Example of name: Development.directory
+func LoadDirectory(name string, locations []string) (*DirectoryEntry, string, error) {
+	if locations == nil {
+		locations = GetDirectoryLocations()
+	}
+
+	relative := filepath.FromSlash(name)
+	if !filepath.IsLocal(relative) {
+		return nil, "", fmt.Errorf("LoadDirectory: %w: %s", ErrNotFound, name)
+	}
+
+	var errs []error
+	for _, dir := range locations {
+		path := filepath.Join(dir, relative)
+
+		_, err := os.Stat(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			logging.Logger().Warn(
+				"Failed to stat directory file",
+				logging.Path, path,
+				logging.Error, err,
+			)
+			continue
+		}
+
+		parsed, err := LoadDirectoryFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		return parsed, path, nil
+	}
+
+	return nil, "", notFound("LoadDirectory", name, errs)
+}
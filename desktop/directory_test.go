@@ -0,0 +1,104 @@
+package desktop
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDirectory(t *testing.T) {
+	parsed, err := ParseDirectory(strings.NewReader(`[Desktop Entry]
+Type=Directory
+Name=Development
+Name[nl]=Ontwikkeling
+Comment=Programming tools
+Icon=applications-development
+OnlyShowIn=KDE;
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := parsed.Name.ToLocale("nl_BE"); name != "Ontwikkeling" {
+		t.Errorf("Name[nl_BE] = %s, expected: Ontwikkeling", name)
+	}
+	if parsed.Comment.Default != "Programming tools" {
+		t.Errorf("Comment = %s, expected: Programming tools", parsed.Comment.Default)
+	}
+	if parsed.Icon.Default != "applications-development" {
+		t.Errorf("Icon = %s, expected: applications-development", parsed.Icon.Default)
+	}
+	if parsed.ShouldShowIn("GNOME") {
+		t.Errorf("ShouldShowIn(GNOME) = true, expected: false")
+	}
+
+	_, err = ParseDirectory(strings.NewReader("[Desktop Entry]\nType=Link\nName=A\nURL=b\n"))
+	if !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("expected ErrNotDirectory, got: %v", err)
+	}
+}
+
+func TestGetDirectoryFiles(t *testing.T) {
+	user := t.TempDir()
+	system := t.TempDir()
+	directory := "[Desktop Entry]\nType=Directory\nName=%s\n"
+	writeFiles(t, user, map[string]string{
+		"Development.directory": strings.Replace(directory, "%s", "User", 1),
+		"broken.directory":      "[Desktop Entry]\nName=Broken\n",
+	})
+	writeFiles(t, system, map[string]string{
+		"Development.directory":   strings.Replace(directory, "%s", "System", 1),
+		"kde/kf5-games.directory": strings.Replace(directory, "%s", "Games", 1),
+		"broken.directory":        "[Desktop Entry]\nType=Application\nName=A\nExec=a\n",
+		"app.desktop":             "[Desktop Entry]\nType=Application\nName=A\nExec=a\n",
+	})
+
+	locations := []string{user, system, filepath.Join(user, "missing")}
+	result, err := GetDirectoryFiles(locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := IdPathMap{
+		"Development.directory": {
+			filepath.Join(user, "Development.directory"),
+			filepath.Join(system, "Development.directory"),
+		},
+		"broken.directory": {
+			filepath.Join(user, "broken.directory"),
+			filepath.Join(system, "broken.directory"),
+		},
+		"kde/kf5-games.directory": {filepath.Join(system, "kde/kf5-games.directory")},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("GetDirectoryFiles mismatch (-expected +got):\n%s", diff)
+	}
+
+	parsed, path, err := LoadDirectory("Development.directory", locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Name.Default != "User" || path != filepath.Join(user, "Development.directory") {
+		t.Errorf("LoadDirectory = %s, %s, expected the user file", parsed.Name.Default, path)
+	}
+
+	parsed, _, err = LoadDirectory("kde/kf5-games.directory", locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Name.Default != "Games" {
+		t.Errorf("Name = %s, expected: Games", parsed.Name.Default)
+	}
+
+	_, _, err = LoadDirectory("broken.directory", locations)
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("expected ErrNotFound and ErrNotDirectory, got: %v", err)
+	}
+
+	_, _, err = LoadDirectory("../escape.directory", locations)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
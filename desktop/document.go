@@ -0,0 +1,321 @@
+package desktop
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Document is an editable representation of a desktop file. Unlike Entry, it keeps comments,
+// blank lines, the order of groups and keys, and keys unknown to this package. When written, the
+// file is reproduced byte for byte except for the edited keys.
+//
+// Values are stored as they appear in the file, escaped. Use the typed setters such as SetString
+// to escape values.
+type Document struct {
+
+	// preamble contains the lines before the first group, these can only be comments and blank
+	// lines.
+	preamble []string
+	groups   []*documentGroup
+
+	// noFinalNewline is set when the file does not end in a newline.
+	noFinalNewline bool
+
+	// bom is set when the file starts with a UTF-8 byte order mark.
+	bom bool
+}
+
+type documentGroup struct {
+	name  string
+	lines []documentLine
+}
+
+type documentLine struct {
+	raw string
+
+	// key is empty for comments and blank lines.
+	key string
+}
+
+// ParseDocument reads a desktop file into a Document. Only the structure of the file is
+// verified, the values are not parsed. Use Document.Entry to parse the values.
+func ParseDocument(reader io.Reader) (*Document, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("ParseDocument: %w", err)
+	}
+
+	var doc Document
+	if len(content) == 0 {
+		return &doc, nil
+	}
+
+	text := string(content)
+	if strings.HasPrefix(text, utf8BOM) {
+		doc.bom = true
+		text = text[len(utf8BOM):]
+	}
+	doc.noFinalNewline = !strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	var current *documentGroup
+	for i, raw := range strings.Split(text, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			if current == nil {
+				doc.preamble = append(doc.preamble, raw)
+			} else {
+				current.lines = append(current.lines, documentLine{raw: raw})
+			}
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := line[1 : len(line)-1]
+			if doc.group(name) != nil {
+				return nil, fmt.Errorf(
+					"ParseDocument: parse failure at line %d, duplicate group %s",
+					i,
+					name,
+				)
+			}
+
+			current = &documentGroup{name: name, lines: []documentLine{{raw: raw}}}
+			doc.groups = append(doc.groups, current)
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf(
+				"ParseDocument: parse failure at line %d, expected a group, found %s",
+				i,
+				line,
+			)
+		}
+
+		key, _, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !isValidKey(key) {
+			return nil, fmt.Errorf(
+				"ParseDocument: parse failure at line %d, invalid key-value line: %s",
+				i,
+				line,
+			)
+		}
+
+		current.lines = append(current.lines, documentLine{raw: raw, key: key})
+	}
+
+	return &doc, nil
+}
+
+// ParseDocumentFile reads the desktop file at path into a Document.
+func ParseDocumentFile(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseDocumentFile, failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return ParseDocument(file)
+}
+
+// Groups returns the names of the groups in the order they appear in the file.
+func (d *Document) Groups() []string {
+	result := make([]string, 0, len(d.groups))
+	for _, group := range d.groups {
+		result = append(result, group.name)
+	}
+
+	return result
+}
+
+// Keys returns the keys of the group in the order they appear in the file. Localized keys, such
+// as Name[nl], are returned as is.
+func (d *Document) Keys(group string) []string {
+	g := d.group(group)
+	if g == nil {
+		return nil
+	}
+
+	var result []string
+	for _, line := range g.lines {
+		if line.key != "" {
+			result = append(result, line.key)
+		}
+	}
+
+	return result
+}
+
+// Get returns the escaped value of the key in group and whether the key exists.
+func (d *Document) Get(group string, key string) (string, bool) {
+	g := d.group(group)
+	if g == nil {
+		return "", false
+	}
+
+	i := g.index(key)
+	if i == -1 {
+		return "", false
+	}
+
+	_, value, _ := strings.Cut(g.lines[i].raw, "=")
+	return strings.TrimLeft(strings.TrimRight(value, " \t\r"), " \t"), true
+}
+
+// Set sets the key in group to value. The value must already be escaped.
+//
+// An existing key is replaced in place. A new key is added after the last key of the group. If
+// the group does not exist, it is added at the end of the document.
+func (d *Document) Set(group string, key string, value string) error {
+	if !isValidKey(key) || strings.Contains(key, "=") {
+		return fmt.Errorf("Document.Set: invalid key: %s", key)
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("Document.Set: value of key %s contains a newline", key)
+	}
+
+	if group == "" || strings.ContainsAny(group, "[]\r\n") {
+		return fmt.Errorf("Document.Set: invalid group: %s", group)
+	}
+
+	line := documentLine{raw: key + "=" + value, key: key}
+
+	g := d.group(group)
+	if g == nil {
+		if len(d.groups) > 0 {
+			last := d.groups[len(d.groups)-1]
+			if strings.TrimSpace(last.lines[len(last.lines)-1].raw) != "" {
+				last.lines = append(last.lines, documentLine{})
+			}
+		}
+
+		d.groups = append(d.groups, &documentGroup{
+			name:  group,
+			lines: []documentLine{{raw: "[" + group + "]"}, line},
+		})
+		return nil
+	}
+
+	if i := g.index(key); i != -1 {
+		g.lines[i] = line
+		return nil
+	}
+
+	insertAt := 1
+	for i, existing := range g.lines {
+		if existing.key != "" {
+			insertAt = i + 1
+		}
+	}
+	g.lines = append(g.lines[:insertAt], append([]documentLine{line}, g.lines[insertAt:]...)...)
+
+	return nil
+}
+
+// SetString sets the key in group to the escaped form of value.
+func (d *Document) SetString(group string, key string, value string) error {
+	return d.Set(group, key, EscapeString(value))
+}
+
+// SetBoolean sets the key in group to true or false.
+func (d *Document) SetBoolean(group string, key string, value bool) error {
+	return d.Set(group, key, fmt.Sprint(value))
+}
+
+// SetList sets the key in group to the escaped, semicolon separated, values.
+func (d *Document) SetList(group string, key string, values []string) error {
+	return d.Set(group, key, EscapeList(values))
+}
+
+// Remove removes the key from group. It returns false if the key did not exist.
+func (d *Document) Remove(group string, key string) bool {
+	g := d.group(group)
+	if g == nil {
+		return false
+	}
+
+	i := g.index(key)
+	if i == -1 {
+		return false
+	}
+
+	g.lines = append(g.lines[:i], g.lines[i+1:]...)
+	return true
+}
+
+// RemoveGroup removes the group and its keys and comments. It returns false if the group did not
+// exist.
+func (d *Document) RemoveGroup(group string) bool {
+	for i, g := range d.groups {
+		if g.name == group {
+			d.groups = append(d.groups[:i], d.groups[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteTo writes the document to w. It implements io.WriterTo.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(d.Bytes())
+	return int64(n), err
+}
+
+// Bytes returns the content of the document.
+func (d *Document) Bytes() []byte {
+	var lines []string
+	lines = append(lines, d.preamble...)
+	for _, group := range d.groups {
+		for _, line := range group.lines {
+			lines = append(lines, line.raw)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if d.bom {
+		buf.WriteString(utf8BOM)
+	}
+	buf.WriteString(strings.Join(lines, "\n"))
+	if !d.noFinalNewline {
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// Entry parses the document into an Entry using Parse.
+func (d *Document) Entry(opts ...ParseOption) (*Entry, error) {
+	return Parse(bytes.NewReader(d.Bytes()), opts...)
+}
+
+func (d *Document) group(name string) *documentGroup {
+	for _, group := range d.groups {
+		if group.name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// index returns the index of the line containing key or -1 if the key does not exist.
+func (g *documentGroup) index(key string) int {
+	for i, line := range g.lines {
+		if line.key == key {
+			return i
+		}
+	}
+
+	return -1
+}
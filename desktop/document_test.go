@@ -0,0 +1,130 @@
+package desktop
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const documentInput = `# Generated by hand
+[Desktop Entry]
+Type=Application
+# The name
+Name=Test
+Name[nl]=Test NL
+Exec=test %f
+X-Unknown = spaced
+
+# Trailing comment
+
+[Desktop Action one]
+Name=One
+`
+
+func TestDocumentUnchanged(t *testing.T) {
+	for _, input := range []string{documentInput, "", "[A]\r\nB=C\r\n", "[A]\nB=C", "\uFEFF[A]\n"} {
+		doc, err := ParseDocument(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		_, err = doc.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != input {
+			t.Errorf("WriteTo() = %q, expected: %q", buf.String(), input)
+		}
+	}
+}
+
+func TestDocumentEdit(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(documentInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := doc.Get(requiredGroupName, "X-Unknown"); !ok || value != "spaced" {
+		t.Errorf("Get(X-Unknown) = %q, %v, expected: spaced, true", value, ok)
+	}
+
+	steps := []error{
+		doc.SetString(requiredGroupName, "Name", "New name"),
+		doc.SetBoolean(requiredGroupName, "NoDisplay", true),
+		doc.SetList(requiredGroupName, "Categories", []string{"Utility", "a;b"}),
+		doc.Set("X-Extra", "Key", "Value"),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !doc.Remove(requiredGroupName, "Name[nl]") {
+		t.Errorf("Remove(Name[nl]) = false, expected: true")
+	}
+	if !doc.RemoveGroup("Desktop Action one") {
+		t.Errorf("RemoveGroup(Desktop Action one) = false, expected: true")
+	}
+
+	expected := `# Generated by hand
+[Desktop Entry]
+Type=Application
+# The name
+Name=New name
+Exec=test %f
+X-Unknown = spaced
+NoDisplay=true
+Categories=Utility;a\;b;
+
+# Trailing comment
+
+[X-Extra]
+Key=Value
+`
+	if diff := cmp.Diff(expected, string(doc.Bytes())); diff != "" {
+		t.Errorf("Bytes() mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedKeys := []string{"Type", "Name", "Exec", "X-Unknown", "NoDisplay", "Categories"}
+	if keys := doc.Keys(requiredGroupName); !slices.Equal(keys, expectedKeys) {
+		t.Errorf("Keys() = %v, expected: %v", keys, expectedKeys)
+	}
+
+	entry, err := doc.Entry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Name.Default != "New name" || !entry.NoDisplay {
+		t.Errorf("Entry() = %+v, expected the edits to be applied", entry)
+	}
+}
+
+func TestDocumentInvalid(t *testing.T) {
+	inputs := []string{
+		"Key=Value\n",
+		"[A]\nnot a key-value line\n",
+		"[A]\n[A]\n",
+	}
+
+	for _, input := range inputs {
+		_, err := ParseDocument(strings.NewReader(input))
+		if err == nil {
+			t.Errorf("ParseDocument(%q) returned no error", input)
+		}
+	}
+
+	doc, err := ParseDocument(strings.NewReader("[A]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.Set("A", "Key", "a\nb"); err == nil {
+		t.Errorf("Set() with newline in value returned no error")
+	}
+}
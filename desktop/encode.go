@@ -0,0 +1,274 @@
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidEntry = errors.New("invalid desktop entry")
+
+// WriteTo writes the entry in the desktop file format to w. It implements io.WriterTo.
+//
+// The "Desktop Entry" group is written first, followed by the action groups in the order of
+// Actions and the remaining groups of OtherGroups sorted by name. Keys with their default value,
+// e.g. NoDisplay=false, are omitted.
+//
+// An error matching ErrInvalidEntry is returned if the entry cannot be represented as a valid
+// desktop file, e.g. because Type or Name is missing.
+func (e *Entry) WriteTo(w io.Writer) (int64, error) {
+	data, err := e.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Encode returns the entry in the desktop file format. See WriteTo.
+func (e *Entry) Encode() ([]byte, error) {
+	if e.Type == "" {
+		return nil, fmt.Errorf("Encode: %w: Type field is required", ErrInvalidEntry)
+	}
+
+	if e.Name.Default == "" {
+		return nil, fmt.Errorf("Encode: %w: Name field is required", ErrInvalidEntry)
+	}
+
+	var enc encoder
+	e.encode(&enc)
+	if enc.err != nil {
+		return nil, fmt.Errorf("Encode: %w", enc.err)
+	}
+
+	return enc.buf.Bytes(), nil
+}
+
+// encode writes the groups and keys of the entry to enc. Values that cannot be written are
+// skipped and the first problem is recorded in enc.err.
+func (e *Entry) encode(enc *encoder) {
+	enc.group(requiredGroupName)
+	enc.string("Type", e.Type)
+	enc.string("Version", e.Version)
+	enc.localeString("Name", e.Name)
+	enc.localeString("GenericName", e.GenericName)
+	enc.boolean("NoDisplay", e.NoDisplay)
+	enc.localeString("Comment", e.Comment)
+	enc.localeString("Icon", LocaleString(e.Icon))
+	enc.boolean("Hidden", e.Hidden)
+	enc.list("OnlyShowIn", e.OnlyShowIn)
+	enc.list("NotShowIn", e.NotShowIn)
+	enc.boolean("DBusActivatable", e.DBusActivatable)
+	enc.string("TryExec", e.TryExec)
+	enc.exec("Exec", e.Exec)
+	enc.string("Path", e.Path)
+	enc.boolean("Terminal", e.Terminal)
+
+	actions := make([]Action, 0, len(e.Actions))
+	actionGroups := make(map[string]bool, len(e.Actions))
+	actionIds := make([]string, 0, len(e.Actions))
+	for _, action := range e.Actions {
+		if !isValidKey(action.ID) || strings.ContainsAny(action.ID, "[]") {
+			enc.fail(fmt.Errorf("%w: invalid action ID %q", ErrInvalidEntry, action.ID))
+			continue
+		}
+
+		if action.Name.Default == "" {
+			enc.fail(fmt.Errorf(
+				"%w: Name field is required for action %s",
+				ErrInvalidEntry,
+				action.ID,
+			))
+		}
+
+		actions = append(actions, action)
+		actionIds = append(actionIds, action.ID)
+		actionGroups[desktopActionPrefix+action.ID] = true
+	}
+	enc.list("Actions", actionIds)
+
+	enc.list("MimeType", e.MimeType)
+	enc.list("Categories", e.Categories)
+	enc.list("Implements", e.Implements)
+	enc.localeStrings("Keywords", e.Keywords)
+
+	switch e.StartupNotify {
+	case StartupNotifyTrue:
+		enc.key("StartupNotify", "true")
+	case StartupNotifyFalse:
+		enc.key("StartupNotify", "false")
+	}
+
+	enc.string("StartupWMClass", e.StartupWMClass)
+	enc.string("URL", e.URL)
+	enc.boolean("PrefersNonDefaultGPU", e.PrefersNonDefaultGPU)
+	enc.boolean("SingleMainWindow", e.SingleMainWindow)
+	enc.raw(e.OtherKeys)
+
+	for _, action := range actions {
+		enc.group(desktopActionPrefix + action.ID)
+		enc.localeString("Name", action.Name)
+		enc.localeString("Icon", LocaleString(action.Icon))
+		enc.exec("Exec", action.Exec)
+	}
+
+	for _, groupName := range slices.Sorted(maps.Keys(e.OtherGroups)) {
+		if actionGroups[groupName] || groupName == requiredGroupName {
+			continue
+		}
+
+		if groupName == "" || strings.ContainsAny(groupName, "[]\n") {
+			enc.fail(fmt.Errorf("%w: invalid group name %q", ErrInvalidEntry, groupName))
+			continue
+		}
+
+		enc.group(groupName)
+		enc.raw(e.OtherGroups[groupName])
+	}
+}
+
+// encoder writes the groups and keys of a desktop file.
+type encoder struct {
+	buf bytes.Buffer
+
+	// err is the first problem encountered.
+	err error
+
+	// values records the written values by group and key if not nil, see Diff.
+	values       map[string]map[string]string
+	currentGroup string
+}
+
+func (enc *encoder) fail(err error) {
+	if enc.err == nil {
+		enc.err = err
+	}
+}
+
+func (enc *encoder) group(name string) {
+	if enc.buf.Len() > 0 {
+		enc.buf.WriteByte('\n')
+	}
+	enc.buf.WriteString("[" + name + "]\n")
+
+	enc.currentGroup = name
+	if enc.values != nil {
+		enc.values[name] = make(map[string]string)
+	}
+}
+
+func (enc *encoder) key(key string, value string) {
+	enc.buf.WriteString(key + "=" + value + "\n")
+
+	if enc.values != nil {
+		enc.values[enc.currentGroup][key] = value
+	}
+}
+
+func (enc *encoder) string(key string, value string) {
+	if value != "" {
+		enc.key(key, EscapeString(value))
+	}
+}
+
+func (enc *encoder) boolean(key string, value bool) {
+	if value {
+		enc.key(key, "true")
+	}
+}
+
+func (enc *encoder) list(key string, value []string) {
+	if len(value) > 0 {
+		enc.key(key, EscapeList(value))
+	}
+}
+
+func (enc *encoder) exec(key string, value ExecValue) {
+	if len(value) > 0 {
+		enc.key(key, value.String())
+	}
+}
+
+func (enc *encoder) localeString(key string, value LocaleString) {
+	enc.string(key, value.Default)
+	for _, locale := range slices.Sorted(maps.Keys(value.Localized)) {
+		enc.string(key+"["+locale+"]", value.Localized[locale])
+	}
+}
+
+func (enc *encoder) localeStrings(key string, value LocaleStrings) {
+	enc.list(key, value.Default)
+	for _, locale := range slices.Sorted(maps.Keys(value.Localized)) {
+		enc.list(key+"["+locale+"]", value.Localized[locale])
+	}
+}
+
+// raw writes the key-value pairs sorted by key. The values are written as is since they are
+// stored unparsed.
+func (enc *encoder) raw(values map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if !isValidKey(key) || strings.Contains(key, "=") {
+			enc.fail(fmt.Errorf("%w: invalid key %q", ErrInvalidEntry, key))
+			continue
+		}
+
+		value := values[key]
+		if strings.ContainsAny(value, "\r\n") {
+			enc.fail(fmt.Errorf("%w: value of key %s contains a newline", ErrInvalidEntry, key))
+			continue
+		}
+
+		enc.key(key, value)
+	}
+}
+
+// EscapeString escapes a value of type string or localestring as described in
+// https://specifications.freedesktop.org/desktop-entry-spec/1.5/value-types.html, e.g. for use
+// with Document.Set or when writing a key file by hand. Leading and trailing spaces are escaped
+// to prevent them from being trimmed.
+func EscapeString(s string) string {
+	var builder strings.Builder
+	builder.Grow(len(s))
+
+	trimmed := strings.TrimLeft(s, " ")
+	leading := len(s) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, " ")
+	trailing := len(s) - leading - len(trimmed)
+
+	builder.WriteString(strings.Repeat(`\s`, leading))
+	for i := 0; i < len(trimmed); i++ {
+		switch trimmed[i] {
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\\':
+			builder.WriteString(`\\`)
+		default:
+			builder.WriteByte(trimmed[i])
+		}
+	}
+	builder.WriteString(strings.Repeat(`\s`, trailing))
+
+	return builder.String()
+}
+
+// EscapeList escapes the values using EscapeString, escapes their semicolons and joins them
+// into a value of a list key such as OnlyShowIn. The result is terminated by a semicolon.
+func EscapeList(list []string) string {
+	var builder strings.Builder
+
+	for _, value := range list {
+		builder.WriteString(strings.ReplaceAll(EscapeString(value), ";", `\;`))
+		builder.WriteByte(';')
+	}
+
+	return builder.String()
+}
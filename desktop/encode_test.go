@@ -0,0 +1,182 @@
+package desktop
+
+import (
+	"bytes"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	entry := &Entry{
+		Type: TypeApplication,
+		Name: LocaleString{
+			Default:   "Firefox",
+			Localized: map[string]string{"nl": "Vuurvos", "de": "Feuerfuchs"},
+		},
+		Comment:    LocaleString{Default: "Browse the\nweb "},
+		Icon:       IconString{Default: "firefox"},
+		OnlyShowIn: []string{"GNOME", "KDE"},
+		Terminal:   true,
+		Actions: []Action{{
+			ID:   "new-window",
+			Name: LocaleString{Default: "New Window"},
+			Exec: mustExec(t, "firefox --new-window %u"),
+		}},
+		Exec:          mustExec(t, `firefox "--profile=a b" %u`),
+		MimeType:      []string{"text/html"},
+		Keywords:      LocaleStrings{Default: []string{"web", "semi;colon"}},
+		StartupNotify: StartupNotifyFalse,
+		OtherKeys:     map[string]string{"X-Custom": `raw\svalue`},
+		OtherGroups: map[string]map[string]string{
+			"X-Extra": {"B": "2", "A": "1"},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := entry.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d, expected: %d", n, buf.Len())
+	}
+
+	expected := `[Desktop Entry]
+Type=Application
+Name=Firefox
+Name[de]=Feuerfuchs
+Name[nl]=Vuurvos
+Comment=Browse the\nweb\s
+Icon=firefox
+OnlyShowIn=GNOME;KDE;
+Exec=firefox "--profile=a b" %u
+Terminal=true
+Actions=new-window;
+MimeType=text/html;
+Keywords=web;semi\;colon;
+StartupNotify=false
+X-Custom=raw\svalue
+
+[Desktop Action new-window]
+Name=New Window
+Exec=firefox --new-window %u
+
+[X-Extra]
+A=1
+B=2
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteTo() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	input := `[Desktop Entry]
+Type=Application
+Version=1.5
+Name=Test
+Name[nl_BE]=Tést
+GenericName=Tester
+Comment=Back\\slash\ttab
+Icon=test
+Icon[nl]=test-nl
+Hidden=true
+NotShowIn=XFCE;
+DBusActivatable=true
+TryExec=test
+Exec=test "a \\"quoted\\" \\$arg" 100%% %F
+Path=/tmp
+Actions=one;two;
+Categories=Utility;
+Implements=org.example.Iface;
+Keywords=a;b\;c;
+Keywords[nl]=d;
+StartupNotify=true
+StartupWMClass=test
+PrefersNonDefaultGPU=true
+SingleMainWindow=true
+
+[Desktop Action one]
+Name=One
+Icon=one
+
+[Desktop Action two]
+Name=Two
+Exec=test --two
+
+[Extra]
+Key=Value
+`
+	entry, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := entry.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reparsed, err := Parse(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Parse() of encoded entry failed: %v\n%s", err, encoded)
+	}
+
+	if diff := cmp.Diff(entry, reparsed, cmp.AllowUnexported(execArgPart{})); diff != "" {
+		t.Errorf("Round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecValueString(t *testing.T) {
+	tests := []string{
+		`test %f`,
+		`"/opt/my app/run" --flag`,
+		`test "a\"b" "$HOME" "x;y" %%i`,
+		`test "hello"%cthere %U`,
+	}
+
+	for _, test := range tests {
+		exec := mustExec(t, test)
+		reparsed := mustExec(t, exec.String())
+		if diff := cmp.Diff(exec, reparsed, cmp.AllowUnexported(execArgPart{})); diff != "" {
+			t.Errorf("NewExec(%q.String()) mismatch (-want +got):\n%s", test, diff)
+		}
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	tests := map[string]*Entry{
+		"missing type": {Name: LocaleString{Default: "Test"}},
+		"missing name": {Type: TypeApplication},
+		"action without name": {
+			Type:    TypeApplication,
+			Name:    LocaleString{Default: "Test"},
+			Actions: []Action{{ID: "one"}},
+		},
+		"invalid other key": {
+			Type:      TypeApplication,
+			Name:      LocaleString{Default: "Test"},
+			OtherKeys: map[string]string{"A=B": "C"},
+		},
+	}
+
+	for name, entry := range tests {
+		_, err := entry.Encode()
+		if !errors.Is(err, ErrInvalidEntry) {
+			t.Errorf("%s: Encode() error = %v, expected: %v", name, err, ErrInvalidEntry)
+		}
+	}
+}
+
+func mustExec(t *testing.T, value string) ExecValue {
+	t.Helper()
+	exec, err := NewExec(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return exec
+}
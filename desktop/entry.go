@@ -2,6 +2,10 @@ package desktop
 
 // Entry presents a Desktop Entry specified by the [Desktop Entry Specification] version 1.5.
 //
+// Entries can be encoded using encoding/json. The fields are encoded using their Go names, which
+// match the keys of the desktop file. See the MarshalJSON methods of LocaleString and ExecValue
+// for the representation of localized and Exec values.
+//
 // [Desktop Entry Specification]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/
 type Entry struct {
 
@@ -184,6 +188,9 @@ type Entry struct {
 
 type Action struct {
 
+	// ID is the identifier of the action as listed in the Actions key, e.g. new-window.
+	ID string
+
 	// Name contains the label that will be shown to the user. Since actions are
 	// always shown in the context of a specific application (that is, as a submenu
 	// of a launcher), this only needs to be unambiguous within one application and
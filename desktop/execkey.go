@@ -78,8 +78,23 @@ type FieldCodeProvider struct {
 	// GetUrls relates to the %U field code.
 	// If the slice is empty, the field code is not expanded.
 	GetUrls func() []string
+
+	// ConvertURLs enables conversion between files and URLs for Exec keys that do not accept
+	// what the caller has. If GetFile or GetFiles is nil, the URLs of GetUrl or GetUrls are used
+	// instead, file URLs are converted to paths and other URLs are given to DownloadURL. If
+	// GetUrl or GetUrls is nil, the files of GetFile or GetFiles are converted to file URLs.
+	ConvertURLs bool
+
+	// DownloadURL is used by ConvertURLs to obtain a local copy of a remote URL for the %f and
+	// %F field codes, e.g. by downloading it to a temporary file. It returns the path of the
+	// copy. URLs that fail to download are left out. If nil, remote URLs are passed as is,
+	// many applications support that.
+	DownloadURL func(url string) (string, error)
 }
 
+// execReservedCharacters must be quoted when used in an argument of the Exec key.
+const execReservedCharacters = " \t\n\"'\\><~|&;$*?#()`"
+
 var (
 	ErrCharacterMustBeQuoted   = errors.New("character must be quoted")
 	ErrEscapeOutsideQuotes     = errors.New("invalid character escaped")
@@ -94,6 +109,35 @@ var (
 // NewExec parses the given strings as an Exec key from the Desktop Entry specification.
 // See https://specifications.freedesktop.org/desktop-entry-spec/1.5/exec-variables.html.
 func NewExec(value string) (ExecValue, error) {
+	return parseExec(value, false)
+}
+
+// SplitExec splits the value into arguments using the quoting and escaping rules of the Exec
+// key, for keys of other formats that share its syntax but define their own field codes, such as
+// the Exec key of .thumbnailer files.
+// Field codes are not interpreted, percent signs are kept as is. Expanding them is up to the
+// caller.
+func SplitExec(value string) ([]string, error) {
+	exec, err := parseExec(value, true)
+	if err != nil {
+		return nil, fmt.Errorf("SplitExec: %w", err)
+	}
+
+	result := make([]string, len(exec))
+	for i, parts := range exec {
+		var builder strings.Builder
+		for _, part := range parts {
+			builder.WriteString(part.arg)
+		}
+		result[i] = builder.String()
+	}
+
+	return result, nil
+}
+
+// parseExec implements NewExec. If rawFieldCodes is set, field codes are kept as literal text
+// instead of being validated and parsed.
+func parseExec(value string, rawFieldCodes bool) (ExecValue, error) {
 	if value == "" {
 		return nil, fmt.Errorf("error: Exec value is empty")
 	}
@@ -164,10 +208,10 @@ func NewExec(value string) (ExecValue, error) {
 			}
 		case '%':
 			switch {
-			case quoted:
+			case quoted || rawFieldCodes:
 				nextArg.WriteByte(char)
 				continue
-			case i+1 > len(value):
+			case i+1 >= len(value):
 				return nil, fmt.Errorf("parseExec: %w", ErrFieldCodeIncomplete)
 			default:
 				fieldCode := value[i+1]
@@ -235,8 +279,99 @@ func NewExec(value string) (ExecValue, error) {
 	return result, nil
 }
 
+// NewExecFromArgs creates an Exec value from literal arguments, e.g. the argv of a command,
+// followed by the given field codes, e.g. 'U', as separate arguments. Use String to obtain the
+// value of the Exec key, quoting and escaping are applied as needed.
+//
+// The field codes f, F, u, U, i, c, and k are supported, at most one of f, F, u, and U may be
+// given. An error is returned for arguments that cannot be represented in an Exec value, i.e.
+// empty arguments and arguments that are not ASCII or contain control characters.
+func NewExecFromArgs(args []string, fieldCodes ...rune) (ExecValue, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("NewExecFromArgs: no program given")
+	}
+
+	result := make(ExecValue, 0, len(args)+len(fieldCodes))
+	for i, arg := range args {
+		if arg == "" {
+			// NewExec drops empty arguments
+			return nil, fmt.Errorf("NewExecFromArgs: argument %d is empty", i)
+		}
+
+		if !isAsciiNoControl(arg) {
+			return nil, fmt.Errorf(
+				"NewExecFromArgs: argument %d must be ASCII without control characters: %q",
+				i,
+				arg,
+			)
+		}
+
+		result = append(result, []execArgPart{{arg: arg}})
+	}
+
+	containsFileFieldCode := false
+	for _, fieldCode := range fieldCodes {
+		switch fieldCode {
+		case 'f', 'F', 'u', 'U':
+			if containsFileFieldCode {
+				return nil, fmt.Errorf("NewExecFromArgs: %w", ErrTooManyFileFieldCodes)
+			}
+			containsFileFieldCode = true
+		case 'i', 'c', 'k':
+		default:
+			return nil, fmt.Errorf("NewExecFromArgs: %w: %c", ErrUnknownFieldCode, fieldCode)
+		}
+
+		result = append(result, []execArgPart{{arg: string(fieldCode), isFieldCode: true}})
+	}
+
+	return result, nil
+}
+
+// String returns the Exec value as it is written in a desktop file, the inverse of NewExec.
+// Arguments containing reserved characters are quoted, after which the general escaping of
+// string values is applied. This means that a backslash in an argument is written as four
+// backslashes.
+// Deprecated field codes, which are dropped while parsing, are not present in the result.
+func (e ExecValue) String() string {
+	var builder strings.Builder
+
+	for i, parts := range e {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+
+		for _, part := range parts {
+			switch {
+			case part.isFieldCode:
+				builder.WriteByte('%')
+				builder.WriteString(part.arg)
+			case strings.ContainsAny(part.arg, execReservedCharacters):
+				builder.WriteByte('"')
+				for j := 0; j < len(part.arg); j++ {
+					switch part.arg[j] {
+					case '"', '`', '$', '\\':
+						builder.WriteByte('\\')
+					}
+					builder.WriteByte(part.arg[j])
+				}
+				builder.WriteByte('"')
+			default:
+				builder.WriteString(strings.ReplaceAll(part.arg, "%", "%%"))
+			}
+		}
+	}
+
+	return EscapeString(builder.String())
+}
+
 // ToArguments converts the Exec value to a list of arguments ready to be passed for execution.
+// Field codes for which the handler provides no value are dropped, see ToArgumentsStrict.
 func (e ExecValue) ToArguments(handler FieldCodeProvider) []string {
+	if handler.ConvertURLs {
+		handler = handler.withConversion()
+	}
+
 	result := make([]string, 0, len(e))
 	var argument strings.Builder
 
@@ -322,3 +457,37 @@ func (e ExecValue) ToArguments(handler FieldCodeProvider) []string {
 
 	return result
 }
+
+// Preview returns the command that ToArguments produces as a single string, for display
+// purposes, e.g. in an "Open with" dialog or a log message. Arguments are quoted for a POSIX
+// shell when needed, which means that the result can be copied into a terminal to run the same
+// command. The command is not executed.
+func (e ExecValue) Preview(handler FieldCodeProvider) string {
+	args := e.ToArguments(handler)
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes the argument for a POSIX shell if it contains characters other than ASCII
+// letters, digits, and characters that have no special meaning to the shell.
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+
+	isSafe := func(r rune) bool {
+		return 'a' <= r && r <= 'z' ||
+			'A' <= r && r <= 'Z' ||
+			'0' <= r && r <= '9' ||
+			strings.ContainsRune("@%+=:,./-_", r)
+	}
+	if strings.IndexFunc(arg, func(r rune) bool { return !isSafe(r) }) == -1 {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -153,6 +153,31 @@ func TestNewExec_UnknownFieldCode(t *testing.T) {
 	}
 }
 
+func TestNewExec_TrailingPercent(t *testing.T) {
+	_, err := NewExec(`test %`)
+
+	if !errors.Is(err, ErrFieldCodeIncomplete) {
+		t.Errorf("err = %v; want ErrFieldCodeIncomplete", err)
+	}
+}
+
+func TestSplitExec(t *testing.T) {
+	result, err := SplitExec(`thumbnailer -s %s %o "out\sfile %%.png" "\\$HOME"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"thumbnailer", "-s", "%s", "%o", "out file %%.png", "$HOME"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("SplitExec = %q; want %q", result, expected)
+	}
+
+	_, err = SplitExec(`thumbnailer %i > out`)
+	if !errors.Is(err, ErrCharacterMustBeQuoted) {
+		t.Errorf("err = %v; want ErrCharacterMustBeQuoted", err)
+	}
+}
+
 func TestExecValue_ToArguments_FCf(t *testing.T) {
 	exec, err := NewExec(`test Well%cHello %f "--location="%k`)
 	if err != nil {
@@ -244,3 +269,89 @@ func TestExecValue_CanOpenFiles(t *testing.T) {
 	test(`test "%f"`, false)
 	test(`test %k`, false)
 }
+
+func TestExecValueStringEscaping(t *testing.T) {
+	tests := map[string]string{
+		`test %f`:                      `test %f`,
+		`test "\\\\"`:                  `test "\\\\"`,
+		`"/opt/my app/run" --flag`:     `"/opt/my app/run" --flag`,
+		`test "a\\"b" "\\$HOME" "x;y"`: `test "a\\"b" "\\$HOME" "x;y"`,
+		`test 100%% "%i"`:              `test 100%% %%i`,
+		`test "hello"%cthere "a\tb"`:   `test hello%cthere "a\tb"`,
+		`test --name="Some App" %U`:    `test --name="Some App" %U`,
+	}
+
+	for input, expected := range tests {
+		exec, err := NewExec(input)
+		if err != nil {
+			t.Errorf("NewExec(%s) error: %v", input, err)
+			continue
+		}
+
+		if actual := exec.String(); actual != expected {
+			t.Errorf("NewExec(%s).String() = %s, expected: %s", input, actual, expected)
+		}
+	}
+}
+
+func TestNewExecFromArgs(t *testing.T) {
+	exec, err := NewExecFromArgs([]string{"/opt/my app/run", `C:\dir`, "100%", "$HOME"}, 'U', 'i')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `"/opt/my app/run" "C:\\\\dir" 100%% "\\$HOME" %U %i`
+	if actual := exec.String(); actual != expected {
+		t.Errorf("String() = %s, expected: %s", actual, expected)
+	}
+
+	reparsed, err := NewExec(exec.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := reparsed.ToArguments(FieldCodeProvider{
+		GetUrls: func() []string {
+			return []string{"https://example.com"}
+		},
+	})
+	expectedArgs := []string{"/opt/my app/run", `C:\dir`, "100%", "$HOME", "https://example.com"}
+	if !slices.Equal(args, expectedArgs) {
+		t.Errorf("ToArguments() = %q, expected: %q", args, expectedArgs)
+	}
+
+	_, err = NewExecFromArgs([]string{"app"}, 'f', 'U')
+	if !errors.Is(err, ErrTooManyFileFieldCodes) {
+		t.Errorf("NewExecFromArgs() error = %v, expected: %v", err, ErrTooManyFileFieldCodes)
+	}
+
+	_, err = NewExecFromArgs([]string{"app"}, 'x')
+	if !errors.Is(err, ErrUnknownFieldCode) {
+		t.Errorf("NewExecFromArgs() error = %v, expected: %v", err, ErrUnknownFieldCode)
+	}
+
+	_, err = NewExecFromArgs(nil)
+	if err == nil {
+		t.Errorf("NewExecFromArgs(nil) returned no error")
+	}
+
+	for _, args := range [][]string{{"/opt/café/run"}, {"app", ""}, {"app", "a\tb"}} {
+		if _, err = NewExecFromArgs(args); err == nil {
+			t.Errorf("NewExecFromArgs(%q) returned no error", args)
+		}
+	}
+}
+
+func TestExecValuePreview(t *testing.T) {
+	exec := mustExec(t, `"/opt/my app/run" --name=%c %U "\\$HOME"`)
+	provider := FieldCodeValues{
+		URLs: []string{"https://example.com/?a=1&b=2", "file:///tmp/it's.txt"},
+		Name: "My App",
+	}.Provider()
+
+	expected := `'/opt/my app/run' '--name=My App' 'https://example.com/?a=1&b=2' ` +
+		`'file:///tmp/it'\''s.txt' '$HOME'`
+	if actual := exec.Preview(provider); actual != expected {
+		t.Errorf("Preview() = %s, expected: %s", actual, expected)
+	}
+}
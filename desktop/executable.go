@@ -0,0 +1,182 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoExec is returned by ResolveExec when the entry has no Exec key.
+var ErrNoExec = errors.New("entry has no Exec key")
+
+// ExecutableOptions configure how the executables of an entry are located by ResolveExec and
+// CheckInstalled. The zero value uses $PATH and the default Flatpak and Snap locations.
+type ExecutableOptions struct {
+	// Path is the list of directories, separated by os.PathListSeparator, in which programs
+	// without a path are looked up. If empty, $PATH is used.
+	Path string
+
+	// Root is prepended to absolute paths, including those of Path, e.g. /run/host to check the
+	// host system from within a Flatpak sandbox.
+	Root string
+
+	// FlatpakInstallations are the Flatpak installation directories. If nil,
+	// $FLATPAK_USER_DIR or $XDG_DATA_HOME/flatpak and $FLATPAK_SYSTEM_DIR or /var/lib/flatpak are
+	// used.
+	FlatpakInstallations []string
+
+	// SnapDir is the directory in which Snap mounts its packages. If empty, /snap is used.
+	SnapDir string
+}
+
+// ResolveExec returns the path of the program that the Exec key runs. A leading env command
+// with its variable assignments, as used by Snap, is skipped, e.g. the program of
+// `env BAMF_DESKTOP_FILE_HINT=/var/lib/snapd/desktop/applications/foo.desktop /snap/bin/foo %U`
+// is /snap/bin/foo.
+//
+// An error matching ErrNotInstalled is returned if the program cannot be found and one matching
+// ErrNoExec if the entry has no Exec key.
+func (e *Entry) ResolveExec(opts ExecutableOptions) (string, error) {
+	program := execProgram(e.Exec.Expand(FieldCodeValues{}))
+	if program == "" {
+		return "", fmt.Errorf("ResolveExec: %w", ErrNoExec)
+	}
+
+	path, err := opts.lookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("ResolveExec: %w: %w", ErrNotInstalled, err)
+	}
+
+	return path, nil
+}
+
+// CheckInstalled is like CheckTryExec but is aware of sandboxed applications and uses the
+// options to locate executables.
+//
+// Applications installed using Flatpak, identified by X-Flatpak, are installed if they are
+// deployed in one of the Flatpak installations. Their TryExec refers to a path inside the
+// sandbox and is ignored. Applications installed using Snap, identified by X-SnapInstanceName,
+// are installed if the snap is mounted. For other entries, TryExec is checked if present,
+// otherwise the program of Exec is, see ResolveExec. Entries without either are considered
+// installed.
+//
+// If the application is not installed, an error matching ErrNotInstalled is returned.
+func (e *Entry) CheckInstalled(opts ExecutableOptions) error {
+	if id := e.FlatpakID(); id != "" {
+		for _, installation := range opts.flatpakInstallations() {
+			info, err := os.Stat(opts.rooted(filepath.Join(installation, "app", id)))
+			if err == nil && info.IsDir() {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("CheckInstalled: %w: flatpak %s is not deployed", ErrNotInstalled, id)
+	}
+
+	if name := e.SnapInstanceName(); name != "" {
+		snapDir := opts.SnapDir
+		if snapDir == "" {
+			snapDir = "/snap"
+		}
+
+		_, err := os.Stat(opts.rooted(filepath.Join(snapDir, name, "current")))
+		if err != nil {
+			return fmt.Errorf("CheckInstalled: %w: snap %s: %w", ErrNotInstalled, name, err)
+		}
+
+		return nil
+	}
+
+	if e.TryExec != "" {
+		_, err := opts.lookPath(e.TryExec)
+		if err != nil {
+			return fmt.Errorf("CheckInstalled: %w: %w", ErrNotInstalled, err)
+		}
+
+		return nil
+	}
+
+	_, err := e.ResolveExec(opts)
+	if err != nil && !errors.Is(err, ErrNoExec) {
+		return fmt.Errorf("CheckInstalled: %w", err)
+	}
+
+	return nil
+}
+
+// execProgram returns the program of the arguments, skipping a leading env command and its
+// variable assignments.
+func execProgram(args []string) string {
+	if len(args) > 0 && filepath.Base(args[0]) == "env" {
+		args = args[1:]
+		for len(args) > 0 && (strings.Contains(args[0], "=") || strings.HasPrefix(args[0], "-")) {
+			args = args[1:]
+		}
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	return args[0]
+}
+
+// lookPath finds the executable like exec.LookPath but uses the Path and Root of the options.
+func (o ExecutableOptions) lookPath(file string) (string, error) {
+	if o.Path == "" && o.Root == "" {
+		return exec.LookPath(file)
+	}
+
+	if strings.ContainsRune(file, '/') || strings.ContainsRune(file, filepath.Separator) {
+		return exec.LookPath(o.rooted(file))
+	}
+
+	searchPath := o.Path
+	if searchPath == "" {
+		searchPath = os.Getenv("PATH")
+	}
+
+	for _, dir := range filepath.SplitList(searchPath) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+
+		path, err := exec.LookPath(o.rooted(filepath.Join(dir, file)))
+		if err == nil {
+			return path, nil
+		}
+	}
+
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+// rooted returns the absolute path prefixed with Root.
+func (o ExecutableOptions) rooted(path string) string {
+	if o.Root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(o.Root, path)
+}
+
+func (o ExecutableOptions) flatpakInstallations() []string {
+	if o.FlatpakInstallations != nil {
+		return o.FlatpakInstallations
+	}
+
+	user := os.Getenv("FLATPAK_USER_DIR")
+	if user == "" {
+		user = filepath.Join(basedir.DataHome, "flatpak")
+	}
+
+	system := os.Getenv("FLATPAK_SYSTEM_DIR")
+	if system == "" {
+		system = "/var/lib/flatpak"
+	}
+
+	return []string{user, system}
+}
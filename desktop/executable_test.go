@@ -0,0 +1,97 @@
+package desktop
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveExec(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"opt/bin/app": "#!/bin/sh\n"})
+	program := filepath.Join(root, "opt/bin/app")
+	if err := os.Chmod(program, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", "")
+
+	tests := []struct {
+		exec     string
+		opts     ExecutableOptions
+		expected string
+	}{
+		{"app %U", ExecutableOptions{Path: filepath.Join(root, "opt/bin")}, program},
+		{"app", ExecutableOptions{Path: "/opt/bin", Root: root}, program},
+		{"/opt/bin/app", ExecutableOptions{Root: root}, program},
+		{"env -i A=b app", ExecutableOptions{Path: "/usr/bin:/opt/bin", Root: root}, program},
+	}
+
+	for _, test := range tests {
+		entry := &Entry{Exec: mustExec(t, test.exec)}
+		actual, err := entry.ResolveExec(test.opts)
+		if err != nil {
+			t.Errorf("ResolveExec() for %s error: %v", test.exec, err)
+		} else if actual != test.expected {
+			t.Errorf("ResolveExec() for %s = %s, expected: %s", test.exec, actual, test.expected)
+		}
+	}
+
+	entry := &Entry{Exec: mustExec(t, "app")}
+	_, err := entry.ResolveExec(ExecutableOptions{})
+	if !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("ResolveExec() with empty PATH = %v, expected: %v", err, ErrNotInstalled)
+	}
+
+	_, err = (&Entry{}).ResolveExec(ExecutableOptions{})
+	if !errors.Is(err, ErrNoExec) {
+		t.Errorf("ResolveExec() without Exec = %v, expected: %v", err, ErrNoExec)
+	}
+}
+
+func TestCheckInstalled(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"var/lib/flatpak/app/org.example.App/current/active/metadata": "",
+		"snap/foo/current/meta/snap.yaml":                             "",
+	})
+	opts := ExecutableOptions{
+		Path:                 "/usr/bin",
+		Root:                 root,
+		FlatpakInstallations: []string{"/home/user/.local/share/flatpak", "/var/lib/flatpak"},
+	}
+
+	tests := []struct {
+		name      string
+		entry     string
+		installed bool
+	}{
+		{"flatpak", "Exec=/usr/bin/flatpak run org.example.App\nX-Flatpak=org.example.App\n", true},
+		{"missing flatpak", "Exec=/usr/bin/flatpak run org.other\nX-Flatpak=org.other\n", false},
+		{"flatpak TryExec", "TryExec=app\nExec=flatpak run a.B\nX-Flatpak=org.example.App\n", true},
+		{"snap", "Exec=env A=b /snap/bin/foo %U\nX-SnapInstanceName=foo\n", true},
+		{"missing snap", "Exec=/snap/bin/bar\nX-SnapInstanceName=bar\n", false},
+		{"TryExec", "TryExec=missing\nExec=sh\n", false},
+		{"Exec", "Exec=missing %f\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, err := Parse(strings.NewReader(
+				"[Desktop Entry]\nType=Application\nName=App\n" + test.entry,
+			))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = entry.CheckInstalled(opts)
+			switch {
+			case test.installed && err != nil:
+				t.Errorf("CheckInstalled() error: %v", err)
+			case !test.installed && !errors.Is(err, ErrNotInstalled):
+				t.Errorf("CheckInstalled() = %v, expected: %v", err, ErrNotInstalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoExtension is returned by Entry.Extension when the entry does not have the key.
+var ErrNoExtension = errors.New("extension key not present")
+
+// ExtensionDecoder converts the value of an extension key, as stored in Entry.OtherKeys, to a
+// typed value. The value is escaped as it appears in the desktop file.
+type ExtensionDecoder func(value string) (any, error)
+
+var (
+	extensionDecodersMu sync.RWMutex
+	extensionDecoders   = make(map[string]ExtensionDecoder)
+)
+
+// RegisterExtension registers the decoder used by Entry.Extension for the key, e.g.
+// X-GNOME-Autostart-Delay. A previously registered decoder for the key is replaced.
+// It is safe to call RegisterExtension concurrently, but it is usually called from init.
+func RegisterExtension(key string, decoder ExtensionDecoder) {
+	extensionDecodersMu.Lock()
+	defer extensionDecodersMu.Unlock()
+	extensionDecoders[key] = decoder
+}
+
+// Extension returns the decoded value of the extension key in the "Desktop Entry" group. The
+// decoder registered using RegisterExtension is used, if there is none, the value is decoded
+// using DecodeString.
+// An error matching ErrNoExtension is returned if the entry does not have the key.
+func (e *Entry) Extension(key string) (any, error) {
+	value, found := e.OtherKeys[key]
+	if !found {
+		return nil, fmt.Errorf("Extension: %w: %s", ErrNoExtension, key)
+	}
+
+	extensionDecodersMu.RLock()
+	decoder, found := extensionDecoders[key]
+	extensionDecodersMu.RUnlock()
+	if !found {
+		decoder = DecodeString
+	}
+
+	decoded, err := decoder(value)
+	if err != nil {
+		return nil, fmt.Errorf("Extension: failed to decode %s: %w", key, err)
+	}
+
+	return decoded, nil
+}
+
+// ExtensionAs is like Entry.Extension but also asserts the type of the decoded value.
+func ExtensionAs[T any](e *Entry, key string) (T, error) {
+	var zero T
+	value, err := e.Extension(key)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("ExtensionAs: value of %s is a %T, not a %T", key, value, zero)
+	}
+
+	return typed, nil
+}
+
+// DecodeString decodes a value of type string or localestring into a string.
+func DecodeString(value string) (any, error) {
+	return unescapeString(value)
+}
+
+// DecodeBoolean decodes a value of type boolean into a bool.
+func DecodeBoolean(value string) (any, error) {
+	return parseBoolean(value)
+}
+
+// DecodeNumeric decodes a value of type numeric into a float64.
+func DecodeNumeric(value string) (any, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeNumeric, invalid numeric value: %s", value)
+	}
+
+	return number, nil
+}
+
+// DecodeList decodes a list of strings, separated by semicolons, into a []string.
+func DecodeList(value string) (any, error) {
+	if value == "" {
+		return []string{}, nil
+	}
+
+	return splitEscapedString(value)
+}
@@ -0,0 +1,101 @@
+package desktop
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEntryExtension(t *testing.T) {
+	RegisterExtension("X-Test-Delay", DecodeNumeric)
+	RegisterExtension("X-Test-Enabled", DecodeBoolean)
+	RegisterExtension("X-Test-List", DecodeList)
+
+	entry, err := Parse(strings.NewReader(`[Desktop Entry]
+Type=Application
+Name=Test
+Exec=test
+X-Test-Delay=5
+X-Test-Enabled=true
+X-Test-List=a;b\;c;
+X-Test-Text=Hello\sworld
+X-Test-Invalid=maybe
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delay, err := ExtensionAs[float64](entry, "X-Test-Delay")
+	if err != nil || delay != 5 {
+		t.Errorf("X-Test-Delay = %v, %v, expected: 5", delay, err)
+	}
+
+	enabled, err := ExtensionAs[bool](entry, "X-Test-Enabled")
+	if err != nil || !enabled {
+		t.Errorf("X-Test-Enabled = %v, %v, expected: true", enabled, err)
+	}
+
+	list, err := ExtensionAs[[]string](entry, "X-Test-List")
+	if expected := []string{"a", "b;c"}; err != nil || !slices.Equal(list, expected) {
+		t.Errorf("X-Test-List = %q, %v, expected: %q", list, err, expected)
+	}
+
+	text, err := ExtensionAs[string](entry, "X-Test-Text")
+	if err != nil || text != "Hello world" {
+		t.Errorf("X-Test-Text = %q, %v, expected: Hello world", text, err)
+	}
+
+	_, err = ExtensionAs[int](entry, "X-Test-Delay")
+	if err == nil {
+		t.Errorf("ExtensionAs[int](X-Test-Delay) returned no error")
+	}
+
+	RegisterExtension("X-Test-Invalid", DecodeBoolean)
+	_, err = entry.Extension("X-Test-Invalid")
+	if err == nil {
+		t.Errorf("Extension(X-Test-Invalid) returned no error")
+	}
+
+	_, err = entry.Extension("X-Test-Missing")
+	if !errors.Is(err, ErrNoExtension) {
+		t.Errorf("Extension(X-Test-Missing) error = %v, expected: %v", err, ErrNoExtension)
+	}
+}
+
+func TestVendorKeys(t *testing.T) {
+	entry, err := Parse(strings.NewReader(`[Desktop Entry]
+Type=Application
+Name=Test
+Exec=test
+X-Flatpak=org.example.Test
+X-SnapInstanceName=test_1
+X-GNOME-UsesNotifications=true
+X-KDE-RunOnDiscreteGpu=true
+X-MultipleArgs=invalid
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := entry.FlatpakID(); id != "org.example.Test" {
+		t.Errorf("FlatpakID() = %s, expected: org.example.Test", id)
+	}
+	if name := entry.SnapInstanceName(); name != "test_1" {
+		t.Errorf("SnapInstanceName() = %s, expected: test_1", name)
+	}
+	if !entry.UsesNotifications() {
+		t.Errorf("UsesNotifications() = false, expected: true")
+	}
+	if !entry.PrefersDiscreteGPU() {
+		t.Errorf("PrefersDiscreteGPU() = false, expected: true")
+	}
+	if entry.MultipleArgs() {
+		t.Errorf("MultipleArgs() = true, expected: false for an invalid value")
+	}
+
+	empty := &Entry{PrefersNonDefaultGPU: true}
+	if empty.FlatpakID() != "" || empty.UsesNotifications() || !empty.PrefersDiscreteGPU() {
+		t.Errorf("vendor keys of entry without extension keys are not the defaults")
+	}
+}
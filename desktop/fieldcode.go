@@ -0,0 +1,286 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"net/url"
+	"path/filepath"
+)
+
+// FieldCodeValues holds the values of the Exec field codes. It is a simpler alternative to
+// FieldCodeProvider for when the values are known up front.
+type FieldCodeValues struct {
+	// Files relate to the %f and %F field codes.
+	Files []string
+
+	// URLs relate to the %u and %U field codes.
+	URLs []string
+
+	// Icon relates to the %i field code.
+	Icon string
+
+	// Name relates to the %c field code, it should be translated.
+	Name string
+
+	// DesktopFile relates to the %k field code.
+	DesktopFile string
+}
+
+// Provider returns a FieldCodeProvider for the values. If only one of Files and URLs is set,
+// it is used for both using FieldCodeProvider.ConvertURLs.
+func (v FieldCodeValues) Provider() FieldCodeProvider {
+	provider := FieldCodeProvider{ConvertURLs: true}
+
+	if len(v.Files) > 0 {
+		provider.GetFile = func() string {
+			return v.Files[0]
+		}
+		provider.GetFiles = func() []string {
+			return v.Files
+		}
+	}
+
+	if len(v.URLs) > 0 {
+		provider.GetUrl = func() string {
+			return v.URLs[0]
+		}
+		provider.GetUrls = func() []string {
+			return v.URLs
+		}
+	}
+
+	if v.Icon != "" {
+		provider.GetIcon = func() string {
+			return v.Icon
+		}
+	}
+
+	if v.Name != "" {
+		provider.GetName = func() string {
+			return v.Name
+		}
+	}
+
+	if v.DesktopFile != "" {
+		provider.GetDesktopFileLocation = func() string {
+			return v.DesktopFile
+		}
+	}
+
+	return provider
+}
+
+// Expand converts the Exec value to a list of arguments using the values, see ToArguments.
+func (e ExecValue) Expand(values FieldCodeValues) []string {
+	return e.ToArguments(values.Provider())
+}
+
+// ErrFieldCodeUnsatisfied is returned by ToArgumentsStrict when the Exec value requires files or
+// URLs but none were provided.
+var ErrFieldCodeUnsatisfied = errors.New("no value provided for field code")
+
+// ToArgumentsStrict is like ToArguments but returns an error matching ErrFieldCodeUnsatisfied if
+// the Exec value has a %f, %F, %u, or %U field code and the handler did not provide a file or
+// URL for it. ToArguments drops such field codes, which starts the application without anything
+// to open. Callers can use the error to refuse to launch or to pick another application.
+//
+// Field codes that are optional by nature, %i, %c, and %k, are not checked.
+func (e ExecValue) ToArgumentsStrict(handler FieldCodeProvider) ([]string, error) {
+	fieldCode := e.fileFieldCode()
+	if fieldCode == "" {
+		return e.ToArguments(handler), nil
+	}
+
+	if handler.ConvertURLs {
+		handler = handler.withConversion()
+		handler.ConvertURLs = false
+	}
+
+	supplied := false
+	switch fieldCode {
+	case "f":
+		if getFile := handler.GetFile; getFile != nil {
+			handler.GetFile = func() string {
+				file := getFile()
+				supplied = file != ""
+				return file
+			}
+		}
+	case "F":
+		if getFiles := handler.GetFiles; getFiles != nil {
+			handler.GetFiles = func() []string {
+				files := getFiles()
+				supplied = len(files) > 0
+				return files
+			}
+		}
+	case "u":
+		if getUrl := handler.GetUrl; getUrl != nil {
+			handler.GetUrl = func() string {
+				url := getUrl()
+				supplied = url != ""
+				return url
+			}
+		}
+	case "U":
+		if getUrls := handler.GetUrls; getUrls != nil {
+			handler.GetUrls = func() []string {
+				urls := getUrls()
+				supplied = len(urls) > 0
+				return urls
+			}
+		}
+	}
+
+	args := e.ToArguments(handler)
+	if !supplied {
+		return nil, fmt.Errorf("ToArgumentsStrict: %w: %%%s", ErrFieldCodeUnsatisfied, fieldCode)
+	}
+
+	return args, nil
+}
+
+// ExpandStrict is like Expand but uses ToArgumentsStrict.
+func (e ExecValue) ExpandStrict(values FieldCodeValues) ([]string, error) {
+	return e.ToArgumentsStrict(values.Provider())
+}
+
+// ToArgumentsMulti converts the Exec value to one or more argument lists, one per program to
+// start. The specification requires that an application whose Exec key accepts a single file
+// or URL, %f or %u, is started once per file or URL. The files and URLs are obtained from
+// GetFiles and GetUrls, respectively, in that case.
+//
+// For all other Exec keys, or when there are no files or URLs, the result is the single
+// argument list of ToArguments.
+func (e ExecValue) ToArgumentsMulti(handler FieldCodeProvider) [][]string {
+	if handler.ConvertURLs {
+		handler = handler.withConversion()
+		handler.ConvertURLs = false
+	}
+
+	var targets []string
+	switch e.fileFieldCode() {
+	case "f":
+		if handler.GetFiles != nil {
+			targets = handler.GetFiles()
+		}
+	case "u":
+		if handler.GetUrls != nil {
+			targets = handler.GetUrls()
+		}
+	}
+
+	if len(targets) == 0 {
+		return [][]string{e.ToArguments(handler)}
+	}
+
+	result := make([][]string, 0, len(targets))
+	for _, target := range targets {
+		single := handler
+		single.GetFile = func() string {
+			return target
+		}
+		single.GetUrl = func() string {
+			return target
+		}
+		result = append(result, e.ToArguments(single))
+	}
+
+	return result
+}
+
+// withConversion returns the provider with the missing file and URL functions derived from the
+// others, see FieldCodeProvider.ConvertURLs.
+func (p FieldCodeProvider) withConversion() FieldCodeProvider {
+	result := p
+
+	if p.GetFile == nil && p.GetUrl != nil {
+		result.GetFile = func() string {
+			files := p.urlsToFiles([]string{p.GetUrl()})
+			if len(files) == 0 {
+				return ""
+			}
+			return files[0]
+		}
+	}
+
+	if p.GetFiles == nil && p.GetUrls != nil {
+		result.GetFiles = func() []string {
+			return p.urlsToFiles(p.GetUrls())
+		}
+	}
+
+	if p.GetUrl == nil && p.GetFile != nil {
+		result.GetUrl = func() string {
+			return fileToURL(p.GetFile())
+		}
+	}
+
+	if p.GetUrls == nil && p.GetFiles != nil {
+		result.GetUrls = func() []string {
+			files := p.GetFiles()
+			urls := make([]string, 0, len(files))
+			for _, file := range files {
+				urls = append(urls, fileToURL(file))
+			}
+			return urls
+		}
+	}
+
+	return result
+}
+
+// urlsToFiles converts file URLs to paths and downloads remote URLs using DownloadURL.
+func (p FieldCodeProvider) urlsToFiles(urls []string) []string {
+	files := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+
+		if path, err := fileuri.ToPath(u); err == nil {
+			files = append(files, path)
+			continue
+		}
+
+		if p.DownloadURL == nil || !hasScheme(u) {
+			files = append(files, u)
+			continue
+		}
+
+		path, err := p.DownloadURL(u)
+		if err != nil {
+			logging.Logger().Warn(
+				"Failed to download URL",
+				logging.URI, u,
+				logging.Error, err,
+			)
+			continue
+		}
+		files = append(files, path)
+	}
+
+	return files
+}
+
+// fileToURL converts a path to a file URL. Values that already are URLs are returned as is.
+func fileToURL(file string) string {
+	if file == "" || hasScheme(file) {
+		return file
+	}
+
+	uri, err := fileuri.FromPath(file)
+	if err != nil {
+		return file
+	}
+
+	return uri
+}
+
+// hasScheme returns true if the value is a URI rather than a path.
+func hasScheme(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && !filepath.IsAbs(value)
+}
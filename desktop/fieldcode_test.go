@@ -0,0 +1,183 @@
+package desktop
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestToArgumentsConvertURLs(t *testing.T) {
+	urls := []string{"file:///tmp/a%20b", "https://example.com/c", "https://example.com/fail"}
+	provider := FieldCodeProvider{
+		GetUrl: func() string {
+			return urls[0]
+		},
+		GetUrls: func() []string {
+			return urls
+		},
+		ConvertURLs: true,
+		DownloadURL: func(url string) (string, error) {
+			if url == "https://example.com/fail" {
+				return "", errors.New("download failed")
+			}
+			return "/tmp/downloaded", nil
+		},
+	}
+
+	tests := []struct {
+		exec     string
+		provider FieldCodeProvider
+		expected []string
+	}{
+		{"app %f", provider, []string{"app", "/tmp/a b"}},
+		{"app %F", provider, []string{"app", "/tmp/a b", "/tmp/downloaded"}},
+		{"app %U", provider, append([]string{"app"}, urls...)},
+		{
+			"app %U",
+			FieldCodeProvider{
+				GetFiles: func() []string {
+					return []string{"/tmp/a b", "https://example.com/c"}
+				},
+				ConvertURLs: true,
+			},
+			[]string{"app", "file:///tmp/a%20b", "https://example.com/c"},
+		},
+		{
+			"app %F",
+			FieldCodeProvider{
+				GetUrls: func() []string {
+					return urls[1:2]
+				},
+				ConvertURLs: true,
+			},
+			[]string{"app", "https://example.com/c"},
+		},
+		{"app %f", FieldCodeProvider{GetUrl: provider.GetUrl}, []string{"app"}},
+	}
+
+	for _, test := range tests {
+		args := mustExec(t, test.exec).ToArguments(test.provider)
+		if !slices.Equal(args, test.expected) {
+			t.Errorf("ToArguments(%s) = %q, expected: %q", test.exec, args, test.expected)
+		}
+	}
+}
+
+func TestToArgumentsMulti(t *testing.T) {
+	provider := FieldCodeProvider{
+		GetFiles: func() []string {
+			return []string{"/a", "/b"}
+		},
+		GetUrls: func() []string {
+			return []string{"https://a", "https://b"}
+		},
+		GetName: func() string {
+			return "App"
+		},
+	}
+
+	tests := []struct {
+		exec     string
+		expected [][]string
+	}{
+		{"app %f %c", [][]string{{"app", "/a", "App"}, {"app", "/b", "App"}}},
+		{"app --url=%u", [][]string{{"app", "--url=https://a"}, {"app", "--url=https://b"}}},
+		{"app %F", [][]string{{"app", "/a", "/b"}}},
+		{"app", [][]string{{"app"}}},
+	}
+
+	for _, test := range tests {
+		actual := mustExec(t, test.exec).ToArgumentsMulti(provider)
+		if !slices.EqualFunc(actual, test.expected, slices.Equal) {
+			t.Errorf("ToArgumentsMulti(%s) = %q, expected: %q", test.exec, actual, test.expected)
+		}
+	}
+
+	converted := mustExec(t, "app %f").ToArgumentsMulti(FieldCodeProvider{
+		GetUrls: func() []string {
+			return []string{"file:///a", "file:///b"}
+		},
+		ConvertURLs: true,
+	})
+	expected := [][]string{{"app", "/a"}, {"app", "/b"}}
+	if !slices.EqualFunc(converted, expected, slices.Equal) {
+		t.Errorf("ToArgumentsMulti() with ConvertURLs = %q, expected: %q", converted, expected)
+	}
+}
+
+func TestFieldCodeValues(t *testing.T) {
+	values := FieldCodeValues{
+		Files:       []string{"/a b", "/c"},
+		Icon:        "app-icon",
+		Name:        "App",
+		DesktopFile: "/usr/share/applications/app.desktop",
+	}
+
+	tests := []struct {
+		exec     string
+		expected []string
+	}{
+		{"app %f", []string{"app", "/a b"}},
+		{"app %F", []string{"app", "/a b", "/c"}},
+		{"app %U", []string{"app", "file:///a%20b", "file:///c"}},
+		{"app %i %c %k", []string{
+			"app",
+			"--icon",
+			"app-icon",
+			"App",
+			"/usr/share/applications/app.desktop",
+		}},
+	}
+
+	for _, test := range tests {
+		actual := mustExec(t, test.exec).Expand(values)
+		if !slices.Equal(actual, test.expected) {
+			t.Errorf("Expand(%s) = %q, expected: %q", test.exec, actual, test.expected)
+		}
+	}
+
+	empty := mustExec(t, "app %f %i").Expand(FieldCodeValues{})
+	if !slices.Equal(empty, []string{"app"}) {
+		t.Errorf("Expand() without values = %q, expected: %q", empty, []string{"app"})
+	}
+}
+
+func TestToArgumentsStrict(t *testing.T) {
+	tests := []struct {
+		exec      string
+		values    FieldCodeValues
+		expected  []string
+		satisfied bool
+	}{
+		{"app %i", FieldCodeValues{}, []string{"app"}, true},
+		{"app %f", FieldCodeValues{Files: []string{"/a"}}, []string{"app", "/a"}, true},
+		{"app %U", FieldCodeValues{Files: []string{"/a"}}, []string{"app", "file:///a"}, true},
+		{"app %u", FieldCodeValues{URLs: []string{"a:b"}}, []string{"app", "a:b"}, true},
+		{"app %f", FieldCodeValues{}, nil, false},
+		{"app %F", FieldCodeValues{Icon: "icon"}, nil, false},
+		{"app %u", FieldCodeValues{}, nil, false},
+		{"app --open=%U", FieldCodeValues{}, nil, false},
+	}
+
+	for _, test := range tests {
+		actual, err := mustExec(t, test.exec).ExpandStrict(test.values)
+		switch {
+		case test.satisfied && err != nil:
+			t.Errorf("ExpandStrict(%s) returned error: %v", test.exec, err)
+		case !test.satisfied && !errors.Is(err, ErrFieldCodeUnsatisfied):
+			t.Errorf("ExpandStrict(%s) = %v, expected ErrFieldCodeUnsatisfied", test.exec, err)
+		case !slices.Equal(actual, test.expected):
+			t.Errorf("ExpandStrict(%s) = %q, expected: %q", test.exec, actual, test.expected)
+		}
+	}
+
+	// An empty value returned by the handler does not satisfy the field code
+	_, err := mustExec(t, "app %f").ToArgumentsStrict(FieldCodeProvider{
+		GetFile: func() string {
+			return ""
+		},
+	})
+	if !errors.Is(err, ErrFieldCodeUnsatisfied) {
+		t.Errorf("expected ErrFieldCodeUnsatisfied, got: %v", err)
+	}
+}
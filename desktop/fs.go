@@ -0,0 +1,70 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// ParseFS parses the desktop file at path in fsys, see Parse.
+func ParseFS(fsys fs.FS, path string, opts ...ParseOption) (*Entry, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFS, failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return Parse(file, opts...)
+}
+
+// GetDesktopFilesFS is like GetDesktopFiles but finds the desktop files in fsys instead of the
+// file system of the operating system. This allows the use of embedded files and virtual file
+// systems, e.g. in tests.
+//
+// The roots and the returned paths are slash-separated paths in fsys, such as
+// usr/share/applications, see fs.ValidPath. Use ParseFS to parse the returned paths.
+func GetDesktopFilesFS(fsys fs.FS, roots []string) (IdPathMap, error) {
+	result := make(IdPathMap)
+
+	for _, root := range roots {
+		err := fs.WalkDir(fsys, root, func(p string, entry fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			add := isDesktopFile(p, func() (io.ReadCloser, error) {
+				return fsys.Open(p)
+			})
+			if !add {
+				return nil
+			}
+
+			relative := p
+			if root != "." {
+				relative = strings.TrimPrefix(p, root+"/")
+			}
+			desktopId := strings.ReplaceAll(relative, "/", "-")
+			result[desktopId] = append(result[desktopId], p)
+
+			return nil
+		})
+
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return result, fmt.Errorf(
+				"GetDesktopFilesFS, failed to walk dir %s for desktop files: %w",
+				root,
+				err,
+			)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package desktop
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetDesktopFilesFS(t *testing.T) {
+	entry := "[Desktop Entry]\nType=Application\nName=Foo\nExec=foo\n"
+	fsys := fstest.MapFS{
+		"usr/share/applications/foo.desktop":         {Data: []byte(entry)},
+		"usr/share/applications/vendor/bar.desktop":  {Data: []byte(entry)},
+		"usr/share/applications/magic":               {Data: []byte(entry)},
+		"usr/share/applications/notes.txt":           {Data: []byte("hello")},
+		"usr/share/applications/games.directory":     {Data: []byte("[Desktop Entry]\n")},
+		"home/.local/share/applications/foo.desktop": {Data: []byte(entry)},
+	}
+
+	result, err := GetDesktopFilesFS(fsys, []string{
+		"home/.local/share/applications",
+		"usr/share/applications",
+		"missing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := IdPathMap{
+		"foo.desktop": {
+			"home/.local/share/applications/foo.desktop",
+			"usr/share/applications/foo.desktop",
+		},
+		"vendor-bar.desktop": {"usr/share/applications/vendor/bar.desktop"},
+		"magic":              {"usr/share/applications/magic"},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("GetDesktopFilesFS mismatch (-expected +got):\n%s", diff)
+	}
+
+	parsed, err := ParseFS(fsys, result["vendor-bar.desktop"][0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Name.Default != "Foo" {
+		t.Errorf("Name = %s, expected: Foo", parsed.Name.Default)
+	}
+
+	_, err = ParseFS(fsys, "usr/share/applications/missing.desktop")
+	if err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
@@ -0,0 +1,216 @@
+package desktop
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The fields of an entry that are searched, in order of importance.
+const (
+	indexFieldName = iota
+	indexFieldGenericName
+	indexFieldKeywords
+	indexFieldExec
+	indexFieldCount
+)
+
+// indexFieldWeights is the weight of a match in each field.
+var indexFieldWeights = [indexFieldCount]int{8, 4, 3, 1}
+
+// The score of the kinds of matches of a query word.
+const (
+	matchFuzzy     = 1
+	matchSubstring = 2
+	matchPrefix    = 4
+	matchExact     = 6
+)
+
+// Index is a search index over desktop entries, as used by application launchers. Entries are
+// searched by Name, GenericName, Keywords, and the program of Exec.
+type Index struct {
+	locales []string
+	entries []indexEntry
+}
+
+type indexEntry struct {
+	desktopId string
+	name      string
+
+	// words contains the lowercase words of each field.
+	words [indexFieldCount][]string
+}
+
+// NewIndex returns an index of the entries of the IdPathMap, using the entry in effect for each
+// desktop ID, see IdPathMap.LoadEffective. Entries with NoDisplay=true and invalid entries are
+// not indexed.
+//
+// Translated values are indexed in addition to the untranslated ones. The first matching locale
+// of locales is used, if nil, CurrentLocales is used.
+func NewIndex(m IdPathMap, locales []string) *Index {
+	index := NewEmptyIndex(locales)
+	for desktopId := range m {
+		entry, _, err := m.LoadEffective(desktopId)
+		if err != nil || entry == nil || entry.NoDisplay {
+			continue
+		}
+
+		index.Add(desktopId, entry)
+	}
+
+	return index
+}
+
+// NewEmptyIndex returns an index without entries, add entries using Index.Add. See NewIndex for
+// the meaning of locales.
+func NewEmptyIndex(locales []string) *Index {
+	if locales == nil {
+		locales = CurrentLocales()
+	}
+
+	return &Index{locales: locales}
+}
+
+// Add adds the entry to the index. An entry that was previously added with the same desktop ID
+// is replaced.
+func (i *Index) Add(desktopId string, entry *Entry) {
+	indexed := indexEntry{
+		desktopId: desktopId,
+		name:      strings.ToLower(entry.Name.ToLocales(i.locales)),
+	}
+
+	addWords := func(field int, values ...string) {
+		for _, value := range values {
+			for _, word := range strings.FieldsFunc(strings.ToLower(value), isWordSeparator) {
+				if !slices.Contains(indexed.words[field], word) {
+					indexed.words[field] = append(indexed.words[field], word)
+				}
+			}
+		}
+	}
+
+	addWords(indexFieldName, entry.Name.Default, entry.Name.ToLocales(i.locales))
+	addWords(
+		indexFieldGenericName,
+		entry.GenericName.Default,
+		entry.GenericName.ToLocales(i.locales),
+	)
+	addWords(indexFieldKeywords, entry.Keywords.Default...)
+	addWords(indexFieldKeywords, entry.Keywords.ToLocales(i.locales)...)
+
+	if args := entry.Exec.Expand(FieldCodeValues{}); len(args) > 0 {
+		addWords(indexFieldExec, filepath.Base(args[0]))
+	}
+
+	i.Remove(desktopId)
+	i.entries = append(i.entries, indexed)
+}
+
+// Remove removes the entry with the desktop ID from the index.
+func (i *Index) Remove(desktopId string) {
+	i.entries = slices.DeleteFunc(i.entries, func(entry indexEntry) bool {
+		return entry.desktopId == desktopId
+	})
+}
+
+// Search returns the desktop IDs of the entries matching the query, best match first.
+//
+// The query is split into words, an entry matches if every word matches a word of its fields.
+// A word matches exactly, as prefix, as substring, or fuzzily if its characters occur in the
+// same order, e.g. ffx matches firefox. Exact matches rank higher than prefix matches and so on,
+// and matches in Name rank higher than matches in GenericName, Keywords, and Exec, in that
+// order. Entries with the same score are sorted by name.
+func (i *Index) Search(query string) []string {
+	queryWords := strings.FieldsFunc(strings.ToLower(query), isWordSeparator)
+	if len(queryWords) == 0 {
+		return nil
+	}
+
+	type result struct {
+		entry *indexEntry
+		score int
+	}
+
+	var results []result
+	for e := range i.entries {
+		entry := &i.entries[e]
+		total := 0
+		for _, queryWord := range queryWords {
+			score := entry.score(queryWord)
+			if score == 0 {
+				total = 0
+				break
+			}
+			total += score
+		}
+
+		if total > 0 {
+			results = append(results, result{entry: entry, score: total})
+		}
+	}
+
+	slices.SortFunc(results, func(a result, b result) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(a.entry.name, b.entry.name),
+			cmp.Compare(a.entry.desktopId, b.entry.desktopId),
+		)
+	})
+
+	desktopIds := make([]string, 0, len(results))
+	for _, r := range results {
+		desktopIds = append(desktopIds, r.entry.desktopId)
+	}
+
+	return desktopIds
+}
+
+// score returns the score of the best match of the query word in the fields of the entry, or 0
+// if it does not match.
+func (e *indexEntry) score(queryWord string) int {
+	best := 0
+	for field, words := range e.words {
+		for _, word := range words {
+			var kind int
+			switch {
+			case word == queryWord:
+				kind = matchExact
+			case strings.HasPrefix(word, queryWord):
+				kind = matchPrefix
+			case strings.Contains(word, queryWord):
+				kind = matchSubstring
+			case isSubsequence(queryWord, word):
+				kind = matchFuzzy
+			default:
+				continue
+			}
+
+			best = max(best, kind*indexFieldWeights[field])
+		}
+	}
+
+	return best
+}
+
+func isWordSeparator(r rune) bool {
+	switch r {
+	case ' ', '\t', '-', '_', '.', ',', '(', ')', '/':
+		return true
+	default:
+		return false
+	}
+}
+
+// isSubsequence returns whether the characters of sub occur in s in the same order.
+func isSubsequence(sub string, s string) bool {
+	for _, r := range sub {
+		i := strings.IndexRune(s, r)
+		if i == -1 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+
+	return true
+}
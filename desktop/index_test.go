@@ -0,0 +1,78 @@
+package desktop
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndexSearch(t *testing.T) {
+	index := NewEmptyIndex([]string{"nl"})
+	index.Add("firefox.desktop", &Entry{
+		Name: LocaleString{Default: "Firefox"},
+		GenericName: LocaleString{
+			Default:   "Web Browser",
+			Localized: map[string]string{"nl": "Webbrowser"},
+		},
+		Keywords: LocaleStrings{Default: []string{"Internet", "WWW"}},
+		Exec:     mustExec(t, "/usr/lib/firefox/firefox %u"),
+	})
+	index.Add("chromium.desktop", &Entry{
+		Name:        LocaleString{Default: "Chromium"},
+		GenericName: LocaleString{Default: "Web Browser"},
+		Exec:        mustExec(t, "chromium-browser %U"),
+	})
+	index.Add("files.desktop", &Entry{
+		Name:     LocaleString{Default: "Files", Localized: map[string]string{"nl": "Bestanden"}},
+		Keywords: LocaleStrings{Default: []string{"folder", "browser"}},
+		Exec:     mustExec(t, "nautilus"),
+	})
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"firefox", []string{"firefox.desktop"}},
+		{"FIRE", []string{"firefox.desktop"}},
+		{"ffx", []string{"firefox.desktop"}},
+		{"webbrowser", []string{"firefox.desktop"}},
+		{"bestanden", []string{"files.desktop"}},
+		{"nautilus", []string{"files.desktop"}},
+		// Name and GenericName matches rank above the keyword of files.desktop.
+		{"browser", []string{"chromium.desktop", "firefox.desktop", "files.desktop"}},
+		{"web browser", []string{"chromium.desktop", "firefox.desktop"}},
+		{"www", []string{"firefox.desktop"}},
+		{"unknown", []string{}},
+		{"", nil},
+	}
+
+	for _, test := range tests {
+		actual := index.Search(test.query)
+		if !slices.Equal(actual, test.expected) {
+			t.Errorf("Search(%q) = %v, expected: %v", test.query, actual, test.expected)
+		}
+	}
+
+	index.Remove("firefox.desktop")
+	if actual := index.Search("firefox"); len(actual) != 0 {
+		t.Errorf("Search(firefox) after Remove = %v, expected no results", actual)
+	}
+}
+
+func TestNewIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"shown.desktop": "[Desktop Entry]\nType=Application\nName=Shown App\nExec=shown\n",
+		"hidden.desktop": "[Desktop Entry]\nType=Application\nName=Hidden App\nNoDisplay=true\n" +
+			"Exec=hidden\n",
+	})
+
+	m, err := GetDesktopFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := NewIndex(m, nil).Search("app")
+	if expected := []string{"shown.desktop"}; !slices.Equal(actual, expected) {
+		t.Errorf("Search(app) = %v, expected: %v", actual, expected)
+	}
+}
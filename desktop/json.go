@@ -0,0 +1,143 @@
+package desktop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonLocalized is the JSON representation of localized values.
+type jsonLocalized[T any] struct {
+	Default   T            `json:"default"`
+	Localized map[string]T `json:"localized,omitempty"`
+}
+
+// MarshalJSON encodes the value as an object with the untranslated value and the translations
+// by locale, the latter is omitted if there are none, e.g.:
+//
+//	{"default": "Files", "localized": {"nl": "Bestanden"}}
+func (s localized[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLocalized[T]{Default: s.Default, Localized: s.Localized})
+}
+
+// UnmarshalJSON decodes a value encoded by MarshalJSON.
+func (s *localized[T]) UnmarshalJSON(data []byte) error {
+	var decoded jsonLocalized[T]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	s.Default = decoded.Default
+	s.Localized = decoded.Localized
+	return nil
+}
+
+// MarshalJSON encodes the icon like a LocaleString.
+func (s IconString) MarshalJSON() ([]byte, error) {
+	return LocaleString(s).MarshalJSON()
+}
+
+// UnmarshalJSON decodes an icon encoded by MarshalJSON.
+func (s *IconString) UnmarshalJSON(data []byte) error {
+	return (*LocaleString)(s).UnmarshalJSON(data)
+}
+
+// jsonExecPart is the JSON representation of a part of an Exec argument. Exactly one of the
+// fields is set.
+type jsonExecPart struct {
+	Text      string `json:"text,omitempty"`
+	FieldCode string `json:"fieldCode,omitempty"`
+}
+
+// MarshalJSON encodes the Exec value as a list of arguments, each of which is a list of parts.
+// A part is either literal text or a field code, which makes the field codes explicit. E.g. the
+// Exec value `app --name=%c %U` is encoded as:
+//
+//	[
+//		[{"text": "app"}],
+//		[{"text": "--name="}, {"fieldCode": "c"}],
+//		[{"fieldCode": "U"}]
+//	]
+func (e ExecValue) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+
+	args := make([][]jsonExecPart, 0, len(e))
+	for _, parts := range e {
+		arg := make([]jsonExecPart, 0, len(parts))
+		for _, part := range parts {
+			if part.isFieldCode {
+				arg = append(arg, jsonExecPart{FieldCode: part.arg})
+			} else {
+				arg = append(arg, jsonExecPart{Text: part.arg})
+			}
+		}
+		args = append(args, arg)
+	}
+
+	return json.Marshal(args)
+}
+
+// UnmarshalJSON decodes an Exec value encoded by MarshalJSON. The field codes are validated
+// like NewExec does.
+func (e *ExecValue) UnmarshalJSON(data []byte) error {
+	var args [][]jsonExecPart
+	if err := json.Unmarshal(data, &args); err != nil {
+		return err
+	}
+
+	if args == nil {
+		*e = nil
+		return nil
+	}
+
+	result := make(ExecValue, 0, len(args))
+	containsFileFieldCode := false
+	for _, arg := range args {
+		parts := make([]execArgPart, 0, len(arg))
+		for _, part := range arg {
+			switch {
+			case part.FieldCode != "" && part.Text != "":
+				return fmt.Errorf("ExecValue.UnmarshalJSON: part has both text and fieldCode")
+			case part.Text != "":
+				parts = append(parts, execArgPart{arg: part.Text})
+				continue
+			case part.FieldCode == "":
+				continue
+			}
+
+			switch part.FieldCode {
+			case "f", "F", "u", "U":
+				if containsFileFieldCode {
+					return fmt.Errorf("ExecValue.UnmarshalJSON: %w", ErrTooManyFileFieldCodes)
+				}
+				containsFileFieldCode = true
+			case "i", "c", "k":
+			default:
+				return fmt.Errorf(
+					"ExecValue.UnmarshalJSON: %w: %s",
+					ErrUnknownFieldCode,
+					part.FieldCode,
+				)
+			}
+
+			if strings.Contains("FUi", part.FieldCode) && len(arg) > 1 {
+				return fmt.Errorf(
+					"ExecValue.UnmarshalJSON: %w: %%%s",
+					ErrFieldCodeMustBeOwnArg,
+					part.FieldCode,
+				)
+			}
+
+			parts = append(parts, execArgPart{arg: part.FieldCode, isFieldCode: true})
+		}
+
+		if len(parts) > 0 {
+			result = append(result, parts)
+		}
+	}
+
+	*e = result
+	return nil
+}
@@ -0,0 +1,87 @@
+package desktop
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestEntryJSONRoundTrip(t *testing.T) {
+	entry, err := Parse(strings.NewReader(`[Desktop Entry]
+Type=Application
+Name=Files
+Name[nl]=Bestanden
+Icon=files
+Icon[nl]=bestanden
+Keywords=folder;
+Keywords[nl]=map;
+Exec=files --name=%c "a b" %U
+StartupNotify=true
+Actions=new;
+X-Custom=yes
+
+[Desktop Action new]
+Name=New Window
+Exec=files --new
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Entry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := cmp.Diff(entry, &decoded, cmp.Comparer(func(a ExecValue, b ExecValue) bool {
+		return a.String() == b.String()
+	}))
+	if diff != "" {
+		t.Errorf("round trip mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestExecValueJSON(t *testing.T) {
+	data, err := json.Marshal(mustExec(t, `app --name=%c "a b" %U`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[[{"text":"app"}],[{"text":"--name="},{"fieldCode":"c"}],[{"text":"a b"}],` +
+		`[{"fieldCode":"U"}]]`
+	if string(data) != expected {
+		t.Errorf("Marshal() = %s, expected: %s", data, expected)
+	}
+
+	data, err = json.Marshal(LocaleString{Default: "Files"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"default":"Files"}` {
+		t.Errorf("Marshal(LocaleString) = %s, expected: {\"default\":\"Files\"}", data)
+	}
+
+	tests := []struct {
+		json     string
+		expected error
+	}{
+		{`[[{"text":"app"}],[{"fieldCode":"x"}]]`, ErrUnknownFieldCode},
+		{`[[{"text":"app"}],[{"fieldCode":"f"}],[{"fieldCode":"u"}]]`, ErrTooManyFileFieldCodes},
+		{`[[{"text":"app"}],[{"text":"--files="},{"fieldCode":"F"}]]`, ErrFieldCodeMustBeOwnArg},
+	}
+
+	for _, test := range tests {
+		var value ExecValue
+		err := json.Unmarshal([]byte(test.json), &value)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("Unmarshal(%s) = %v, expected: %v", test.json, err, test.expected)
+		}
+	}
+}
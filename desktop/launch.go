@@ -0,0 +1,344 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/activation"
+	"github.com/MatthiasKunnen/xdg/dbusactivation"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNotLaunchable is returned when launching an entry that is not of type Application or
+	// that has no Exec key.
+	ErrNotLaunchable = errors.New("entry cannot be launched")
+
+	// ErrTooManyTargets is returned when multiple targets are given while the Exec key only
+	// accepts one, i.e. uses %f or %u. Such applications must be started once per target, see
+	// ExecValue.ToArgumentsMulti.
+	ErrTooManyTargets = errors.New("Exec key accepts a single target")
+
+	// ErrNoTerminal is returned when launching an entry with Terminal=true without a terminal.
+	ErrNoTerminal = errors.New("entry requires a terminal but none is available")
+
+	// ErrUnknownAction is returned by LaunchAction when the entry has no action with the ID.
+	ErrUnknownAction = errors.New("unknown action")
+)
+
+// LaunchOptions configure Entry.Launch.
+type LaunchOptions struct {
+	// DesktopId of the entry, e.g. org.example.App.desktop. It is required to launch entries
+	// with DBusActivatable=true using D-Bus, without it, the Exec key is used.
+	DesktopId string
+
+	// Targets are the files and URIs to open. Local files can be given as path or as file URI,
+	// file URIs are converted to paths for the %f and %F field codes. If the Exec key has no
+	// field code for files or URIs, the targets are appended to the arguments.
+	Targets []string
+
+	// DesktopFileLocation is the path of the desktop file, used for the %k field code.
+	DesktopFileLocation string
+
+	// Locale is used to translate the name for the %c field code. If empty, the untranslated
+	// name is used.
+	Locale string
+
+	// Terminal is the command used to run applications with Terminal=true, the command of the
+	// application is appended, e.g. []string{"xterm", "-e"}. If empty, ResolveTerminal is used.
+	Terminal []string
+
+	// ResolveTerminal returns the terminal command for applications with Terminal=true when
+	// Terminal is empty, e.g. terminal.Resolver which uses the preferred terminal emulator. If
+	// nil, such applications fail to launch with ErrNoTerminal.
+	ResolveTerminal func() ([]string, error)
+
+	// ActivationToken, if set, allows the application to take focus. It is passed as
+	// XDG_ACTIVATION_TOKEN and DESKTOP_STARTUP_ID, or as platform data when using D-Bus.
+	ActivationToken string
+
+	// TokenProvider obtains the activation token when ActivationToken is empty, e.g.
+	// activation.Default(). It is only used for entries with StartupNotify=true and entries that
+	// are launched using D-Bus, other applications are not known to use the token. If nil, or if
+	// obtaining a token fails, no token is passed.
+	TokenProvider activation.TokenProvider
+
+	// Env is the environment of the application. If nil, the environment of the current process
+	// is used.
+	Env []string
+}
+
+// Command returns the command that runs the application of the entry, without starting it.
+// The Exec key is expanded using the options and Path is used as working directory.
+func (e *Entry) Command(opts LaunchOptions) (*exec.Cmd, error) {
+	if e.Type != TypeApplication || len(e.Exec) == 0 {
+		return nil, fmt.Errorf("Command: %w", ErrNotLaunchable)
+	}
+
+	var files []string
+	for _, target := range opts.Targets {
+		if path, err := fileuri.ToPath(target); err == nil {
+			target = path
+		}
+		files = append(files, target)
+	}
+
+	fileCode := e.Exec.fileFieldCode()
+	if len(opts.Targets) > 1 && (fileCode == "f" || fileCode == "u") {
+		return nil, fmt.Errorf("Command: %w", ErrTooManyTargets)
+	}
+
+	args := e.Exec.Expand(FieldCodeValues{
+		Files:       files,
+		URLs:        opts.Targets,
+		Icon:        e.Icon.Default,
+		Name:        e.Name.ToLocale(opts.Locale),
+		DesktopFile: opts.DesktopFileLocation,
+	})
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Command: %w: Exec expands to nothing", ErrNotLaunchable)
+	}
+
+	if fileCode == "" {
+		args = append(args, files...)
+	}
+
+	if e.Terminal {
+		terminal := opts.Terminal
+		if len(terminal) == 0 && opts.ResolveTerminal != nil {
+			var err error
+			terminal, err = opts.ResolveTerminal()
+			if err != nil {
+				return nil, fmt.Errorf("Command: %w: %w", ErrNoTerminal, err)
+			}
+		}
+		if len(terminal) == 0 {
+			return nil, fmt.Errorf("Command: %w", ErrNoTerminal)
+		}
+		args = append(append([]string{}, terminal...), args...)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = e.Path
+	cmd.Env = opts.Env
+	if opts.ActivationToken != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(
+			cmd.Env,
+			"XDG_ACTIVATION_TOKEN="+opts.ActivationToken,
+			"DESKTOP_STARTUP_ID="+opts.ActivationToken,
+		)
+	}
+
+	return cmd, nil
+}
+
+// Launch starts the application of the entry, see Command for how the command is built.
+//
+// Entries with DBusActivatable=true are launched using the org.freedesktop.Application D-Bus
+// interface if LaunchOptions.DesktopId is set. The application is activated, or, if there are
+// targets, asked to open them. A nil command is returned in that case. If D-Bus activation
+// fails, the Exec key is used if present.
+//
+// The application is started in its own session so that it is not affected by signals sent to
+// the process group of the caller. Launch does not wait for the application to exit, it is
+// waited for in the background to prevent zombie processes. The PID is available using
+// cmd.Process.Pid, the returned command must not be waited for.
+//
+// If the application cannot be launched, the startup sequence of the activation token is ended
+// using activation.Cancel.
+func (e *Entry) Launch(ctx context.Context, opts LaunchOptions) (*exec.Cmd, error) {
+	opts.ActivationToken = activationToken(ctx, e, opts)
+
+	if e.DBusActivatable && opts.DesktopId != "" {
+		err := activate(ctx, "", opts)
+		if err == nil {
+			return nil, nil
+		}
+
+		if len(e.Exec) == 0 {
+			cancelActivationToken(ctx, opts.ActivationToken)
+			return nil, fmt.Errorf("Launch: %w", err)
+		}
+		logging.Logger().Debug(
+			"D-Bus activation failed, using Exec",
+			logging.DesktopId, opts.DesktopId,
+			logging.Error, err,
+		)
+	}
+
+	cmd, err := start(ctx, e, opts)
+	if err != nil {
+		cancelActivationToken(ctx, opts.ActivationToken)
+		return nil, fmt.Errorf("Launch: %w", err)
+	}
+
+	return cmd, nil
+}
+
+// LaunchAction starts the action with the given ID, as listed in the Actions key, like Launch.
+// Entries with DBusActivatable=true use the ActivateAction method, the targets are not passed
+// in that case.
+func (e *Entry) LaunchAction(
+	ctx context.Context,
+	actionId string,
+	opts LaunchOptions,
+) (*exec.Cmd, error) {
+	index := slices.IndexFunc(e.Actions, func(action Action) bool {
+		return action.ID == actionId
+	})
+	if index == -1 {
+		return nil, fmt.Errorf("LaunchAction: %w: %s", ErrUnknownAction, actionId)
+	}
+	action := e.Actions[index]
+	opts.ActivationToken = activationToken(ctx, e, opts)
+
+	if e.DBusActivatable && opts.DesktopId != "" {
+		err := activate(ctx, actionId, opts)
+		if err == nil {
+			return nil, nil
+		}
+
+		if len(action.Exec) == 0 {
+			cancelActivationToken(ctx, opts.ActivationToken)
+			return nil, fmt.Errorf("LaunchAction: %w", err)
+		}
+		logging.Logger().Debug(
+			"D-Bus activation failed, using Exec",
+			logging.DesktopId, opts.DesktopId,
+			logging.Error, err,
+		)
+	}
+
+	actionEntry := *e
+	actionEntry.Exec = action.Exec
+	if action.Icon.Default != "" {
+		actionEntry.Icon = action.Icon
+	}
+
+	cmd, err := start(ctx, &actionEntry, opts)
+	if err != nil {
+		cancelActivationToken(ctx, opts.ActivationToken)
+		return nil, fmt.Errorf("LaunchAction: %w", err)
+	}
+
+	return cmd, nil
+}
+
+// start starts the command of the entry in a new session.
+func start(ctx context.Context, e *Entry, opts LaunchOptions) (*exec.Cmd, error) {
+	cmd, err := e.Command(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	detach(cmd)
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", cmd.Path, err)
+	}
+
+	go cmd.Wait()
+
+	return cmd, nil
+}
+
+// activationToken returns LaunchOptions.ActivationToken or, if the entry uses it, a new token of
+// LaunchOptions.TokenProvider.
+func activationToken(ctx context.Context, e *Entry, opts LaunchOptions) string {
+	if opts.ActivationToken != "" || opts.TokenProvider == nil {
+		return opts.ActivationToken
+	}
+
+	usesDBus := e.DBusActivatable && opts.DesktopId != ""
+	if e.StartupNotify != StartupNotifyTrue && !usesDBus {
+		return ""
+	}
+
+	token, err := opts.TokenProvider(ctx, strings.TrimSuffix(opts.DesktopId, ".desktop"))
+	if err != nil {
+		logging.Logger().Debug(
+			"Failed to obtain activation token",
+			logging.DesktopId, opts.DesktopId,
+			logging.Error, err,
+		)
+		return ""
+	}
+
+	return token
+}
+
+// cancelActivationToken ends the startup sequence of the token after the application failed to
+// launch, see activation.Cancel. The context is only used for its values, the token must also be
+// canceled when ctx is done.
+func cancelActivationToken(ctx context.Context, token string) {
+	err := activation.Cancel(context.WithoutCancel(ctx), token)
+	if err != nil {
+		logging.Logger().Debug(
+			"Failed to cancel activation token",
+			logging.Error, err,
+		)
+	}
+}
+
+// activate launches the application using the org.freedesktop.Application interface. If action
+// is not empty, the action is activated.
+func activate(ctx context.Context, action string, opts LaunchOptions) error {
+	client, err := dbusactivation.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	platformData := dbusactivation.PlatformData{
+		StartupID:       opts.ActivationToken,
+		ActivationToken: opts.ActivationToken,
+	}
+
+	switch {
+	case action != "":
+		return client.ActivateAction(ctx, opts.DesktopId, action, nil, platformData)
+	case len(opts.Targets) == 0:
+		return client.Activate(ctx, opts.DesktopId, platformData)
+	}
+
+	uris := make([]string, 0, len(opts.Targets))
+	for _, target := range opts.Targets {
+		if u, err := url.Parse(target); err != nil || u.Scheme == "" || filepath.IsAbs(target) {
+			target, err = fileuri.FromPath(target)
+			if err != nil {
+				return err
+			}
+		}
+		uris = append(uris, target)
+	}
+
+	return client.Open(ctx, opts.DesktopId, uris, platformData)
+}
+
+// fileFieldCode returns the field code used for files or URIs, f, F, u, or U, or an empty string
+// if there is none.
+func (e ExecValue) fileFieldCode() string {
+	for _, parts := range e {
+		for _, part := range parts {
+			if part.isFieldCode && strings.ContainsAny(part.arg, "fFuU") {
+				return part.arg
+			}
+		}
+	}
+
+	return ""
+}
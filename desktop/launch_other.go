@@ -0,0 +1,11 @@
+//go:build !unix
+
+package desktop
+
+import (
+	"os/exec"
+)
+
+// detach is a no-op, sessions are a Unix concept.
+func detach(cmd *exec.Cmd) {
+}
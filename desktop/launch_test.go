@@ -0,0 +1,229 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"github.com/MatthiasKunnen/xdg/internal/dbus/dbustest"
+	"github.com/google/go-cmp/cmp"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestEntryCommand(t *testing.T) {
+	tests := []struct {
+		exec     string
+		targets  []string
+		expected []string
+	}{
+		{"app %f", []string{"file:///tmp/a%20b"}, []string{"app", "/tmp/a b"}},
+		{"app %u", []string{"file:///tmp/a"}, []string{"app", "file:///tmp/a"}},
+		{"app %F", []string{"/tmp/a", "file:///tmp/b"}, []string{"app", "/tmp/a", "/tmp/b"}},
+		{"app %U", []string{"/tmp/a", "https://x"}, []string{"app", "/tmp/a", "https://x"}},
+		{"app", []string{"file:///tmp/a"}, []string{"app", "/tmp/a"}},
+		{"app %f", nil, []string{"app"}},
+		{"app %i %c %k", nil, []string{"app", "--icon", "icon", "Naam", "/app.desktop"}},
+	}
+
+	for _, test := range tests {
+		entry := &Entry{
+			Type: TypeApplication,
+			Name: LocaleString{Default: "Name", Localized: map[string]string{"nl": "Naam"}},
+			Icon: IconString{Default: "icon"},
+			Exec: mustExec(t, test.exec),
+		}
+
+		cmd, err := entry.Command(LaunchOptions{
+			Targets:             test.targets,
+			DesktopFileLocation: "/app.desktop",
+			Locale:              "nl",
+		})
+		if err != nil {
+			t.Errorf("Command(%s) error: %v", test.exec, err)
+			continue
+		}
+
+		if !slices.Equal(cmd.Args, test.expected) {
+			t.Errorf("Command(%s).Args = %q, expected: %q", test.exec, cmd.Args, test.expected)
+		}
+	}
+}
+
+func TestEntryCommandOptions(t *testing.T) {
+	entry := &Entry{
+		Type:     TypeApplication,
+		Exec:     mustExec(t, "vim %f"),
+		Path:     "/work",
+		Terminal: true,
+	}
+
+	_, err := entry.Command(LaunchOptions{})
+	if !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("Command() error = %v, expected: %v", err, ErrNoTerminal)
+	}
+
+	_, err = entry.Command(LaunchOptions{
+		ResolveTerminal: func() ([]string, error) {
+			return nil, errors.New("no terminal installed")
+		},
+	})
+	if !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("Command() error = %v, expected: %v", err, ErrNoTerminal)
+	}
+
+	cmd, err := entry.Command(LaunchOptions{
+		ResolveTerminal: func() ([]string, error) {
+			return []string{"foot"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"foot", "vim"}; !slices.Equal(cmd.Args, expected) {
+		t.Errorf("Args = %q, expected: %q", cmd.Args, expected)
+	}
+
+	_, err = entry.Command(LaunchOptions{
+		Terminal: []string{"xterm", "-e"},
+		Targets:  []string{"/a", "/b"},
+	})
+	if !errors.Is(err, ErrTooManyTargets) {
+		t.Errorf("Command() error = %v, expected: %v", err, ErrTooManyTargets)
+	}
+
+	cmd, err = entry.Command(LaunchOptions{
+		Terminal:        []string{"xterm", "-e"},
+		ActivationToken: "token",
+		Env:             []string{"A=B"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"xterm", "-e", "vim"}; !slices.Equal(cmd.Args, expected) {
+		t.Errorf("Args = %q, expected: %q", cmd.Args, expected)
+	}
+
+	if cmd.Dir != "/work" {
+		t.Errorf("Dir = %s, expected: /work", cmd.Dir)
+	}
+
+	expectedEnv := []string{"A=B", "XDG_ACTIVATION_TOKEN=token", "DESKTOP_STARTUP_ID=token"}
+	if !slices.Equal(cmd.Env, expectedEnv) {
+		t.Errorf("Env = %q, expected: %q", cmd.Env, expectedEnv)
+	}
+
+	_, err = (&Entry{Type: TypeLink}).Command(LaunchOptions{})
+	if !errors.Is(err, ErrNotLaunchable) {
+		t.Errorf("Command() error = %v, expected: %v", err, ErrNotLaunchable)
+	}
+}
+
+func TestEntryLaunch(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("true is not available")
+	}
+
+	entry := &Entry{Type: TypeApplication, Exec: mustExec(t, "true")}
+	cmd, err := entry.Launch(context.Background(), LaunchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmd.Process == nil || cmd.Process.Pid <= 0 {
+		t.Errorf("Launch() did not start a process")
+	}
+}
+
+func TestActivationToken(t *testing.T) {
+	var appIds []string
+	opts := LaunchOptions{
+		DesktopId: "org.example.App.desktop",
+		TokenProvider: func(ctx context.Context, appId string) (string, error) {
+			appIds = append(appIds, appId)
+			return "token", nil
+		},
+	}
+
+	tests := []struct {
+		entry    Entry
+		expected string
+	}{
+		{Entry{StartupNotify: StartupNotifyTrue}, "token"},
+		{Entry{StartupNotify: StartupNotifyUnset}, ""},
+		{Entry{StartupNotify: StartupNotifyFalse}, ""},
+		{Entry{DBusActivatable: true}, "token"},
+	}
+
+	for _, test := range tests {
+		token := activationToken(context.Background(), &test.entry, opts)
+		if token != test.expected {
+			t.Errorf("activationToken(%+v) = %q, expected: %q", test.entry, token, test.expected)
+		}
+	}
+
+	if expected := []string{"org.example.App", "org.example.App"}; !slices.Equal(appIds, expected) {
+		t.Errorf("TokenProvider app IDs = %v, expected: %v", appIds, expected)
+	}
+
+	opts.ActivationToken = "given"
+	entry := Entry{StartupNotify: StartupNotifyTrue}
+	if token := activationToken(context.Background(), &entry, opts); token != "given" {
+		t.Errorf("activationToken() = %q, expected: given", token)
+	}
+}
+
+func TestEntryLaunchDBus(t *testing.T) {
+	bus := dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		return "", nil, nil
+	})
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address)
+
+	entry := &Entry{
+		Type:            TypeApplication,
+		DBusActivatable: true,
+		Actions:         []Action{{ID: "new-window", Name: LocaleString{Default: "New"}}},
+	}
+	opts := LaunchOptions{DesktopId: "org.example.App.desktop", ActivationToken: "token"}
+
+	cmd, err := entry.Launch(context.Background(), opts)
+	if err != nil || cmd != nil {
+		t.Fatalf("Launch() = %v, %v, expected: nil, nil", cmd, err)
+	}
+
+	opts.Targets = []string{"/tmp/a b", "https://example.com"}
+	_, err = entry.Launch(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = entry.LaunchAction(context.Background(), "new-window", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = entry.LaunchAction(context.Background(), "missing", opts)
+	if !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("LaunchAction() error = %v, expected: %v", err, ErrUnknownAction)
+	}
+
+	calls := bus.Calls()
+	members := make([]string, 0, len(calls))
+	for _, call := range calls {
+		if call.Path != "/org/example/App" {
+			t.Errorf("Call path = %s, expected: /org/example/App", call.Path)
+		}
+		members = append(members, call.Member)
+	}
+
+	expected := []string{"Activate", "Open", "ActivateAction"}
+	if !slices.Equal(members, expected) {
+		t.Fatalf("Calls = %v, expected: %v", members, expected)
+	}
+
+	expectedUris := []any{"file:///tmp/a%20b", "https://example.com"}
+	if diff := cmp.Diff(expectedUris, calls[1].Body[0]); diff != "" {
+		t.Errorf("Open URIs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package desktop
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the command in a new session.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,98 @@
+package desktop
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CurrentLocales returns the locales used for messages as configured by the environment, in
+// order of preference. The first non-empty value of $LANGUAGE, $LC_ALL, $LC_MESSAGES, and $LANG
+// is used, this is the precedence used by gettext and GLib. $LANGUAGE is a colon-separated
+// priority list, e.g. fr:nl, that is ignored when the locale is C or POSIX.
+//
+// An empty list is returned if no locale is configured or if the locale is C or POSIX, in which
+// case the untranslated values must be used.
+func CurrentLocales() []string {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			locale = value
+			break
+		}
+	}
+
+	if isCLocale(locale) {
+		return nil
+	}
+
+	var languages []string
+	for _, language := range strings.Split(os.Getenv("LANGUAGE"), ":") {
+		if language != "" {
+			languages = append(languages, language)
+		}
+	}
+
+	if len(languages) > 0 {
+		return languages
+	}
+
+	return []string{locale}
+}
+
+// CurrentLocale returns the preferred locale of CurrentLocales or an empty string if there is
+// none.
+func CurrentLocale() string {
+	locales := CurrentLocales()
+	if len(locales) == 0 {
+		return ""
+	}
+
+	return locales[0]
+}
+
+// ToCurrentLocale returns the value of the string for the locales configured by the environment,
+// see CurrentLocales and ToLocales.
+func (s *localized[T]) ToCurrentLocale() T {
+	return s.ToLocales(CurrentLocales())
+}
+
+// Locales returns the locales for which the entry has a translation of any of its localized
+// keys, including those of the actions, e.g. [de fr nl_BE]. The locales are sorted and are
+// written as in the desktop file, e.g. sr@latin.
+func (e *Entry) Locales() []string {
+	locales := make(map[string]bool)
+	for _, s := range []LocaleString{
+		e.Name,
+		e.GenericName,
+		e.Comment,
+		LocaleString(e.Icon),
+	} {
+		for locale := range s.Localized {
+			locales[locale] = true
+		}
+	}
+
+	for locale := range e.Keywords.Localized {
+		locales[locale] = true
+	}
+
+	for _, action := range e.Actions {
+		for locale := range action.Name.Localized {
+			locales[locale] = true
+		}
+		for locale := range action.Icon.Localized {
+			locales[locale] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(locales))
+}
+
+// isCLocale returns true for the C and POSIX locales, including variants such as C.UTF-8. The
+// LANGUAGE variable is ignored for these. An unset locale is treated as C.
+func isCLocale(locale string) bool {
+	name, _, _ := strings.Cut(locale, ".")
+	return name == "" || name == "C" || name == "POSIX"
+}
@@ -24,17 +24,36 @@ var localeStringRegex = regexp.MustCompile(
 //
 // [Localized values for keys]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/localized-keys.html
 func (s *localized[T]) ToLocale(locale string) T {
+	return s.ToLocales([]string{locale})
+}
+
+// ToLocales returns the value of the string for the first locale of the priority list that has
+// a value, e.g. the locales of $LANGUAGE. Each locale is matched like ToLocale, the default
+// value is only used if none of the locales match.
+func (s *localized[T]) ToLocales(locales []string) T {
+	for _, locale := range locales {
+		if value, ok := s.lookup(locale); ok {
+			return value
+		}
+	}
+
+	return s.Default
+}
+
+// lookup returns the localized value that best matches the locale, if any.
+func (s *localized[T]) lookup(locale string) (T, bool) {
+	var zero T
 	matches := localeStringRegex.FindStringSubmatch(locale)
 
 	if matches == nil {
-		return s.Default
+		return zero, false
 	}
 
 	lang := matches[1]
 	country := matches[2]
 	modifier := matches[3]
 
-	checks := make([]string, 4)
+	checks := make([]string, 0, 4)
 
 	if country != "" && modifier != "" {
 		checks = append(checks, fmt.Sprintf("%s_%s@%s", lang, country, modifier))
@@ -55,16 +74,16 @@ func (s *localized[T]) ToLocale(locale string) T {
 		switch v := any(maybe).(type) {
 		case string:
 			if v != "" {
-				return maybe
+				return maybe, true
 			}
 		case []string:
 			if v != nil && len(v) > 0 {
-				return maybe
+				return maybe, true
 			}
 		default:
 			panic("unsupported type")
 		}
 	}
 
-	return s.Default
+	return zero, false
 }
@@ -1,6 +1,10 @@
 package desktop
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+)
 
 func sliceToMap[T comparable](src []T) map[T]T {
 	result := make(map[T]T)
@@ -114,3 +118,108 @@ func TestLocaleString_ToLocaleSpecExample(t *testing.T) {
 		t.Fatalf("Expected: %s, got: %s", expected, result)
 	}
 }
+
+func TestCurrentLocale(t *testing.T) {
+	tests := []struct {
+		language string
+		lcAll    string
+		lang     string
+		expected string
+	}{
+		{"", "", "", ""},
+		{"", "", "nl_BE.UTF-8", "nl_BE.UTF-8"},
+		{"", "de_DE", "nl_BE.UTF-8", "de_DE"},
+		{"fr:nl", "", "nl_BE.UTF-8", "fr"},
+		{":fr", "", "nl_BE.UTF-8", "fr"},
+		{"fr", "C.UTF-8", "nl_BE.UTF-8", ""},
+		{"fr", "", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Setenv("LANGUAGE", test.language)
+		t.Setenv("LC_ALL", test.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", test.lang)
+
+		if actual := CurrentLocale(); actual != test.expected {
+			t.Errorf(
+				"CurrentLocale() with LANGUAGE=%s, LC_ALL=%s, LANG=%s = %s, expected: %s",
+				test.language,
+				test.lcAll,
+				test.lang,
+				actual,
+				test.expected,
+			)
+		}
+	}
+
+	t.Setenv("LANGUAGE", "")
+	t.Setenv("LANG", "nl_BE.UTF-8")
+	name := LocaleString{Default: "Default", Localized: map[string]string{"nl": "Standaard"}}
+	if actual := name.ToCurrentLocale(); actual != "Standaard" {
+		t.Errorf("ToCurrentLocale() = %s, expected: Standaard", actual)
+	}
+}
+
+func TestLocaleString_ToLocales(t *testing.T) {
+	lstring := LocaleString{
+		Default:   "Default",
+		Localized: sliceToMap([]string{"nl", "de_DE"}),
+	}
+
+	tests := []struct {
+		locales  []string
+		expected string
+	}{
+		{nil, "Default"},
+		{[]string{"fr"}, "Default"},
+		{[]string{"fr", "nl_BE"}, "nl"},
+		{[]string{"de_DE.UTF-8", "nl"}, "de_DE"},
+		{[]string{"de_AT", "nl"}, "nl"},
+	}
+
+	for _, test := range tests {
+		if actual := lstring.ToLocales(test.locales); actual != test.expected {
+			t.Errorf("ToLocales(%v) = %s, expected: %s", test.locales, actual, test.expected)
+		}
+	}
+
+	t.Setenv("LANGUAGE", "fr:nl")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if actual := lstring.ToCurrentLocale(); actual != "nl" {
+		t.Errorf("ToCurrentLocale() with LANGUAGE=fr:nl = %s, expected: nl", actual)
+	}
+}
+
+func TestEntryLocales(t *testing.T) {
+	entry, err := Parse(strings.NewReader(`[Desktop Entry]
+Type=Application
+Name=App
+Name[nl]=Toep
+Comment[de]=Kommentar
+Icon[sr@latin]=app-sr
+Keywords=a;b;
+Keywords[fr]=c;
+Exec=app
+Actions=new;
+
+[Desktop Action new]
+Name=New
+Name[nl_BE]=Nieuw
+Name[de]=Neu
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"de", "fr", "nl", "nl_BE", "sr@latin"}
+	if actual := entry.Locales(); !slices.Equal(actual, expected) {
+		t.Errorf("Locales() = %v, expected: %v", actual, expected)
+	}
+
+	if actual := (&Entry{}).Locales(); len(actual) != 0 {
+		t.Errorf("Locales() of empty entry = %v, expected: []", actual)
+	}
+}
@@ -0,0 +1,244 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MimeInfoCacheName is the name of the file written by update-desktop-database in each directory
+// containing desktop files.
+const MimeInfoCacheName = "mimeinfo.cache"
+
+const mimeCacheGroupName = "MIME Cache"
+
+// ErrStaleMimeInfoCache is returned by LoadFreshMimeInfoCache if the cache is older than its
+// directory or one of its subdirectories.
+var ErrStaleMimeInfoCache = errors.New("mimeinfo.cache is older than its directory")
+
+// MimeInfoCache maps MIME types to the desktop IDs of the applications that support them, as
+// listed in a mimeinfo.cache file.
+type MimeInfoCache map[string][]string
+
+// ParseMimeInfoCache reads the [MIME Cache] group of a mimeinfo.cache file.
+func ParseMimeInfoCache(reader io.Reader) (MimeInfoCache, error) {
+	sc := newLineScanner(reader, 0)
+	result := make(MimeInfoCache)
+	inGroup := false
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			inGroup = line[1:len(line)-1] == mimeCacheGroupName
+			continue
+		case !inGroup:
+			continue
+		}
+
+		mimeType, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("ParseMimeInfoCache: expected mimetype=.desktop: %s", line)
+		}
+
+		for _, desktopId := range strings.Split(strings.TrimSuffix(value, ";"), ";") {
+			if desktopId != "" && !slices.Contains(result[mimeType], desktopId) {
+				result[mimeType] = append(result[mimeType], desktopId)
+			}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("ParseMimeInfoCache: %w", scanError(err, 0))
+	}
+
+	return result, nil
+}
+
+// LoadMimeInfoCache reads the mimeinfo.cache file at path.
+func LoadMimeInfoCache(path string) (MimeInfoCache, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadMimeInfoCache: %w", err)
+	}
+	defer file.Close()
+
+	return ParseMimeInfoCache(file)
+}
+
+// GetDesktopFilesFromCache is like GetDesktopFiles but avoids opening files. The desktop IDs are
+// collected by reading the directories of each location, only files with the .desktop extension
+// are included and none of them are parsed. The mimeinfo.cache file of each location is used for
+// the MIME associations, which are returned as well. The desktop IDs of each MIME type are in
+// order of precedence.
+// The cache of a location is ignored if it is older than the location or any of its
+// subdirectories, no associations are returned for such locations.
+func GetDesktopFilesFromCache(locations []string) (IdPathMap, MimeInfoCache, error) {
+	return GetDesktopFilesFromCacheContext(context.Background(), locations)
+}
+
+// GetDesktopFilesFromCacheContext is like GetDesktopFilesFromCache but stops when ctx is done,
+// returning the error of ctx.
+func GetDesktopFilesFromCacheContext(
+	ctx context.Context,
+	locations []string,
+) (IdPathMap, MimeInfoCache, error) {
+	result := make(IdPathMap)
+	associations := make(MimeInfoCache)
+
+	for _, dir := range locations {
+		modTime, err := readDesktopIds(ctx, dir, dir, result)
+		switch {
+		case ctx.Err() != nil:
+			return result, associations, fmt.Errorf(
+				"GetDesktopFilesFromCacheContext: %w",
+				ctx.Err(),
+			)
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			return result, associations, fmt.Errorf(
+				"GetDesktopFilesFromCacheContext, failed to read dir %s: %w",
+				dir,
+				err,
+			)
+		}
+
+		cache, err := loadMimeInfoCacheNewerThan(dir, modTime)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				logging.Logger().Debug(
+					"Not using mimeinfo.cache",
+					logging.Path, dir,
+					logging.Error, err,
+				)
+			}
+			continue
+		}
+
+		for mimeType, desktopIds := range cache {
+			for _, desktopId := range desktopIds {
+				if !slices.Contains(associations[mimeType], desktopId) {
+					associations[mimeType] = append(associations[mimeType], desktopId)
+				}
+			}
+		}
+	}
+
+	return result, associations, nil
+}
+
+// LoadFreshMimeInfoCache loads the mimeinfo.cache file of dir, e.g. /usr/share/applications.
+// An error matching ErrStaleMimeInfoCache is returned if the cache is older than dir or any of
+// its subdirectories, which means that desktop files were added or removed since the cache was
+// written. An error matching os.ErrNotExist is returned if dir has no cache.
+func LoadFreshMimeInfoCache(dir string) (MimeInfoCache, error) {
+	var modTime time.Time
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LoadFreshMimeInfoCache: %w", err)
+	}
+
+	cache, err := loadMimeInfoCacheNewerThan(dir, modTime)
+	if err != nil {
+		return nil, fmt.Errorf("LoadFreshMimeInfoCache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// loadMimeInfoCacheNewerThan loads the mimeinfo.cache file of dir if it was not modified before
+// modTime.
+func loadMimeInfoCacheNewerThan(dir string, modTime time.Time) (MimeInfoCache, error) {
+	path := filepath.Join(dir, MimeInfoCacheName)
+	cacheInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if modTime.After(cacheInfo.ModTime()) {
+		return nil, fmt.Errorf("%w: %s", ErrStaleMimeInfoCache, path)
+	}
+
+	return LoadMimeInfoCache(path)
+}
+
+// readDesktopIds adds the .desktop files in dir, and its subdirectories, to result using their
+// desktop ID relative to base. Only directories are stat'ed, the most recent modification time
+// of dir and its subdirectories is returned.
+func readDesktopIds(
+	ctx context.Context,
+	base string,
+	dir string,
+	result IdPathMap,
+) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	modTime := info.ModTime()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return modTime, err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			subModTime, err := readDesktopIds(ctx, base, path, result)
+			if err != nil {
+				return modTime, err
+			}
+			if subModTime.After(modTime) {
+				modTime = subModTime
+			}
+			continue
+		}
+
+		if filepath.Ext(path) != ".desktop" {
+			continue
+		}
+
+		desktopId, err := DesktopIDForPath(base, path)
+		if err != nil || slices.Contains(result[desktopId], path) {
+			continue
+		}
+		result[desktopId] = append(result[desktopId], path)
+	}
+
+	return modTime, nil
+}
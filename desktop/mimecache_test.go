@@ -0,0 +1,106 @@
+package desktop
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMimeInfoCache(t *testing.T) {
+	input := `[MIME Cache]
+text/plain=gedit.desktop;vim.desktop;
+image/png=eog.desktop;eog.desktop;
+
+[Other]
+text/html=ignored.desktop;
+`
+	cache, err := ParseMimeInfoCache(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := MimeInfoCache{
+		"text/plain": {"gedit.desktop", "vim.desktop"},
+		"image/png":  {"eog.desktop"},
+	}
+	if diff := cmp.Diff(expected, cache); diff != "" {
+		t.Errorf("ParseMimeInfoCache() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetDesktopFilesFromCache(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "user")
+	system := filepath.Join(dir, "system")
+	writeFiles(t, dir, map[string]string{
+		"user/vim.desktop":            "[Desktop Entry]\n",
+		"user/okular.desktop":         "[Desktop Entry]\nHidden=true\n",
+		"system/vim.desktop":          "[Desktop Entry]\n",
+		"system/kde/okular.desktop":   "[Desktop Entry]\n",
+		"system/no-mime-type.desktop": "[Desktop Entry]\n",
+		"system/" + MimeInfoCacheName: `[MIME Cache]
+text/plain=vim.desktop;kde-okular.desktop;removed.desktop;
+application/pdf=kde-okular.desktop;
+`,
+	})
+
+	// Make sure the cache is not older than its directory.
+	future := time.Now().Add(time.Hour)
+	err := os.Chtimes(filepath.Join(system, MimeInfoCacheName), future, future)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, associations, err := GetDesktopFilesFromCache([]string{user, system})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := IdPathMap{
+		"vim.desktop": {
+			filepath.Join(user, "vim.desktop"),
+			filepath.Join(system, "vim.desktop"),
+		},
+		"kde-okular.desktop":   {filepath.Join(system, "kde", "okular.desktop")},
+		"no-mime-type.desktop": {filepath.Join(system, "no-mime-type.desktop")},
+		"okular.desktop":       {filepath.Join(user, "okular.desktop")},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("GetDesktopFilesFromCache() mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedAssociations := MimeInfoCache{
+		"text/plain":      {"vim.desktop", "kde-okular.desktop", "removed.desktop"},
+		"application/pdf": {"kde-okular.desktop"},
+	}
+	if diff := cmp.Diff(expectedAssociations, associations); diff != "" {
+		t.Errorf("GetDesktopFilesFromCache() associations mismatch (-want +got):\n%s", diff)
+	}
+
+	// A cache older than a subdirectory is stale, its associations are ignored.
+	later := future.Add(time.Hour)
+	err = os.Chtimes(filepath.Join(system, "kde"), later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadFreshMimeInfoCache(system)
+	if !errors.Is(err, ErrStaleMimeInfoCache) {
+		t.Errorf("LoadFreshMimeInfoCache() error = %v, expected: %v", err, ErrStaleMimeInfoCache)
+	}
+
+	result, associations, err = GetDesktopFilesFromCache([]string{system})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result["no-mime-type.desktop"] == nil {
+		t.Errorf("GetDesktopFilesFromCache() with stale cache did not read the directory")
+	}
+	if len(associations) != 0 {
+		t.Errorf("GetDesktopFilesFromCache() associations = %v, expected none", associations)
+	}
+}
@@ -0,0 +1,94 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotOverride is returned by RemoveHiddenOverride when the user desktop file is not a
+// Hidden=true override.
+var ErrNotOverride = errors.New("desktop file is not a hidden override")
+
+// WriteHiddenOverride hides the desktop entry with the given ID for the current user by writing
+// a minimal desktop file with Hidden=true to $XDG_DATA_HOME/applications. This is the mechanism
+// of the specification to uninstall an entry at the user level, the system desktop file is left
+// untouched. The path of the override is returned.
+//
+// To not lose user data, an error matching os.ErrExist is returned if the user directory already
+// contains a desktop file with the ID that is not hidden. Writing an override when one exists is
+// a no-op. Use RemoveHiddenOverride to show the entry again.
+// Example of desktopId: vim.desktop
+func WriteHiddenOverride(desktopId string) (string, error) {
+	path, err := hiddenOverridePath(desktopId)
+	if err != nil {
+		return "", fmt.Errorf("WriteHiddenOverride: %w", err)
+	}
+
+	existing, err := loadFileLenient(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case existing != nil && existing.Hidden:
+		return path, nil
+	default:
+		return "", fmt.Errorf("WriteHiddenOverride: %w: %s", os.ErrExist, path)
+	}
+
+	override := Entry{
+		Type:   TypeApplication,
+		Hidden: true,
+	}
+	override.Name.Default = strings.TrimSuffix(desktopId, ".desktop")
+
+	data, err := override.Encode()
+	if err != nil {
+		return "", fmt.Errorf("WriteHiddenOverride: %w", err)
+	}
+
+	err = fileutil.WriteFileAtomic(path, data, 0644)
+	if err != nil {
+		return "", fmt.Errorf("WriteHiddenOverride: %w", err)
+	}
+
+	return path, nil
+}
+
+// RemoveHiddenOverride undoes WriteHiddenOverride by removing the user desktop file with the
+// given ID if it has Hidden=true. Nothing happens if there is no user desktop file. An error
+// matching ErrNotOverride is returned if the user desktop file is not hidden.
+func RemoveHiddenOverride(desktopId string) error {
+	path, err := hiddenOverridePath(desktopId)
+	if err != nil {
+		return fmt.Errorf("RemoveHiddenOverride: %w", err)
+	}
+
+	existing, err := loadFileLenient(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case existing == nil || !existing.Hidden:
+		return fmt.Errorf("RemoveHiddenOverride: %w: %s", ErrNotOverride, path)
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("RemoveHiddenOverride: %w", err)
+	}
+
+	return nil
+}
+
+// hiddenOverridePath returns the path of the user desktop file with the given ID.
+func hiddenOverridePath(desktopId string) (string, error) {
+	if !strings.HasSuffix(desktopId, ".desktop") ||
+		strings.HasPrefix(desktopId, "-") ||
+		strings.ContainsAny(desktopId, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDesktopId, desktopId)
+	}
+
+	return filepath.Join(basedir.DataHome, "applications", desktopId), nil
+}
@@ -0,0 +1,69 @@
+package desktop
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteHiddenOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local/share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "usr/share"))
+	basedir.Reinit()
+	t.Cleanup(basedir.Reinit)
+
+	app := "[Desktop Entry]\nType=Application\nName=App\nExec=app\n"
+	writeFiles(t, home, map[string]string{
+		"usr/share/applications/app.desktop":     app,
+		".local/share/applications/mine.desktop": app,
+	})
+
+	path, err := WriteHiddenOverride("app.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(home, ".local/share/applications/app.desktop"); path != expected {
+		t.Errorf("path = %s, expected: %s", path, expected)
+	}
+
+	m, err := GetDesktopFiles(GetDesktopFileLocations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = m.LoadEffective("app.desktop")
+	if !errors.Is(err, ErrDeleted) {
+		t.Errorf("expected ErrDeleted, got: %v", err)
+	}
+
+	if _, err := WriteHiddenOverride("app.desktop"); err != nil {
+		t.Errorf("writing an existing override failed: %v", err)
+	}
+
+	_, err = WriteHiddenOverride("mine.desktop")
+	if !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected os.ErrExist, got: %v", err)
+	}
+
+	err = RemoveHiddenOverride("mine.desktop")
+	if !errors.Is(err, ErrNotOverride) {
+		t.Errorf("expected ErrNotOverride, got: %v", err)
+	}
+
+	if err := RemoveHiddenOverride("app.desktop"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("override still exists: %v", err)
+	}
+	if err := RemoveHiddenOverride("app.desktop"); err != nil {
+		t.Errorf("removing a missing override failed: %v", err)
+	}
+
+	_, err = WriteHiddenOverride("../app.desktop")
+	if !errors.Is(err, ErrInvalidDesktopId) {
+		t.Errorf("expected ErrInvalidDesktopId, got: %v", err)
+	}
+}
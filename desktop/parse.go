@@ -1,11 +1,13 @@
 package desktop
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -15,6 +17,9 @@ const desktopActionPrefix = "Desktop Action "
 const requiredGroupHeader = "[Desktop Entry]"
 const requiredGroupName = "Desktop Entry"
 
+// utf8BOM is the byte order mark that some editors write at the start of UTF-8 files.
+const utf8BOM = "\uFEFF"
+
 const (
 	StartupNotifyUnset = iota
 	StartupNotifyTrue
@@ -35,9 +40,199 @@ const (
 var ErrEscapeIncomplete = errors.New("unexpected end of string, escape sequence not completed")
 var ErrActionHasNoGroup = errors.New("action has no matching Desktop Action Group")
 
-func Parse(reader io.Reader) (*Entry, error) {
+var (
+	// ErrMissingDesktopEntry is used when the file does not start with the [Desktop Entry] group.
+	ErrMissingDesktopEntry = errors.New("expected " + requiredGroupHeader)
+
+	// ErrDuplicateGroup is used when a group occurs multiple times.
+	ErrDuplicateGroup = errors.New("duplicate group")
+
+	// ErrDuplicateKey is used when a key occurs multiple times in a group.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrInvalidLine is used for lines that are not a group header, key-value pair, or comment.
+	ErrInvalidLine = errors.New("invalid line, expected a key-value pair")
+
+	// ErrInvalidKey is used for keys containing invalid characters.
+	ErrInvalidKey = errors.New("invalid key")
+
+	// ErrInvalidValue is used for values that do not match the type of their key.
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrMissingKey is used when a required key, such as Name, is absent.
+	ErrMissingKey = errors.New("missing required key")
+)
+
+// ParseError describes a problem found while parsing a desktop file. Err matches one of the
+// sentinel errors of this package, such as ErrDuplicateKey or ErrInvalidValue, when applicable.
+type ParseError struct {
+	// Line is the 0-based line number or -1 if the error applies to the file as a whole.
+	Line int
+
+	// Group is the name of the group, e.g. Desktop Entry, or empty if the error is not related
+	// to a group.
+	Group string
+
+	// Key is the key, including the locale, or empty if the error is not related to a key.
+	Key string
+
+	// Value is the raw value of the key, if relevant.
+	Value string
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var builder strings.Builder
+	if e.Line >= 0 {
+		fmt.Fprintf(&builder, "parse failure on line %d, ", e.Line)
+	} else {
+		builder.WriteString("invalid desktop file, ")
+	}
+
+	if e.Group != "" {
+		fmt.Fprintf(&builder, "group [%s], ", e.Group)
+	}
+
+	if e.Key != "" {
+		fmt.Fprintf(&builder, "key %s, ", e.Key)
+	}
+
+	if e.Value != "" {
+		fmt.Fprintf(&builder, "value %q, ", e.Value)
+	}
+
+	builder.WriteString(e.Err.Error())
+	return builder.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseIssue is a problem found while parsing a desktop file. Err is a *ParseError.
+type ParseIssue struct {
+	// Line is the 0-based line number, as used in the errors of Parse, or -1 if the issue
+	// applies to the file as a whole.
+	Line int
+
+	Err error
+}
+
+func (i ParseIssue) Error() string {
+	return i.Err.Error()
+}
+
+func (i ParseIssue) Unwrap() error {
+	return i.Err
+}
+
+// Parse parses a desktop file. The first problem encountered is returned as a *ParseError, see
+// ParseLenient to collect all problems instead. The strictness can be changed using opts.
+func Parse(reader io.Reader, opts ...ParseOption) (*Entry, error) {
+	p := newParser(opts)
+	entry := p.parse(reader)
+	if len(p.issues) > 0 {
+		return entry, p.issues[0].Err
+	}
+
+	return entry, nil
+}
+
+// ParseLenient parses the desktop file like Parse but does not stop at the first problem.
+// Malformed lines are skipped, the first occurrence of a duplicate key or group wins, and every
+// problem is returned as a ParseIssue. The returned Entry is always non-nil and contains
+// everything that could be parsed, it can be incomplete, e.g. have no Name.
+//
+// This is useful to show the many slightly broken desktop files that exist in the wild.
+func ParseLenient(reader io.Reader, opts ...ParseOption) (*Entry, []ParseIssue) {
+	p := newParser(opts)
+	p.lenient = true
+	entry := p.parse(reader)
+	return entry, p.issues
+}
+
+// ParseHeader parses only the given keys of the "Desktop Entry" group, e.g. Name, Icon, and
+// NoDisplay, which is much cheaper than Parse for callers that list many entries. Localized
+// variants of the keys, such as Name[nl], are included. If no keys are given, all keys of the
+// "Desktop Entry" group are parsed.
+//
+// Scanning stops at the end of the "Desktop Entry" group or once all requested keys, and the
+// localized variants that directly follow them, have been read. The action groups, OtherGroups,
+// and the validity of the entry as a whole, such as the presence of Name, are not checked.
+// Actions is therefore always empty.
+func ParseHeader(reader io.Reader, keys ...string) (*Entry, error) {
+	p := &parser{headerOnly: true}
+	if len(keys) > 0 {
+		p.headerKeys = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			p.headerKeys[key] = true
+		}
+	}
+
+	entry := p.parse(reader)
+	if len(p.issues) > 0 {
+		return entry, fmt.Errorf("ParseHeader: %w", p.issues[0].Err)
+	}
+
+	return entry, nil
+}
+
+type parser struct {
+	lenient bool
+	issues  []ParseIssue
+
+	// headerOnly is set by ParseHeader, only the keys of headerKeys in the Desktop Entry group
+	// are parsed, or all of them if headerKeys is nil.
+	headerOnly bool
+	headerKeys map[string]bool
+
+	allowDuplicateKeys bool
+	allowUnknownType   bool
+	allowNonASCIIKeys  bool
+	rejectBOM          bool
+	maxFileSize        int64
+	maxLineLength      int
+	maxGroups          int
+	maxKeys            int
+	legacy             bool
+}
+
+func newParser(opts []ParseOption) *parser {
+	var p parser
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	return &p
+}
+
+// report records an issue and returns whether parsing must stop.
+func (p *parser) report(err *ParseError) bool {
+	p.issues = append(p.issues, ParseIssue{Line: err.Line, Err: err})
+	return !p.lenient
+}
+
+func (p *parser) parse(reader io.Reader) *Entry {
 	var entry Entry
-	sc := bufio.NewScanner(reader)
+
+	if p.maxFileSize > 0 {
+		content, err := io.ReadAll(io.LimitReader(reader, p.maxFileSize+1))
+		switch {
+		case err != nil:
+			p.report(&ParseError{Line: -1, Err: fmt.Errorf("failed reading file: %w", err)})
+			return &entry
+		case int64(len(content)) > p.maxFileSize:
+			p.report(&ParseError{
+				Line: -1,
+				Err:  fmt.Errorf("%w of %d bytes", ErrFileTooLarge, p.maxFileSize),
+			})
+			return &entry
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	sc := newLineScanner(reader, p.maxLineLength)
 
 	seenKeys := make(map[string]bool)
 	seenGroups := make(map[string]bool)
@@ -47,10 +242,32 @@ func Parse(reader io.Reader) (*Entry, error) {
 	parseState := parseStateLookingForDEGroup
 	var groupName string
 
+	// skipGroup is set when the lines of the current group must be ignored.
+	skipGroup := false
+	reportedHeader := false
+
+	// currentGroup returns the name of the group of the current line.
+	currentGroup := func() string {
+		if groupName == "" {
+			return requiredGroupName
+		}
+		return groupName
+	}
+
+	// seenHeaderKeys contains the keys of headerKeys that have been read.
+	seenHeaderKeys := make(map[string]bool)
+
+	groupCount := 0
+	keyCount := 0
+
 	lineNumber := -1
+lines:
 	for sc.Scan() {
 		lineNumber++
 		line := strings.TrimRight(sc.Text(), " \t")
+		if lineNumber == 0 && !p.rejectBOM {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
 		switch {
 		case len(line) == 0:
 			continue
@@ -59,21 +276,42 @@ func Parse(reader io.Reader) (*Entry, error) {
 		}
 
 		if parseState == parseStateLookingForDEGroup {
-			if line != requiredGroupHeader {
-				return &entry, fmt.Errorf(
-					"parse failure at line %d, expected %s, found %s",
-					lineNumber,
-					requiredGroupHeader,
-					line,
-				)
+			if line != requiredGroupHeader && !(p.legacy && line == legacyGroupHeader) {
+				if reportedHeader {
+					continue
+				}
+				reportedHeader = true
+
+				if p.report(&ParseError{
+					Line: lineNumber,
+					Err:  fmt.Errorf("%w, found %s", ErrMissingDesktopEntry, line),
+				}) {
+					return &entry
+				}
+				continue
 			} else {
 				parseState = parseStateLookingForGroupsOrKeys
 				seenGroups[requiredGroupName] = true
+				groupCount++
 				continue
 			}
 		}
 
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if p.headerOnly {
+				break lines
+			}
+
+			groupCount++
+			if p.maxGroups > 0 && groupCount > p.maxGroups {
+				p.report(&ParseError{
+					Line:  lineNumber,
+					Group: line[1 : len(line)-1],
+					Err:   fmt.Errorf("%w of %d", ErrTooManyGroups, p.maxGroups),
+				})
+				return &entry
+			}
+
 			if currentAction != nil && currentAction.Name.Default != "" {
 				entry.Actions = append(entry.Actions, *currentAction)
 			}
@@ -81,14 +319,19 @@ func Parse(reader io.Reader) (*Entry, error) {
 
 			groupName = line[1 : len(line)-1]
 			if seenGroups[groupName] {
-				return &entry, fmt.Errorf(
-					"parse failure at line %d, duplicate group %s",
-					lineNumber,
-					groupName,
-				)
+				if p.report(&ParseError{
+					Line:  lineNumber,
+					Group: groupName,
+					Err:   ErrDuplicateGroup,
+				}) {
+					return &entry
+				}
+				skipGroup = true
+				continue
 			}
 			seenGroups[groupName] = true
 			clear(seenKeys)
+			skipGroup = false
 
 			if strings.HasPrefix(groupName, desktopActionPrefix) {
 				actionName := groupName[len(desktopActionPrefix):]
@@ -96,7 +339,7 @@ func Parse(reader io.Reader) (*Entry, error) {
 				// Action groups that are not in the Actions key are ignored
 				if _, exists := actions[actionName]; exists {
 					actions[actionName] = true
-					currentAction = &Action{}
+					currentAction = &Action{ID: actionName}
 				}
 			}
 
@@ -108,104 +351,189 @@ func Parse(reader io.Reader) (*Entry, error) {
 			continue
 		}
 
+		if skipGroup {
+			continue
+		}
+
 		keyValSplit := strings.SplitN(line, "=", 2)
 		if len(keyValSplit) < 2 {
-			return &entry, fmt.Errorf("parse failure on line %d, tried to read key-value"+
-				" line but no value could be determined. Line: %s", lineNumber, line)
+			if p.report(&ParseError{
+				Line:  lineNumber,
+				Group: currentGroup(),
+				Err:   fmt.Errorf("%w: %s", ErrInvalidLine, line),
+			}) {
+				return &entry
+			}
+			continue
 		}
 
 		key := keyValSplit[0]
 		value := keyValSplit[1]
 
-		if !isValidKey(key) {
-			return &entry, fmt.Errorf(
-				"parse failure at line %d, invalid key: %s",
-				lineNumber,
-				key,
-			)
+		keyCount++
+		if p.maxKeys > 0 && keyCount > p.maxKeys {
+			p.report(&ParseError{
+				Line:  lineNumber,
+				Group: currentGroup(),
+				Key:   key,
+				Err:   fmt.Errorf("%w of %d", ErrTooManyKeys, p.maxKeys),
+			})
+			return &entry
+		}
+
+		if !p.isValidKey(key) {
+			if p.report(&ParseError{
+				Line:  lineNumber,
+				Group: currentGroup(),
+				Key:   key,
+				Err:   ErrInvalidKey,
+			}) {
+				return &entry
+			}
+			continue
+		}
+
+		if p.headerKeys != nil {
+			baseKey, _, _ := strings.Cut(key, "[")
+			if !p.headerKeys[baseKey] {
+				if len(seenHeaderKeys) == len(p.headerKeys) {
+					break lines
+				}
+				continue
+			}
+			seenHeaderKeys[baseKey] = true
+		}
+
+		// otherKey is set for deprecated keys that are stored including their locale
+		var otherKey string
+		if p.legacy {
+			otherKey, value = legacyValue(key, value)
 		}
 
 		if !utf8.ValidString(value) {
-			return &entry, fmt.Errorf(
-				"parse failure at line %d, value is not valid UTF-8: %s",
-				lineNumber,
-				value,
-			)
+			if p.report(&ParseError{
+				Line:  lineNumber,
+				Group: currentGroup(),
+				Key:   key,
+				Value: value,
+				Err:   fmt.Errorf("%w: not valid UTF-8", ErrInvalidValue),
+			}) {
+				return &entry
+			}
+			continue
 		}
 
-		if seenKeys[key] {
-			return &entry, fmt.Errorf(
-				"parse failure at line %d, duplicate key %s",
-				lineNumber,
-				key,
-			)
+		if seenKeys[key] && !p.allowDuplicateKeys {
+			if p.report(&ParseError{
+				Line:  lineNumber,
+				Group: currentGroup(),
+				Key:   key,
+				Err:   ErrDuplicateKey,
+			}) {
+				return &entry
+			}
+			continue
 		}
 		seenKeys[key] = true
 
 		switch {
+		case groupName == "" && otherKey != "":
+			if entry.OtherKeys == nil {
+				entry.OtherKeys = make(map[string]string)
+			}
+			entry.OtherKeys[otherKey] = value
 		case groupName == "":
 			switch key {
 			case "Actions":
 				list, err := parseList(value)
 				if err != nil {
-					return &entry, fmt.Errorf(
-						"parse failure on line %d, error parsing Actions \"%s\": %w",
-						lineNumber,
-						value,
-						err,
-					)
+					if p.report(&ParseError{
+						Line:  lineNumber,
+						Group: requiredGroupName,
+						Key:   key,
+						Value: value,
+						Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+					}) {
+						return &entry
+					}
+					continue
 				}
 
+				// When duplicate keys are allowed, the last Actions key wins
+				clear(actions)
 				for _, actionName := range list {
 					actions[actionName] = false
 				}
 			default:
 				err := applyMainKeyValue(&entry, key, value)
 				if err != nil {
-					return &entry, fmt.Errorf(
-						"parse failure on line %d, error key='%s', value='%s': %w",
-						lineNumber,
-						key,
-						value,
-						err,
-					)
+					if p.report(&ParseError{
+						Line:  lineNumber,
+						Group: requiredGroupName,
+						Key:   key,
+						Value: value,
+						Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+					}) {
+						return &entry
+					}
+					continue
 				}
 			}
 		case currentAction != nil:
 			keyName, locale, err := parseKey(key)
 			if err != nil {
-				return &entry, err
+				if p.report(&ParseError{
+					Line:  lineNumber,
+					Group: groupName,
+					Key:   key,
+					Err:   fmt.Errorf("%w: %w", ErrInvalidKey, err),
+				}) {
+					return &entry
+				}
+				continue
 			}
 			switch keyName {
 			case "Name":
 				err := assignLocaleString(&currentAction.Name, locale, value)
 				if err != nil {
-					return &entry, fmt.Errorf(
-						"parse failure on line %d, error parsing action.Name %s: %w",
-						lineNumber,
-						value,
-						err,
-					)
+					if p.report(&ParseError{
+						Line:  lineNumber,
+						Group: groupName,
+						Key:   key,
+						Value: value,
+						Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+					}) {
+						return &entry
+					}
+					continue
 				}
 			case "Icon":
 				err := assignIconString(&currentAction.Icon, locale, value)
 				if err != nil {
-					return &entry, fmt.Errorf(
-						"parse failure on line %d, error parsing action.Name %s: %w",
-						lineNumber,
-						value,
-						err,
-					)
+					if p.report(&ParseError{
+						Line:  lineNumber,
+						Group: groupName,
+						Key:   key,
+						Value: value,
+						Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+					}) {
+						return &entry
+					}
+					continue
 				}
 			case "Exec":
 				execValue, err := NewExec(value)
 				if err != nil {
-					return &entry, fmt.Errorf(
-						"parse failure on line %d, error parsing action.Exec %s: %w",
-						lineNumber,
-						value,
-						err,
-					)
+					if p.report(&ParseError{
+						Line:  lineNumber,
+						Group: groupName,
+						Key:   key,
+						Value: value,
+						Err:   fmt.Errorf("%w: %w", ErrInvalidValue, err),
+					}) {
+						return &entry
+					}
+					continue
 				}
 				currentAction.Exec = execValue
 			default:
@@ -216,19 +544,37 @@ func Parse(reader io.Reader) (*Entry, error) {
 	}
 
 	if err := sc.Err(); err != nil {
-		return &entry, fmt.Errorf("failed reading line on line %d: %w", lineNumber, err)
+		if p.report(&ParseError{
+			Line: lineNumber + 1,
+			Err:  fmt.Errorf("failed reading line: %w", scanError(err, p.maxLineLength)),
+		}) {
+			return &entry
+		}
 	}
 
-	for actionName, hasGroup := range actions {
-		if hasGroup {
+	if p.headerOnly {
+		return &entry
+	}
+
+	if reportedHeader && parseState == parseStateLookingForDEGroup {
+		// The missing Desktop Entry group has been reported, the required fields would only
+		// repeat that.
+		return &entry
+	}
+
+	for _, actionName := range slices.Sorted(maps.Keys(actions)) {
+		if actions[actionName] {
 			continue
 		}
 
-		return &entry, fmt.Errorf(
-			"invalid desktop file, %w: \"%s\"",
-			ErrActionHasNoGroup,
-			actionName,
-		)
+		if p.report(&ParseError{
+			Line:  -1,
+			Group: requiredGroupName,
+			Key:   "Actions",
+			Err:   fmt.Errorf("%w: %s", ErrActionHasNoGroup, actionName),
+		}) {
+			return &entry
+		}
 	}
 
 	if currentAction != nil && currentAction.Name.Default != "" {
@@ -236,26 +582,58 @@ func Parse(reader io.Reader) (*Entry, error) {
 	}
 
 	if entry.Name.Default == "" {
-		return &entry, fmt.Errorf("invalid desktop file: Name field is required")
+		if p.report(missingKey("Name", "Name field is required")) {
+			return &entry
+		}
 	}
 
 	if entry.Type == "" {
-		return &entry, fmt.Errorf("invalid desktop file: Type field is required")
+		if p.report(missingKey("Type", "Type field is required")) {
+			return &entry
+		}
+	}
+
+	switch entry.Type {
+	case "", TypeApplication, TypeLink, TypeDirectory:
+	default:
+		if !p.allowUnknownType && p.report(&ParseError{
+			Line:  -1,
+			Group: requiredGroupName,
+			Key:   "Type",
+			Value: entry.Type,
+			Err:   ErrUnknownType,
+		}) {
+			return &entry
+		}
 	}
 
-	if entry.Type == TypeLink && !seenKeys["URL"] {
-		return &entry, fmt.Errorf("invalid desktop file: URL field is required for type Link")
+	if entry.Type == TypeLink && entry.URL == "" && !seenKeys["URL"] {
+		if p.report(missingKey("URL", "URL field is required for type Link")) {
+			return &entry
+		}
 	}
 
 	if entry.Type == TypeApplication && !entry.DBusActivatable && len(entry.Exec) == 0 {
-		return &entry, fmt.Errorf("invalid desktop file: Exec field is required for Type=%s"+
-			" and DBusActivatable=false", TypeApplication)
+		p.report(missingKey(
+			"Exec",
+			"Exec field is required for Type="+TypeApplication+" and DBusActivatable=false",
+		))
 	}
 
-	return &entry, nil
+	return &entry
+}
+
+// missingKey returns the error for a missing required key of the Desktop Entry group.
+func missingKey(key string, message string) *ParseError {
+	return &ParseError{
+		Line:  -1,
+		Group: requiredGroupName,
+		Key:   key,
+		Err:   fmt.Errorf("%w: %s", ErrMissingKey, message),
+	}
 }
 
-func ParseFile(path string) (*Entry, error) {
+func ParseFile(path string, opts ...ParseOption) (*Entry, error) {
 	file, err := os.Open(path)
 	defer file.Close()
 
@@ -263,7 +641,7 @@ func ParseFile(path string) (*Entry, error) {
 		return nil, fmt.Errorf("ParseFile, failed to open file %s: %w", path, err)
 	}
 
-	return Parse(file)
+	return Parse(file, opts...)
 }
 
 func isValidKey(key string) bool {
@@ -383,3 +383,344 @@ Name=Browse gallery
 		t.Errorf("Action name is %s, expected: %s", actualDefault2, expectedDefault2)
 	}
 }
+
+func TestParseLenient(t *testing.T) {
+	entry, issues := ParseLenient(strings.NewReader(`
+[Desktop Entry]
+Type=Application
+Name=Firefox
+Name=Duplicate
+not a key-value line
+NoDisplay=maybe
+Exec=/usr/lib/firefox/firefox %u
+Actions=missing;
+
+[Other]
+A=1
+
+[Other]
+A=2
+`))
+
+	if entry.Name.Default != "Firefox" {
+		t.Errorf("Name = %s, expected: Firefox", entry.Name.Default)
+	}
+
+	if len(entry.Exec) == 0 {
+		t.Errorf("Exec was not parsed after the malformed lines")
+	}
+
+	if entry.OtherGroups["Other"]["A"] != "1" {
+		t.Errorf("Other.A = %s, expected: 1", entry.OtherGroups["Other"]["A"])
+	}
+
+	lines := make([]int, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.Line)
+	}
+
+	expected := []int{4, 5, 6, 13, -1}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("Issue lines = %v, expected: %v. Issues: %v", lines, expected, issues)
+	}
+
+	if !errors.Is(issues[len(issues)-1], ErrActionHasNoGroup) {
+		t.Errorf("Last issue = %v, expected: %v", issues[len(issues)-1], ErrActionHasNoGroup)
+	}
+}
+
+func TestParseLenientMissingHeader(t *testing.T) {
+	entry, issues := ParseLenient(strings.NewReader(`
+Type=Ignored
+[Desktop Entry]
+Type=Application
+Name=Firefox
+Exec=firefox
+`))
+
+	if len(issues) != 1 || issues[0].Line != 1 {
+		t.Errorf("Issues = %v, expected one issue on line 1", issues)
+	}
+
+	if entry.Type != TypeApplication {
+		t.Errorf("Type = %s, expected: %s", entry.Type, TypeApplication)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	input := `[Desktop Entry]
+Type=Service
+Name=First
+Name=Last
+Exec=test
+X-Nàme=value
+`
+
+	_, err := Parse(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("Parse() error = %v, expected duplicate key error", err)
+	}
+
+	_, err = Parse(strings.NewReader(input), AllowDuplicateKeys())
+	if err == nil || !strings.Contains(err.Error(), "invalid key") {
+		t.Errorf("Parse() error = %v, expected invalid key error", err)
+	}
+
+	_, err = Parse(strings.NewReader(input), AllowDuplicateKeys(), AllowNonASCIIKeys())
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Parse() error = %v, expected: %v", err, ErrUnknownType)
+	}
+
+	entry, err := Parse(
+		strings.NewReader(input),
+		AllowDuplicateKeys(),
+		AllowNonASCIIKeys(),
+		AllowUnknownType(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Name.Default != "Last" {
+		t.Errorf("Name = %s, expected: Last", entry.Name.Default)
+	}
+
+	if entry.OtherKeys["X-Nàme"] != "value" {
+		t.Errorf("OtherKeys[X-Nàme] = %s, expected: value", entry.OtherKeys["X-Nàme"])
+	}
+}
+
+func TestParseMaxFileSize(t *testing.T) {
+	input := "[Desktop Entry]\nType=Application\nName=Test\nExec=test\n"
+
+	_, err := Parse(strings.NewReader(input), MaxFileSize(int64(len(input))))
+	if err != nil {
+		t.Errorf("Parse() of file at the limit returned error: %v", err)
+	}
+
+	_, err = Parse(strings.NewReader(input), MaxFileSize(int64(len(input)-1)))
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Parse() error = %v, expected: %v", err, ErrFileTooLarge)
+	}
+}
+
+func TestParseLongLine(t *testing.T) {
+	comment := strings.Repeat("a", 100_000)
+	input := "[Desktop Entry]\nType=Application\nName=Test\nExec=test\nComment=" + comment + "\n"
+
+	entry, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() of a line longer than 64 KiB returned error: %v", err)
+	}
+	if entry.Comment.Default != comment {
+		t.Errorf("Comment has length %d, expected: %d", len(entry.Comment.Default), len(comment))
+	}
+
+	_, err = Parse(strings.NewReader(input), MaxLineLength(len(comment)))
+	if !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("Parse() error = %v, expected: %v", err, ErrLineTooLong)
+	}
+
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.Line != 4 {
+		t.Errorf("ParseError.Line = %d, expected: 4", parseErr.Line)
+	}
+
+	_, err = Parse(strings.NewReader(input), MaxLineLength(len("Comment=")+len(comment)))
+	if err != nil {
+		t.Errorf("Parse() of line at the limit returned error: %v", err)
+	}
+}
+
+func TestParseMaxGroupsAndKeys(t *testing.T) {
+	input := `[Desktop Entry]
+Type=Application
+Name=Test
+Exec=test
+[X-A]
+A=1
+[X-B]
+B=1
+`
+
+	if _, err := Parse(strings.NewReader(input), MaxGroups(3), MaxKeys(5)); err != nil {
+		t.Errorf("Parse() of file at the limits returned error: %v", err)
+	}
+
+	_, err := Parse(strings.NewReader(input), MaxGroups(2))
+	if !errors.Is(err, ErrTooManyGroups) {
+		t.Errorf("Parse() error = %v, expected: %v", err, ErrTooManyGroups)
+	}
+
+	_, issues := ParseLenient(strings.NewReader(input), MaxKeys(4))
+	if len(issues) != 1 || !errors.Is(issues[0], ErrTooManyKeys) {
+		t.Errorf("ParseLenient() issues = %v, expected: [%v]", issues, ErrTooManyKeys)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	input := `[Desktop Entry]
+Type=Application
+Name=Firefox
+Name[nl]=Vuurvos
+Icon=firefox
+Exec=firefox %u
+NoDisplay=invalid
+Actions=missing;
+
+[Other]
+Key=Value
+`
+	result, err := ParseHeader(strings.NewReader(input), "Name", "Icon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Name.ToLocale("nl") != "Vuurvos" || result.Icon.Default != "firefox" {
+		t.Errorf("ParseHeader() = %+v, expected Name and Icon to be set", result)
+	}
+	if result.Type != "" || len(result.Exec) != 0 || result.OtherGroups != nil {
+		t.Errorf("ParseHeader() = %+v, expected only Name and Icon to be set", result)
+	}
+
+	// The invalid NoDisplay value is only reported when it is requested.
+	_, err = ParseHeader(strings.NewReader(input), "NoDisplay")
+	if err == nil {
+		t.Errorf("ParseHeader(NoDisplay) returned no error")
+	}
+
+	result, err = ParseHeader(strings.NewReader(strings.Replace(input, "invalid", "true", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Type != TypeApplication || !result.NoDisplay || result.OtherGroups != nil {
+		t.Errorf("ParseHeader() = %+v, expected the Desktop Entry group only", result)
+	}
+
+	_, err = ParseHeader(strings.NewReader("[Other]\nName=Test\n"), "Name")
+	if err == nil {
+		t.Errorf("ParseHeader() without Desktop Entry group returned no error")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ParseError
+	}{
+		{
+			input: "[Desktop Entry]\nType=Application\nName=A\nName=B\n",
+			expected: ParseError{
+				Line:  3,
+				Group: "Desktop Entry",
+				Key:   "Name",
+				Err:   ErrDuplicateKey,
+			},
+		},
+		{
+			input: "[Desktop Entry]\nType=Application\nName=A\nTerminal=maybe\n",
+			expected: ParseError{
+				Line:  3,
+				Group: "Desktop Entry",
+				Key:   "Terminal",
+				Value: "maybe",
+				Err:   ErrInvalidValue,
+			},
+		},
+		{
+			input: "[Desktop Entry]\nType=Application\nExec=test\n",
+			expected: ParseError{
+				Line:  -1,
+				Group: "Desktop Entry",
+				Key:   "Name",
+				Err:   ErrMissingKey,
+			},
+		},
+		{
+			input:    "[Desktop Entry]\nType=Application\nName=A\nExec=a\n[X]\n[X]\n",
+			expected: ParseError{Line: 5, Group: "X", Err: ErrDuplicateGroup},
+		},
+		{
+			input:    "[Other]\n",
+			expected: ParseError{Line: 0, Err: ErrMissingDesktopEntry},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.input))
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, expected a *ParseError", test.input, err)
+			continue
+		}
+
+		expected := test.expected
+		if parseErr.Line != expected.Line ||
+			parseErr.Group != expected.Group ||
+			parseErr.Key != expected.Key ||
+			parseErr.Value != expected.Value ||
+			!errors.Is(err, expected.Err) {
+			t.Errorf("Parse(%q) error = %+v, expected: %+v", test.input, parseErr, expected)
+		}
+	}
+}
+
+func TestParseBOM(t *testing.T) {
+	input := "\xef\xbb\xbf[Desktop Entry]\nType=Application\nName=Test\nExec=test\n"
+	result, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Name.Default != "Test" {
+		t.Errorf("Name = %s, expected: Test", result.Name.Default)
+	}
+
+	_, err = Parse(strings.NewReader(input), RejectBOM())
+	if !errors.Is(err, ErrMissingDesktopEntry) {
+		t.Errorf("Parse() with RejectBOM error = %v, expected: %v", err, ErrMissingDesktopEntry)
+	}
+}
+
+func TestParseLegacyCompatibility(t *testing.T) {
+	input := "[KDE Desktop Entry]\n" +
+		"Encoding=Legacy-Mixed\n" +
+		"Type=Application\n" +
+		"Name=Caf\xe9\n" +
+		"Name[ru.KOI8-R]=\xf0\xd2\xcf\xc7\n" +
+		"Exec=app\n" +
+		"Terminal=1\n" +
+		"SwallowTitle=App\n" +
+		"SwallowTitle[de]=Anwendung\n" +
+		"SortOrder=a.desktop,b.desktop\n"
+
+	_, err := Parse(strings.NewReader(input))
+	if !errors.Is(err, ErrMissingDesktopEntry) {
+		t.Errorf("Parse() without compatibility = %v, expected: %v", err, ErrMissingDesktopEntry)
+	}
+
+	result, issues := ParseLenient(strings.NewReader(input), LegacyCompatibility())
+	var parseError *ParseError
+	if len(issues) != 1 || !errors.As(issues[0], &parseError) ||
+		parseError.Key != "Name[ru.KOI8-R]" {
+		t.Errorf("issues = %v, expected one for Name[ru.KOI8-R]", issues)
+	}
+
+	if result.Name.Default != "Café" {
+		t.Errorf("Name = %s, expected: Café", result.Name.Default)
+	}
+	if !result.Terminal {
+		t.Errorf("Terminal = false, expected: true")
+	}
+
+	expected := map[string]string{
+		"Encoding":         "Legacy-Mixed",
+		"SwallowTitle":     "App",
+		"SwallowTitle[de]": "Anwendung",
+		"SortOrder":        "a.desktop,b.desktop",
+	}
+	for key, value := range expected {
+		if result.OtherKeys[key] != value {
+			t.Errorf("OtherKeys[%s] = %s, expected: %s", key, result.OtherKeys[key], value)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package desktop
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrUnknownType = errors.New("unknown Type")
+var ErrFileTooLarge = errors.New("file exceeds the maximum size")
+var ErrLineTooLong = errors.New("line exceeds the maximum length")
+var ErrTooManyGroups = errors.New("file exceeds the maximum number of groups")
+var ErrTooManyKeys = errors.New("file exceeds the maximum number of keys")
+
+// DefaultMaxLineLength is the maximum length of a line in bytes, unless changed using
+// MaxLineLength. It is much larger than the 64 KiB limit of bufio.Scanner since the Exec and
+// Comment keys of some desktop files, and the lines of mimeinfo.cache files, exceed it.
+const DefaultMaxLineLength = 1 << 20
+
+// ParseOption changes the strictness of Parse, ParseFile, and ParseLenient. By default, the
+// parser follows the specification: duplicate keys, unknown types, and keys containing non-ASCII
+// characters are rejected.
+type ParseOption func(p *parser)
+
+// AllowDuplicateKeys accepts keys that occur multiple times in a group. The last occurrence wins.
+func AllowDuplicateKeys() ParseOption {
+	return func(p *parser) {
+		p.allowDuplicateKeys = true
+	}
+}
+
+// AllowUnknownType accepts entries with a Type other than Application, Link, and Directory.
+// The specification reserves such types for future use and asks implementations to ignore these
+// entries, which is what happens by default by returning an error matching ErrUnknownType.
+func AllowUnknownType() ParseOption {
+	return func(p *parser) {
+		p.allowUnknownType = true
+	}
+}
+
+// AllowNonASCIIKeys accepts keys containing UTF-8 characters outside the ASCII range. Control
+// characters remain invalid.
+func AllowNonASCIIKeys() ParseOption {
+	return func(p *parser) {
+		p.allowNonASCIIKeys = true
+	}
+}
+
+// RejectBOM treats a UTF-8 byte order mark at the start of the file as an error. By default, it
+// is ignored, like MagicIsDesktopFile does.
+func RejectBOM() ParseOption {
+	return func(p *parser) {
+		p.rejectBOM = true
+	}
+}
+
+// MaxFileSize limits the size of the file to the given amount of bytes. Larger files are not
+// parsed and result in an error matching ErrFileTooLarge. A size of 0 or less means no limit,
+// which is the default.
+func MaxFileSize(bytes int64) ParseOption {
+	return func(p *parser) {
+		p.maxFileSize = bytes
+	}
+}
+
+// MaxLineLength limits the length of a line, excluding the line ending, to the given amount of
+// bytes. A longer line results in an error matching ErrLineTooLong and stops parsing, also for
+// ParseLenient. A length of 0 or less means DefaultMaxLineLength, which is the default.
+func MaxLineLength(bytes int) ParseOption {
+	return func(p *parser) {
+		p.maxLineLength = bytes
+	}
+}
+
+// MaxGroups limits the number of groups in the file, including the Desktop Entry group.
+// Exceeding it results in an error matching ErrTooManyGroups and stops parsing, also for
+// ParseLenient. A count of 0 or less means no limit, which is the default.
+func MaxGroups(count int) ParseOption {
+	return func(p *parser) {
+		p.maxGroups = count
+	}
+}
+
+// MaxKeys limits the number of keys in the file, counted over all groups. Exceeding it results
+// in an error matching ErrTooManyKeys and stops parsing, also for ParseLenient. A count of 0 or
+// less means no limit, which is the default.
+func MaxKeys(count int) ParseOption {
+	return func(p *parser) {
+		p.maxKeys = count
+	}
+}
+
+// newLineScanner returns a scanner of the lines of reader that accepts lines of up to
+// maxLineLength bytes, or DefaultMaxLineLength if it is 0 or less.
+func newLineScanner(reader io.Reader, maxLineLength int) *bufio.Scanner {
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+
+	sc := bufio.NewScanner(reader)
+	// The buffer must also hold the line ending, \r\n at most
+	sc.Buffer(nil, maxLineLength+2)
+	return sc
+}
+
+// scanError converts the error of a scanner created by newLineScanner.
+func scanError(err error, maxLineLength int) error {
+	if !errors.Is(err, bufio.ErrTooLong) {
+		return err
+	}
+
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+
+	return fmt.Errorf("%w of %d bytes", ErrLineTooLong, maxLineLength)
+}
+
+// LegacyCompatibility accepts desktop files written before version 1.0 of the specification,
+// which old third-party applications still install:
+//   - The first group may be [KDE Desktop Entry] instead of [Desktop Entry].
+//   - Values that are not valid UTF-8, as written by files with Encoding=Legacy-Mixed, are
+//     converted from ISO-8859-1. Values of locales with another charset, e.g. Name[ru.KOI8-R],
+//     cannot be converted and remain invalid.
+//   - Booleans may be 0 or 1.
+//   - Deprecated keys such as SwallowTitle and SortOrder are stored in Entry.OtherKeys including
+//     their locale, e.g. SwallowTitle[de], so that translations are not lost.
+func LegacyCompatibility() ParseOption {
+	return func(p *parser) {
+		p.legacy = true
+	}
+}
+
+// legacyGroupHeader is the header of the main group used by KDE before version 1.0 of the
+// specification.
+const legacyGroupHeader = "[KDE Desktop Entry]"
+
+// legacyValue converts the value of the key of a pre-1.0 desktop file into its modern form, see
+// LegacyCompatibility. The returned key is the key to store deprecated keys under in OtherKeys,
+// or an empty string for other keys.
+func legacyValue(key string, value string) (string, string) {
+	baseKey, locale, err := parseKey(key)
+	if err != nil {
+		return "", value
+	}
+
+	if !utf8.ValidString(value) && isLatin1Locale(locale) {
+		runes := make([]rune, 0, len(value))
+		for i := 0; i < len(value); i++ {
+			runes = append(runes, rune(value[i]))
+		}
+		value = string(runes)
+	}
+
+	if mainGroupKeys[baseKey].valueType == valueBoolean {
+		switch value {
+		case "0":
+			value = "false"
+		case "1":
+			value = "true"
+		}
+	}
+
+	if slices.Contains(deprecatedKeys, baseKey) && locale != "" {
+		return key, value
+	}
+
+	return "", value
+}
+
+// isLatin1Locale returns whether the values of the locale of a Legacy-Mixed desktop file are
+// encoded using ISO-8859-1. Locales without a charset are assumed to use it.
+func isLatin1Locale(locale string) bool {
+	_, charset, found := strings.Cut(locale, ".")
+	if !found {
+		return true
+	}
+
+	charset, _, _ = strings.Cut(charset, "@")
+	switch strings.ToUpper(strings.ReplaceAll(charset, "_", "-")) {
+	case "ISO-8859-1", "ISO8859-1", "LATIN1":
+		return true
+	default:
+		return false
+	}
+}
+
+// isValidKey checks the key using the options of the parser.
+func (p *parser) isValidKey(key string) bool {
+	if !p.allowNonASCIIKeys {
+		return isValidKey(key)
+	}
+
+	if len(key) == 0 || strings.HasSuffix(key, "[]") || !utf8.ValidString(key) {
+		return false
+	}
+
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,36 @@
+package desktop
+
+import (
+	"slices"
+	"strings"
+)
+
+// schemeHandlerPrefix is the prefix of the MIME types that applications use to register as
+// handler of a URL scheme, e.g. x-scheme-handler/https.
+const schemeHandlerPrefix = "x-scheme-handler/"
+
+// SupportedSchemes returns the URL schemes that the application can open according to the
+// x-scheme-handler MIME types in its MimeType key, e.g. https for x-scheme-handler/https.
+// The schemes are lowercase, since schemes are case-insensitive, and in the order of MimeType.
+func (e *Entry) SupportedSchemes() []string {
+	var schemes []string
+	for _, mimeType := range e.MimeType {
+		if len(mimeType) <= len(schemeHandlerPrefix) ||
+			!strings.EqualFold(mimeType[:len(schemeHandlerPrefix)], schemeHandlerPrefix) {
+			continue
+		}
+
+		scheme := strings.ToLower(mimeType[len(schemeHandlerPrefix):])
+		if !slices.Contains(schemes, scheme) {
+			schemes = append(schemes, scheme)
+		}
+	}
+
+	return schemes
+}
+
+// CanOpenScheme returns whether the application can open URLs of the scheme, e.g. mailto,
+// according to its MimeType key. The scheme is compared case-insensitively.
+func (e *Entry) CanOpenScheme(scheme string) bool {
+	return slices.Contains(e.SupportedSchemes(), strings.ToLower(scheme))
+}
@@ -0,0 +1,36 @@
+package desktop
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestSupportedSchemes(t *testing.T) {
+	entry := &Entry{
+		MimeType: []string{
+			"text/html",
+			"x-scheme-handler/https",
+			"X-Scheme-Handler/HTTP",
+			"x-scheme-handler/",
+			"x-scheme-handler/https",
+			"x-scheme-handler/mailto",
+		},
+	}
+
+	expected := []string{"https", "http", "mailto"}
+	if diff := cmp.Diff(expected, entry.SupportedSchemes()); diff != "" {
+		t.Errorf("SupportedSchemes() mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := map[string]bool{
+		"https":  true,
+		"MAILTO": true,
+		"ftp":    false,
+		"":       false,
+	}
+	for scheme, expected := range tests {
+		if actual := entry.CanOpenScheme(scheme); actual != expected {
+			t.Errorf("CanOpenScheme(%q) = %t, expected: %t", scheme, actual, expected)
+		}
+	}
+}
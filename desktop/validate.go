@@ -0,0 +1,381 @@
+package desktop
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Severity is the severity of a validation diagnostic. The levels match those of
+// desktop-file-validate.
+type Severity int
+
+const (
+	// SeverityOff disables a rule when used in ValidateOptions.Severity.
+	SeverityOff Severity = iota
+	SeverityHint
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityOff:
+		return "off"
+	case SeverityHint:
+		return "hint"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// MarshalText encodes the severity as its name, e.g. warning.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name: off, hint, warning, or error.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for _, severity := range []Severity{SeverityOff, SeverityHint, SeverityWarning, SeverityError} {
+		if string(text) == severity.String() {
+			*s = severity
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown severity: %s", text)
+}
+
+// Diagnostic is a problem found by Validate. Diagnostics can be encoded using encoding/json
+// for machine-readable output.
+type Diagnostic struct {
+	// Path of the validated file, as given to Validate.
+	Path string `json:"path"`
+
+	// Line is the 1-based line number the diagnostic applies to, or 0 if it applies to the
+	// file or group as a whole.
+	Line int `json:"line,omitempty"`
+
+	// Group is the group the diagnostic applies to, if any.
+	Group string `json:"group,omitempty"`
+
+	// Key is the key the diagnostic applies to, if any.
+	Key string `json:"key,omitempty"`
+
+	// Rule is the ID of the rule that produced the diagnostic, see Rules.
+	Rule string `json:"rule"`
+
+	Severity Severity `json:"severity"`
+
+	// Message describes the problem using the wording of desktop-file-validate.
+	Message string `json:"message"`
+}
+
+// String formats the diagnostic like desktop-file-validate, e.g.
+// app.desktop: error: required key "Name" in group "Desktop Entry" is not present.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Path, d.Severity, d.Message)
+}
+
+// Rule is a validation rule.
+type Rule struct {
+	// ID identifies the rule in diagnostics and ValidateOptions.Severity.
+	ID string
+
+	// Severity is the default severity of the diagnostics of the rule.
+	Severity Severity
+
+	// Description summarizes what the rule checks.
+	Description string
+}
+
+// Rules lists the rules applied by Validate. The tryexec-missing and id-* rules are only
+// applied by ValidateDir and ValidateAll.
+var Rules = []Rule{
+	{"line-invalid", SeverityError, "Lines must be a comment, a group header, or a key-value pair"},
+	{"key-before-group", SeverityError, "Only comments may precede the first group"},
+	{"first-group", SeverityError, "The first group must be Desktop Entry"},
+	{"group-invalid", SeverityError, "Group names must not contain [, ], or control characters"},
+	{"group-duplicate", SeverityError, "Groups must be unique"},
+	{"group-unknown", SeverityError, "Groups extending the format must start with X-"},
+	{"key-invalid", SeverityError, "Keys may only contain A-Za-z0-9-"},
+	{"key-duplicate", SeverityError, "Keys must be unique within a group"},
+	{"key-unknown", SeverityError, "Keys extending the format must start with X-"},
+	{"key-deprecated", SeverityWarning, "Deprecated keys should not be used"},
+	{"key-not-localized", SeverityError, "Only keys of localized types may have a locale"},
+	{"key-required", SeverityError, "Required keys must be present"},
+	{"key-wrong-type", SeverityError, "Keys must be valid for the Type of the entry"},
+	{"locale-invalid", SeverityError, "Locales must have the form lang_COUNTRY.ENCODING@MODIFIER"},
+	{"value-utf8", SeverityError, "Values must be valid UTF-8"},
+	{"value-string", SeverityError, "Values of type string must be ASCII without control characters"},
+	{"value-escape", SeverityError, "Only \\s, \\n, \\t, \\r, and \\\\ may be escaped"},
+	{"value-boolean", SeverityError, "Boolean values must be true or false"},
+	{"value-boolean-deprecated", SeverityWarning, "Boolean values 0 and 1 are deprecated"},
+	{"list-semicolon", SeverityWarning, "Lists should end with a semicolon"},
+	{"type-unknown", SeverityError, "Type must be Application, Link, or Directory"},
+	{"version-unknown", SeverityWarning, "Version must be a known specification version"},
+	{"exec-invalid", SeverityError, "Exec must be a valid command line"},
+	{"icon-extension", SeverityWarning, "Icon names must not have an extension"},
+	{"mimetype-invalid", SeverityError, "MIME types must have the form media/subtype"},
+	{"category-unknown", SeverityError, "Categories extending the format must start with X-"},
+	{"category-main-missing", SeverityHint, "Categories should contain a main category"},
+	{"desktop-unknown", SeverityError, "Desktop names extending the format must start with X-"},
+	{"show-in-conflict", SeverityError, "A desktop must not be in both OnlyShowIn and NotShowIn"},
+	{"action-missing", SeverityError, "Actions must have a Desktop Action group"},
+	{"action-unused", SeverityWarning, "Desktop Action groups should be listed in Actions"},
+	{"dbus-name", SeverityError, "D-Bus activatable files must be named after a D-Bus name"},
+	{"tryexec-missing", SeverityWarning, "The TryExec executable should be installed"},
+	{"id-duplicate", SeverityError, "Desktop IDs must be unique within a directory"},
+	{"id-masked", SeverityHint, "Desktop files masked by a location of higher precedence"},
+}
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// Severity overrides the severity of rules by ID. SeverityOff disables the rule.
+	Severity map[string]Severity
+
+	// MinSeverity drops diagnostics of a lower severity. E.g. SeverityWarning mimics the
+	// --no-hints flag of desktop-file-validate.
+	MinSeverity Severity
+}
+
+// ValidateFile validates the desktop file at path, see Validate.
+func ValidateFile(path string, opts ValidateOptions) ([]Diagnostic, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ValidateFile: %w", err)
+	}
+	defer file.Close()
+
+	return Validate(file, path, opts)
+}
+
+// Validate checks the desktop file against the [Desktop Entry Specification] using the checks of
+// desktop-file-validate, and returns the problems found ordered by line. path is used in the
+// diagnostics and to check the file name. An error is only returned if reading fails.
+//
+// Unlike Parse, Validate does not stop at the first problem. Its diagnostics use the categories
+// and messages of desktop-file-validate so that it can replace it in CI pipelines, see Rules.
+//
+// [Desktop Entry Specification]: https://specifications.freedesktop.org/desktop-entry-spec/1.5/
+func Validate(reader io.Reader, path string, opts ValidateOptions) ([]Diagnostic, error) {
+	v := validator{path: path, opts: opts}
+	err := v.read(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Validate: %w", err)
+	}
+
+	v.checkGroups()
+	if main := v.group(requiredGroupName); main != nil {
+		v.checkMainGroup(main)
+	}
+
+	slices.SortStableFunc(v.diagnostics, func(a, b Diagnostic) int {
+		return a.Line - b.Line
+	})
+
+	return v.diagnostics, nil
+}
+
+// HasErrors reports whether any of the diagnostics is an error.
+func HasErrors(diagnostics []Diagnostic) bool {
+	return slices.ContainsFunc(diagnostics, func(d Diagnostic) bool {
+		return d.Severity == SeverityError
+	})
+}
+
+type rawEntry struct {
+	line   int
+	key    string
+	name   string
+	locale string
+	value  string
+}
+
+type rawGroup struct {
+	line    int
+	name    string
+	entries []rawEntry
+}
+
+// lookup returns the unlocalized entry with the given key.
+func (g *rawGroup) lookup(key string) (rawEntry, bool) {
+	for _, entry := range g.entries {
+		if entry.key == key {
+			return entry, true
+		}
+	}
+
+	return rawEntry{}, false
+}
+
+type validator struct {
+	path        string
+	opts        ValidateOptions
+	groups      []*rawGroup
+	diagnostics []Diagnostic
+}
+
+func (v *validator) report(
+	rule string,
+	line int,
+	group string,
+	key string,
+	format string,
+	args ...any,
+) {
+	index := slices.IndexFunc(Rules, func(r Rule) bool {
+		return r.ID == rule
+	})
+	severity := Rules[index].Severity
+	if override, ok := v.opts.Severity[rule]; ok {
+		severity = override
+	}
+
+	if severity == SeverityOff || severity < v.opts.MinSeverity {
+		return
+	}
+
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Path:     v.path,
+		Line:     line,
+		Group:    group,
+		Key:      key,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) group(name string) *rawGroup {
+	for _, group := range v.groups {
+		if group.name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// read splits the file into groups and entries, reporting the structural problems.
+func (v *validator) read(reader io.Reader) error {
+	sc := newLineScanner(reader, 0)
+	var current *rawGroup
+	seenGroups := make(map[string]bool)
+	seenKeys := make(map[string]bool)
+
+	lineNumber := 0
+	for sc.Scan() {
+		lineNumber++
+		line := strings.TrimRight(sc.Text(), " \t")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				v.report("line-invalid", lineNumber, "", "",
+					"file contains line \"%s\", which is not a comment, a group or an entry", line)
+				continue
+			}
+
+			name := line[1 : len(line)-1]
+			if strings.ContainsAny(name, "[]") || !isAsciiNoControl(name) {
+				v.report("group-invalid", lineNumber, name, "",
+					"file contains group \"%s\", but group names may contain all ASCII characters "+
+						"except for [ and ] and control characters", name)
+			}
+			if seenGroups[name] {
+				v.report("group-duplicate", lineNumber, name, "",
+					"file contains multiple groups named \"%s\", but multiple groups may not have "+
+						"the same name", name)
+			}
+			if current == nil && name != requiredGroupName {
+				v.report("first-group", lineNumber, name, "",
+					"first group must be \"%s\"", requiredGroupName)
+			}
+
+			seenGroups[name] = true
+			clear(seenKeys)
+			current = &rawGroup{line: lineNumber, name: name}
+			v.groups = append(v.groups, current)
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimLeft(value, " \t")
+		if !found || key == "" {
+			v.report("line-invalid", lineNumber, "", "",
+				"file contains line \"%s\", which is not a comment, a group or an entry", line)
+			continue
+		}
+
+		if current == nil {
+			v.report("key-before-group", lineNumber, "", key,
+				"file contains key \"%s\" before the first group, but only comments are accepted",
+				key)
+			continue
+		}
+
+		name, locale, err := parseKey(key)
+		if err != nil || !isValidKeyName(name) {
+			v.report("key-invalid", lineNumber, current.name, key,
+				"file contains key \"%s\" in group \"%s\", but keys may contain only A-Za-z0-9- "+
+					"characters", key, current.name)
+			continue
+		}
+
+		if locale != "" && !localeRegex.MatchString(locale) {
+			v.report("locale-invalid", lineNumber, current.name, key,
+				"file contains key \"%s\" in group \"%s\", but \"%s\" is not a valid locale",
+				key, current.name, locale)
+		}
+
+		if seenKeys[key] {
+			v.report("key-duplicate", lineNumber, current.name, key,
+				"file contains multiple keys named \"%s\" in group \"%s\"", key, current.name)
+			continue
+		}
+		seenKeys[key] = true
+
+		current.entries = append(current.entries, rawEntry{
+			line:   lineNumber,
+			key:    key,
+			name:   name,
+			locale: locale,
+			value:  value,
+		})
+	}
+
+	if err := sc.Err(); err != nil {
+		return scanError(err, 0)
+	}
+
+	if len(v.groups) == 0 {
+		v.report("first-group", 0, "", "", "first group must be \"%s\"", requiredGroupName)
+	}
+
+	return nil
+}
+
+func isValidKeyName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
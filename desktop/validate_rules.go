@@ -0,0 +1,435 @@
+package desktop
+
+import (
+	"github.com/MatthiasKunnen/xdg/dbusactivation"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type valueType int
+
+const (
+	valueString valueType = iota
+	valueLocaleString
+	valueIconString
+	valueBoolean
+	valueStrings
+	valueLocaleStrings
+)
+
+type keySpec struct {
+	valueType valueType
+
+	// types lists the entry types the key is valid for. Nil means all types.
+	types []string
+}
+
+var applicationOnly = []string{TypeApplication}
+
+var mainGroupKeys = map[string]keySpec{
+	"Type":                 {valueString, nil},
+	"Version":              {valueString, nil},
+	"Name":                 {valueLocaleString, nil},
+	"GenericName":          {valueLocaleString, nil},
+	"NoDisplay":            {valueBoolean, nil},
+	"Comment":              {valueLocaleString, nil},
+	"Icon":                 {valueIconString, nil},
+	"Hidden":               {valueBoolean, nil},
+	"OnlyShowIn":           {valueStrings, nil},
+	"NotShowIn":            {valueStrings, nil},
+	"DBusActivatable":      {valueBoolean, applicationOnly},
+	"TryExec":              {valueString, applicationOnly},
+	"Exec":                 {valueString, applicationOnly},
+	"Path":                 {valueString, applicationOnly},
+	"Terminal":             {valueBoolean, applicationOnly},
+	"Actions":              {valueStrings, applicationOnly},
+	"MimeType":             {valueStrings, applicationOnly},
+	"Categories":           {valueStrings, applicationOnly},
+	"Implements":           {valueStrings, nil},
+	"Keywords":             {valueLocaleStrings, applicationOnly},
+	"StartupNotify":        {valueBoolean, applicationOnly},
+	"StartupWMClass":       {valueString, applicationOnly},
+	"URL":                  {valueString, []string{TypeLink}},
+	"PrefersNonDefaultGPU": {valueBoolean, applicationOnly},
+	"SingleMainWindow":     {valueBoolean, applicationOnly},
+}
+
+var actionGroupKeys = map[string]keySpec{
+	"Name": {valueLocaleString, nil},
+	"Icon": {valueIconString, nil},
+	"Exec": {valueString, nil},
+}
+
+var deprecatedKeys = []string{
+	"BinaryPattern",
+	"Encoding",
+	"Extensions",
+	"FilePattern",
+	"MapNotify",
+	"MiniIcon",
+	"Protocols",
+	"SortOrder",
+	"SwallowExec",
+	"SwallowTitle",
+	"TerminalOptions",
+}
+
+var knownVersions = []string{"1.0", "1.1", "1.2", "1.3", "1.4", "1.5"}
+
+// mainCategories are the main categories of the Desktop Menu Specification.
+var mainCategories = []string{
+	"AudioVideo", "Audio", "Video", "Development", "Education", "Game", "Graphics", "Network",
+	"Office", "Science", "Settings", "System", "Utility",
+}
+
+// additionalCategories are the additional categories of the Desktop Menu Specification.
+var additionalCategories = []string{
+	"Building", "Debugger", "IDE", "GUIDesigner", "Profiling", "RevisionControl", "Translation",
+	"Calendar", "ContactManagement", "Database", "Dictionary", "Chart", "Email", "Finance",
+	"FlowChart", "PDA", "ProjectManagement", "Presentation", "Spreadsheet", "WordProcessor",
+	"2DGraphics", "VectorGraphics", "RasterGraphics", "3DGraphics", "Scanning", "OCR",
+	"Photography", "Publishing", "Viewer", "TextTools", "DesktopSettings", "HardwareSettings",
+	"Printing", "PackageManager", "Dialup", "InstantMessaging", "Chat", "IRCClient", "Feed",
+	"FileTransfer", "HamRadio", "News", "P2P", "RemoteAccess", "Telephony", "TelephonyTools",
+	"VideoConference", "WebBrowser", "WebDevelopment", "Midi", "Mixer", "Sequencer", "Tuner",
+	"TV", "AudioVideoEditing", "Player", "Recorder", "DiscBurning", "ActionGame",
+	"AdventureGame", "ArcadeGame", "BoardGame", "BlocksGame", "CardGame", "KidsGame",
+	"LogicGame", "RolePlaying", "Shooter", "Simulation", "SportsGame", "StrategyGame", "Art",
+	"Construction", "Music", "Languages", "ArtificialIntelligence", "Astronomy", "Biology",
+	"Chemistry", "ComputerScience", "DataVisualization", "Economy", "Electricity", "Geography",
+	"Geology", "Geoscience", "History", "Humanities", "ImageProcessing", "Literature", "Maps",
+	"Math", "NumericalAnalysis", "MedicalSoftware", "Physics", "Robotics", "Spirituality",
+	"Sports", "ParallelComputing", "Amusement", "Archiving", "Compression", "Electronics",
+	"Emulator", "Engineering", "FileTools", "FileManager", "TerminalEmulator", "Filesystem",
+	"Monitor", "Security", "Accessibility", "Calculator", "Clock", "TextEditor",
+	"Documentation", "Adult", "Core", "KDE", "GNOME", "XFCE", "DDE", "GTK", "Qt", "Motif",
+	"Java", "ConsoleOnly", "Screensaver", "TrayIcon", "Applet", "Shell",
+}
+
+// registeredDesktops are the desktop environments registered in the Desktop Menu
+// Specification.
+var registeredDesktops = []string{
+	"GNOME", "GNOME-Classic", "GNOME-Flashback", "KDE", "LXDE", "LXQt", "MATE", "Razor", "ROX",
+	"TDE", "Unity", "XFCE", "EDE", "Cinnamon", "Pantheon", "Budgie", "Enlightenment", "DDE",
+	"Endless", "Old",
+}
+
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?(\.[A-Za-z0-9-]+)?(@[A-Za-z0-9]+)?$`)
+
+var mimeTypeRegex = regexp.MustCompile(`^[A-Za-z0-9!#$&^_.+-]+/[A-Za-z0-9!#$&^_.+-]+$`)
+
+// checkGroups checks the groups other than the main group.
+func (v *validator) checkGroups() {
+	for _, group := range v.groups {
+		switch {
+		case group.name == requiredGroupName:
+		case strings.HasPrefix(group.name, desktopActionPrefix):
+			v.checkKeys(group, actionGroupKeys, "")
+			if _, ok := group.lookup("Name"); !ok {
+				v.reportRequired(group, "Name")
+			}
+		case strings.HasPrefix(group.name, "X-"):
+		default:
+			v.report("group-unknown", group.line, group.name, "",
+				"file contains group \"%s\", but groups extending the format should start with "+
+					"\"X-\"", group.name)
+		}
+	}
+}
+
+// checkMainGroup checks the Desktop Entry group and the relations between groups.
+func (v *validator) checkMainGroup(main *rawGroup) {
+	entryType := ""
+	if entry, ok := main.lookup("Type"); ok {
+		switch entry.value {
+		case TypeApplication, TypeLink, TypeDirectory:
+			entryType = entry.value
+		default:
+			v.reportEntry("type-unknown", main, entry,
+				"value \"%s\" for key \"Type\" in group \"%s\" is not a registered type value "+
+					"(\"Application\", \"Link\" and \"Directory\")", entry.value, main.name)
+		}
+	} else {
+		v.reportRequired(main, "Type")
+	}
+
+	v.checkKeys(main, mainGroupKeys, entryType)
+
+	if _, ok := main.lookup("Name"); !ok {
+		v.reportRequired(main, "Name")
+	}
+
+	dbusActivatable := false
+	if entry, ok := main.lookup("DBusActivatable"); ok {
+		dbusActivatable = entry.value == "true" || entry.value == "1"
+	}
+
+	switch entryType {
+	case TypeApplication:
+		if _, ok := main.lookup("Exec"); !ok && !dbusActivatable {
+			v.reportRequired(main, "Exec")
+		}
+	case TypeLink:
+		if _, ok := main.lookup("URL"); !ok {
+			v.reportRequired(main, "URL")
+		}
+	}
+
+	if dbusActivatable && strings.HasSuffix(v.path, ".desktop") {
+		if _, err := dbusactivation.BusName(filepath.Base(v.path)); err != nil {
+			v.report("dbus-name", 0, main.name, "DBusActivatable",
+				"DBusActivatable is true but the file name \"%s\" is not a valid D-Bus name",
+				filepath.Base(v.path))
+		}
+	}
+
+	if entry, ok := main.lookup("Version"); ok && !slices.Contains(knownVersions, entry.value) {
+		v.reportEntry("version-unknown", main, entry,
+			"value \"%s\" for key \"Version\" in group \"%s\" is not a known version",
+			entry.value, main.name)
+	}
+
+	v.checkShowIn(main)
+	v.checkActions(main)
+}
+
+// checkKeys checks the keys of the group and their values. entryType is the Type of the entry
+// or empty if the type is not checked.
+func (v *validator) checkKeys(group *rawGroup, specs map[string]keySpec, entryType string) {
+	for _, entry := range group.entries {
+		if !utf8.ValidString(entry.value) {
+			v.reportEntry("value-utf8", group, entry,
+				"value for key \"%s\" in group \"%s\" contains invalid UTF-8", entry.key,
+				group.name)
+			continue
+		}
+
+		if slices.Contains(deprecatedKeys, entry.name) {
+			v.reportEntry("key-deprecated", group, entry,
+				"key \"%s\" in group \"%s\" is deprecated", entry.key, group.name)
+			continue
+		}
+
+		if strings.HasPrefix(entry.name, "X-") {
+			continue
+		}
+
+		spec, known := specs[entry.name]
+		if !known {
+			v.reportEntry("key-unknown", group, entry,
+				"file contains key \"%s\" in group \"%s\", but keys extending the format should "+
+					"start with \"X-\"", entry.key, group.name)
+			continue
+		}
+
+		if entryType != "" && spec.types != nil && !slices.Contains(spec.types, entryType) {
+			v.reportEntry("key-wrong-type", group, entry,
+				"key \"%s\" is present in group \"%s\", but the type is \"%s\" while this key is "+
+					"only valid for type \"%s\"", entry.name, group.name, entryType,
+				strings.Join(spec.types, ", "))
+		}
+
+		localized := spec.valueType == valueLocaleString ||
+			spec.valueType == valueIconString ||
+			spec.valueType == valueLocaleStrings
+		if entry.locale != "" && !localized {
+			v.reportEntry("key-not-localized", group, entry,
+				"file contains key \"%s\" in group \"%s\", but \"%s\" is not a localized key",
+				entry.key, group.name, entry.name)
+		}
+
+		v.checkValue(group, entry, spec.valueType)
+	}
+}
+
+// checkValue checks the value of the entry against its type.
+func (v *validator) checkValue(group *rawGroup, entry rawEntry, valueType valueType) {
+	value := entry.value
+
+	switch valueType {
+	case valueBoolean:
+		switch value {
+		case "true", "false":
+		case "0", "1":
+			v.reportEntry("value-boolean-deprecated", group, entry,
+				"boolean key \"%s\" in group \"%s\" has value \"%s\", which is deprecated: "+
+					"boolean values should be \"false\" or \"true\"", entry.key, group.name, value)
+		default:
+			v.reportEntry("value-boolean", group, entry,
+				"value \"%s\" for boolean key \"%s\" in group \"%s\" contains invalid "+
+					"characters, boolean values must be \"false\" or \"true\"",
+				value, entry.key, group.name)
+		}
+		return
+	case valueString, valueStrings:
+		if !isAsciiNoControl(value) {
+			v.reportEntry("value-string", group, entry,
+				"value \"%s\" for string key \"%s\" in group \"%s\" contains invalid "+
+					"characters, string values may contain all ASCII characters except for "+
+					"control characters", value, entry.key, group.name)
+			return
+		}
+	}
+
+	if invalid, found := invalidEscape(value, valueType); found {
+		v.reportEntry("value-escape", group, entry,
+			"value \"%s\" for key \"%s\" in group \"%s\" contains an escaped character '%c', "+
+				"but only \\s, \\n, \\t, \\r and \\\\ are valid escape sequences",
+			value, entry.key, group.name, invalid)
+		return
+	}
+
+	isList := valueType == valueStrings || valueType == valueLocaleStrings
+	if isList && value != "" && !strings.HasSuffix(value, ";") {
+		v.reportEntry("list-semicolon", group, entry,
+			"value \"%s\" for list key \"%s\" in group \"%s\" does not have a semicolon (\";\") "+
+				"as trailing character", value, entry.key, group.name)
+	}
+
+	switch entry.name {
+	case "Exec":
+		if _, err := NewExec(value); err != nil {
+			v.reportEntry("exec-invalid", group, entry,
+				"value \"%s\" for key \"%s\" in group \"%s\" is not a valid command line: %v",
+				value, entry.key, group.name, err)
+		}
+	case "Icon":
+		extension := strings.ToLower(filepath.Ext(value))
+		isImage := extension == ".png" || extension == ".xpm" || extension == ".svg"
+		if !filepath.IsAbs(value) && isImage {
+			v.reportEntry("icon-extension", group, entry,
+				"value \"%s\" for key \"%s\" in group \"%s\" is an icon name with an extension, "+
+					"but there should be no extension as described in the Icon Theme "+
+					"Specification if the value is not an absolute path",
+				value, entry.key, group.name)
+		}
+	case "MimeType":
+		values, _ := splitEscapedString(value)
+		for _, mimeType := range values {
+			if !mimeTypeRegex.MatchString(mimeType) {
+				v.reportEntry("mimetype-invalid", group, entry,
+					"value \"%s\" for key \"%s\" in group \"%s\" contains value \"%s\" which is "+
+						"an invalid MIME type", value, entry.key, group.name, mimeType)
+			}
+		}
+	case "Categories":
+		v.checkCategories(group, entry)
+	}
+}
+
+// invalidEscape returns the first character that is escaped but may not be. In lists, \; is
+// allowed as well.
+func invalidEscape(value string, valueType valueType) (byte, bool) {
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			continue
+		}
+
+		if i+1 >= len(value) {
+			return '\\', true
+		}
+
+		i++
+		switch value[i] {
+		case 's', 'n', 't', 'r', '\\':
+		case ';':
+			if valueType != valueStrings && valueType != valueLocaleStrings {
+				return ';', true
+			}
+		default:
+			return value[i], true
+		}
+	}
+
+	return 0, false
+}
+
+func (v *validator) checkCategories(group *rawGroup, entry rawEntry) {
+	categories, _ := splitEscapedString(entry.value)
+	hasMain := false
+	for _, category := range categories {
+		switch {
+		case slices.Contains(mainCategories, category):
+			hasMain = true
+		case slices.Contains(additionalCategories, category), strings.HasPrefix(category, "X-"):
+		default:
+			v.reportEntry("category-unknown", group, entry,
+				"value \"%s\" for key \"Categories\" in group \"%s\" contains an unregistered "+
+					"value \"%s\"; values extending the format should start with \"X-\"",
+				entry.value, group.name, category)
+		}
+	}
+
+	if !hasMain {
+		v.reportEntry("category-main-missing", group, entry,
+			"value \"%s\" for key \"Categories\" in group \"%s\" does not contain a registered "+
+				"main category; application might only show up in a \"catch-all\" section of "+
+				"the application menu", entry.value, group.name)
+	}
+}
+
+func (v *validator) checkShowIn(main *rawGroup) {
+	onlyShowIn, _ := main.lookup("OnlyShowIn")
+	notShowIn, _ := main.lookup("NotShowIn")
+	only, _ := splitEscapedString(onlyShowIn.value)
+	not, _ := splitEscapedString(notShowIn.value)
+
+	for _, entry := range []rawEntry{onlyShowIn, notShowIn} {
+		desktops, _ := splitEscapedString(entry.value)
+		for _, desktop := range desktops {
+			if !slices.Contains(registeredDesktops, desktop) && !strings.HasPrefix(desktop, "X-") {
+				v.reportEntry("desktop-unknown", main, entry,
+					"value \"%s\" for key \"%s\" in group \"%s\" contains an unregistered value "+
+						"\"%s\"; values extending the format should start with \"X-\"",
+					entry.value, entry.key, main.name, desktop)
+			}
+		}
+	}
+
+	for _, desktop := range only {
+		if slices.Contains(not, desktop) {
+			v.reportEntry("show-in-conflict", main, notShowIn,
+				"value \"%s\" for key \"NotShowIn\" in group \"%s\" contains \"%s\", which is "+
+					"also in key \"OnlyShowIn\"", notShowIn.value, main.name, desktop)
+		}
+	}
+}
+
+func (v *validator) checkActions(main *rawGroup) {
+	entry, _ := main.lookup("Actions")
+	actions, _ := splitEscapedString(entry.value)
+	for _, action := range actions {
+		if v.group(desktopActionPrefix+action) == nil {
+			v.reportEntry("action-missing", main, entry,
+				"action \"%s\" is defined, but there is no matching \"%s%s\" group",
+				action, desktopActionPrefix, action)
+		}
+	}
+
+	for _, group := range v.groups {
+		action, isAction := strings.CutPrefix(group.name, desktopActionPrefix)
+		if isAction && !slices.Contains(actions, action) {
+			v.report("action-unused", group.line, group.name, "",
+				"action group \"%s\" exists, but there is no matching action \"%s\"",
+				group.name, action)
+		}
+	}
+}
+
+func (v *validator) reportEntry(
+	rule string,
+	group *rawGroup,
+	entry rawEntry,
+	format string,
+	args ...any,
+) {
+	v.report(rule, entry.line, group.name, entry.key, format, args...)
+}
+
+func (v *validator) reportRequired(group *rawGroup, key string) {
+	v.report("key-required", group.line, group.name, key,
+		"required key \"%s\" in group \"%s\" is not present", key, group.name)
+}
@@ -0,0 +1,242 @@
+package desktop
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func diagnosticRules(diagnostics []Diagnostic) []string {
+	result := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic.Rule)
+	}
+
+	return result
+}
+
+func TestValidateValid(t *testing.T) {
+	diagnostics, err := Validate(strings.NewReader(`# Comment
+[Desktop Entry]
+Version=1.5
+Type=Application
+Name=Firefox
+Name[nl_BE]=Vúúrvos
+Icon=firefox
+Exec=firefox %u
+Categories=Network;WebBrowser;
+MimeType=text/html;x-scheme-handler/https;
+Actions=new-window;
+X-Custom=yes
+
+[Desktop Action new-window]
+Name=New Window
+Exec=firefox --new-window %u
+`), "firefox.desktop", ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 0 {
+		t.Errorf("Validate returned diagnostics for a valid file: %v", diagnostics)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	diagnostics, err := Validate(strings.NewReader(`Key=before
+[Desktop Entry]
+Type=Application
+Terminal=yes
+StartupNotify=1
+Icon=app.png
+Exec=app "unterminated
+Categories=Foo
+Encoding=UTF-8
+Unknown=value
+Type[nl]=Toepassing
+OnlyShowIn=GNOME;Foo;
+NotShowIn=GNOME;
+URL=https://example.com
+Actions=missing;
+Comment=a\qb
+Type=Link
+[Desktop Action unused]
+Name=Unused
+[Custom]
+`), "app.desktop", ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"key-before-group",
+		"key-required", // Name
+		"value-boolean",
+		"value-boolean-deprecated",
+		"icon-extension",
+		"exec-invalid",
+		"list-semicolon",
+		"category-unknown",
+		"category-main-missing",
+		"key-deprecated",
+		"key-unknown",
+		"key-not-localized",
+		"desktop-unknown",
+		"show-in-conflict",
+		"key-wrong-type",
+		"action-missing",
+		"value-escape",
+		"key-duplicate",
+		"action-unused",
+		"group-unknown",
+	}
+	actual := diagnosticRules(diagnostics)
+	slices.Sort(expected)
+	slices.Sort(actual)
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Validate rules = %v, expected: %v", actual, expected)
+	}
+
+	if !HasErrors(diagnostics) {
+		t.Errorf("HasErrors = false, expected: true")
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	diagnostics, err := Validate(strings.NewReader(`[Desktop Entry]
+Type=Application
+Name=App
+Exec=app
+Terminal=yes
+`), "app.desktop", ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("Validate returned %d diagnostics, expected: 1", len(diagnostics))
+	}
+
+	expected := `app.desktop: error: value "yes" for boolean key "Terminal" in group ` +
+		`"Desktop Entry" contains invalid characters, boolean values must be "false" or "true"`
+	if diagnostics[0].String() != expected {
+		t.Errorf("String() = %s, expected: %s", diagnostics[0].String(), expected)
+	}
+
+	if diagnostics[0].Line != 5 {
+		t.Errorf("Line = %d, expected: 5", diagnostics[0].Line)
+	}
+}
+
+func TestValidateFirstGroup(t *testing.T) {
+	diagnostics, err := Validate(strings.NewReader(`[Other]
+[Desktop Entry]
+Type=Directory
+Name=Dir
+`), "dir.directory", ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := diagnosticRules(diagnostics)
+	expected := []string{"first-group", "group-unknown"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Validate rules = %v, expected: %v", actual, expected)
+	}
+}
+
+func TestValidateDBusName(t *testing.T) {
+	content := "[Desktop Entry]\nType=Application\nName=App\nDBusActivatable=true\n"
+	diagnostics, err := Validate(
+		strings.NewReader(content),
+		"/usr/share/app.desktop",
+		ValidateOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := diagnosticRules(diagnostics)
+	if !slices.Equal(actual, []string{"dbus-name"}) {
+		t.Errorf("Validate rules = %v, expected: [dbus-name]", actual)
+	}
+
+	diagnostics, err = Validate(
+		strings.NewReader(content),
+		"org.example.App.desktop",
+		ValidateOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("Validate returned diagnostics for a valid D-Bus name: %v", diagnostics)
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	content := `[Desktop Entry]
+Type=Application
+Name=App
+Exec=app
+Icon=app.png
+Categories=X-Custom;
+Terminal=0
+`
+	diagnostics, err := Validate(strings.NewReader(content), "app.desktop", ValidateOptions{
+		Severity: map[string]Severity{
+			"icon-extension":           SeverityOff,
+			"value-boolean-deprecated": SeverityError,
+		},
+		MinSeverity: SeverityWarning,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("Validate returned %v, expected: only value-boolean-deprecated", diagnostics)
+	}
+	if diagnostics[0].Rule != "value-boolean-deprecated" || diagnostics[0].Severity != SeverityError {
+		t.Errorf("Diagnostic = %s %s, expected: value-boolean-deprecated error",
+			diagnostics[0].Rule, diagnostics[0].Severity)
+	}
+}
+
+func TestDiagnosticJSON(t *testing.T) {
+	data, err := json.Marshal(Diagnostic{
+		Path:     "app.desktop",
+		Line:     3,
+		Group:    "Desktop Entry",
+		Key:      "Terminal",
+		Rule:     "value-boolean",
+		Severity: SeverityError,
+		Message:  "message",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"path":"app.desktop","line":3,"group":"Desktop Entry","key":"Terminal",` +
+		`"rule":"value-boolean","severity":"error","message":"message"}`
+	if string(data) != expected {
+		t.Errorf("json.Marshal = %s, expected: %s", data, expected)
+	}
+
+	var severity Severity
+	err = json.Unmarshal([]byte(`"hint"`), &severity)
+	if err != nil || severity != SeverityHint {
+		t.Errorf("json.Unmarshal = %v, %v, expected: hint", severity, err)
+	}
+}
+
+func TestRulesUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, rule := range Rules {
+		if seen[rule.ID] {
+			t.Errorf("Duplicate rule %s", rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+}
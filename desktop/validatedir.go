@@ -0,0 +1,143 @@
+package desktop
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// FileReport contains the diagnostics of a desktop file validated by ValidateDir or ValidateAll.
+type FileReport struct {
+	// Path of the desktop file.
+	Path string
+
+	// DesktopId is the desktop ID of the file, e.g. kde-okular.desktop.
+	DesktopId string
+
+	// Precedence is the index of the location of the file, see IdEntry.
+	Precedence int
+
+	Diagnostics []Diagnostic
+
+	// Err is set if the file could not be read, Diagnostics is empty in that case.
+	Err error
+}
+
+// ValidationReport is the result of validating the desktop files of one or more locations.
+type ValidationReport struct {
+	// Files are the validated desktop files, ordered by precedence and path.
+	Files []FileReport
+}
+
+// HasErrors reports whether any of the files could not be read or has an error diagnostic.
+func (r *ValidationReport) HasErrors() bool {
+	return slices.ContainsFunc(r.Files, func(file FileReport) bool {
+		return file.Err != nil || HasErrors(file.Diagnostics)
+	})
+}
+
+// Diagnostics returns the diagnostics of all files.
+func (r *ValidationReport) Diagnostics() []Diagnostic {
+	var result []Diagnostic
+	for _, file := range r.Files {
+		result = append(result, file.Diagnostics...)
+	}
+
+	return result
+}
+
+// ValidateDir validates every desktop file in dir and its subdirectories, e.g.
+// /usr/share/applications or the staging directory of a package. Besides the checks of Validate,
+// the TryExec executable must be installed and desktop IDs must be unique, e.g. foo-bar.desktop
+// and foo/bar.desktop conflict. See Rules for the IDs of these checks.
+//
+// An error is only returned if dir cannot be walked, problems with individual files are
+// reported in the FileReport.
+func ValidateDir(dir string, opts ValidateOptions) (*ValidationReport, error) {
+	report, err := validateLocations([]string{dir}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ValidateDir: %w", err)
+	}
+
+	return report, nil
+}
+
+// ValidateAll is like ValidateDir but validates the desktop files of all locations of
+// GetDesktopFileLocations. Desktop files that are masked by a desktop file with the same ID in a
+// location of higher precedence are reported using a hint.
+func ValidateAll(opts ValidateOptions) (*ValidationReport, error) {
+	report, err := validateLocations(GetDesktopFileLocations(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("ValidateAll: %w", err)
+	}
+
+	return report, nil
+}
+
+func validateLocations(locations []string, opts ValidateOptions) (*ValidationReport, error) {
+	entries, err := GetDesktopFileEntries(locations)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{}
+	for _, desktopId := range slices.Sorted(maps.Keys(entries)) {
+		idEntries := entries[desktopId]
+		for i, entry := range idEntries {
+			file := validateEntry(desktopId, idEntries[:i], entry, opts)
+			report.Files = append(report.Files, file)
+		}
+	}
+
+	slices.SortFunc(report.Files, func(a FileReport, b FileReport) int {
+		return cmp.Or(cmp.Compare(a.Precedence, b.Precedence), cmp.Compare(a.Path, b.Path))
+	})
+
+	return report, nil
+}
+
+// validateEntry validates the desktop file of entry. previous are the desktop files with the same
+// ID and a higher or equal precedence.
+func validateEntry(
+	desktopId string,
+	previous []IdEntry,
+	entry IdEntry,
+	opts ValidateOptions,
+) FileReport {
+	result := FileReport{
+		Path:       entry.Path,
+		DesktopId:  desktopId,
+		Precedence: entry.Precedence,
+	}
+
+	diagnostics, err := ValidateFile(entry.Path, opts)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	v := validator{path: entry.Path, opts: opts, diagnostics: diagnostics}
+	for _, other := range previous {
+		if other.Precedence == entry.Precedence {
+			v.report("id-duplicate", 0, "", "",
+				"desktop ID \"%s\" is also used by \"%s\" in the same directory",
+				desktopId, other.Path)
+		} else {
+			v.report("id-masked", 0, "", "",
+				"desktop ID \"%s\" is masked by \"%s\"", desktopId, other.Path)
+		}
+	}
+
+	parsed, _ := loadFileLenient(entry.Path)
+	if parsed != nil && parsed.TryExec != "" {
+		if err := parsed.CheckTryExec(); err != nil {
+			v.report("tryexec-missing", 0, requiredGroupName, "TryExec",
+				"value \"%s\" for key \"TryExec\" in group \"%s\" is not an installed executable",
+				parsed.TryExec, requiredGroupName)
+		}
+	}
+
+	result.Diagnostics = v.diagnostics
+	return result
+}
@@ -0,0 +1,95 @@
+package desktop
+
+import (
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"valid.desktop":      "[Desktop Entry]\nType=Application\nName=Valid\nExec=valid\n",
+		"invalid.desktop":    "[Desktop Entry]\nType=Application\nExec=invalid\n",
+		"tryexec.desktop":    "[Desktop Entry]\nType=Application\nName=A\nExec=a\nTryExec=nope\n",
+		"vendor-app.desktop": "[Desktop Entry]\nType=Application\nName=A\nExec=a\n",
+		"vendor/app.desktop": "[Desktop Entry]\nType=Application\nName=A\nExec=a\n",
+	})
+	t.Setenv("PATH", "")
+
+	report, err := ValidateDir(dir, ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"invalid.desktop":    {"key-required"},
+		"tryexec.desktop":    {"tryexec-missing"},
+		"valid.desktop":      {},
+		"vendor-app.desktop": {"id-duplicate"},
+		"vendor/app.desktop": {},
+	}
+	if len(report.Files) != len(expected) {
+		t.Fatalf("len(Files) = %d, expected: %d", len(report.Files), len(expected))
+	}
+	for _, file := range report.Files {
+		name, _ := filepath.Rel(dir, file.Path)
+		name = filepath.ToSlash(name)
+		if file.Err != nil {
+			t.Errorf("%s: %v", name, file.Err)
+		}
+		if rules := diagnosticRules(file.Diagnostics); !slices.Equal(rules, expected[name]) {
+			t.Errorf("%s diagnostics = %v, expected: %v", name, rules, expected[name])
+		}
+	}
+
+	if !report.HasErrors() {
+		t.Errorf("HasErrors() = false, expected: true")
+	}
+
+	report, err = ValidateDir(dir, ValidateOptions{
+		Severity: map[string]Severity{"tryexec-missing": SeverityOff},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diagnostic := range report.Diagnostics() {
+		if diagnostic.Rule == "tryexec-missing" {
+			t.Errorf("disabled rule reported: %s", diagnostic)
+		}
+	}
+}
+
+func TestValidateAllMasked(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local/share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "usr/share"))
+	basedir.Reinit()
+	t.Cleanup(basedir.Reinit)
+
+	app := "[Desktop Entry]\nType=Application\nName=App\nExec=app\n"
+	writeFiles(t, home, map[string]string{
+		".local/share/applications/app.desktop": app,
+		"usr/share/applications/app.desktop":    app,
+	})
+
+	report, err := ValidateAll(ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("len(Files) = %d, expected: 2", len(report.Files))
+	}
+	if rules := diagnosticRules(report.Files[0].Diagnostics); len(rules) != 0 {
+		t.Errorf("user file diagnostics = %v, expected none", rules)
+	}
+	rules := diagnosticRules(report.Files[1].Diagnostics)
+	if !slices.Equal(rules, []string{"id-masked"}) {
+		t.Errorf("system file diagnostics = %v, expected: [id-masked]", rules)
+	}
+	if report.HasErrors() {
+		t.Errorf("HasErrors() = true, expected: false")
+	}
+}
@@ -0,0 +1,67 @@
+package desktop
+
+// Extension keys that are widely used by desktop environments and packaging formats. Their
+// decoders are registered by this package, see RegisterExtension.
+const (
+	// KeyFlatpak contains the Flatpak application ID of applications installed using Flatpak.
+	KeyFlatpak = "X-Flatpak"
+
+	// KeySnapInstanceName contains the instance name of applications installed using Snap.
+	KeySnapInstanceName = "X-SnapInstanceName"
+
+	// KeyGNOMEUsesNotifications signals that the application sends notifications.
+	KeyGNOMEUsesNotifications = "X-GNOME-UsesNotifications"
+
+	// KeyKDERunOnDiscreteGpu is the KDE predecessor of PrefersNonDefaultGPU.
+	KeyKDERunOnDiscreteGpu = "X-KDE-RunOnDiscreteGpu"
+
+	// KeyMultipleArgs signals that the application accepts multiple files in a single
+	// invocation. It predates the %F and %U field codes.
+	KeyMultipleArgs = "X-MultipleArgs"
+)
+
+func init() {
+	RegisterExtension(KeyFlatpak, DecodeString)
+	RegisterExtension(KeySnapInstanceName, DecodeString)
+	RegisterExtension(KeyGNOMEUsesNotifications, DecodeBoolean)
+	RegisterExtension(KeyKDERunOnDiscreteGpu, DecodeBoolean)
+	RegisterExtension(KeyMultipleArgs, DecodeBoolean)
+}
+
+// FlatpakID returns the Flatpak application ID, e.g. org.mozilla.firefox, or an empty string if
+// the application is not installed using Flatpak.
+func (e *Entry) FlatpakID() string {
+	id, _ := ExtensionAs[string](e, KeyFlatpak)
+	return id
+}
+
+// SnapInstanceName returns the Snap instance name or an empty string if the application is not
+// installed using Snap.
+func (e *Entry) SnapInstanceName() string {
+	name, _ := ExtensionAs[string](e, KeySnapInstanceName)
+	return name
+}
+
+// UsesNotifications returns whether the application declares that it sends notifications.
+func (e *Entry) UsesNotifications() bool {
+	uses, _ := ExtensionAs[bool](e, KeyGNOMEUsesNotifications)
+	return uses
+}
+
+// PrefersDiscreteGPU returns whether the application prefers to run on a discrete GPU, either
+// using PrefersNonDefaultGPU or the older X-KDE-RunOnDiscreteGpu key.
+func (e *Entry) PrefersDiscreteGPU() bool {
+	if e.PrefersNonDefaultGPU {
+		return true
+	}
+
+	prefers, _ := ExtensionAs[bool](e, KeyKDERunOnDiscreteGpu)
+	return prefers
+}
+
+// MultipleArgs returns whether the application accepts multiple files in a single invocation
+// according to X-MultipleArgs. The %F and %U field codes are the standard way to express this.
+func (e *Entry) MultipleArgs() bool {
+	multiple, _ := ExtensionAs[bool](e, KeyMultipleArgs)
+	return multiple
+}
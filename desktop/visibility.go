@@ -0,0 +1,120 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// ErrNotInstalled is returned by CheckTryExec when the TryExec executable is not available.
+var ErrNotInstalled = errors.New("TryExec executable not available")
+
+// CheckTryExec checks whether the program of the entry is installed using the TryExec key.
+// An absolute TryExec must be an executable file, other values are looked up in $PATH.
+//
+// If the executable is not available, an error matching ErrNotInstalled is returned. It also
+// wraps the reason, e.g. exec.ErrNotFound or fs.ErrPermission. Entries without TryExec are
+// considered installed. See CheckInstalled for a check that supports Flatpak and Snap.
+func (e *Entry) CheckTryExec() error {
+	if e.TryExec == "" {
+		return nil
+	}
+
+	_, err := exec.LookPath(e.TryExec)
+	if err != nil {
+		return fmt.Errorf("CheckTryExec: %w: %w", ErrNotInstalled, err)
+	}
+
+	return nil
+}
+
+// IsInstalled returns true if the TryExec executable is available, see CheckTryExec.
+func (e *Entry) IsInstalled() bool {
+	return e.CheckTryExec() == nil
+}
+
+// ShouldShowIn returns true if the entry should be shown in the current desktop environments
+// according to the OnlyShowIn and NotShowIn keys. currentDesktop is the colon-separated value
+// of $XDG_CURRENT_DESKTOP, e.g. ubuntu:GNOME.
+//
+// Each desktop is considered in order. If it is found in OnlyShowIn, the entry is shown. If it
+// is found in NotShowIn, the entry is not shown. If no desktop matches, the entry is shown
+// unless OnlyShowIn is present.
+func (e *Entry) ShouldShowIn(currentDesktop string) bool {
+	for _, name := range strings.Split(currentDesktop, ":") {
+		if name == "" {
+			continue
+		}
+
+		if slices.Contains(e.OnlyShowIn, name) {
+			return true
+		}
+
+		if slices.Contains(e.NotShowIn, name) {
+			return false
+		}
+	}
+
+	return len(e.OnlyShowIn) == 0
+}
+
+// VisibilityOptions configure Entry.Visibility.
+type VisibilityOptions struct {
+	// CurrentDesktop is the colon-separated list of current desktop environments used for
+	// OnlyShowIn and NotShowIn, see ShouldShowIn. If empty, $XDG_CURRENT_DESKTOP is used.
+	CurrentDesktop string
+
+	// CheckTryExec enables checking whether the TryExec executable is installed.
+	CheckTryExec bool
+}
+
+// Visibility explains whether an entry should be shown in menus and launchers. Every condition
+// is evaluated, so all reasons an entry is not shown are known.
+type Visibility struct {
+	// Hidden is true if the entry has Hidden=true, i.e. it was deleted.
+	Hidden bool
+
+	// NoDisplay is true if the entry has NoDisplay=true.
+	NoDisplay bool
+
+	// NotShownIn is true if OnlyShowIn or NotShowIn exclude the current desktop environments.
+	NotShownIn bool
+
+	// TryExecErr is the error of CheckTryExec if it was enabled and failed.
+	TryExecErr error
+}
+
+// Visible returns true if none of the conditions hide the entry.
+func (v Visibility) Visible() bool {
+	return !v.Hidden && !v.NoDisplay && !v.NotShownIn && v.TryExecErr == nil
+}
+
+// Visibility evaluates Hidden, NoDisplay, OnlyShowIn and NotShowIn, and optionally TryExec to
+// determine whether the entry should be shown.
+func (e *Entry) Visibility(opts VisibilityOptions) Visibility {
+	currentDesktop := opts.CurrentDesktop
+	if currentDesktop == "" {
+		currentDesktop = os.Getenv("XDG_CURRENT_DESKTOP")
+	}
+
+	result := Visibility{
+		Hidden:     e.Hidden,
+		NoDisplay:  e.NoDisplay,
+		NotShownIn: !e.ShouldShowIn(currentDesktop),
+	}
+
+	if opts.CheckTryExec {
+		result.TryExecErr = e.CheckTryExec()
+	}
+
+	return result
+}
+
+// IsVisible returns true if the entry should be shown in menus and launchers. See Visibility to
+// know why an entry is not visible.
+func (e *Entry) IsVisible(opts VisibilityOptions) bool {
+	return e.Visibility(opts).Visible()
+}
@@ -0,0 +1,112 @@
+package desktop
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckTryExec(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "app")
+	notExecutable := filepath.Join(dir, "data")
+	for path, mode := range map[string]os.FileMode{executable: 0700, notExecutable: 0600} {
+		err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		tryExec  string
+		expected error
+	}{
+		{"", nil},
+		{executable, nil},
+		{"app", nil},
+		{"missing", exec.ErrNotFound},
+		{notExecutable, fs.ErrPermission},
+		{filepath.Join(dir, "missing"), fs.ErrNotExist},
+	}
+
+	for _, test := range tests {
+		entry := &Entry{TryExec: test.tryExec}
+		err := entry.CheckTryExec()
+
+		switch {
+		case test.expected == nil && err != nil:
+			t.Errorf("CheckTryExec() for %s error: %v", test.tryExec, err)
+		case test.expected != nil && !errors.Is(err, ErrNotInstalled):
+			t.Errorf("CheckTryExec() for %s = %v, expected: %v", test.tryExec, err, ErrNotInstalled)
+		case test.expected != nil && !errors.Is(err, test.expected):
+			t.Errorf("CheckTryExec() for %s = %v, expected: %v", test.tryExec, err, test.expected)
+		}
+
+		if entry.IsInstalled() != (test.expected == nil) {
+			t.Errorf("IsInstalled() for %s = %v", test.tryExec, entry.IsInstalled())
+		}
+	}
+}
+
+func TestShouldShowIn(t *testing.T) {
+	tests := []struct {
+		only           []string
+		not            []string
+		currentDesktop string
+		expected       bool
+	}{
+		{nil, nil, "", true},
+		{nil, nil, "GNOME", true},
+		{[]string{"KDE"}, nil, "GNOME", false},
+		{[]string{"KDE"}, nil, "", false},
+		{[]string{"GNOME"}, nil, "ubuntu:GNOME", true},
+		{nil, []string{"GNOME"}, "ubuntu:GNOME", false},
+		{[]string{"ubuntu"}, []string{"GNOME"}, "ubuntu:GNOME", true},
+		{[]string{"ubuntu"}, []string{"GNOME"}, "GNOME:ubuntu", false},
+	}
+
+	for _, test := range tests {
+		entry := &Entry{OnlyShowIn: test.only, NotShowIn: test.not}
+		if actual := entry.ShouldShowIn(test.currentDesktop); actual != test.expected {
+			t.Errorf(
+				"ShouldShowIn(%s) with OnlyShowIn=%v, NotShowIn=%v = %v, expected: %v",
+				test.currentDesktop,
+				test.only,
+				test.not,
+				actual,
+				test.expected,
+			)
+		}
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	t.Setenv("XDG_CURRENT_DESKTOP", "GNOME")
+	t.Setenv("PATH", t.TempDir())
+
+	entry := &Entry{NoDisplay: true, OnlyShowIn: []string{"KDE"}, TryExec: "missing"}
+
+	visibility := entry.Visibility(VisibilityOptions{})
+	expected := Visibility{NoDisplay: true, NotShownIn: true}
+	if visibility != expected {
+		t.Errorf("Visibility() = %+v, expected: %+v", visibility, expected)
+	}
+
+	visibility = entry.Visibility(VisibilityOptions{CurrentDesktop: "KDE", CheckTryExec: true})
+	if visibility.NotShownIn || !errors.Is(visibility.TryExecErr, ErrNotInstalled) {
+		t.Errorf("Visibility() = %+v, expected shown in KDE with TryExec error", visibility)
+	}
+
+	if entry.IsVisible(VisibilityOptions{CurrentDesktop: "KDE"}) {
+		t.Errorf("IsVisible() = true for NoDisplay=true")
+	}
+
+	entry.NoDisplay = false
+	if !entry.IsVisible(VisibilityOptions{CurrentDesktop: "KDE"}) {
+		t.Errorf("IsVisible() = false, expected: true")
+	}
+}
@@ -0,0 +1,119 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"github.com/MatthiasKunnen/xdg/internal/watch"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// watchDebounce is the duration without changes after which the locations are scanned again.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch scans the locations like GetDesktopFiles and calls callback with the result before
+// returning. The locations and their subdirectories are then watched and, whenever desktop files
+// are installed, removed, or modified, the locations are scanned again and callback is called
+// with the new IdPathMap. Changes are debounced so that installing a package results in a single
+// call.
+//
+// Locations that do not exist when Watch is called are not watched. callback is called from a
+// single goroutine. Watching stops when ctx is done.
+func Watch(ctx context.Context, locations []string, callback func(IdPathMap)) error {
+	idPathMap, err := GetDesktopFilesContext(ctx, locations)
+	if err != nil {
+		return fmt.Errorf("Watch: %w", err)
+	}
+
+	dirs := watchDirs(locations)
+	watcher, err := watch.New(dirs)
+	if err != nil {
+		return fmt.Errorf("Watch: %w", err)
+	}
+
+	callback(idPathMap)
+
+	go func() {
+		defer func() {
+			closeWatcher(watcher)
+		}()
+
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.C:
+				if !ok {
+					return
+				}
+				timer.Reset(watchDebounce)
+				continue
+			case <-timer.C:
+			}
+
+			idPathMap, err := GetDesktopFilesContext(ctx, locations)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				logging.Logger().Warn("Failed to scan desktop files", logging.Error, err)
+				continue
+			}
+
+			// Subdirectories may have been added or removed.
+			if current := watchDirs(locations); !slices.Equal(current, dirs) {
+				replacement, err := watch.New(current)
+				if err != nil {
+					logging.Logger().Warn(
+						"Failed to watch desktop file locations",
+						logging.Error, err,
+					)
+				} else {
+					closeWatcher(watcher)
+					watcher = replacement
+					dirs = current
+				}
+			}
+
+			callback(idPathMap)
+		}
+	}()
+
+	return nil
+}
+
+// watchDirs returns the locations that exist and all of their subdirectories.
+func watchDirs(locations []string) []string {
+	var result []string
+	for _, location := range locations {
+		filepath.WalkDir(location, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if entry.IsDir() {
+				result = append(result, path)
+			}
+
+			return nil
+		})
+	}
+
+	return result
+}
+
+// closeWatcher closes the watcher and discards the changes that are still pending.
+func closeWatcher(watcher *watch.Watcher) {
+	watcher.Close()
+	go func() {
+		for range watcher.C {
+		}
+	}()
+}
@@ -0,0 +1,63 @@
+package desktop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.desktop": "[Desktop Entry]\n"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan IdPathMap, 10)
+	err := Watch(ctx, []string{dir}, func(m IdPathMap) {
+		updates <- m
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectUpdate := func(check func(m IdPathMap) bool) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case m := <-updates:
+				if check(m) {
+					return
+				}
+			case <-timeout:
+				t.Fatal("no matching update received")
+			}
+		}
+	}
+
+	expectUpdate(func(m IdPathMap) bool {
+		return m["a.desktop"] != nil
+	})
+
+	// Files in new subdirectories are found as well.
+	writeFiles(t, dir, map[string]string{"vendor/b.desktop": "[Desktop Entry]\n"})
+	expectUpdate(func(m IdPathMap) bool {
+		return m["vendor-b.desktop"] != nil
+	})
+
+	writeFiles(t, dir, map[string]string{"vendor/c.desktop": "[Desktop Entry]\n"})
+	expectUpdate(func(m IdPathMap) bool {
+		return m["vendor-c.desktop"] != nil
+	})
+
+	err = os.Remove(filepath.Join(dir, "a.desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUpdate(func(m IdPathMap) bool {
+		return m["a.desktop"] == nil
+	})
+}
@@ -0,0 +1,199 @@
+// Package fileuri converts between local paths and file URIs following the conventions shared
+// by the freedesktop.org specifications and GLib.
+// See https://www.freedesktop.org/wiki/Specifications/file-uri-spec/.
+//
+// Paths are byte strings, every byte outside the set of characters allowed unescaped in a URI
+// path, including all non-ASCII bytes, is percent-encoded. The host part is either empty, which
+// is how local files are usually written, or the hostname of the machine the file is on.
+package fileuri
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const scheme = "file://"
+
+var (
+	// ErrNotFileURI is returned when a URI does not use the file scheme.
+	ErrNotFileURI = errors.New("not a file URI")
+
+	// ErrInvalidURI is returned when a file URI cannot be converted to a path, e.g. because of
+	// invalid escapes.
+	ErrInvalidURI = errors.New("invalid file URI")
+
+	// ErrRemoteHost is returned when a file URI refers to a file on another host.
+	ErrRemoteHost = errors.New("file URI refers to another host")
+)
+
+// FromPath returns the file URI of the file at path, without host.
+// The path is made absolute and cleaned.
+// E.g. /home/user/my photo.jpg becomes file:///home/user/my%20photo.jpg.
+func FromPath(path string) (string, error) {
+	return FromPathWithHost(path, "")
+}
+
+// FromPathWithHost returns the file URI of the file at path with the given hostname as host
+// part. Use os.Hostname to produce URIs that remain valid on other hosts, e.g. for files on
+// network shares.
+func FromPathWithHost(path string, host string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("FromPathWithHost: failed to make %s absolute: %w", path, err)
+	}
+
+	pathHost, uriPath := toURIPath(absolute)
+	if pathHost != "" {
+		host = pathHost
+	}
+
+	return scheme + escapeHost(host) + EscapePath(uriPath), nil
+}
+
+// Split returns the unescaped host and path of the file URI. The path uses slashes and is not
+// converted to a local path, the host is not checked.
+func Split(uri string) (string, string, error) {
+	if len(uri) < len(scheme) || !strings.EqualFold(uri[:len(scheme)], scheme) {
+		return "", "", fmt.Errorf("%w: %s", ErrNotFileURI, uri)
+	}
+
+	rest := uri[len(scheme):]
+	if strings.ContainsAny(rest, "#?") {
+		return "", "", fmt.Errorf("%w: %s contains a query or fragment", ErrInvalidURI, uri)
+	}
+
+	host, path, found := strings.Cut(rest, "/")
+	if !found {
+		return "", "", fmt.Errorf("%w: %s has no path", ErrInvalidURI, uri)
+	}
+
+	host, err := url.PathUnescape(host)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s: %w", ErrInvalidURI, uri, err)
+	}
+
+	path, err = UnescapePath("/" + path)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s: %w", ErrInvalidURI, uri, err)
+	}
+
+	return host, path, nil
+}
+
+// ToPath returns the local path of the file URI. The host must be empty, localhost, or the
+// hostname of this machine, otherwise ErrRemoteHost is returned. On Windows, URIs of other hosts
+// are converted to UNC paths instead.
+func ToPath(uri string) (string, error) {
+	host, uriPath, err := Split(uri)
+	if err != nil {
+		return "", fmt.Errorf("ToPath: %w", err)
+	}
+
+	if IsLocalHost(host) {
+		host = ""
+	}
+
+	path, err := fromURIPath(host, uriPath)
+	if err != nil {
+		return "", fmt.Errorf("ToPath: %s: %w", uri, err)
+	}
+
+	return path, nil
+}
+
+// IsLocal reports whether uri is a file URI of a file on this machine.
+func IsLocal(uri string) bool {
+	host, _, err := Split(uri)
+	return err == nil && IsLocalHost(host)
+}
+
+// IsLocalHost reports whether host, the host part of a file URI, refers to this machine.
+func IsLocalHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	hostname, err := os.Hostname()
+	return err == nil && strings.EqualFold(host, hostname)
+}
+
+// EscapePath percent-encodes all bytes of the slash separated path that are not allowed
+// unescaped in the path of a file URI, using upper case hexadecimal digits like GLib does.
+func EscapePath(path string) string {
+	return escape(path, "-._~!$&'()*+,=:@/")
+}
+
+// UnescapePath decodes the percent-encoded path. Escaped slashes and null bytes are rejected as
+// they cannot be part of a file name.
+func UnescapePath(path string) (string, error) {
+	var builder strings.Builder
+	builder.Grow(len(path))
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c != '%' {
+			builder.WriteByte(c)
+			continue
+		}
+
+		if i+2 >= len(path) || !isHex(path[i+1]) || !isHex(path[i+2]) {
+			return "", fmt.Errorf("invalid escape %q", path[i:min(i+3, len(path))])
+		}
+
+		decoded := unhex(path[i+1])<<4 | unhex(path[i+2])
+		if decoded == '/' || decoded == 0 {
+			return "", fmt.Errorf("escaped %q is not allowed in a path", decoded)
+		}
+
+		builder.WriteByte(decoded)
+		i += 2
+	}
+
+	return builder.String(), nil
+}
+
+func escapeHost(host string) string {
+	return escape(host, "-._~!$&'()*+,=:[]")
+}
+
+// escape percent-encodes all bytes except alphanumeric ASCII and the allowed bytes.
+func escape(s string, allowed string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var builder strings.Builder
+	builder.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			strings.IndexByte(allowed, c) >= 0:
+			builder.WriteByte(c)
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hexDigits[c>>4])
+			builder.WriteByte(hexDigits[c&0xF])
+		}
+	}
+
+	return builder.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
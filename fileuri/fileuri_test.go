@@ -0,0 +1,117 @@
+//go:build !windows
+
+package fileuri
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/home/user/my photo#1 (ü).jpg", "file:///home/user/my%20photo%231%20(%C3%BC).jpg"},
+		{"/tmp/a/../b%c", "file:///tmp/b%25c"},
+		{"/tmp/semi;colon?", "file:///tmp/semi%3Bcolon%3F"},
+	}
+
+	for _, test := range tests {
+		actual, err := FromPath(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Errorf("FromPath(%s) = %s, expected: %s", test.path, actual, test.expected)
+		}
+	}
+}
+
+func TestFromPathWithHost(t *testing.T) {
+	actual, err := FromPathWithHost("/srv/file", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != "file://example/srv/file" {
+		t.Errorf("FromPathWithHost = %s, expected: file://example/srv/file", actual)
+	}
+}
+
+func TestToPath(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		uri      string
+		expected string
+	}{
+		{"file:///home/user/my%20photo%231%20(%C3%BC).jpg", "/home/user/my photo#1 (ü).jpg"},
+		{"FILE:///tmp/a", "/tmp/a"},
+		{"file://localhost/tmp/a", "/tmp/a"},
+		{"file://" + hostname + "/tmp/a", "/tmp/a"},
+	}
+
+	for _, test := range tests {
+		actual, err := ToPath(test.uri)
+		if err != nil {
+			t.Errorf("ToPath(%s) failed: %v", test.uri, err)
+		} else if actual != test.expected {
+			t.Errorf("ToPath(%s) = %s, expected: %s", test.uri, actual, test.expected)
+		}
+	}
+}
+
+func TestToPathErrors(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected error
+	}{
+		{"https://example.com/a", ErrNotFileURI},
+		{"/tmp/a", ErrNotFileURI},
+		{"file://other-host.invalid/tmp/a", ErrRemoteHost},
+		{"file:///tmp/a%2Fb", ErrInvalidURI},
+		{"file:///tmp/a%00", ErrInvalidURI},
+		{"file:///tmp/a%zz", ErrInvalidURI},
+		{"file:///tmp/a#fragment", ErrInvalidURI},
+		{"file://host-only", ErrInvalidURI},
+	}
+
+	for _, test := range tests {
+		_, err := ToPath(test.uri)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("ToPath(%s) error = %v, expected: %v", test.uri, err, test.expected)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	path := "/tmp/\x01 weird\tname\xff%41"
+	uri, err := FromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := ToPath(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != path {
+		t.Errorf("ToPath(FromPath(%q)) = %q", path, actual)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	if !IsLocal("file:///tmp/a") {
+		t.Errorf("IsLocal(file:///tmp/a) = false, expected: true")
+	}
+	if IsLocal("file://other-host.invalid/tmp/a") {
+		t.Errorf("IsLocal(file://other-host.invalid/tmp/a) = true, expected: false")
+	}
+	if IsLocal("https://example.com") {
+		t.Errorf("IsLocal(https://example.com) = true, expected: false")
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package fileuri
+
+import (
+	"fmt"
+)
+
+// toURIPath returns the host and URI path of the absolute path.
+func toURIPath(path string) (string, string) {
+	return "", path
+}
+
+// fromURIPath returns the local path of the URI path. host is empty for local files.
+func fromURIPath(host string, uriPath string) (string, error) {
+	if host != "" {
+		return "", fmt.Errorf("%w: %s", ErrRemoteHost, host)
+	}
+
+	return uriPath, nil
+}
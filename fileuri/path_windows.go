@@ -0,0 +1,34 @@
+package fileuri
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// toURIPath returns the host and URI path of the absolute path. Drive paths such as C:\dir
+// become /C:/dir, UNC paths such as \\server\share\dir become host server with path /share/dir.
+func toURIPath(path string) (string, string) {
+	slashed := filepath.ToSlash(path)
+	if rest, isUNC := strings.CutPrefix(slashed, "//"); isUNC {
+		host, share, _ := strings.Cut(rest, "/")
+		return host, "/" + share
+	}
+
+	return "", "/" + slashed
+}
+
+// fromURIPath returns the local path of the URI path. URIs of other hosts are converted to UNC
+// paths.
+func fromURIPath(host string, uriPath string) (string, error) {
+	if host != "" {
+		return `\\` + host + filepath.FromSlash(uriPath), nil
+	}
+
+	trimmed := strings.TrimPrefix(uriPath, "/")
+	if filepath.VolumeName(trimmed) == "" {
+		return "", fmt.Errorf("%w: %s has no drive letter", ErrInvalidURI, uriPath)
+	}
+
+	return filepath.FromSlash(trimmed), nil
+}
@@ -0,0 +1,429 @@
+// Package dbus is a minimal client of the D-Bus message bus, sufficient to call methods and
+// receive signals on the session bus.
+// See https://dbus.freedesktop.org/doc/dbus-specification.html.
+package dbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	busName      = "org.freedesktop.DBus"
+	busPath      = ObjectPath("/org/freedesktop/DBus")
+	busInterface = "org.freedesktop.DBus"
+)
+
+// ErrClosed is returned for calls on a closed connection.
+var ErrClosed = errors.New("dbus: connection closed")
+
+var errUnixFDsUnsupported = errors.New("passing file descriptors is not supported")
+
+// Error is an error reply to a method call.
+type Error struct {
+	Name    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Name
+	}
+
+	return e.Name + ": " + e.Message
+}
+
+// Conn is a connection to a message bus.
+type Conn struct {
+	conn    net.Conn
+	unixFDs bool
+
+	writeLock sync.Mutex
+	serial    uint32
+
+	lock        sync.Mutex
+	pending     map[uint32]chan *Message
+	subscribers map[*subscriber]bool
+	closed      bool
+	err         error
+	done        chan struct{}
+
+	// UniqueName is the name assigned by the bus, e.g. :1.42.
+	UniqueName string
+}
+
+type subscriber struct {
+	c chan *Message
+}
+
+// SessionBusAddress returns the address of the session bus: $DBUS_SESSION_BUS_ADDRESS or, if
+// not set, $XDG_RUNTIME_DIR/bus.
+func SessionBusAddress() string {
+	if address := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); address != "" {
+		return address
+	}
+
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return "unix:path=" + filepath.Join(runtimeDir, "bus")
+	}
+
+	return ""
+}
+
+// SessionBus connects to the session bus.
+func SessionBus(ctx context.Context) (*Conn, error) {
+	address := SessionBusAddress()
+	if address == "" {
+		return nil, fmt.Errorf("dbus: session bus address is unknown")
+	}
+
+	return Dial(ctx, address)
+}
+
+// Dial connects to the bus at the address, authenticates, and registers with the bus.
+// Only unix:path and unix:abstract addresses are supported.
+func Dial(ctx context.Context, address string) (*Conn, error) {
+	var errs []error
+	for _, addr := range strings.Split(address, ";") {
+		socket, err := parseAddress(addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var dialer net.Dialer
+		netConn, err := dialer.DialContext(ctx, "unix", socket)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		conn, err := newConn(ctx, netConn)
+		if err != nil {
+			netConn.Close()
+			errs = append(errs, err)
+			continue
+		}
+
+		return conn, nil
+	}
+
+	return nil, fmt.Errorf("dbus: failed to connect to %s: %w", address, errors.Join(errs...))
+}
+
+func parseAddress(address string) (string, error) {
+	transport, params, found := strings.Cut(address, ":")
+	if !found || transport != "unix" {
+		return "", fmt.Errorf("unsupported address: %s", address)
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(param, "=")
+		value, err := unescapeAddressValue(value)
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case "path":
+			return value, nil
+		case "abstract":
+			return "@" + value, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported address: %s", address)
+}
+
+func unescapeAddressValue(value string) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			builder.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("invalid escape in address value: %s", value)
+		}
+		b, err := strconv.ParseUint(value[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in address value: %s", value)
+		}
+		builder.WriteByte(byte(b))
+		i += 2
+	}
+
+	return builder.String(), nil
+}
+
+// newConn authenticates on the connection and registers with the bus.
+func newConn(ctx context.Context, netConn net.Conn) (*Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		netConn.SetDeadline(deadline)
+	}
+
+	reader := bufio.NewReader(netConn)
+	unixFDs, err := authenticate(netConn, reader)
+	if err != nil {
+		return nil, err
+	}
+	netConn.SetDeadline(time.Time{})
+
+	c := &Conn{
+		conn:        netConn,
+		unixFDs:     unixFDs,
+		pending:     make(map[uint32]chan *Message),
+		subscribers: make(map[*subscriber]bool),
+		done:        make(chan struct{}),
+	}
+	go c.readLoop(reader)
+
+	body, err := c.Call(ctx, busName, busPath, busInterface, "Hello", "")
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("dbus: Hello failed: %w", err)
+	}
+	if len(body) == 1 {
+		c.UniqueName, _ = body[0].(string)
+	}
+
+	return c, nil
+}
+
+// authenticate performs the EXTERNAL authentication and negotiates passing of file
+// descriptors. It returns whether file descriptors can be passed.
+func authenticate(conn net.Conn, reader *bufio.Reader) (bool, error) {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	line, err := authCommand(conn, reader, "\x00AUTH EXTERNAL "+uid)
+	if err != nil {
+		return false, err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return false, fmt.Errorf("dbus: authentication rejected: %s", line)
+	}
+
+	line, err = authCommand(conn, reader, "NEGOTIATE_UNIX_FD")
+	if err != nil {
+		return false, err
+	}
+
+	_, err = conn.Write([]byte("BEGIN\r\n"))
+	if err != nil {
+		return false, fmt.Errorf("dbus: authentication failed: %w", err)
+	}
+
+	return line == "AGREE_UNIX_FD", nil
+}
+
+// authCommand sends the command of the authentication protocol and returns the response line.
+func authCommand(conn net.Conn, reader *bufio.Reader, command string) (string, error) {
+	_, err := conn.Write([]byte(command + "\r\n"))
+	if err != nil {
+		return "", fmt.Errorf("dbus: authentication failed: %w", err)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("dbus: authentication failed: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func (c *Conn) readLoop(reader *bufio.Reader) {
+	for {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			c.closeWithError(err)
+			return
+		}
+
+		switch msg.Type {
+		case TypeMethodReturn, TypeError:
+			c.lock.Lock()
+			reply := c.pending[msg.ReplySerial]
+			delete(c.pending, msg.ReplySerial)
+			c.lock.Unlock()
+			if reply != nil {
+				reply <- msg
+			}
+		case TypeSignal:
+			c.lock.Lock()
+			for sub := range c.subscribers {
+				select {
+				case sub.c <- msg:
+				default:
+					// Slow subscribers miss signals rather than blocking the connection
+				}
+			}
+			c.lock.Unlock()
+		}
+	}
+}
+
+// send sends the message and returns the serial that was used. If reply is not nil, it receives
+// the reply to the message.
+func (c *Conn) send(msg *Message, reply chan *Message) (uint32, error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.serial++
+	serial := c.serial
+	msg.Serial = serial
+	data, err := msg.Marshal()
+	if err != nil {
+		return 0, fmt.Errorf("dbus: %w", err)
+	}
+
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return 0, ErrClosed
+	}
+	if reply != nil {
+		c.pending[serial] = reply
+	}
+	c.lock.Unlock()
+
+	if len(msg.UnixFDs) > 0 {
+		if !c.unixFDs {
+			err = errUnixFDsUnsupported
+		} else {
+			err = writeWithFDs(c.conn, data, msg.UnixFDs)
+		}
+	} else {
+		_, err = c.conn.Write(data)
+	}
+	if err != nil {
+		c.lock.Lock()
+		delete(c.pending, serial)
+		c.lock.Unlock()
+		return 0, fmt.Errorf("dbus: failed to send message: %w", err)
+	}
+
+	return serial, nil
+}
+
+// Call calls the method and waits for the reply, of which the body is returned.
+// An error reply is returned as *Error.
+func (c *Conn) Call(
+	ctx context.Context,
+	destination string,
+	path ObjectPath,
+	iface string,
+	method string,
+	signature string,
+	args ...any,
+) ([]any, error) {
+	return c.CallMessage(ctx, &Message{
+		Path:        path,
+		Interface:   iface,
+		Member:      method,
+		Destination: destination,
+		Signature:   signature,
+		Body:        args,
+	})
+}
+
+// CallMessage sends the method call and waits for the reply like Call. Use it to set fields that
+// Call does not expose, such as UnixFDs.
+func (c *Conn) CallMessage(ctx context.Context, call *Message) ([]any, error) {
+	call.Type = TypeMethodCall
+	reply := make(chan *Message, 1)
+	serial, err := c.send(call, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-reply:
+		if msg.Type == TypeError {
+			dbusErr := &Error{Name: msg.ErrorName}
+			if len(msg.Body) > 0 {
+				dbusErr.Message, _ = msg.Body[0].(string)
+			}
+			return nil, dbusErr
+		}
+		return msg.Body, nil
+	case <-ctx.Done():
+		c.lock.Lock()
+		delete(c.pending, serial)
+		c.lock.Unlock()
+		return nil, ctx.Err()
+	case <-c.done:
+		return nil, c.closeErr()
+	}
+}
+
+// Subscribe returns a channel receiving all signals delivered to the connection. Use AddMatch
+// to receive signals that are not addressed to this connection. The returned function
+// unsubscribes.
+func (c *Conn) Subscribe() (<-chan *Message, func()) {
+	sub := &subscriber{c: make(chan *Message, 16)}
+	c.lock.Lock()
+	c.subscribers[sub] = true
+	c.lock.Unlock()
+
+	return sub.c, func() {
+		c.lock.Lock()
+		delete(c.subscribers, sub)
+		c.lock.Unlock()
+	}
+}
+
+// AddMatch asks the bus to deliver the messages matching the rule, e.g.
+// type='signal',interface='org.freedesktop.portal.Request'.
+func (c *Conn) AddMatch(ctx context.Context, rule string) error {
+	_, err := c.Call(ctx, busName, busPath, busInterface, "AddMatch", "s", rule)
+	return err
+}
+
+// RemoveMatch removes a rule added using AddMatch.
+func (c *Conn) RemoveMatch(ctx context.Context, rule string) error {
+	_, err := c.Call(ctx, busName, busPath, busInterface, "RemoveMatch", "s", rule)
+	return err
+}
+
+// Done is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.closeWithError(ErrClosed)
+	return nil
+}
+
+func (c *Conn) closeWithError(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+	c.err = err
+	c.conn.Close()
+	close(c.done)
+}
+
+func (c *Conn) closeErr() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if errors.Is(c.err, ErrClosed) {
+		return ErrClosed
+	}
+
+	return fmt.Errorf("%w: %w", ErrClosed, c.err)
+}
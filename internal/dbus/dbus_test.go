@@ -0,0 +1,180 @@
+package dbus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"github.com/MatthiasKunnen/xdg/internal/dbus/dbustest"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	msg := &dbus.Message{
+		Type:        dbus.TypeMethodCall,
+		Serial:      7,
+		Path:        "/org/example/App",
+		Interface:   "org.freedesktop.Application",
+		Member:      "Open",
+		Destination: "org.example.App",
+		Signature:   "asa{sv}(ybx)",
+		Body: []any{
+			[]string{"file:///tmp/a", "file:///tmp/b"},
+			map[string]dbus.Variant{
+				"activation-token": {Signature: "s", Value: "token"},
+				"count":            {Signature: "u", Value: uint32(3)},
+			},
+			[]any{byte(1), true, int64(-5)},
+		},
+	}
+
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	decoded, err := dbus.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+
+	expected := &dbus.Message{
+		Type:        dbus.TypeMethodCall,
+		Serial:      7,
+		Path:        "/org/example/App",
+		Interface:   "org.freedesktop.Application",
+		Member:      "Open",
+		Destination: "org.example.App",
+		Signature:   "asa{sv}(ybx)",
+		Body: []any{
+			[]any{"file:///tmp/a", "file:///tmp/b"},
+			map[string]any{
+				"activation-token": dbus.Variant{Signature: "s", Value: "token"},
+				"count":            dbus.Variant{Signature: "u", Value: uint32(3)},
+			},
+			[]any{byte(1), true, int64(-5)},
+		},
+	}
+	if diff := cmp.Diff(expected, decoded); diff != "" {
+		t.Errorf("ReadMessage mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestMarshalSignatureMismatch(t *testing.T) {
+	msg := &dbus.Message{Type: dbus.TypeMethodCall, Signature: "s", Body: []any{uint32(1)}}
+	_, err := msg.Marshal()
+	if err == nil {
+		t.Errorf("Marshal of uint32 as string succeeded, expected error")
+	}
+}
+
+func TestSessionBusAddress(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	address := dbus.SessionBusAddress()
+	if address != "unix:path=/run/user/1000/bus" {
+		t.Errorf("SessionBusAddress() = %s, expected: unix:path=/run/user/1000/bus", address)
+	}
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:abstract=/tmp/dbus-x")
+	address = dbus.SessionBusAddress()
+	if address != "unix:abstract=/tmp/dbus-x" {
+		t.Errorf("SessionBusAddress() = %s, expected: unix:abstract=/tmp/dbus-x", address)
+	}
+}
+
+func TestCall(t *testing.T) {
+	bus := dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		if call.Member == "Fail" {
+			return "", nil, &dbus.Error{Name: "org.example.Error.Failed", Message: "it failed"}
+		}
+		return "s", []any{"hello " + call.Body[0].(string)}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := dbus.Dial(ctx, bus.Address)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if conn.UniqueName != ":1.0" {
+		t.Errorf("UniqueName = %s, expected: :1.0", conn.UniqueName)
+	}
+
+	body, err := conn.Call(
+		ctx,
+		"org.example.App",
+		"/org/example/App",
+		"org.example.App",
+		"Greet",
+		"s",
+		"world",
+	)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if diff := cmp.Diff([]any{"hello world"}, body); diff != "" {
+		t.Errorf("Call body mismatch (-expected +got):\n%s", diff)
+	}
+
+	_, err = conn.Call(ctx, "org.example.App", "/org/example/App", "org.example.App", "Fail", "")
+	var dbusErr *dbus.Error
+	if !errors.As(err, &dbusErr) {
+		t.Fatalf("Call error = %v, expected: *dbus.Error", err)
+	}
+	if dbusErr.Name != "org.example.Error.Failed" || dbusErr.Message != "it failed" {
+		t.Errorf("Call error = %v, expected: org.example.Error.Failed: it failed", dbusErr)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	bus := dbustest.NewBus(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := dbus.Dial(ctx, bus.Address)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	signals, unsubscribe := conn.Subscribe()
+	defer unsubscribe()
+
+	bus.Emit(&dbus.Message{
+		Path:      "/org/example/Request",
+		Interface: "org.example.Request",
+		Member:    "Response",
+		Signature: "u",
+		Body:      []any{uint32(0)},
+	})
+
+	select {
+	case signal := <-signals:
+		if signal.Member != "Response" || signal.Path != "/org/example/Request" {
+			t.Errorf("Signal = %s %s, expected: /org/example/Request Response", signal.Path, signal.Member)
+		}
+	case <-ctx.Done():
+		t.Fatalf("Signal was not received")
+	}
+}
+
+func TestCallAfterClose(t *testing.T) {
+	bus := dbustest.NewBus(t, nil)
+	ctx := context.Background()
+	conn, err := dbus.Dial(ctx, bus.Address)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	conn.Close()
+
+	_, err = conn.Call(ctx, "org.example.App", "/", "org.example.App", "Method", "")
+	if !errors.Is(err, dbus.ErrClosed) {
+		t.Errorf("Call after Close = %v, expected: %v", err, dbus.ErrClosed)
+	}
+}
@@ -0,0 +1,194 @@
+// Package dbustest provides a fake message bus for tests.
+package dbustest
+
+import (
+	"bufio"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Handler handles a method call that is not handled by the bus itself. It returns the signature
+// and body of the reply or an error reply.
+type Handler func(call *dbus.Message) (signature string, body []any, err *dbus.Error)
+
+// Bus is a fake session bus listening on a unix socket. Method calls to the bus itself, such as
+// Hello and AddMatch, are answered by the bus. Other calls are passed to the handler.
+type Bus struct {
+	// Address is the address of the bus, to be used with dbus.Dial or
+	// $DBUS_SESSION_BUS_ADDRESS.
+	Address string
+
+	handler  Handler
+	listener net.Listener
+
+	lock   sync.Mutex
+	calls  []*dbus.Message
+	conns  []*busConn
+	serial uint32
+}
+
+type busConn struct {
+	conn       net.Conn
+	writeLock  sync.Mutex
+	uniqueName string
+}
+
+// NewBus starts a fake bus which is stopped when the test finishes.
+func NewBus(t testing.TB, handler Handler) *Bus {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bus")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", path, err)
+	}
+
+	bus := &Bus{
+		Address:  "unix:path=" + path,
+		handler:  handler,
+		listener: listener,
+	}
+	t.Cleanup(bus.close)
+	go bus.accept()
+
+	return bus
+}
+
+// Calls returns the method calls that were passed to the handler.
+func (b *Bus) Calls() []*dbus.Message {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]*dbus.Message{}, b.calls...)
+}
+
+// Emit sends the signal to all connected clients.
+func (b *Bus) Emit(signal *dbus.Message) {
+	b.lock.Lock()
+	conns := append([]*busConn{}, b.conns...)
+	b.lock.Unlock()
+
+	signal.Type = dbus.TypeSignal
+	for _, c := range conns {
+		b.send(c, signal)
+	}
+}
+
+func (b *Bus) close() {
+	b.listener.Close()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, c := range b.conns {
+		c.conn.Close()
+	}
+}
+
+func (b *Bus) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.serve(conn)
+	}
+}
+
+func (b *Bus) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// The leading null byte of the credentials
+	_, err := reader.ReadByte()
+	if err != nil {
+		return
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "AUTH EXTERNAL") {
+			conn.Write([]byte("OK 0123456789abcdef0123456789abcdef\r\n"))
+		} else if line == "NEGOTIATE_UNIX_FD" {
+			conn.Write([]byte("AGREE_UNIX_FD\r\n"))
+		} else if line == "BEGIN" {
+			break
+		} else {
+			conn.Write([]byte("ERROR\r\n"))
+		}
+	}
+
+	b.lock.Lock()
+	c := &busConn{conn: conn, uniqueName: ":1." + strconv.Itoa(len(b.conns))}
+	b.conns = append(b.conns, c)
+	b.lock.Unlock()
+
+	for {
+		msg, err := dbus.ReadMessage(reader)
+		if err != nil {
+			return
+		}
+		if msg.Type != dbus.TypeMethodCall {
+			continue
+		}
+
+		msg.Sender = c.uniqueName
+		reply := &dbus.Message{
+			Type:        dbus.TypeMethodReturn,
+			ReplySerial: msg.Serial,
+			Destination: c.uniqueName,
+		}
+
+		switch {
+		case msg.Destination == "org.freedesktop.DBus" && msg.Member == "Hello":
+			reply.Signature = "s"
+			reply.Body = []any{c.uniqueName}
+		case msg.Destination == "org.freedesktop.DBus":
+			// AddMatch and RemoveMatch, all signals are delivered regardless
+		default:
+			b.lock.Lock()
+			b.calls = append(b.calls, msg)
+			b.lock.Unlock()
+
+			var dbusErr *dbus.Error
+			if b.handler == nil {
+				dbusErr = &dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+			} else {
+				reply.Signature, reply.Body, dbusErr = b.handler(msg)
+			}
+			if dbusErr != nil {
+				reply.Type = dbus.TypeError
+				reply.ErrorName = dbusErr.Name
+				reply.Signature = "s"
+				reply.Body = []any{dbusErr.Message}
+			}
+		}
+
+		if msg.Flags&dbus.FlagNoReplyExpected == 0 {
+			b.send(c, reply)
+		}
+	}
+}
+
+func (b *Bus) send(c *busConn, msg *dbus.Message) {
+	b.lock.Lock()
+	b.serial++
+	msg.Serial = b.serial
+	b.lock.Unlock()
+
+	data, err := msg.Marshal()
+	if err != nil {
+		return
+	}
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	c.conn.Write(data)
+}
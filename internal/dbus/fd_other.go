@@ -0,0 +1,11 @@
+//go:build !unix
+
+package dbus
+
+import (
+	"net"
+)
+
+func writeWithFDs(conn net.Conn, data []byte, fds []int) error {
+	return errUnixFDsUnsupported
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package dbus
+
+import (
+	"net"
+	"syscall"
+)
+
+// writeWithFDs writes the data and passes the file descriptors as ancillary data.
+func writeWithFDs(conn net.Conn, data []byte, fds []int) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errUnixFDsUnsupported
+	}
+
+	_, _, err := unixConn.WriteMsgUnix(data, syscall.UnixRights(fds...), nil)
+
+	return err
+}
@@ -0,0 +1,532 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ObjectPath is a D-Bus object path, type code o.
+type ObjectPath string
+
+// Signature is a D-Bus type signature, type code g.
+type Signature string
+
+// Variant is a value together with its type, type code v.
+type Variant struct {
+	Signature string
+	Value     any
+}
+
+// MakeVariant returns a variant of a value of a basic Go type.
+func MakeVariant(value any) (Variant, error) {
+	var signature string
+	switch value.(type) {
+	case byte:
+		signature = "y"
+	case bool:
+		signature = "b"
+	case int16:
+		signature = "n"
+	case uint16:
+		signature = "q"
+	case int32:
+		signature = "i"
+	case int:
+		value = int32(value.(int))
+		signature = "i"
+	case uint32:
+		signature = "u"
+	case int64:
+		signature = "x"
+	case uint64:
+		signature = "t"
+	case float64:
+		signature = "d"
+	case string:
+		signature = "s"
+	case ObjectPath:
+		signature = "o"
+	case Signature:
+		signature = "g"
+	case []string:
+		signature = "as"
+	case []byte:
+		signature = "ay"
+	case Variant:
+		signature = "v"
+	case map[string]Variant:
+		signature = "a{sv}"
+	default:
+		return Variant{}, fmt.Errorf("cannot determine D-Bus type of %T", value)
+	}
+
+	return Variant{Signature: signature, Value: value}, nil
+}
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// nextType splits the first complete type from the signature.
+func nextType(signature string) (string, string, error) {
+	if signature == "" {
+		return "", "", errInvalidSignature
+	}
+
+	switch signature[0] {
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v', 'h':
+		return signature[:1], signature[1:], nil
+	case 'a':
+		elem, rest, err := nextType(signature[1:])
+		if err != nil {
+			return "", "", err
+		}
+		return "a" + elem, rest, nil
+	case '(', '{':
+		closing := byte(')')
+		if signature[0] == '{' {
+			closing = '}'
+		}
+		rest := signature[1:]
+		for len(rest) > 0 && rest[0] != closing {
+			var err error
+			_, rest, err = nextType(rest)
+			if err != nil {
+				return "", "", err
+			}
+		}
+		if len(rest) == 0 {
+			return "", "", errInvalidSignature
+		}
+		end := len(signature) - len(rest) + 1
+		return signature[:end], signature[end:], nil
+	default:
+		return "", "", fmt.Errorf("%w: unknown type %c", errInvalidSignature, signature[0])
+	}
+}
+
+// splitTypes splits a signature into its complete types.
+func splitTypes(signature string) ([]string, error) {
+	var result []string
+	for signature != "" {
+		var t string
+		var err error
+		t, signature, err = nextType(signature)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func alignment(signature string) int {
+	switch signature[0] {
+	case 'y', 'g', 'v':
+		return 1
+	case 'n', 'q':
+		return 2
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	default:
+		return 4
+	}
+}
+
+// encoder marshals values in little endian. Offsets are relative to the start of the message,
+// which is 8-byte aligned, as is the body.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) encode(signature string, value any) error {
+	switch signature[0] {
+	case 'y':
+		v, ok := value.(byte)
+		if !ok {
+			return typeError(signature, value)
+		}
+		e.buf = append(e.buf, v)
+	case 'b':
+		v, ok := value.(bool)
+		if !ok {
+			return typeError(signature, value)
+		}
+		if v {
+			e.uint32(1)
+		} else {
+			e.uint32(0)
+		}
+	case 'n', 'q':
+		v := reflect.ValueOf(value)
+		if !v.CanInt() && !v.CanUint() {
+			return typeError(signature, value)
+		}
+		e.align(2)
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(toUint64(v)))
+	case 'i', 'u', 'h':
+		v := reflect.ValueOf(value)
+		if !v.CanInt() && !v.CanUint() {
+			return typeError(signature, value)
+		}
+		e.uint32(uint32(toUint64(v)))
+	case 'x', 't':
+		v := reflect.ValueOf(value)
+		if !v.CanInt() && !v.CanUint() {
+			return typeError(signature, value)
+		}
+		e.align(8)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, toUint64(v))
+	case 'd':
+		v, ok := value.(float64)
+		if !ok {
+			return typeError(signature, value)
+		}
+		e.align(8)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case 's', 'o':
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.String {
+			return typeError(signature, value)
+		}
+		e.uint32(uint32(v.Len()))
+		e.buf = append(e.buf, v.String()...)
+		e.buf = append(e.buf, 0)
+	case 'g':
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.String {
+			return typeError(signature, value)
+		}
+		e.buf = append(e.buf, byte(v.Len()))
+		e.buf = append(e.buf, v.String()...)
+		e.buf = append(e.buf, 0)
+	case 'v':
+		v, ok := value.(Variant)
+		if !ok {
+			var err error
+			v, err = MakeVariant(value)
+			if err != nil {
+				return err
+			}
+		}
+		if _, rest, err := nextType(v.Signature); err != nil || rest != "" {
+			return fmt.Errorf("variant: %w: %s", errInvalidSignature, v.Signature)
+		}
+		e.buf = append(e.buf, byte(len(v.Signature)))
+		e.buf = append(e.buf, v.Signature...)
+		e.buf = append(e.buf, 0)
+		return e.encode(v.Signature, v.Value)
+	case 'a':
+		return e.encodeArray(signature[1:], value)
+	case '(':
+		fields, err := splitTypes(signature[1 : len(signature)-1])
+		if err != nil {
+			return err
+		}
+		values, ok := value.([]any)
+		if !ok || len(values) != len(fields) {
+			return typeError(signature, value)
+		}
+		e.align(8)
+		for i, field := range fields {
+			err := e.encode(field, values[i])
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", errInvalidSignature, signature)
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeArray(elem string, value any) error {
+	e.uint32(0)
+	lengthOffset := len(e.buf) - 4
+	e.align(alignment(elem))
+	start := len(e.buf)
+
+	v := reflect.ValueOf(value)
+	switch {
+	case elem[0] == '{':
+		types, err := splitTypes(elem[1 : len(elem)-1])
+		if err != nil || len(types) != 2 {
+			return fmt.Errorf("%w: %s", errInvalidSignature, elem)
+		}
+		if v.Kind() != reflect.Map {
+			return typeError("a"+elem, value)
+		}
+
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+		for _, key := range keys {
+			e.align(8)
+			err := e.encode(types[0], key.Interface())
+			if err != nil {
+				return err
+			}
+			err = e.encode(types[1], v.MapIndex(key).Interface())
+			if err != nil {
+				return err
+			}
+		}
+	case v.Kind() == reflect.Slice:
+		for i := range v.Len() {
+			err := e.encode(elem, v.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+	case value == nil:
+	default:
+		return typeError("a"+elem, value)
+	}
+
+	binary.LittleEndian.PutUint32(e.buf[lengthOffset:], uint32(len(e.buf)-start))
+
+	return nil
+}
+
+func toUint64(v reflect.Value) uint64 {
+	if v.CanInt() {
+		return uint64(v.Int())
+	}
+
+	return v.Uint()
+}
+
+func typeError(signature string, value any) error {
+	return fmt.Errorf("cannot marshal %T as D-Bus type %s", value, signature)
+}
+
+// decoder unmarshals little endian values.
+type decoder struct {
+	buf []byte
+	pos int
+}
+
+var errShortBuffer = errors.New("message too short")
+
+func (d *decoder) align(n int) error {
+	for d.pos%n != 0 {
+		d.pos++
+	}
+	if d.pos > len(d.buf) {
+		return errShortBuffer
+	}
+
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if d.pos+n > len(d.buf) {
+		return nil, errShortBuffer
+	}
+	result := d.buf[d.pos : d.pos+n]
+	d.pos += n
+
+	return result, nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *decoder) signature() (string, error) {
+	length, err := d.read(1)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(length[0]) + 1)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b[:length[0]]), nil
+}
+
+// decode unmarshals a value of a single complete type.
+// Arrays are returned as []any, except for ay which is returned as []byte. Dictionaries with
+// string keys are returned as map[string]any, other dictionaries as map[any]any. Structs are
+// returned as []any.
+func (d *decoder) decode(signature string) (any, error) {
+	switch signature[0] {
+	case 'y':
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		v, err := d.uint32()
+		return v != 0, err
+	case 'n', 'q':
+		if err := d.align(2); err != nil {
+			return nil, err
+		}
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint16(b)
+		if signature[0] == 'n' {
+			return int16(v), nil
+		}
+		return v, nil
+	case 'i':
+		v, err := d.uint32()
+		return int32(v), err
+	case 'u', 'h':
+		return d.uint32()
+	case 'x', 't', 'd':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint64(b)
+		switch signature[0] {
+		case 'x':
+			return int64(v), nil
+		case 'd':
+			return math.Float64frombits(v), nil
+		}
+		return v, nil
+	case 's', 'o':
+		length, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.read(int(length) + 1)
+		if err != nil {
+			return nil, err
+		}
+		if signature[0] == 'o' {
+			return ObjectPath(b[:length]), nil
+		}
+		return string(b[:length]), nil
+	case 'g':
+		s, err := d.signature()
+		return Signature(s), err
+	case 'v':
+		s, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		if _, rest, err := nextType(s); err != nil || rest != "" {
+			return nil, fmt.Errorf("variant: %w: %s", errInvalidSignature, s)
+		}
+		value, err := d.decode(s)
+		return Variant{Signature: s, Value: value}, err
+	case 'a':
+		return d.decodeArray(signature[1:])
+	case '(':
+		fields, err := splitTypes(signature[1 : len(signature)-1])
+		if err != nil {
+			return nil, err
+		}
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		result := make([]any, 0, len(fields))
+		for _, field := range fields {
+			value, err := d.decode(field)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidSignature, signature)
+	}
+}
+
+func (d *decoder) decodeArray(elem string) (any, error) {
+	length, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.align(alignment(elem)); err != nil {
+		return nil, err
+	}
+	end := d.pos + int(length)
+	if end > len(d.buf) {
+		return nil, errShortBuffer
+	}
+
+	switch elem[0] {
+	case 'y':
+		b, err := d.read(int(length))
+		return append([]byte{}, b...), err
+	case '{':
+		types, err := splitTypes(elem[1 : len(elem)-1])
+		if err != nil || len(types) != 2 {
+			return nil, fmt.Errorf("%w: %s", errInvalidSignature, elem)
+		}
+
+		stringKeys := strings.ContainsRune("sog", rune(types[0][0]))
+		stringMap := make(map[string]any)
+		anyMap := make(map[any]any)
+		for d.pos < end {
+			if err := d.align(8); err != nil {
+				return nil, err
+			}
+			key, err := d.decode(types[0])
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode(types[1])
+			if err != nil {
+				return nil, err
+			}
+			if stringKeys {
+				stringMap[fmt.Sprint(key)] = value
+			} else {
+				anyMap[key] = value
+			}
+		}
+		if stringKeys {
+			return stringMap, nil
+		}
+		return anyMap, nil
+	default:
+		var result []any
+		for d.pos < end {
+			value, err := d.decode(elem)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,212 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Message types.
+const (
+	TypeMethodCall   byte = 1
+	TypeMethodReturn byte = 2
+	TypeError        byte = 3
+	TypeSignal       byte = 4
+)
+
+// Message flags.
+const (
+	FlagNoReplyExpected byte = 0x1
+)
+
+const (
+	fieldPath        byte = 1
+	fieldInterface   byte = 2
+	fieldMember      byte = 3
+	fieldErrorName   byte = 4
+	fieldReplySerial byte = 5
+	fieldDestination byte = 6
+	fieldSender      byte = 7
+	fieldSignature   byte = 8
+	fieldUnixFDs     byte = 9
+)
+
+// maxMessageSize is the maximum size of a message as defined by the specification.
+const maxMessageSize = 128 * 1024 * 1024
+
+// Message is a D-Bus message.
+type Message struct {
+	Type        byte
+	Flags       byte
+	Serial      uint32
+	ReplySerial uint32
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	Destination string
+	Sender      string
+	Signature   string
+	Body        []any
+
+	// UnixFDs are the file descriptors sent along with the message. Values of type h in the
+	// body are indices into this slice. Received file descriptors are not supported.
+	UnixFDs []int
+}
+
+// Marshal encodes the message in little endian byte order.
+func (m *Message) Marshal() ([]byte, error) {
+	var body encoder
+	types, err := splitTypes(m.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) != len(m.Body) {
+		return nil, fmt.Errorf("signature %s does not match %d body values", m.Signature, len(m.Body))
+	}
+	for i, t := range types {
+		err := body.encode(t, m.Body[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fields []any
+	addField := func(code byte, signature string, value any) {
+		fields = append(fields, []any{code, Variant{Signature: signature, Value: value}})
+	}
+	if m.Path != "" {
+		addField(fieldPath, "o", m.Path)
+	}
+	if m.Interface != "" {
+		addField(fieldInterface, "s", m.Interface)
+	}
+	if m.Member != "" {
+		addField(fieldMember, "s", m.Member)
+	}
+	if m.ErrorName != "" {
+		addField(fieldErrorName, "s", m.ErrorName)
+	}
+	if m.ReplySerial != 0 {
+		addField(fieldReplySerial, "u", m.ReplySerial)
+	}
+	if m.Destination != "" {
+		addField(fieldDestination, "s", m.Destination)
+	}
+	if m.Sender != "" {
+		addField(fieldSender, "s", m.Sender)
+	}
+	if m.Signature != "" {
+		addField(fieldSignature, "g", Signature(m.Signature))
+	}
+	if len(m.UnixFDs) > 0 {
+		addField(fieldUnixFDs, "u", uint32(len(m.UnixFDs)))
+	}
+
+	header := encoder{buf: []byte{'l', m.Type, m.Flags, 1}}
+	header.uint32(uint32(len(body.buf)))
+	header.uint32(m.Serial)
+	err = header.encode("a(yv)", fields)
+	if err != nil {
+		return nil, err
+	}
+	header.align(8)
+
+	return append(header.buf, body.buf...), nil
+}
+
+// ReadMessage reads and decodes a single message.
+func ReadMessage(r io.Reader) (*Message, error) {
+	fixed := make([]byte, 16)
+	_, err := io.ReadFull(r, fixed)
+	if err != nil {
+		return nil, err
+	}
+
+	if fixed[0] != 'l' && fixed[0] != 'B' {
+		return nil, fmt.Errorf("invalid endianness %c", fixed[0])
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if fixed[0] == 'B' {
+		order = binary.BigEndian
+	}
+
+	bodyLength := order.Uint32(fixed[4:])
+	fieldsLength := order.Uint32(fixed[12:])
+	headerLength := 16 + int(fieldsLength)
+	headerLength += (8 - headerLength%8) % 8
+	total := headerLength + int(bodyLength)
+	if total > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum size", total)
+	}
+
+	buf := make([]byte, total)
+	copy(buf, fixed)
+	_, err = io.ReadFull(r, buf[16:])
+	if err != nil {
+		return nil, err
+	}
+
+	if order == binary.BigEndian {
+		return nil, fmt.Errorf("big endian messages are not supported")
+	}
+
+	msg := &Message{
+		Type:   buf[1],
+		Flags:  buf[2],
+		Serial: order.Uint32(buf[8:]),
+	}
+
+	d := decoder{buf: buf[:headerLength], pos: 12}
+	fields, err := d.decode("a(yv)")
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	for _, field := range fields.([]any) {
+		parts := field.([]any)
+		value := parts[1].(Variant).Value
+		var ok bool
+		switch parts[0].(byte) {
+		case fieldPath:
+			msg.Path, ok = value.(ObjectPath)
+		case fieldInterface:
+			msg.Interface, ok = value.(string)
+		case fieldMember:
+			msg.Member, ok = value.(string)
+		case fieldErrorName:
+			msg.ErrorName, ok = value.(string)
+		case fieldReplySerial:
+			msg.ReplySerial, ok = value.(uint32)
+		case fieldDestination:
+			msg.Destination, ok = value.(string)
+		case fieldSender:
+			msg.Sender, ok = value.(string)
+		case fieldSignature:
+			var signature Signature
+			signature, ok = value.(Signature)
+			msg.Signature = string(signature)
+		default:
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid type of header field %d", parts[0])
+		}
+	}
+
+	types, err := splitTypes(msg.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	body := decoder{buf: buf[headerLength:]}
+	for _, t := range types {
+		value, err := body.decode(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body: %w", err)
+		}
+		msg.Body = append(msg.Body, value)
+	}
+
+	return msg, nil
+}
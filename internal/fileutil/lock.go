@@ -0,0 +1,39 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock acquires an exclusive advisory lock on the file at lockPath, creating it if needed,
+// and blocks until the lock is acquired. The returned function releases the lock.
+// Missing parent directories are created with 0o700 permissions as per the basedir spec.
+func Lock(lockPath string) (func() error, error) {
+	dir := filepath.Dir(lockPath)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("Lock: failed to create directory %s: %w", dir, err)
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Lock: %w", err)
+	}
+
+	err = lockFile(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Lock: failed to lock %s: %w", lockPath, err)
+	}
+
+	return func() error {
+		unlockErr := unlockFile(file)
+		closeErr := file.Close()
+		if unlockErr != nil {
+			return unlockErr
+		}
+
+		return closeErr
+	}, nil
+}
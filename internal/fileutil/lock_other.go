@@ -0,0 +1,21 @@
+//go:build !unix
+
+package fileutil
+
+import (
+	"os"
+	"sync"
+)
+
+// Advisory file locks are not supported, only writers within this process are serialized.
+var processLock sync.Mutex
+
+func lockFile(file *os.File) error {
+	processLock.Lock()
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	processLock.Unlock()
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package keyfile edits files in the desktop entry key file format, such as .desktop files and
+// mimeapps.list, while preserving comments, ordering, and unrelated keys.
+package keyfile
+
+import (
+	"strings"
+)
+
+// SetKey returns content with key in group set to value. The value must already be escaped.
+// An existing key is replaced in place, a new key is added after the last key of the group. If
+// the group does not exist, it is appended.
+func SetKey(content []byte, group string, key string, value string) []byte {
+	lines := splitLines(content)
+	newLine := key + "=" + value
+
+	start, end := findGroup(lines, group)
+	if start == -1 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+group+"]", newLine)
+		return joinLines(lines)
+	}
+
+	insertAt := start + 1
+	for i := start + 1; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		k, _, found := strings.Cut(trimmed, "=")
+		if found && strings.TrimSpace(k) == key {
+			lines[i] = newLine
+			return joinLines(lines)
+		}
+		insertAt = i + 1
+	}
+
+	lines = append(lines[:insertAt], append([]string{newLine}, lines[insertAt:]...)...)
+	return joinLines(lines)
+}
+
+// RemoveKey returns content without key in group.
+func RemoveKey(content []byte, group string, key string) []byte {
+	lines := splitLines(content)
+	start, end := findGroup(lines, group)
+	if start == -1 {
+		return content
+	}
+
+	for i := start + 1; i < end; i++ {
+		k, _, found := strings.Cut(strings.TrimSpace(lines[i]), "=")
+		if found && strings.TrimSpace(k) == key {
+			return joinLines(append(lines[:i], lines[i+1:]...))
+		}
+	}
+
+	return content
+}
+
+// findGroup returns the index of the group header and the index of the next group header or
+// the number of lines. -1 is returned if the group does not exist.
+func findGroup(lines []string, group string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "[") {
+			continue
+		}
+
+		if start != -1 {
+			return start, i
+		}
+		if trimmed == "["+group+"]" {
+			start = i
+		}
+	}
+
+	return start, len(lines)
+}
+
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,51 @@
+package keyfile
+
+import (
+	"testing"
+)
+
+func TestSetKey(t *testing.T) {
+	content := "# comment\n[Default Applications]\ntext/plain=a.desktop;\n\n[Added Associations]\n# keep\nimage/png=b.desktop;\n"
+
+	tests := []struct {
+		group    string
+		key      string
+		value    string
+		expected string
+	}{
+		{
+			"Default Applications", "text/plain", "c.desktop;",
+			"# comment\n[Default Applications]\ntext/plain=c.desktop;\n\n[Added Associations]\n# keep\nimage/png=b.desktop;\n",
+		},
+		{
+			"Default Applications", "image/png", "b.desktop;",
+			"# comment\n[Default Applications]\ntext/plain=a.desktop;\nimage/png=b.desktop;\n\n[Added Associations]\n# keep\nimage/png=b.desktop;\n",
+		},
+		{
+			"Removed Associations", "image/png", "c.desktop;",
+			content + "\n[Removed Associations]\nimage/png=c.desktop;\n",
+		},
+	}
+
+	for _, test := range tests {
+		actual := string(SetKey([]byte(content), test.group, test.key, test.value))
+		if actual != test.expected {
+			t.Errorf("SetKey(%s, %s) = %q, expected: %q", test.group, test.key, actual, test.expected)
+		}
+	}
+
+	actual := string(SetKey(nil, "Desktop Entry", "Hidden", "true"))
+	if actual != "[Desktop Entry]\nHidden=true\n" {
+		t.Errorf("SetKey on empty content = %q", actual)
+	}
+}
+
+func TestRemoveKey(t *testing.T) {
+	content := "[Desktop Entry]\nName=A\nHidden=true\n[Other]\nHidden=true\n"
+	expected := "[Desktop Entry]\nName=A\n[Other]\nHidden=true\n"
+
+	actual := string(RemoveKey([]byte(content), "Desktop Entry", "Hidden"))
+	if actual != expected {
+		t.Errorf("RemoveKey = %q, expected: %q", actual, expected)
+	}
+}
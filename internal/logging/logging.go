@@ -0,0 +1,52 @@
+// Package logging holds the logger shared by the packages of the module. It discards all records
+// until a logger is set using xdg.SetLogger.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Attribute keys used consistently across the module.
+const (
+	// Path is the key of a file or directory path.
+	Path = "path"
+
+	// DesktopId is the key of a desktop ID, e.g. firefox.desktop.
+	DesktopId = "desktop_id"
+
+	// MimeType is the key of a MIME type, e.g. text/plain.
+	MimeType = "mime"
+
+	// URI is the key of a URI, e.g. https://example.com.
+	URI = "uri"
+
+	// Error is the key of the error that caused the record.
+	Error = "error"
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+var discard = slog.New(discardHandler{})
+
+// Logger returns the logger of the module.
+func Logger() *slog.Logger {
+	if l := logger.Load(); l != nil {
+		return l
+	}
+
+	return discard
+}
+
+// SetLogger sets the logger of the module. If l is nil, records are discarded.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
@@ -0,0 +1,68 @@
+// Package testenv sets up isolated XDG environments for the tests of the packages of this
+// module. Tests outside the module, and of packages not imported by the xdg package, can use
+// xdgtest instead.
+package testenv
+
+import (
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Setup points $HOME, the XDG base directory variables, and basedir to a new temporary directory
+// and returns its path. Relative to it, the directories are .config, .local/share, .cache,
+// .local/state, etc/xdg for $XDG_CONFIG_DIRS, and usr/share for $XDG_DATA_DIRS. None of them are
+// created. $XDG_RUNTIME_DIR is a separate temporary directory.
+// $XDG_CURRENT_DESKTOP, $XDG_MENU_PREFIX, $FLATPAK_ID, and $SNAP_NAME are cleared. Everything is
+// restored when the test ends.
+func Setup(t testing.TB) string {
+	t.Helper()
+
+	home := t.TempDir()
+	runtimeDir := t.TempDir()
+
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(home, "etc/xdg"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local/share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "usr/share"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local/state"))
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("XDG_CURRENT_DESKTOP", "")
+	t.Setenv("XDG_MENU_PREFIX", "")
+	t.Setenv("FLATPAK_ID", "")
+	t.Setenv("SNAP_NAME", "")
+	basedir.Reinit()
+	t.Cleanup(basedir.Reinit)
+
+	return home
+}
+
+// WriteFile writes content to the file at path, creating its parent directories.
+func WriteFile(t testing.TB, path string, content string) {
+	t.Helper()
+	writeFile(t, path, content, 0600)
+}
+
+// WriteExecutable is like WriteFile but makes the file executable, e.g. for scripts that stand
+// in for applications.
+func WriteExecutable(t testing.TB, path string, content string) {
+	t.Helper()
+	writeFile(t, path, content, 0700)
+}
+
+func writeFile(t testing.TB, path string, content string, perm os.FileMode) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, []byte(content), perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
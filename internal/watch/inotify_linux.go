@@ -0,0 +1,83 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+func newWatcher(dirs []string) (*Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("watch: failed to initialize inotify: %w", err)
+	}
+
+	wds := make(map[int32]string, len(dirs))
+	for _, dir := range dirs {
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			syscall.Close(fd)
+			return nil, fmt.Errorf("watch: failed to watch %s: %w", dir, err)
+		}
+		wds[int32(wd)] = dir
+	}
+
+	// A non-blocking file descriptor is registered with the runtime poller, this allows Close
+	// to interrupt a pending Read.
+	file := os.NewFile(uintptr(fd), "inotify")
+	c := make(chan string)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(c)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				// The file is closed by Close
+				return
+			}
+
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameStart := offset + syscall.SizeofInotifyEvent
+				nameEnd := nameStart + int(event.Len)
+				offset = nameEnd
+				if nameEnd > n {
+					break
+				}
+
+				dir, found := wds[event.Wd]
+				if !found {
+					continue
+				}
+
+				name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+				select {
+				case c <- filepath.Join(dir, name):
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return &Watcher{
+		C: c,
+		close: func() error {
+			// Closing done releases a pending send, closing the file a pending Read
+			close(done)
+			return file.Close()
+		},
+	}, nil
+}
@@ -0,0 +1,103 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PollInterval is the interval at which directories are checked for changes on platforms
+// without native change notifications.
+const PollInterval = 2 * time.Second
+
+type entryState struct {
+	size    int64
+	modTime time.Time
+	mode    os.FileMode
+}
+
+// newPollingWatcher checks the directories for changes every interval.
+func newPollingWatcher(dirs []string, interval time.Duration) *Watcher {
+	c := make(chan string)
+	done := make(chan struct{})
+
+	states := make([]map[string]entryState, len(dirs))
+	for i, dir := range dirs {
+		states[i] = readStates(dir)
+	}
+
+	go func() {
+		defer close(c)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			for i, dir := range dirs {
+				current := readStates(dir)
+				for _, name := range diffStates(states[i], current) {
+					select {
+					case c <- filepath.Join(dir, name):
+					case <-done:
+						return
+					}
+				}
+				states[i] = current
+			}
+		}
+	}()
+
+	return &Watcher{
+		C: c,
+		close: func() error {
+			close(done)
+			return nil
+		},
+	}
+}
+
+func readStates(dir string) map[string]entryState {
+	result := make(map[string]entryState)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		result[entry.Name()] = entryState{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			mode:    info.Mode(),
+		}
+	}
+
+	return result
+}
+
+// diffStates returns the names of the entries that were added, removed, or changed.
+func diffStates(old map[string]entryState, current map[string]entryState) []string {
+	var result []string
+	for name, state := range current {
+		if oldState, found := old[name]; !found || oldState != state {
+			result = append(result, name)
+		}
+	}
+
+	for name := range old {
+		if _, found := current[name]; !found {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
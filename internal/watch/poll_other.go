@@ -0,0 +1,7 @@
+//go:build !linux
+
+package watch
+
+func newWatcher(dirs []string) (*Watcher, error) {
+	return newPollingWatcher(dirs, PollInterval), nil
+}
@@ -0,0 +1,32 @@
+// Package watch reports changes to the entries of directories. On Linux, inotify is used,
+// other platforms fall back to polling.
+package watch
+
+import (
+	"sync"
+)
+
+// Watcher reports the paths of entries that are created, removed, renamed, or modified in the
+// watched directories.
+type Watcher struct {
+	// C receives the path of every changed entry. It is closed when the watcher is closed.
+	C <-chan string
+
+	closeOnce sync.Once
+	close     func() error
+	closeErr  error
+}
+
+// New starts watching the given directories. Directories are not watched recursively.
+func New(dirs []string) (*Watcher, error) {
+	return newWatcher(dirs)
+}
+
+// Close stops the watcher.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		w.closeErr = w.close()
+	})
+
+	return w.closeErr
+}
@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func expectEvent(t *testing.T, w *Watcher, expected string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case path, ok := <-w.C:
+			if !ok {
+				t.Fatal("watcher closed")
+			}
+			if path == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event received for %s", expected)
+		}
+	}
+}
+
+func testWatcher(t *testing.T, w *Watcher, dir string) {
+	path := filepath.Join(dir, "file")
+	err := os.WriteFile(path, []byte("a"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, path)
+
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, path)
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range w.C {
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testWatcher(t, w, dir)
+}
+
+func TestPollingWatcher(t *testing.T) {
+	dir := t.TempDir()
+	testWatcher(t, newPollingWatcher([]string{dir}, 10*time.Millisecond), dir)
+}
+
+func testCloseWithPendingEvents(t *testing.T, w *Watcher, dir string) {
+	// Nothing reads the events, the watcher blocks on sending the first one
+	for i := range 10 {
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d", i)), []byte("a"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A watcher that is stuck sending would deliver the pending event instead of closing C
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case path, ok := <-w.C:
+		if ok {
+			t.Errorf("received %s after Close, expected C to be closed", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("C was not closed after Close with pending events")
+	}
+}
+
+func TestWatcherCloseWithPendingEvents(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCloseWithPendingEvents(t, w, dir)
+}
+
+func TestPollingWatcherCloseWithPendingEvents(t *testing.T) {
+	dir := t.TempDir()
+	testCloseWithPendingEvents(t, newPollingWatcher([]string{dir}, 10*time.Millisecond), dir)
+}
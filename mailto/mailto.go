@@ -0,0 +1,231 @@
+// Package mailto builds and parses mailto: URIs as defined by [RFC 6068] and opens them with the
+// preferred mail client, like xdg-email.
+//
+// [RFC 6068]: https://www.rfc-editor.org/rfc/rfc6068
+package mailto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"github.com/MatthiasKunnen/xdg/open"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const scheme = "mailto:"
+
+// ErrNotMailto is returned when parsing a URI that does not use the mailto scheme.
+var ErrNotMailto = errors.New("not a mailto URI")
+
+// Message is the content of a mailto URI.
+type Message struct {
+	To  []string
+	Cc  []string
+	Bcc []string
+
+	Subject string
+
+	// Body of the message. Line breaks are written as CRLF in the URI and read as LF.
+	Body string
+
+	// Attach are the paths of files to attach. Absolute paths are written as file URIs using
+	// the attach header, which is supported by most mail clients but not standardized.
+	Attach []string
+
+	// Headers are other header fields, e.g. In-Reply-To, keyed by lowercase name.
+	Headers map[string]string
+}
+
+// URI returns the mailto URI of the message. The recipients of To are written in the path, the
+// other fields as header fields, all percent-encoded.
+func (m Message) URI() string {
+	var b strings.Builder
+	b.WriteString(scheme)
+
+	b.WriteString(joinAddresses(m.To))
+
+	var fields []string
+	addField := func(name string, value string) {
+		fields = append(fields, name+"="+escape(value, ""))
+	}
+
+	if len(m.Cc) > 0 {
+		fields = append(fields, "cc="+joinAddresses(m.Cc))
+	}
+	if len(m.Bcc) > 0 {
+		fields = append(fields, "bcc="+joinAddresses(m.Bcc))
+	}
+	if m.Subject != "" {
+		addField("subject", m.Subject)
+	}
+	if m.Body != "" {
+		body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+		addField("body", strings.ReplaceAll(body, "\n", "\r\n"))
+	}
+	for _, path := range m.Attach {
+		if filepath.IsAbs(path) {
+			if uri, err := fileuri.FromPath(path); err == nil {
+				path = uri
+			}
+		}
+		addField("attach", path)
+	}
+
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		addField(escape(strings.ToLower(name), ""), m.Headers[name])
+	}
+
+	if len(fields) > 0 {
+		b.WriteByte('?')
+		b.WriteString(strings.Join(fields, "&"))
+	}
+
+	return b.String()
+}
+
+// Parse parses the mailto URI. Recipients of the to header field are appended to those of the
+// path. Attachments given as local file URIs are converted to paths.
+func Parse(uri string) (Message, error) {
+	if len(uri) < len(scheme) || !strings.EqualFold(uri[:len(scheme)], scheme) {
+		return Message{}, fmt.Errorf("Parse: %w: %s", ErrNotMailto, uri)
+	}
+
+	path, query, _ := strings.Cut(uri[len(scheme):], "?")
+	var m Message
+
+	to, err := splitAddresses(path)
+	if err != nil {
+		return Message{}, fmt.Errorf("Parse: %w", err)
+	}
+	m.To = to
+
+	for _, field := range strings.Split(query, "&") {
+		if field == "" {
+			continue
+		}
+
+		rawName, rawValue, _ := strings.Cut(field, "=")
+		name, err := url.PathUnescape(rawName)
+		if err != nil {
+			return Message{}, fmt.Errorf("Parse: %w", err)
+		}
+		name = strings.ToLower(name)
+
+		switch name {
+		case "to", "cc", "bcc":
+			addresses, err := splitAddresses(rawValue)
+			if err != nil {
+				return Message{}, fmt.Errorf("Parse: %w", err)
+			}
+
+			switch name {
+			case "to":
+				m.To = append(m.To, addresses...)
+			case "cc":
+				m.Cc = append(m.Cc, addresses...)
+			case "bcc":
+				m.Bcc = append(m.Bcc, addresses...)
+			}
+			continue
+		}
+
+		value, err := url.PathUnescape(rawValue)
+		if err != nil {
+			return Message{}, fmt.Errorf("Parse: %w", err)
+		}
+
+		switch name {
+		case "subject":
+			m.Subject = value
+		case "body":
+			m.Body = strings.ReplaceAll(value, "\r\n", "\n")
+		case "attach", "attachment":
+			if path, err := fileuri.ToPath(value); err == nil {
+				value = path
+			}
+			m.Attach = append(m.Attach, value)
+		default:
+			if m.Headers == nil {
+				m.Headers = make(map[string]string)
+			}
+			m.Headers[name] = value
+		}
+	}
+
+	return m, nil
+}
+
+// Send opens the mailto URI of the message with the preferred mail client, the handler of
+// x-scheme-handler/mailto. See open.Open.
+func Send(ctx context.Context, m Message, opts open.Options) error {
+	err := open.Open(ctx, m.URI(), opts)
+	if err != nil {
+		return fmt.Errorf("Send: %w", err)
+	}
+
+	return nil
+}
+
+// joinAddresses encodes and joins the addresses using commas.
+func joinAddresses(addresses []string) string {
+	escaped := make([]string, len(addresses))
+	for i, address := range addresses {
+		escaped[i] = escape(address, addressChars)
+	}
+
+	return strings.Join(escaped, ",")
+}
+
+// splitAddresses splits and decodes a comma separated list of addresses.
+func splitAddresses(s string) ([]string, error) {
+	var result []string
+	for _, raw := range strings.Split(s, ",") {
+		address, err := url.PathUnescape(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if address = strings.TrimSpace(address); address != "" {
+			result = append(result, address)
+		}
+	}
+
+	return result, nil
+}
+
+// addressChars are the characters, besides the unreserved ones, that are not escaped in
+// addresses. Others, such as the comma separating addresses, are.
+const addressChars = "!$'()*+;:@"
+
+// escape percent-encodes all bytes except the unreserved characters of RFC 3986 and the allowed
+// bytes.
+func escape(s string, allowed string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var builder strings.Builder
+	builder.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			strings.IndexByte("-._~", c) >= 0, strings.IndexByte(allowed, c) >= 0:
+			builder.WriteByte(c)
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(hexDigits[c>>4])
+			builder.WriteByte(hexDigits[c&0xF])
+		}
+	}
+
+	return builder.String()
+}
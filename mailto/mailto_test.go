@@ -0,0 +1,100 @@
+package mailto
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestURI(t *testing.T) {
+	tests := []struct {
+		message  Message
+		expected string
+	}{
+		{Message{To: []string{"someone@example.com"}}, "mailto:someone@example.com"},
+		{
+			Message{
+				To:      []string{"a@example.com", "b+tag@example.com"},
+				Cc:      []string{"c@example.com"},
+				Subject: "Hello & welcome?",
+				Body:    "Line 1\nLine 2 100%",
+			},
+			"mailto:a@example.com,b+tag@example.com?cc=c@example.com" +
+				"&subject=Hello%20%26%20welcome%3F&body=Line%201%0D%0ALine%202%20100%25",
+		},
+		{
+			Message{Bcc: []string{"x@example.com"}, Attach: []string{"/tmp/a b.txt"}},
+			"mailto:?bcc=x@example.com&attach=file%3A%2F%2F%2Ftmp%2Fa%2520b.txt",
+		},
+		{
+			Message{
+				To:      []string{"\"a,b\"@example.com"},
+				Headers: map[string]string{"In-Reply-To": "<1@x>"},
+			},
+			"mailto:%22a%2Cb%22@example.com?in-reply-to=%3C1%40x%3E",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := test.message.URI(); actual != test.expected {
+			t.Errorf("URI() = %s, expected: %s", actual, test.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected Message
+	}{
+		{
+			"MAILTO:a@example.com,b@example.com?to=c@example.com&CC=d@example.com" +
+				"&subject=Hi%20there&body=1%0D%0A2+3",
+			Message{
+				To:      []string{"a@example.com", "b@example.com", "c@example.com"},
+				Cc:      []string{"d@example.com"},
+				Subject: "Hi there",
+				Body:    "1\n2+3",
+			},
+		},
+		{
+			"mailto:?attach=file:///tmp/a%20b.txt&attachment=relative.txt&in-reply-to=%3C1@x%3E",
+			Message{
+				Attach:  []string{"/tmp/a b.txt", "relative.txt"},
+				Headers: map[string]string{"in-reply-to": "<1@x>"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := Parse(test.uri)
+		if err != nil {
+			t.Errorf("Parse(%s) failed: %v", test.uri, err)
+			continue
+		}
+		if diff := cmp.Diff(test.expected, actual); diff != "" {
+			t.Errorf("Parse(%s) mismatch (-expected +got):\n%s", test.uri, diff)
+		}
+	}
+
+	if _, err := Parse("https://example.com"); err == nil {
+		t.Errorf("expected an error for a non-mailto URI")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	message := Message{
+		To:      []string{"\"a,b\"@example.com"},
+		Cc:      []string{"c@example.com", "d@example.com"},
+		Subject: "100% = sure?",
+		Body:    "Hello,\n\nBye & thanks",
+		Attach:  []string{"/home/user/résumé.pdf"},
+	}
+
+	parsed, err := Parse(message.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(message, parsed); diff != "" {
+		t.Errorf("round trip mismatch (-expected +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,43 @@
+package menu
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"path/filepath"
+	"strings"
+)
+
+// WriteDirectoryFile writes a .directory file describing a menu to the directory returned by
+// GetDirectoryFilesDir and returns its path.
+// A file with the same name in a system directory is overridden by the user-level file.
+// Example of name: development.directory
+func WriteDirectoryFile(name string, title string, icon string) (string, error) {
+	if !strings.HasSuffix(name, ".directory") {
+		name += ".directory"
+	}
+
+	if strings.ContainsRune(name, filepath.Separator) {
+		return "", fmt.Errorf("WriteDirectoryFile: name must not contain a path separator: %s", name)
+	}
+
+	if title == "" {
+		return "", fmt.Errorf("WriteDirectoryFile: title must not be empty")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("[Desktop Entry]\n")
+	builder.WriteString("Type=Directory\n")
+	builder.WriteString("Name=" + desktop.EscapeString(title) + "\n")
+	if icon != "" {
+		builder.WriteString("Icon=" + desktop.EscapeString(icon) + "\n")
+	}
+
+	path := filepath.Join(GetDirectoryFilesDir(), name)
+	err := fileutil.WriteFileAtomic(path, []byte(builder.String()), 0644)
+	if err != nil {
+		return "", fmt.Errorf("WriteDirectoryFile: %w", err)
+	}
+
+	return path, nil
+}
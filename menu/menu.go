@@ -0,0 +1,100 @@
+// Package menu implements writing user-level menu overrides as described by the
+// [Desktop Menu Specification].
+//
+// Overrides are written as menu fragments to $XDG_CONFIG_HOME/menus/applications-merged.
+// Menu implementations merge every file in that directory into the applications menu, which
+// allows editing the menu without touching the system-provided .menu files.
+//
+// [Desktop Menu Specification]: https://specifications.freedesktop.org/menu-spec/1.1/
+package menu
+
+import (
+	"encoding/xml"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+)
+
+// RootMenuName is the name of the root menu of the applications menu.
+const RootMenuName = "Applications"
+
+const menuDoctype = `<!DOCTYPE Menu PUBLIC "-//freedesktop//DTD Menu 1.0//EN"` +
+	` "http://www.freedesktop.org/standards/menu-spec/1.0/menu.dtd">` + "\n"
+
+// Menu represents a <Menu> element of a menu file.
+// Only the elements that are needed to express overrides are supported.
+type Menu struct {
+	XMLName xml.Name `xml:"Menu"`
+
+	// Name of the menu. Menus with the same name at the same level are merged.
+	Name string `xml:"Name"`
+
+	// Directory is the name of a .directory file, relative to the desktop-directories
+	// directories, that describes the menu.
+	Directory string `xml:"Directory,omitempty"`
+
+	// Include holds the desktop entries that must be included in the menu.
+	Include *Rules `xml:"Include,omitempty"`
+
+	// Exclude holds the desktop entries that must be excluded from the menu.
+	Exclude *Rules `xml:"Exclude,omitempty"`
+
+	// Menus contains the submenus.
+	Menus []Menu `xml:"Menu,omitempty"`
+}
+
+// Rules represents the matching rules of an <Include> or <Exclude> element.
+type Rules struct {
+	// Filenames contains desktop IDs, e.g. vim.desktop.
+	Filenames []string `xml:"Filename,omitempty"`
+
+	// Categories contains categories, e.g. Graphics.
+	Categories []string `xml:"Category,omitempty"`
+}
+
+// GetMergedDir returns the directory in which user-level menu fragments are stored.
+// $XDG_MENU_PREFIX is taken into account.
+func GetMergedDir() string {
+	return filepath.Join(
+		basedir.ConfigHome,
+		"menus",
+		os.Getenv("XDG_MENU_PREFIX")+"applications-merged",
+	)
+}
+
+// GetDirectoryFilesDir returns the user directory in which .directory files are stored.
+func GetDirectoryFilesDir() string {
+	return filepath.Join(basedir.DataHome, "desktop-directories")
+}
+
+// Encode returns the menu as an XML document including the menu DOCTYPE.
+func (m Menu) Encode() ([]byte, error) {
+	body, err := xml.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(menuDoctype)+len(body)+1)
+	result = append(result, menuDoctype...)
+	result = append(result, body...)
+	result = append(result, '\n')
+
+	return result, nil
+}
+
+// nest wraps leaf in the menus described by path, starting with the root menu.
+// E.g. path [Accessories, Text] results in Applications > Accessories > Text > leaf contents.
+// The name of leaf is ignored and replaced by the last element of path.
+func nest(path []string, leaf Menu) Menu {
+	if len(path) == 0 {
+		leaf.Name = RootMenuName
+		return leaf
+	}
+
+	leaf.Name = path[len(path)-1]
+	for i := len(path) - 2; i >= 0; i-- {
+		leaf = Menu{Name: path[i], Menus: []Menu{leaf}}
+	}
+
+	return Menu{Name: RootMenuName, Menus: []Menu{leaf}}
+}
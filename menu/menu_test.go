@@ -0,0 +1,135 @@
+package menu
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHideEntry(t *testing.T) {
+	home := testenv.Setup(t)
+
+	path, err := HideEntry([]string{"Accessories"}, "vim.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPath := filepath.Join(
+		home,
+		".config/menus/applications-merged/xdg-hide-Accessories-vim.menu",
+	)
+	if path != expectedPath {
+		t.Errorf("path = %s, expected: %s", path, expectedPath)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := menuDoctype + `<Menu>
+	<Name>Applications</Name>
+	<Menu>
+		<Name>Accessories</Name>
+		<Exclude>
+			<Filename>vim.desktop</Filename>
+		</Exclude>
+	</Menu>
+</Menu>
+`
+	if string(data) != expected {
+		t.Errorf("HideEntry wrote:\n%s\nexpected:\n%s", data, expected)
+	}
+}
+
+func TestShowEntryAfterHideEntry(t *testing.T) {
+	testenv.Setup(t)
+
+	hidden, err := HideEntry([]string{"Accessories"}, "vim.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shown, err := ShowEntry([]string{"Accessories"}, "vim.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(hidden); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("hide fragment still exists after ShowEntry: %v", err)
+	}
+	if _, err := os.Stat(shown); err != nil {
+		t.Errorf("show fragment is missing: %v", err)
+	}
+
+	_, err = HideEntry([]string{"Accessories"}, "vim.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(shown); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("show fragment still exists after HideEntry: %v", err)
+	}
+}
+
+func TestMoveEntry(t *testing.T) {
+	testenv.Setup(t)
+
+	path, err := MoveEntry("vim.desktop", []string{"Accessories"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := menuDoctype + `<Menu>
+	<Name>Applications</Name>
+	<Include>
+		<Filename>vim.desktop</Filename>
+	</Include>
+	<Menu>
+		<Name>Accessories</Name>
+		<Exclude>
+			<Filename>vim.desktop</Filename>
+		</Exclude>
+	</Menu>
+</Menu>
+`
+	if string(data) != expected {
+		t.Errorf("MoveEntry wrote:\n%s\nexpected:\n%s", data, expected)
+	}
+}
+
+func TestWriteDirectoryFile(t *testing.T) {
+	home := testenv.Setup(t)
+
+	path, err := WriteDirectoryFile("dev", " Dev\ttools", "applications-development")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(path, filepath.Join(home, ".local/share/desktop-directories")) {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[Desktop Entry]\nType=Directory\nName=\\sDev\\ttools\nIcon=applications-development\n"
+	if string(data) != expected {
+		t.Errorf("WriteDirectoryFile wrote %q, expected: %q", data, expected)
+	}
+
+	_, err = WriteDirectoryFile("../dev", "Dev", "")
+	if err == nil {
+		t.Errorf("WriteDirectoryFile(../dev) returned no error")
+	}
+}
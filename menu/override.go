@@ -0,0 +1,147 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteOverride writes the menu as a fragment named name to the merged directory returned by
+// GetMergedDir and returns the path of the file.
+// The root of the menu should be named RootMenuName.
+// An existing fragment with the same name is replaced atomically.
+func WriteOverride(name string, menu Menu) (string, error) {
+	if !strings.HasSuffix(name, ".menu") {
+		name += ".menu"
+	}
+
+	if strings.ContainsRune(name, filepath.Separator) {
+		return "", fmt.Errorf("WriteOverride: name must not contain a path separator: %s", name)
+	}
+
+	data, err := menu.Encode()
+	if err != nil {
+		return "", fmt.Errorf("WriteOverride: failed to encode menu %s: %w", name, err)
+	}
+
+	path := filepath.Join(GetMergedDir(), name)
+	err = fileutil.WriteFileAtomic(path, data, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// HideEntry writes a fragment that excludes the desktop entry from the menu at menuPath.
+// A fragment written by ShowEntry for the same entry and menu is removed.
+// menuPath is relative to the root menu, e.g. [Accessories] for Applications > Accessories.
+// Example of desktopId: vim.desktop
+func HideEntry(menuPath []string, desktopId string) (string, error) {
+	menu := nest(menuPath, Menu{
+		Exclude: &Rules{Filenames: []string{desktopId}},
+	})
+
+	return writeVisibility("hide", "show", menuPath, desktopId, menu)
+}
+
+// ShowEntry writes a fragment that includes the desktop entry in the menu at menuPath.
+// A fragment written by HideEntry for the same entry and menu is removed.
+// See HideEntry for the format of the arguments.
+func ShowEntry(menuPath []string, desktopId string) (string, error) {
+	menu := nest(menuPath, Menu{
+		Include: &Rules{Filenames: []string{desktopId}},
+	})
+
+	return writeVisibility("show", "hide", menuPath, desktopId, menu)
+}
+
+// writeVisibility writes the fragment of a HideEntry or ShowEntry call and removes the fragment
+// of the opposite call. Otherwise, the entry would be both excluded and included and the result
+// would depend on the order in which the fragments are merged.
+func writeVisibility(
+	verb string,
+	opposite string,
+	menuPath []string,
+	desktopId string,
+	menu Menu,
+) (string, error) {
+	path, err := WriteOverride(overrideName(verb, menuPath, desktopId), menu)
+	if err != nil {
+		return "", err
+	}
+
+	oppositePath := filepath.Join(GetMergedDir(), overrideName(opposite, menuPath, desktopId))
+	err = os.Remove(oppositePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return path, fmt.Errorf("failed to remove opposite override %s: %w", oppositePath, err)
+	}
+
+	return path, nil
+}
+
+// MoveEntry writes a fragment that excludes the desktop entry from the menu at from and includes
+// it in the menu at to.
+func MoveEntry(desktopId string, from []string, to []string) (string, error) {
+	excluded := nest(from, Menu{
+		Exclude: &Rules{Filenames: []string{desktopId}},
+	})
+	included := nest(to, Menu{
+		Include: &Rules{Filenames: []string{desktopId}},
+	})
+
+	// Menus with the same name are merged, combining both trees under one root is therefore
+	// equivalent to writing them separately.
+	menu := Menu{Name: RootMenuName}
+	menu.Menus = append(menu.Menus, excluded.Menus...)
+	menu.Menus = append(menu.Menus, included.Menus...)
+	if len(from) == 0 {
+		menu.Exclude = excluded.Exclude
+	}
+	if len(to) == 0 {
+		menu.Include = included.Include
+	}
+
+	return WriteOverride(overrideName("move", nil, desktopId), menu)
+}
+
+// CreateSubmenu writes a fragment that creates the menu at menuPath.
+// directoryFile is the optional name of a .directory file describing the menu, see
+// WriteDirectoryFile.
+// categories are included in the new menu, e.g. Development.
+func CreateSubmenu(menuPath []string, directoryFile string, categories ...string) (string, error) {
+	if len(menuPath) == 0 {
+		return "", fmt.Errorf("CreateSubmenu: menuPath must not be empty")
+	}
+
+	leaf := Menu{Directory: directoryFile}
+	if len(categories) > 0 {
+		leaf.Include = &Rules{Categories: categories}
+	}
+
+	return WriteOverride(overrideName("submenu", menuPath, ""), nest(menuPath, leaf))
+}
+
+// overrideName returns a deterministic file name for an override so that repeating the same
+// edit replaces the previous fragment instead of adding another one.
+func overrideName(verb string, menuPath []string, desktopId string) string {
+	parts := []string{"xdg", verb}
+	parts = append(parts, menuPath...)
+	if desktopId != "" {
+		parts = append(parts, strings.TrimSuffix(desktopId, ".desktop"))
+	}
+
+	name := strings.Join(parts, "-")
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case filepath.Separator, ' ':
+			return '_'
+		}
+		return r
+	}, name)
+
+	return name + ".menu"
+}
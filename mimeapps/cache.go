@@ -0,0 +1,68 @@
+package mimeapps
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dirMimeTypes provides the MimeType keys of the desktop files in a directory, using the
+// mimeinfo.cache file of the directory when it is up to date.
+type dirMimeTypes struct {
+	// byId maps the desktop IDs in the cache to their MIME types, nil if there is no usable
+	// cache.
+	byId map[string][]string
+
+	// written is the modification time of the cache.
+	written time.Time
+}
+
+// loadDirMimeTypes loads the mimeinfo.cache file of dir, see desktop.LoadFreshMimeInfoCache.
+func loadDirMimeTypes(dir string) dirMimeTypes {
+	cache, err := desktop.LoadFreshMimeInfoCache(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Logger().Debug(
+				"Not using mimeinfo.cache",
+				logging.Path, dir,
+				logging.Error, err,
+			)
+		}
+		return dirMimeTypes{}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, desktop.MimeInfoCacheName))
+	if err != nil {
+		return dirMimeTypes{}
+	}
+
+	byId := make(map[string][]string)
+	for mimeType, desktopIds := range cache {
+		for _, desktopId := range desktopIds {
+			byId[desktopId] = append(byId[desktopId], mimeType)
+		}
+	}
+
+	return dirMimeTypes{byId: byId, written: info.ModTime()}
+}
+
+// mimeTypes returns the MIME types of the desktop file at path. The desktop file is parsed if
+// there is no cache or if it was modified after the cache was written.
+func (d dirMimeTypes) mimeTypes(desktopId string, path string) ([]string, error) {
+	if d.byId != nil {
+		info, err := os.Stat(path)
+		if err == nil && !info.ModTime().After(d.written) {
+			return d.byId[desktopId], nil
+		}
+	}
+
+	entry, err := desktop.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.MimeType, nil
+}
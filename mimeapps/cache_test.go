@@ -0,0 +1,56 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetAssociationsMimeInfoCache(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app.desktop")
+	cache := filepath.Join(dir, desktop.MimeInfoCacheName)
+	files := map[string]string{
+		app: "[Desktop Entry]\nType=Application\nName=App\nExec=app %f\nMimeType=text/plain;\n",
+		// The cache differs from the desktop file to tell which one was used
+		cache: "[MIME Cache]\nimage/png=app.desktop;\n",
+	}
+	for path, content := range files {
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setTime := func(path string, offset time.Duration) {
+		modTime := time.Now().Add(offset)
+		err := os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lists := []ListLocation{{Path: filepath.Join(dir, "mimeapps.list"), HasDesktopFiles: true}}
+	idPathMap := desktop.IdPathMap{"app.desktop": {app}}
+	check := func(name string, expected Associations) {
+		t.Helper()
+		if diff := cmp.Diff(expected, GetAssociations(lists, idPathMap)); diff != "" {
+			t.Errorf("%s: GetAssociations mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	setTime(app, -2*time.Hour)
+	setTime(dir, -2*time.Hour)
+	setTime(cache, -time.Hour)
+	check("fresh cache", Associations{"image/png": {"app.desktop"}})
+
+	setTime(app, 0)
+	check("modified desktop file", Associations{"text/plain": {"app.desktop"}})
+
+	setTime(app, -2*time.Hour)
+	setTime(dir, 0)
+	check("stale cache", Associations{"text/plain": {"app.desktop"}})
+}
@@ -0,0 +1,197 @@
+package mimeapps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// GetDefaultApp returns the desktop ID of the default application of the MIME type, or an empty
+// string if there is none. It gives the same result as the first desktop ID of the MIME type in
+// GetDefaults, but only parses the mimeapps.list files until a valid default is found and only
+// the desktop files of the desktop IDs that are listed for the MIME type.
+// See GetPreferredApplications to fall back to the associated applications.
+//
+// desktopIdToPathsMap is used to look up the paths of a desktop file by its ID.
+// If it is nil, the paths of the listed desktop IDs are looked up in the filesystem, see
+// [desktop.GetDesktopFilesById].
+func GetDefaultApp(
+	mimeappsFileList []ListLocation,
+	mimeType string,
+	desktopIdToPathsMap desktop.IdPathMap,
+) string {
+	result, _ := GetDefaultAppContext(
+		context.Background(),
+		mimeappsFileList,
+		mimeType,
+		desktopIdToPathsMap,
+	)
+	return result
+}
+
+// GetDefaultAppContext is like GetDefaultApp but stops when ctx is done, returning the error of
+// ctx.
+func GetDefaultAppContext(
+	ctx context.Context,
+	mimeappsFileList []ListLocation,
+	mimeType string,
+	desktopIdToPathsMap desktop.IdPathMap,
+) (string, error) {
+	lookup := defaultLookup{
+		lists:     mimeappsFileList,
+		idPathMap: desktopIdToPathsMap,
+		parsed:    make(map[string]MimeApps),
+	}
+	if lookup.idPathMap == nil {
+		lookup.idPathMap = make(desktop.IdPathMap)
+		lookup.scan = true
+	}
+
+	for _, location := range mimeappsFileList {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("GetDefaultAppContext: %w", err)
+		}
+
+		for _, desktopId := range lookup.parse(location.Path).Default[mimeType] {
+			_, dfPath, dfParseError := lookup.paths(desktopId).LoadById(desktopId)
+			if dfPath == "" {
+				continue
+			}
+
+			if dfParseError != nil {
+				logging.Logger().Warn(
+					"Failed to parse desktop file",
+					logging.DesktopId, desktopId,
+					logging.Path, dfPath,
+					logging.Error, dfParseError,
+				)
+				continue
+			}
+
+			if !lookup.isAssociated(mimeType, desktopId) {
+				logging.Logger().Debug(
+					"Ignoring default application that is not associated with the MIME type",
+					logging.Path, location.Path,
+					logging.DesktopId, desktopId,
+					logging.MimeType, mimeType,
+				)
+				continue
+			}
+
+			return desktopId, nil
+		}
+	}
+
+	return "", nil
+}
+
+// defaultLookup holds the files that GetDefaultApp has read.
+type defaultLookup struct {
+	lists     []ListLocation
+	idPathMap desktop.IdPathMap
+
+	// scan is set when the paths of desktop IDs are looked up in the filesystem on first use.
+	scan   bool
+	parsed map[string]MimeApps
+}
+
+// parse returns the parsed mimeapps.list file at path, which is empty if the file does not exist
+// or is invalid.
+func (l *defaultLookup) parse(path string) MimeApps {
+	if parsed, ok := l.parsed[path]; ok {
+		return parsed
+	}
+
+	parsed, err := ParseFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		logging.Logger().Warn(
+			"Failed to parse mimeapps file",
+			logging.Path, path,
+			logging.Error, err,
+		)
+	}
+
+	l.parsed[path] = parsed
+	return parsed
+}
+
+// paths returns the IdPathMap containing the paths of the desktop ID.
+func (l *defaultLookup) paths(desktopId string) desktop.IdPathMap {
+	if _, ok := l.idPathMap[desktopId]; !ok && l.scan {
+		l.idPathMap[desktopId] = desktop.GetDesktopFilesById(nil, desktopId)[desktopId]
+	}
+
+	return l.idPathMap
+}
+
+// isAssociated returns whether the desktop ID is associated with the MIME type, following the
+// rules of GetAssociations for only this pair.
+func (l *defaultLookup) isAssociated(mimeType string, desktopId string) bool {
+	paths := l.paths(desktopId)[desktopId]
+
+	// The index of the lowest precedence location containing a desktop file of the ID, additions
+	// and removals in locations with a lower precedence are ignored.
+	lowestPrecedence := -1
+	for i, location := range l.lists {
+		if !location.HasDesktopFiles {
+			continue
+		}
+
+		dir := filepath.Dir(location.Path)
+		for _, path := range paths {
+			if isSubPathAbs(path, dir) {
+				lowestPrecedence = i
+			}
+		}
+	}
+
+	for i, location := range l.lists {
+		if filepath.Base(location.Path) != "mimeapps.list" {
+			continue
+		}
+
+		parsed := l.parse(location.Path)
+		if i <= lowestPrecedence {
+			if slices.Contains(parsed.Added[mimeType], desktopId) {
+				return true
+			}
+
+			if slices.Contains(parsed.Removed[mimeType], desktopId) {
+				return false
+			}
+		}
+
+		if !location.HasDesktopFiles {
+			continue
+		}
+
+		dir := filepath.Dir(location.Path)
+		for _, path := range paths {
+			if !isSubPathAbs(path, dir) {
+				continue
+			}
+
+			entry, err := desktop.ParseFile(path)
+			if err != nil {
+				logging.Logger().Warn(
+					"Skipping invalid desktop file",
+					logging.DesktopId, desktopId,
+					logging.Path, path,
+					logging.Error, err,
+				)
+				return false
+			}
+
+			return slices.Contains(entry.MimeType, mimeType)
+		}
+	}
+
+	return false
+}
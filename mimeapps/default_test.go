@@ -0,0 +1,71 @@
+package mimeapps
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDefaultAppMatchesGetDefaults(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		scenario := fmt.Sprintf("scenario%02d", i)
+		lists, idPathMap := getScenarioMimeapps(scenario, t)
+		defaults := GetDefaults(lists, GetAssociations(lists, idPathMap), idPathMap)
+
+		mimeTypes := map[string]bool{"application/x-missing": true}
+		for _, location := range lists {
+			parsed, _ := ParseFile(location.Path)
+			for mimeType := range parsed.Default {
+				mimeTypes[mimeType] = true
+			}
+		}
+
+		for mimeType := range mimeTypes {
+			var expected string
+			if len(defaults[mimeType]) > 0 {
+				expected = defaults[mimeType][0]
+			}
+
+			if actual := GetDefaultApp(lists, mimeType, idPathMap); actual != expected {
+				t.Errorf(
+					"%s: GetDefaultApp(%s) = %q, expected: %q",
+					scenario,
+					mimeType,
+					actual,
+					expected,
+				)
+			}
+		}
+	}
+}
+
+func TestGetDefaultAppWithoutIdPathMap(t *testing.T) {
+	setupSettings(t)
+
+	err := os.MkdirAll(basedir.ConfigHome, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(basedir.ConfigHome, "mimeapps.list"), []byte(`
+[Default Applications]
+x-scheme-handler/mailto=missing.desktop;firefox.desktop;mail.desktop;
+text/html=chromium.desktop;
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		// firefox.desktop is not associated with mailto
+		"x-scheme-handler/mailto": "mail.desktop",
+		"text/html":               "chromium.desktop",
+		"text/plain":              "",
+	}
+	for mimeType, expected := range tests {
+		if actual := GetDefaultApp(GetLists(""), mimeType, nil); actual != expected {
+			t.Errorf("GetDefaultApp(%s) = %q, expected: %q", mimeType, actual, expected)
+		}
+	}
+}
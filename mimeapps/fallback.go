@@ -0,0 +1,50 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+)
+
+// ResolveWithFallback returns the desktop ID of the preferred application of the MIME type and
+// the type through which it was found. If no application supports the type itself, its broader
+// types are considered in the order of [sharedmimeinfo.Database.BroaderDfs], e.g.
+// application/json and text/plain for application/ld+json, as recommended by the
+// [MIME apps spec]. Empty strings are returned if no application is found.
+//
+// For each type, the default application is looked up using GetDefaultApp. If the type has no
+// valid default, its associated applications are considered in the order of GetAssociations.
+// The associations are only computed once a type without a default is encountered. See
+// RankApplications to get all applications that can open the type.
+//
+// If subclassDB is nil, only mimeType itself is considered.
+// mimeappsFileList and desktopIdPathMap are used as in GetPreferredApplications.
+//
+// [MIME apps spec]: https://specifications.freedesktop.org/mime-apps-spec/1.0.1/default.html
+func ResolveWithFallback(
+	mimeType string,
+	subclassDB *sharedmimeinfo.Database,
+	mimeappsFileList []ListLocation,
+	desktopIdPathMap desktop.IdPathMap,
+) (string, string) {
+	var associations Associations
+
+	for _, candidateType := range subclassDB.BroaderDfs(mimeType) {
+		desktopId := GetDefaultApp(mimeappsFileList, candidateType, desktopIdPathMap)
+		if desktopId != "" {
+			return desktopId, candidateType
+		}
+
+		if associations == nil {
+			associations = GetAssociations(mimeappsFileList, desktopIdPathMap)
+		}
+
+		for _, desktopId := range associations[candidateType] {
+			entry, _, _ := desktopIdPathMap.LoadById(desktopId)
+			if entry != nil {
+				return desktopId, candidateType
+			}
+		}
+	}
+
+	return "", ""
+}
@@ -0,0 +1,91 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveWithFallback(t *testing.T) {
+	home := t.TempDir()
+	overrideEnv(t, map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_CONFIG_DIRS": filepath.Join(home, "etc/xdg"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local/share"),
+		"XDG_DATA_DIRS":   filepath.Join(home, "usr/share"),
+	})
+
+	files := map[string]string{
+		"usr/share/mime/subclasses": "application/ld+json application/json\n" +
+			"application/json application/javascript\napplication/javascript text/plain\n",
+		"usr/share/applications/editor.desktop": "[Desktop Entry]\nType=Application\n" +
+			"Name=Editor\nExec=editor %f\nMimeType=text/plain;\n",
+		"usr/share/applications/viewer.desktop": "[Desktop Entry]\nType=Application\n" +
+			"Name=Viewer\nExec=viewer %f\nMimeType=text/plain;application/json;\n",
+		".config/mimeapps.list": "[Default Applications]\ntext/plain=editor.desktop\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(home, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sharedmimeinfo.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idPathMap, err := desktop.GetDesktopFiles(desktop.GetDesktopFileLocations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mimeType     string
+		expectedId   string
+		expectedType string
+	}{
+		{"application/ld+json", "viewer.desktop", "application/json"},
+		{"application/javascript", "editor.desktop", "text/plain"},
+		{"text/plain", "editor.desktop", "text/plain"},
+		{"image/png", "", ""},
+	}
+	for _, test := range tests {
+		id, mimeType := ResolveWithFallback(test.mimeType, db, GetLists(""), idPathMap)
+		if id != test.expectedId || mimeType != test.expectedType {
+			t.Errorf(
+				"ResolveWithFallback(%s) = %s, %s, expected: %s, %s",
+				test.mimeType,
+				id,
+				mimeType,
+				test.expectedId,
+				test.expectedType,
+			)
+		}
+	}
+
+	// Without a database, only the type itself is considered
+	id, mimeType := ResolveWithFallback("application/javascript", nil, GetLists(""), idPathMap)
+	if id != "" || mimeType != "" {
+		t.Errorf(
+			"ResolveWithFallback(application/javascript, nil) = %s, %s, expected no result",
+			id,
+			mimeType,
+		)
+	}
+	id, mimeType = ResolveWithFallback("text/plain", nil, GetLists(""), idPathMap)
+	if id != "editor.desktop" || mimeType != "text/plain" {
+		t.Errorf(
+			"ResolveWithFallback(text/plain, nil) = %s, %s, expected: editor.desktop, text/plain",
+			id,
+			mimeType,
+		)
+	}
+}
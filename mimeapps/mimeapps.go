@@ -1,10 +1,12 @@
 package mimeapps
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/MatthiasKunnen/xdg/basedir"
 	"github.com/MatthiasKunnen/xdg/desktop"
-	"log"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
 	"os"
 	"path/filepath"
 	"slices"
@@ -97,23 +99,51 @@ func GetDefaults(
 	associations Associations,
 	desktopIdToPathsMap desktop.IdPathMap,
 ) map[string][]string {
+	result, _ := GetDefaultsContext(
+		context.Background(),
+		mimeappsFileList,
+		associations,
+		desktopIdToPathsMap,
+	)
+	return result
+}
+
+// GetDefaultsContext is like GetDefaults but stops when ctx is done, returning the error of ctx.
+func GetDefaultsContext(
+	ctx context.Context,
+	mimeappsFileList []ListLocation,
+	associations Associations,
+	desktopIdToPathsMap desktop.IdPathMap,
+) (map[string][]string, error) {
 	result := make(map[string][]string)
 
 	for _, location := range mimeappsFileList {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("GetDefaultsContext: %w", err)
+		}
+
 		path := location.Path
 		file, err := os.Open(path)
 		switch {
 		case errors.Is(err, os.ErrNotExist):
 			continue
 		case err != nil:
-			log.Printf("Error opening mimeapps file '%s': %v\n", path, err)
+			logging.Logger().Warn(
+				"Failed to open mimeapps file",
+				logging.Path, path,
+				logging.Error, err,
+			)
 			continue
 		}
 
 		parsed, err := Parse(file)
 		file.Close()
 		if err != nil {
-			log.Printf("Failed to parse mimeapps file '%s': %v\n", path, err)
+			logging.Logger().Warn(
+				"Failed to parse mimeapps file",
+				logging.Path, path,
+				logging.Error, err,
+			)
 			continue
 		}
 
@@ -132,18 +162,22 @@ func GetDefaults(
 				}
 
 				if dfParseError != nil {
-					log.Printf("Failed to parse desktop file with ID '%s': %v\n", path, dfParseError)
+					logging.Logger().Warn(
+						"Failed to parse desktop file",
+						logging.DesktopId, desktopId,
+						logging.Path, dfPath,
+						logging.Error, dfParseError,
+					)
 					continue
 				}
 
 				if associations[mimeType] == nil || !slices.Contains(associations[mimeType], desktopId) {
 					// If a valid desktop file is found, verify that it is associated with the type
-					log.Printf(
-						"Mimeapps file %s states %s as default application for mimetype %s "+
-							"but the mime type is not in any [Added Associations] section.\n",
-						path,
-						desktopId,
-						mimeType,
+					logging.Logger().Debug(
+						"Ignoring default application that is not associated with the MIME type",
+						logging.Path, path,
+						logging.DesktopId, desktopId,
+						logging.MimeType, mimeType,
 					)
 					continue
 				}
@@ -158,7 +192,7 @@ func GetDefaults(
 		}
 	}
 
-	return result
+	return result, nil
 }
 
 // Associations is a map of Key=MIME type, Value=List of desktop IDs.
@@ -167,10 +201,26 @@ type Associations = map[string][]string
 
 // GetAssociations returns all mime-desktop associations created by entries in the
 // [Added Associations] and [Remove Associations] sections and the MimeType in the .desktop files.
+//
+// The MimeType keys are read from the mimeinfo.cache file written by update-desktop-database
+// when the directory of the desktop files has one that is up to date. Desktop files that were
+// modified after the cache was written are parsed, as are all desktop files of directories
+// without a cache or with a cache that is older than the directory.
 func GetAssociations(
 	mimeappsLocations []ListLocation,
 	idPathsMap desktop.IdPathMap,
 ) Associations {
+	result, _ := GetAssociationsContext(context.Background(), mimeappsLocations, idPathsMap)
+	return result
+}
+
+// GetAssociationsContext is like GetAssociations but stops when ctx is done, returning the error
+// of ctx.
+func GetAssociationsContext(
+	ctx context.Context,
+	mimeappsLocations []ListLocation,
+	idPathsMap desktop.IdPathMap,
+) (Associations, error) {
 	result := make(Associations)
 	blacklistMimeDesktop := make(map[string]map[string]bool)
 	blacklistDesktopIds := make(map[string]bool)
@@ -201,6 +251,10 @@ func GetAssociations(
 	}
 
 	for i, location := range mimeappsLocations {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("GetAssociationsContext: %w", err)
+		}
+
 		path := location.Path
 
 		if filepath.Base(path) != "mimeapps.list" {
@@ -214,7 +268,11 @@ func GetAssociations(
 		case errors.Is(err, os.ErrNotExist):
 			// A nonexistent mimeapps.list should be treated as an empty file.
 		case err != nil:
-			log.Printf("Error parsing mimeapps file '%s': %v\n", path, err)
+			logging.Logger().Warn(
+				"Failed to parse mimeapps file",
+				logging.Path, path,
+				logging.Error, err,
+			)
 		}
 
 		for mime, desktopIds := range parsed.Added {
@@ -278,6 +336,7 @@ func GetAssociations(
 		// mimeapps.list which lists the given type in its MimeType= line, excluding any
 		// desktop files already in the blacklist.
 		dirname := filepath.Dir(path)
+		dirTypes := loadDirMimeTypes(dirname)
 		// Needed for stable output
 		toAdd := make(map[string][]string)
 		for desktopId, paths := range idPathsMap {
@@ -295,13 +354,18 @@ func GetAssociations(
 				}
 				blacklistDesktopIds[desktopId] = true
 
-				entry, err := desktop.ParseFile(desktopFilePath)
+				mimeTypes, err := dirTypes.mimeTypes(desktopId, desktopFilePath)
 				if err != nil {
-					log.Printf("Failed to load desktop file '%s', skipping: %v\n", desktopFilePath, err)
+					logging.Logger().Warn(
+						"Skipping invalid desktop file",
+						logging.DesktopId, desktopId,
+						logging.Path, desktopFilePath,
+						logging.Error, err,
+					)
 					continue
 				}
 
-				for _, mime := range entry.MimeType {
+				for _, mime := range mimeTypes {
 					if blacklistMimeDesktop[mime][desktopId] {
 						continue
 					}
@@ -326,7 +390,7 @@ func GetAssociations(
 		}
 	}
 
-	return result
+	return result, nil
 }
 
 // GetPreferredApplications returns the preferred applications for each supported mime type based
@@ -337,8 +401,30 @@ func GetPreferredApplications(
 	mimeappsFileList []ListLocation,
 	desktopIdPathMap desktop.IdPathMap,
 ) Associations {
-	associations := GetAssociations(mimeappsFileList, desktopIdPathMap)
-	defaults := GetDefaults(mimeappsFileList, associations, desktopIdPathMap)
+	result, _ := GetPreferredApplicationsContext(
+		context.Background(),
+		mimeappsFileList,
+		desktopIdPathMap,
+	)
+	return result
+}
+
+// GetPreferredApplicationsContext is like GetPreferredApplications but stops when ctx is done,
+// returning the error of ctx.
+func GetPreferredApplicationsContext(
+	ctx context.Context,
+	mimeappsFileList []ListLocation,
+	desktopIdPathMap desktop.IdPathMap,
+) (Associations, error) {
+	associations, err := GetAssociationsContext(ctx, mimeappsFileList, desktopIdPathMap)
+	if err != nil {
+		return nil, fmt.Errorf("GetPreferredApplicationsContext: %w", err)
+	}
+
+	defaults, err := GetDefaultsContext(ctx, mimeappsFileList, associations, desktopIdPathMap)
+	if err != nil {
+		return nil, fmt.Errorf("GetPreferredApplicationsContext: %w", err)
+	}
 
 	for mime, desktopIds := range defaults {
 		if associations[mime] == nil {
@@ -348,5 +434,5 @@ func GetPreferredApplications(
 		}
 	}
 
-	return associations
+	return associations, nil
 }
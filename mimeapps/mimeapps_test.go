@@ -1,7 +1,9 @@
 package mimeapps
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/MatthiasKunnen/xdg/basedir"
 	"github.com/MatthiasKunnen/xdg/desktop"
@@ -242,3 +244,13 @@ func TestGetPreferredApplicationsS05Regression(t *testing.T) {
 		t.Errorf("Scenario 5 wrong output:\n%s", cmp.Diff(expected, associations))
 	}
 }
+
+func TestGetPreferredApplicationsContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GetPreferredApplicationsContext(ctx, GetLists(""), desktop.IdPathMap{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package mimeapps
+
+import (
+	"cmp"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"slices"
+)
+
+// RankedApplication is an application that can open a MIME type, together with the factors
+// that determine its rank. See CompareRanked for how the factors are weighed.
+type RankedApplication struct {
+	DesktopId string
+
+	// MimeType is the type through which the application was found. It is the requested type
+	// or one of its ancestors, e.g. text/plain for text/x-csrc.
+	MimeType string
+
+	// Distance is the number of subclass steps from the requested type to MimeType. It is 0 if
+	// the application supports the requested type itself.
+	Distance int
+
+	// Default is true if the application is a default application for MimeType.
+	Default bool
+
+	// ListIndex is the index, in the mimeapps.list locations, of the list that declares the
+	// application as default, lower is higher precedence. It is -1 if Default is false.
+	ListIndex int
+
+	// Position is the position of the application among the defaults of MimeType in the list
+	// at ListIndex or, if Default is false, among the associations of MimeType.
+	Position int
+}
+
+// CompareRanked compares two ranked applications, returning a negative number if a ranks higher
+// than b. The factors are, in order of importance:
+//  1. Distance, the application supporting the more specific type ranks higher.
+//  2. Default applications rank higher than other associated applications.
+//  3. ListIndex, defaults of higher precedence mimeapps.list files rank higher.
+//  4. Position, the order of the defaults or associations.
+//  5. DesktopId, to make the order stable.
+//
+// Use it with slices.SortFunc, or write a custom comparison to change the ordering.
+func CompareRanked(a RankedApplication, b RankedApplication) int {
+	if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+		return c
+	}
+
+	if a.Default != b.Default {
+		if a.Default {
+			return -1
+		}
+		return 1
+	}
+
+	if c := cmp.Compare(a.ListIndex, b.ListIndex); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(a.Position, b.Position); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.DesktopId, b.DesktopId)
+}
+
+// RankApplications returns the applications that can open the MIME type, highest ranked first,
+// each application once with its highest rank. The requested type and all of its ancestors in
+// db are considered, see CompareRanked for the ordering.
+//
+// mimeappsFileList and desktopIdPathMap are used as in GetPreferredApplications.
+func RankApplications(
+	mimeType string,
+	db *sharedmimeinfo.Database,
+	mimeappsFileList []ListLocation,
+	desktopIdPathMap desktop.IdPathMap,
+) []RankedApplication {
+	distances := subclassDistances(mimeType, db)
+	associations := GetAssociations(mimeappsFileList, desktopIdPathMap)
+
+	var candidates []RankedApplication
+	for i, location := range mimeappsFileList {
+		defaults := GetDefaults([]ListLocation{location}, associations, desktopIdPathMap)
+		for candidateType, distance := range distances {
+			for position, desktopId := range defaults[candidateType] {
+				candidates = append(candidates, RankedApplication{
+					DesktopId: desktopId,
+					MimeType:  candidateType,
+					Distance:  distance,
+					Default:   true,
+					ListIndex: i,
+					Position:  position,
+				})
+			}
+		}
+	}
+
+	for candidateType, distance := range distances {
+		for position, desktopId := range associations[candidateType] {
+			candidates = append(candidates, RankedApplication{
+				DesktopId: desktopId,
+				MimeType:  candidateType,
+				Distance:  distance,
+				ListIndex: -1,
+				Position:  position,
+			})
+		}
+	}
+
+	slices.SortFunc(candidates, CompareRanked)
+
+	result := make([]RankedApplication, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if seen[candidate.DesktopId] {
+			continue
+		}
+		seen[candidate.DesktopId] = true
+		result = append(result, candidate)
+	}
+
+	return result
+}
+
+// subclassDistances returns the MIME type and its ancestors mapped to the smallest number of
+// subclass steps to reach them from mimeType. application/octet-stream, the broadest type, is
+// always placed after all other ancestors.
+func subclassDistances(mimeType string, db *sharedmimeinfo.Database) map[string]int {
+	mimeType = db.Canonical(mimeType)
+	result := map[string]int{mimeType: 0}
+	queue := []string{mimeType}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range db.Parents(current) {
+			parent = db.Canonical(parent)
+			if _, seen := result[parent]; seen {
+				continue
+			}
+
+			result[parent] = result[current] + 1
+			queue = append(queue, parent)
+		}
+	}
+
+	_, found := result[sharedmimeinfo.OctetStream]
+	if found && mimeType != sharedmimeinfo.OctetStream {
+		maxDistance := 0
+		for t, distance := range result {
+			if t != sharedmimeinfo.OctetStream {
+				maxDistance = max(maxDistance, distance)
+			}
+		}
+		result[sharedmimeinfo.OctetStream] = maxDistance + 1
+	}
+
+	return result
+}
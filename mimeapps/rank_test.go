@@ -0,0 +1,84 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRankApplications(t *testing.T) {
+	home := t.TempDir()
+	overrideEnv(t, map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_CONFIG_DIRS": filepath.Join(home, "etc/xdg"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local/share"),
+		"XDG_DATA_DIRS":   filepath.Join(home, "usr/share"),
+	})
+
+	files := map[string]string{
+		"usr/share/mime/subclasses": "text/x-csrc text/plain\n",
+		"usr/share/applications/ide.desktop": "[Desktop Entry]\nType=Application\nName=IDE\n" +
+			"Exec=ide %f\nMimeType=text/x-csrc;\n",
+		"usr/share/applications/editor.desktop": "[Desktop Entry]\nType=Application\nName=Editor\n" +
+			"Exec=editor %f\nMimeType=text/plain;\n",
+		"usr/share/applications/viewer.desktop": "[Desktop Entry]\nType=Application\nName=Viewer\n" +
+			"Exec=viewer %f\nMimeType=text/plain;text/x-csrc;\n",
+		"usr/share/applications/hex.desktop": "[Desktop Entry]\nType=Application\nName=Hex\n" +
+			"Exec=hex %f\nMimeType=application/octet-stream;\n",
+		".config/mimeapps.list": "[Default Applications]\ntext/plain=editor.desktop\n",
+		"usr/share/applications/mimeapps.list": "[Default Applications]\n" +
+			"text/x-csrc=viewer.desktop\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(home, name)
+		err := os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sharedmimeinfo.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idPathMap, err := desktop.GetDesktopFiles(desktop.GetDesktopFileLocations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lists := GetLists("")
+	actual := RankApplications("text/x-csrc", db, lists, idPathMap)
+	expected := []RankedApplication{
+		{
+			DesktopId: "viewer.desktop",
+			MimeType:  "text/x-csrc",
+			Default:   true,
+			ListIndex: 3,
+		},
+		{DesktopId: "ide.desktop", MimeType: "text/x-csrc", ListIndex: -1},
+		{
+			DesktopId: "editor.desktop",
+			MimeType:  "text/plain",
+			Distance:  1,
+			Default:   true,
+			ListIndex: 0,
+		},
+		{
+			DesktopId: "hex.desktop",
+			MimeType:  "application/octet-stream",
+			Distance:  2,
+			ListIndex: -1,
+		},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("RankApplications mismatch (-expected +got):\n%s", diff)
+	}
+}
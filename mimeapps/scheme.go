@@ -0,0 +1,124 @@
+package mimeapps
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"github.com/MatthiasKunnen/xdg/internal/keyfile"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultGroup = "Default Applications"
+	addedGroup   = "Added Associations"
+	removedGroup = "Removed Associations"
+)
+
+var schemeRegex = regexp.MustCompile("^[a-z][a-z0-9+.-]*$")
+
+// RegisterSchemeHandler makes the application with the given desktop ID the default handler of
+// URIs with the scheme, e.g. myapp for myapp://action.
+//
+// If the desktop file does not list x-scheme-handler/<scheme> in its MimeType key, the MIME
+// type is added to a copy of the desktop file in $XDG_DATA_HOME/applications, or to the
+// desktop file itself if it is already located there.
+// The application is then set as the default in $XDG_CONFIG_HOME/mimeapps.list and added to
+// its [Added Associations].
+//
+// To install a new desktop file for the handler, use RegisterSchemeHandlerEntry.
+func RegisterSchemeHandler(scheme string, desktopId string) error {
+	mimeType, err := schemeMimeType(scheme)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandler: %w", err)
+	}
+
+	entry, path, err := desktop.LoadById(desktopId, nil)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandler: %w", err)
+	}
+	if path == "" {
+		return fmt.Errorf("RegisterSchemeHandler: desktop file %s not found", desktopId)
+	}
+
+	if !entry.CanOpenScheme(scheme) {
+		err = addMimeType(path, desktopId, append(slices.Clone(entry.MimeType), mimeType))
+		if err != nil {
+			return fmt.Errorf("RegisterSchemeHandler: %w", err)
+		}
+	}
+
+	err = setUserDefault(mimeType, desktopId)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandler: %w", err)
+	}
+
+	return nil
+}
+
+// RegisterSchemeHandlerEntry installs the entry as the desktop file with the given desktop ID in
+// $XDG_DATA_HOME/applications and makes it the default handler of URIs with the scheme, like
+// RegisterSchemeHandler. An existing desktop file with the ID in that directory is replaced.
+//
+// If the MimeType key of the entry does not list x-scheme-handler/<scheme>, the MIME type is
+// added to the installed file, the entry itself is not modified.
+func RegisterSchemeHandlerEntry(scheme string, desktopId string, entry *desktop.Entry) error {
+	mimeType, err := schemeMimeType(scheme)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandlerEntry: %w", err)
+	}
+
+	if !strings.HasSuffix(desktopId, ".desktop") || desktopId == ".desktop" ||
+		strings.ContainsAny(desktopId, `/\`) {
+		return fmt.Errorf("RegisterSchemeHandlerEntry: invalid desktop ID: %q", desktopId)
+	}
+
+	installed := *entry
+	if !entry.CanOpenScheme(scheme) {
+		installed.MimeType = append(slices.Clone(entry.MimeType), mimeType)
+	}
+
+	content, err := installed.Encode()
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandlerEntry: %w", err)
+	}
+
+	path := filepath.Join(basedir.DataHome, "applications", desktopId)
+	err = fileutil.WriteFileAtomic(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandlerEntry: %w", err)
+	}
+
+	err = setUserDefault(mimeType, desktopId)
+	if err != nil {
+		return fmt.Errorf("RegisterSchemeHandlerEntry: %w", err)
+	}
+
+	return nil
+}
+
+// addMimeType writes the desktop file at path with the MimeType key replaced to the user
+// applications directory.
+func addMimeType(path string, desktopId string, mimeTypes []string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	content = keyfile.SetKey(content, "Desktop Entry", "MimeType", strings.Join(mimeTypes, ";")+";")
+	userPath := filepath.Join(basedir.DataHome, "applications", desktopId)
+
+	return fileutil.WriteFileAtomic(userPath, content, 0644)
+}
+
+func joinDesktopIds(ids []string) string {
+	ids = slices.DeleteFunc(ids, func(id string) bool {
+		return id == ""
+	})
+
+	return strings.Join(ids, ";") + ";"
+}
@@ -0,0 +1,148 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegisterSchemeHandler(t *testing.T) {
+	home := t.TempDir()
+	overrideEnv(t, map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_CONFIG_DIRS": filepath.Join(home, "etc/xdg"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local/share"),
+		"XDG_DATA_DIRS":   filepath.Join(home, "usr/share"),
+	})
+
+	systemFile := filepath.Join(home, "usr/share/applications/myapp.desktop")
+	err := os.MkdirAll(filepath.Dir(systemFile), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(
+		systemFile,
+		[]byte("[Desktop Entry]\n# Comment\nType=Application\nName=My app\nExec=myapp %u\nMimeType=text/plain;\n"),
+		0644,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.MkdirAll(basedir.ConfigHome, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(
+		filepath.Join(basedir.ConfigHome, "mimeapps.list"),
+		[]byte("[Default Applications]\nx-scheme-handler/myapp=other.desktop;\ntext/html=browser.desktop;\n"),
+		0644,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = RegisterSchemeHandler("MyApp", "myapp.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userEntry, err := desktop.LoadFile(filepath.Join(basedir.DataHome, "applications/myapp.desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMime := []string{"text/plain", "x-scheme-handler/myapp"}
+	if !slices.Equal(userEntry.MimeType, expectedMime) {
+		t.Errorf("MimeType = %v, expected: %v", userEntry.MimeType, expectedMime)
+	}
+
+	list, err := ParseFile(filepath.Join(basedir.ConfigHome, "mimeapps.list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDefaults := []string{"myapp.desktop", "other.desktop"}
+	if !slices.Equal(list.Default["x-scheme-handler/myapp"], expectedDefaults) {
+		t.Errorf("defaults = %v, expected: %v", list.Default["x-scheme-handler/myapp"], expectedDefaults)
+	}
+	if !slices.Equal(list.Default["text/html"], []string{"browser.desktop"}) {
+		t.Errorf("unrelated default was changed: %v", list.Default["text/html"])
+	}
+
+	idPathMap, err := desktop.GetDesktopFiles(desktop.GetDesktopFileLocations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	preferred := GetPreferredApplications(GetLists(""), idPathMap)
+	if apps := preferred["x-scheme-handler/myapp"]; len(apps) == 0 || apps[0] != "myapp.desktop" {
+		t.Errorf("preferred applications = %v, expected myapp.desktop first", apps)
+	}
+
+	err = RegisterSchemeHandler("my app", "myapp.desktop")
+	if err == nil || !strings.Contains(err.Error(), "invalid scheme") {
+		t.Errorf("expected an invalid scheme error, got: %v", err)
+	}
+}
+
+func TestRegisterSchemeHandlerEntry(t *testing.T) {
+	home := t.TempDir()
+	overrideEnv(t, map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(home, ".config"),
+		"XDG_CONFIG_DIRS": filepath.Join(home, "etc/xdg"),
+		"XDG_DATA_HOME":   filepath.Join(home, ".local/share"),
+		"XDG_DATA_DIRS":   filepath.Join(home, "usr/share"),
+	})
+
+	exec, err := desktop.NewExec("myapp %u")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &desktop.Entry{
+		Type:     desktop.TypeApplication,
+		Name:     desktop.LocaleString{Default: "My app"},
+		Exec:     exec,
+		MimeType: []string{"text/plain"},
+	}
+
+	err = RegisterSchemeHandlerEntry("myapp", "myapp.desktop", entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(entry.MimeType, []string{"text/plain"}) {
+		t.Errorf("entry was modified, MimeType = %v", entry.MimeType)
+	}
+
+	path := filepath.Join(basedir.DataHome, "applications/myapp.desktop")
+	installed, err := desktop.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMime := []string{"text/plain", "x-scheme-handler/myapp"}
+	if !slices.Equal(installed.MimeType, expectedMime) {
+		t.Errorf("MimeType = %v, expected: %v", installed.MimeType, expectedMime)
+	}
+
+	list, err := ParseFile(filepath.Join(basedir.ConfigHome, "mimeapps.list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := list.Default["x-scheme-handler/myapp"]
+	if !slices.Equal(defaults, []string{"myapp.desktop"}) {
+		t.Errorf("defaults = %v, expected: [myapp.desktop]", defaults)
+	}
+
+	for _, desktopId := range []string{"", "myapp", "../myapp.desktop"} {
+		if err := RegisterSchemeHandlerEntry("myapp", desktopId, entry); err == nil {
+			t.Errorf("RegisterSchemeHandlerEntry(%q) returned no error", desktopId)
+		}
+	}
+
+	err = RegisterSchemeHandlerEntry("myapp", "invalid.desktop", &desktop.Entry{})
+	if err == nil {
+		t.Errorf("RegisterSchemeHandlerEntry() of invalid entry returned no error")
+	}
+}
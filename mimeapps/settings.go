@@ -0,0 +1,162 @@
+package mimeapps
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"os"
+	"strings"
+)
+
+// browserMimeTypes are the MIME types that xdg-settings associates with the default web
+// browser.
+var browserMimeTypes = []string{
+	"x-scheme-handler/http",
+	"x-scheme-handler/https",
+	"x-scheme-handler/about",
+	"x-scheme-handler/unknown",
+	"text/html",
+}
+
+// GetDefaultWebBrowser returns the desktop ID of the default web browser, the preferred handler
+// of x-scheme-handler/http, like xdg-settings get default-web-browser.
+// An empty string is returned if there is none.
+func GetDefaultWebBrowser() (string, error) {
+	id, err := getPreferred("x-scheme-handler/http")
+	if err != nil {
+		return "", fmt.Errorf("GetDefaultWebBrowser: %w", err)
+	}
+
+	return id, nil
+}
+
+// CheckDefaultWebBrowser reports whether the application with the desktop ID is the default
+// web browser, like xdg-settings check default-web-browser. This requires it to be the preferred
+// handler of both http and https URIs.
+func CheckDefaultWebBrowser(desktopId string) (bool, error) {
+	for _, mimeType := range []string{"x-scheme-handler/http", "x-scheme-handler/https"} {
+		id, err := getPreferred(mimeType)
+		if err != nil {
+			return false, fmt.Errorf("CheckDefaultWebBrowser: %w", err)
+		}
+
+		if id != desktopId {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SetDefaultWebBrowser makes the application with the desktop ID the default web browser, like
+// xdg-settings set default-web-browser. As xdg-settings does, it becomes the default of http,
+// https, about, and unknown URIs, and of text/html, in $XDG_CONFIG_HOME/mimeapps.list.
+func SetDefaultWebBrowser(desktopId string) error {
+	err := checkDesktopFile(desktopId)
+	if err != nil {
+		return fmt.Errorf("SetDefaultWebBrowser: %w", err)
+	}
+
+	for _, mimeType := range browserMimeTypes {
+		err = setUserDefault(mimeType, desktopId)
+		if err != nil {
+			return fmt.Errorf("SetDefaultWebBrowser: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDefaultURLSchemeHandler returns the desktop ID of the preferred handler of URIs with the
+// scheme, like xdg-settings get default-url-scheme-handler. An empty string is returned if there
+// is none.
+func GetDefaultURLSchemeHandler(scheme string) (string, error) {
+	mimeType, err := schemeMimeType(scheme)
+	if err != nil {
+		return "", fmt.Errorf("GetDefaultURLSchemeHandler: %w", err)
+	}
+
+	id, err := getPreferred(mimeType)
+	if err != nil {
+		return "", fmt.Errorf("GetDefaultURLSchemeHandler: %w", err)
+	}
+
+	return id, nil
+}
+
+// CheckDefaultURLSchemeHandler reports whether the application with the desktop ID is the
+// preferred handler of URIs with the scheme, like xdg-settings check
+// default-url-scheme-handler.
+func CheckDefaultURLSchemeHandler(scheme string, desktopId string) (bool, error) {
+	id, err := GetDefaultURLSchemeHandler(scheme)
+	if err != nil {
+		return false, fmt.Errorf("CheckDefaultURLSchemeHandler: %w", err)
+	}
+
+	return id == desktopId, nil
+}
+
+// SetDefaultURLSchemeHandler makes the application with the desktop ID the default handler of
+// URIs with the scheme, like xdg-settings set default-url-scheme-handler. Setting the handler of
+// http or https sets the default web browser, see SetDefaultWebBrowser.
+// Unlike RegisterSchemeHandler, the desktop file is not changed.
+func SetDefaultURLSchemeHandler(scheme string, desktopId string) error {
+	mimeType, err := schemeMimeType(scheme)
+	if err != nil {
+		return fmt.Errorf("SetDefaultURLSchemeHandler: %w", err)
+	}
+
+	if mimeType == "x-scheme-handler/http" || mimeType == "x-scheme-handler/https" {
+		return SetDefaultWebBrowser(desktopId)
+	}
+
+	err = checkDesktopFile(desktopId)
+	if err != nil {
+		return fmt.Errorf("SetDefaultURLSchemeHandler: %w", err)
+	}
+
+	err = setUserDefault(mimeType, desktopId)
+	if err != nil {
+		return fmt.Errorf("SetDefaultURLSchemeHandler: %w", err)
+	}
+
+	return nil
+}
+
+func schemeMimeType(scheme string) (string, error) {
+	scheme = strings.ToLower(scheme)
+	if !schemeRegex.MatchString(scheme) {
+		return "", fmt.Errorf("invalid scheme: %s", scheme)
+	}
+
+	return "x-scheme-handler/" + scheme, nil
+}
+
+// getPreferred returns the most preferred application of the MIME type for the current desktop.
+func getPreferred(mimeType string) (string, error) {
+	idPathMap, err := desktop.GetDesktopFiles(desktop.GetDesktopFileLocations())
+	if err != nil {
+		return "", err
+	}
+
+	currentDesktop, _, _ := strings.Cut(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	preferred := GetPreferredApplications(GetLists(currentDesktop), idPathMap)
+	if len(preferred[mimeType]) == 0 {
+		return "", nil
+	}
+
+	return preferred[mimeType][0], nil
+}
+
+// checkDesktopFile returns an error if there is no valid desktop file with the desktop ID.
+func checkDesktopFile(desktopId string) error {
+	_, path, err := desktop.LoadById(desktopId, nil)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return fmt.Errorf("desktop file %s not found", desktopId)
+	}
+
+	return nil
+}
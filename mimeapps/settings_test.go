@@ -0,0 +1,123 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// setupSettings creates a home with two browsers and a mail client.
+func setupSettings(t *testing.T) {
+	home := t.TempDir()
+	overrideEnv(t, map[string]string{
+		"XDG_CONFIG_HOME":     filepath.Join(home, ".config"),
+		"XDG_CONFIG_DIRS":     filepath.Join(home, "etc/xdg"),
+		"XDG_DATA_HOME":       filepath.Join(home, ".local/share"),
+		"XDG_DATA_DIRS":       filepath.Join(home, "usr/share"),
+		"XDG_CURRENT_DESKTOP": "",
+	})
+
+	apps := map[string]string{
+		"firefox.desktop":  "x-scheme-handler/http;x-scheme-handler/https;text/html;",
+		"chromium.desktop": "x-scheme-handler/http;x-scheme-handler/https;text/html;",
+		"mail.desktop":     "x-scheme-handler/mailto;",
+	}
+	dir := filepath.Join(home, "usr/share/applications")
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, mimeTypes := range apps {
+		err := os.WriteFile(
+			filepath.Join(dir, id),
+			[]byte("[Desktop Entry]\nType=Application\nName="+id+"\nExec=app %u\nMimeType="+mimeTypes+"\n"),
+			0644,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDefaultWebBrowser(t *testing.T) {
+	setupSettings(t)
+
+	err := SetDefaultWebBrowser("chromium.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	browser, err := GetDefaultWebBrowser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if browser != "chromium.desktop" {
+		t.Errorf("GetDefaultWebBrowser() = %s, expected: chromium.desktop", browser)
+	}
+
+	list, err := ParseFile(filepath.Join(basedir.ConfigHome, "mimeapps.list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mimeType := range browserMimeTypes {
+		if !slices.Equal(list.Default[mimeType], []string{"chromium.desktop"}) {
+			t.Errorf("Default %s = %v, expected: [chromium.desktop]", mimeType, list.Default[mimeType])
+		}
+	}
+
+	isDefault, err := CheckDefaultWebBrowser("chromium.desktop")
+	if err != nil || !isDefault {
+		t.Errorf("CheckDefaultWebBrowser(chromium) = %v, %v, expected: true", isDefault, err)
+	}
+
+	err = SetDefaultURLSchemeHandler("https", "firefox.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isDefault, err = CheckDefaultWebBrowser("chromium.desktop")
+	if err != nil || isDefault {
+		t.Errorf("CheckDefaultWebBrowser(chromium) = %v, %v, expected: false", isDefault, err)
+	}
+	isDefault, err = CheckDefaultWebBrowser("firefox.desktop")
+	if err != nil || !isDefault {
+		t.Errorf("CheckDefaultWebBrowser(firefox) = %v, %v, expected: true", isDefault, err)
+	}
+}
+
+func TestDefaultURLSchemeHandler(t *testing.T) {
+	setupSettings(t)
+
+	handler, err := GetDefaultURLSchemeHandler("mailto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler != "mail.desktop" {
+		t.Errorf("GetDefaultURLSchemeHandler(mailto) = %s, expected: mail.desktop", handler)
+	}
+
+	err = SetDefaultURLSchemeHandler("MAILTO", "firefox.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isDefault, err := CheckDefaultURLSchemeHandler("mailto", "firefox.desktop")
+	if err != nil || !isDefault {
+		t.Errorf("CheckDefaultURLSchemeHandler = %v, %v, expected: true", isDefault, err)
+	}
+
+	browser, err := GetDefaultWebBrowser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if browser == "firefox.desktop" {
+		t.Errorf("Setting the mailto handler changed the default web browser")
+	}
+
+	err = SetDefaultURLSchemeHandler("mailto", "missing.desktop")
+	if err == nil {
+		t.Errorf("SetDefaultURLSchemeHandler with a missing desktop file succeeded")
+	}
+}
@@ -0,0 +1,178 @@
+package mimeapps
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"github.com/MatthiasKunnen/xdg/internal/keyfile"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SetDefault makes the application with the desktop ID the default application of the MIME type,
+// like xdg-mime default. The desktop ID is put first in the [Default Applications] of
+// $XDG_CONFIG_HOME/mimeapps.list, before the defaults that were already listed, and added to its
+// [Added Associations] so that the application is associated even if its desktop file does not
+// list the MIME type. The desktop ID is removed from the [Removed Associations].
+//
+// The file is created if it does not exist. Other content, including comments, is preserved
+// and the file is replaced atomically.
+func SetDefault(mimeType string, desktopId string) error {
+	err := checkMimeType(mimeType)
+	if err != nil {
+		return fmt.Errorf("SetDefault: %w", err)
+	}
+
+	err = checkDesktopFile(desktopId)
+	if err != nil {
+		return fmt.Errorf("SetDefault: %w", err)
+	}
+
+	err = setUserDefault(mimeType, desktopId)
+	if err != nil {
+		return fmt.Errorf("SetDefault: %w", err)
+	}
+
+	return nil
+}
+
+// AddAssociation associates the application with the desktop ID with the MIME type, which makes
+// it available to open files of the type even if its desktop file does not list the MIME type.
+// The desktop ID is appended to the [Added Associations] of $XDG_CONFIG_HOME/mimeapps.list,
+// unless it is already listed, and removed from its [Removed Associations].
+//
+// The file is created if it does not exist. Other content is preserved and the file is replaced
+// atomically.
+func AddAssociation(mimeType string, desktopId string) error {
+	err := checkMimeType(mimeType)
+	if err != nil {
+		return fmt.Errorf("AddAssociation: %w", err)
+	}
+
+	err = checkDesktopFile(desktopId)
+	if err != nil {
+		return fmt.Errorf("AddAssociation: %w", err)
+	}
+
+	err = updateUserList(mimeType, func(lists map[string][]string) {
+		if !slices.Contains(lists[addedGroup], desktopId) {
+			lists[addedGroup] = append(lists[addedGroup], desktopId)
+		}
+		lists[removedGroup] = deleteDesktopId(lists[removedGroup], desktopId)
+	})
+	if err != nil {
+		return fmt.Errorf("AddAssociation: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveAssociation removes the association of the application with the desktop ID with the
+// MIME type, also if the association comes from the MimeType key of its desktop file.
+// The desktop ID is removed from the [Default Applications] and [Added Associations] of
+// $XDG_CONFIG_HOME/mimeapps.list and appended to its [Removed Associations], unless it is
+// already listed. Keys that become empty are removed.
+//
+// The desktop file does not need to exist. The mimeapps.list file is created if it does not
+// exist, other content is preserved and the file is replaced atomically.
+func RemoveAssociation(mimeType string, desktopId string) error {
+	err := checkMimeType(mimeType)
+	if err != nil {
+		return fmt.Errorf("RemoveAssociation: %w", err)
+	}
+
+	if desktopId == "" {
+		return fmt.Errorf("RemoveAssociation: empty desktop ID")
+	}
+
+	err = updateUserList(mimeType, func(lists map[string][]string) {
+		lists[defaultGroup] = deleteDesktopId(lists[defaultGroup], desktopId)
+		lists[addedGroup] = deleteDesktopId(lists[addedGroup], desktopId)
+		if !slices.Contains(lists[removedGroup], desktopId) {
+			lists[removedGroup] = append(lists[removedGroup], desktopId)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("RemoveAssociation: %w", err)
+	}
+
+	return nil
+}
+
+// setUserDefault puts the desktop ID first in the defaults of the MIME type in
+// $XDG_CONFIG_HOME/mimeapps.list, adds it to the added associations and removes it from the
+// removed associations.
+func setUserDefault(mimeType string, desktopId string) error {
+	return updateUserList(mimeType, func(lists map[string][]string) {
+		lists[defaultGroup] = append([]string{desktopId}, lists[defaultGroup]...)
+		lists[addedGroup] = append([]string{desktopId}, lists[addedGroup]...)
+		lists[removedGroup] = deleteDesktopId(lists[removedGroup], desktopId)
+	})
+}
+
+// updateUserList calls update with the desktop IDs of the MIME type in each group of
+// $XDG_CONFIG_HOME/mimeapps.list and writes the groups that changed. Duplicates are removed.
+func updateUserList(mimeType string, update func(lists map[string][]string)) error {
+	path := filepath.Join(basedir.ConfigHome, "mimeapps.list")
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	parsed, err := ParseFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	original := map[string][]string{
+		defaultGroup: removeDuplicates(parsed.Default[mimeType]),
+		addedGroup:   removeDuplicates(parsed.Added[mimeType]),
+		removedGroup: removeDuplicates(parsed.Removed[mimeType]),
+	}
+	lists := make(map[string][]string, len(original))
+	for group, ids := range original {
+		lists[group] = slices.Clone(ids)
+	}
+
+	update(lists)
+
+	changed := false
+	for _, group := range []string{defaultGroup, addedGroup, removedGroup} {
+		ids := removeDuplicates(lists[group])
+		switch {
+		case slices.Equal(ids, original[group]):
+			continue
+		case len(ids) == 0:
+			content = keyfile.RemoveKey(content, group, mimeType)
+		default:
+			content = keyfile.SetKey(content, group, mimeType, joinDesktopIds(ids))
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return fileutil.WriteFileAtomic(path, content, 0644)
+}
+
+// deleteDesktopId returns ids without the desktop ID.
+func deleteDesktopId(ids []string, desktopId string) []string {
+	return slices.DeleteFunc(ids, func(id string) bool {
+		return id == desktopId
+	})
+}
+
+// checkMimeType returns an error if the MIME type cannot be used as key in a mimeapps.list file.
+func checkMimeType(mimeType string) error {
+	media, subtype, found := strings.Cut(mimeType, "/")
+	if !found || media == "" || subtype == "" || strings.ContainsAny(mimeType, "[]=;\n\r\t ") {
+		return fmt.Errorf("invalid MIME type: %q", mimeType)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package mimeapps
+
+import (
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetDefault(t *testing.T) {
+	setupSettings(t)
+
+	path := filepath.Join(basedir.ConfigHome, "mimeapps.list")
+	err := SetDefault("text/html", "chromium.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, []byte(`# Managed by hand
+[Default Applications]
+text/html=chromium.desktop;
+image/png=viewer.desktop;
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SetDefault("text/html", "firefox.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# Managed by hand\n") {
+		t.Errorf("comment was not preserved:\n%s", content)
+	}
+
+	list, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"text/html": {"firefox.desktop", "chromium.desktop"},
+		"image/png": {"viewer.desktop"},
+	}
+	if diff := cmp.Diff(expected, list.Default); diff != "" {
+		t.Errorf("Default mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"firefox.desktop"}, list.Added["text/html"]); diff != "" {
+		t.Errorf("Added mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetDefaultInvalid(t *testing.T) {
+	setupSettings(t)
+
+	for _, mimeType := range []string{"", "text", "text/", "text/html;x", "text/html=x"} {
+		if err := SetDefault(mimeType, "firefox.desktop"); err == nil {
+			t.Errorf("SetDefault(%q) returned no error", mimeType)
+		}
+	}
+
+	if err := SetDefault("text/html", "missing.desktop"); err == nil {
+		t.Errorf("SetDefault() of missing desktop file returned no error")
+	}
+
+	_, err := os.Stat(filepath.Join(basedir.ConfigHome, "mimeapps.list"))
+	if !os.IsNotExist(err) {
+		t.Errorf("mimeapps.list was written, error: %v", err)
+	}
+}
+
+func TestAddRemoveAssociation(t *testing.T) {
+	setupSettings(t)
+
+	path := filepath.Join(basedir.ConfigHome, "mimeapps.list")
+	err := os.MkdirAll(basedir.ConfigHome, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(`[Default Applications]
+text/plain=mail.desktop;firefox.desktop;
+
+[Added Associations]
+text/plain=mail.desktop;
+
+[Removed Associations]
+text/plain=chromium.desktop;
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"chromium.desktop", "firefox.desktop", "chromium.desktop"} {
+		err = AddAssociation("text/plain", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := MimeApps{
+		Default: map[string][]string{"text/plain": {"mail.desktop", "firefox.desktop"}},
+		Added: map[string][]string{
+			"text/plain": {"mail.desktop", "chromium.desktop", "firefox.desktop"},
+		},
+		Removed: map[string][]string{},
+	}
+	if diff := cmp.Diff(expected, list); diff != "" {
+		t.Errorf("AddAssociation mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, id := range []string{"mail.desktop", "uninstalled.desktop", "mail.desktop"} {
+		err = RemoveAssociation("text/plain", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err = ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = MimeApps{
+		Default: map[string][]string{"text/plain": {"firefox.desktop"}},
+		Added:   map[string][]string{"text/plain": {"chromium.desktop", "firefox.desktop"}},
+		Removed: map[string][]string{
+			"text/plain": {"mail.desktop", "uninstalled.desktop"},
+		},
+	}
+	if diff := cmp.Diff(expected, list); diff != "" {
+		t.Errorf("RemoveAssociation mismatch (-want +got):\n%s", diff)
+	}
+
+	err = SetDefault("text/plain", "mail.desktop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = MimeApps{
+		Default: map[string][]string{"text/plain": {"mail.desktop", "firefox.desktop"}},
+		Added: map[string][]string{
+			"text/plain": {"mail.desktop", "chromium.desktop", "firefox.desktop"},
+		},
+		Removed: map[string][]string{"text/plain": {"uninstalled.desktop"}},
+	}
+	if diff := cmp.Diff(expected, list); diff != "" {
+		t.Errorf("SetDefault after RemoveAssociation mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := AddAssociation("text/plain", "missing.desktop"); err == nil {
+		t.Errorf("AddAssociation() of missing desktop file returned no error")
+	}
+}
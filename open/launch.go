@@ -0,0 +1,55 @@
+package open
+
+import (
+	"context"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"github.com/MatthiasKunnen/xdg/terminal"
+	"strings"
+)
+
+// launch starts the application of the desktop entry with the target. Applications with
+// DBusActivatable=true are opened using D-Bus, if that fails, their Exec key is used.
+func launch(
+	ctx context.Context,
+	desktopId string,
+	entry *desktop.Entry,
+	entryPath string,
+	target Target,
+	opts Options,
+) error {
+	opts.ActivationToken = activationToken(ctx, strings.TrimSuffix(desktopId, ".desktop"), opts)
+
+	_, err := entry.Launch(ctx, desktop.LaunchOptions{
+		DesktopId: desktopId,
+		// File URIs are converted to paths for the %f and %F field codes. Applications that only
+		// accept files are given other URIs as is, many of them support it.
+		Targets:             []string{target.URI},
+		DesktopFileLocation: entryPath,
+		Terminal:            opts.Terminal,
+		ResolveTerminal:     terminal.Resolver(terminal.Options{DesktopFiles: opts.DesktopFiles}),
+		ActivationToken:     opts.ActivationToken,
+	})
+	return err
+}
+
+// activationToken returns Options.ActivationToken or, if empty, a new token of the
+// TokenProvider. Failing to obtain a token does not prevent the launch, the application is then
+// started without one.
+func activationToken(ctx context.Context, appId string, opts Options) string {
+	if opts.ActivationToken != "" || opts.TokenProvider == nil {
+		return opts.ActivationToken
+	}
+
+	token, err := opts.TokenProvider(ctx, appId)
+	if err != nil {
+		logging.Logger().Warn(
+			"Failed to obtain an activation token",
+			logging.DesktopId, appId+".desktop",
+			logging.Error, err,
+		)
+		return ""
+	}
+
+	return token
+}
@@ -0,0 +1,188 @@
+// Package open opens files and URIs with the preferred application of the user, like xdg-open.
+//
+// The handler is resolved using the MIME type of the target, see Open for the exact steps.
+package open
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/activation"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/mimeapps"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"os"
+	"strings"
+)
+
+// ErrNoHandler is returned, wrapped in NoHandlerError, when no application can open a target.
+var ErrNoHandler = errors.New("no application found")
+
+// NoHandlerError is returned when no application can open the target.
+type NoHandlerError struct {
+	Target string
+
+	// MimeType of the target, e.g. text/plain or x-scheme-handler/https.
+	MimeType string
+
+	// LaunchErrors are the errors of the applications that were found but failed to launch.
+	LaunchErrors []error
+}
+
+func (e *NoHandlerError) Error() string {
+	message := fmt.Sprintf("%v for %s (%s)", ErrNoHandler, e.Target, e.MimeType)
+	if len(e.LaunchErrors) > 0 {
+		message += ": " + errors.Join(e.LaunchErrors...).Error()
+	}
+
+	return message
+}
+
+func (e *NoHandlerError) Unwrap() error {
+	return ErrNoHandler
+}
+
+// Options configure Open.
+type Options struct {
+	// Desktop is used to include desktop specific mimeapps.list files such as
+	// gnome-mimeapps.list. If empty, the first desktop of $XDG_CURRENT_DESKTOP is used.
+	Desktop string
+
+	// MimeDatabase is used to determine the MIME type of files. If nil, the database is loaded
+	// from the standard locations.
+	MimeDatabase *sharedmimeinfo.Database
+
+	// DesktopFiles maps desktop IDs to their files. If nil, the standard locations are scanned.
+	DesktopFiles desktop.IdPathMap
+
+	// Terminal is the command used to run applications with Terminal=true, the command of the
+	// application is appended, e.g. []string{"xterm", "-e"}. If empty, the preferred terminal
+	// emulator is used, see terminal.Preferred. If there is none, such applications are skipped.
+	Terminal []string
+
+	// ActivationToken, if set, allows the application to take focus. It is passed as
+	// XDG_ACTIVATION_TOKEN and DESKTOP_STARTUP_ID to started applications and as platform data
+	// to D-Bus activated applications.
+	ActivationToken string
+
+	// TokenProvider obtains an activation token for each launched application when
+	// ActivationToken is empty, e.g. activation.Default(). If nil, no token is passed.
+	TokenProvider activation.TokenProvider
+
+	// Backend selects whether the target is opened directly or using the OpenURI portal. The
+	// default, BackendAuto, uses the portal when running in a Flatpak or Snap sandbox.
+	Backend Backend
+}
+
+// Open opens the target, a path or URI, with the preferred application.
+//
+// The handler is resolved as follows:
+//  1. The target is classified using Classify. Files use the MIME type of the shared MIME-info
+//     database, other URIs use x-scheme-handler/<scheme>.
+//  2. The MIME type and its broader types, e.g. text/x-csrc followed by text/plain, are
+//     considered in order. For each, the preferred applications of the mimeapps.list files are
+//     tried, default applications first.
+//  3. The first application that launches successfully is used. Applications that cannot be
+//     loaded or launched are skipped. Applications with DBusActivatable=true are opened using
+//     the org.freedesktop.Application interface, falling back to their Exec key.
+//
+// If no application could open the target, a *NoHandlerError is returned which matches
+// ErrNoHandler.
+// The application is started in the background, Open does not wait for it to exit.
+//
+// In a Flatpak or Snap sandbox, host applications cannot be started directly. There, the
+// target is passed to the OpenURI portal instead, see Options.Backend.
+func Open(ctx context.Context, target string, opts Options) error {
+	classified, err := Classify(target)
+	if err != nil {
+		return fmt.Errorf("Open: %w", err)
+	}
+
+	if opts.Backend.usePortal() {
+		opts.ActivationToken = activationToken(ctx, "", opts)
+		err = openPortal(ctx, classified, opts)
+		if err != nil {
+			return fmt.Errorf("Open: %w", err)
+		}
+		return nil
+	}
+
+	db := opts.MimeDatabase
+	if db == nil {
+		db, err = sharedmimeinfo.LoadContext(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("Open: %w", err)
+		}
+	}
+
+	mimeType, err := mimeTypeOf(ctx, classified, db)
+	if err != nil {
+		return fmt.Errorf("Open: %w", err)
+	}
+
+	idPathMap := opts.DesktopFiles
+	if idPathMap == nil {
+		idPathMap, err = desktop.GetDesktopFilesContext(ctx, desktop.GetDesktopFileLocations())
+		if err != nil {
+			return fmt.Errorf("Open: %w", err)
+		}
+	}
+
+	currentDesktop := opts.Desktop
+	if currentDesktop == "" {
+		currentDesktop, _, _ = strings.Cut(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	}
+
+	preferred, err := mimeapps.GetPreferredApplicationsContext(
+		ctx,
+		mimeapps.GetLists(currentDesktop),
+		idPathMap,
+	)
+	if err != nil {
+		return fmt.Errorf("Open: %w", err)
+	}
+
+	var launchErrors []error
+	tried := make(map[string]bool)
+
+	for _, candidateType := range db.BroaderDfs(mimeType) {
+		for _, desktopId := range preferred[candidateType] {
+			if tried[desktopId] {
+				continue
+			}
+			tried[desktopId] = true
+
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("Open: %w", err)
+			}
+
+			// Applications deleted using Hidden=true are skipped, LoadEffective returns an error
+			entry, path, err := idPathMap.LoadEffective(desktopId)
+			if err != nil || entry == nil || entry.Hidden {
+				continue
+			}
+
+			err = launch(ctx, desktopId, entry, path, classified, opts)
+			if err != nil {
+				launchErrors = append(launchErrors, fmt.Errorf("%s: %w", desktopId, err))
+				continue
+			}
+
+			return nil
+		}
+	}
+
+	return &NoHandlerError{
+		Target:       target,
+		MimeType:     mimeType,
+		LaunchErrors: launchErrors,
+	}
+}
+
+func mimeTypeOf(ctx context.Context, target Target, db *sharedmimeinfo.Database) (string, error) {
+	if target.Kind == KindURI {
+		return "x-scheme-handler/" + target.Scheme, nil
+	}
+
+	return db.TypeByFileContext(ctx, target.Path)
+}
@@ -0,0 +1,259 @@
+package open
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"github.com/MatthiasKunnen/xdg/internal/dbus/dbustest"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createRecorder creates an application that writes its arguments to the returned file.
+func createRecorder(t *testing.T, home string, id string, mimeTypes string) string {
+	output := filepath.Join(home, id+".out")
+	script := filepath.Join(home, id+".sh")
+	testenv.WriteExecutable(t, script, "#!/bin/sh\necho \"$@\" > "+output+".tmp && mv "+output+".tmp "+output+"\n")
+
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", id),
+		"[Desktop Entry]\nType=Application\nName="+id+"\nExec="+script+" %u\nMimeType="+mimeTypes+"\n",
+	)
+
+	return output
+}
+
+func waitForFile(t *testing.T, path string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return string(content)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not created", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected Target
+	}{
+		{"/tmp/a b.txt", Target{KindFile, "/tmp/a b.txt", "file:///tmp/a%20b.txt", "file"}},
+		{"file:///tmp/a%20b.txt", Target{KindFile, "/tmp/a b.txt", "file:///tmp/a%20b.txt", "file"}},
+		{"HTTPS://example.com/", Target{KindURI, "", "HTTPS://example.com/", "https"}},
+		{"mailto:someone@example.com", Target{KindURI, "", "mailto:someone@example.com", "mailto"}},
+	}
+
+	for _, test := range tests {
+		actual, err := Classify(test.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Errorf("Classify(%s) = %+v, expected: %+v", test.target, actual, test.expected)
+		}
+	}
+
+	_, err := Classify("file://remote/file")
+	if err == nil {
+		t.Errorf("expected an error for a remote file URI")
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	home := testenv.Setup(t)
+	testenv.WriteFile(t, filepath.Join(basedir.DataHome, "mime/globs2"), "50:text/x-foo:*.foo\n")
+	output := createRecorder(t, home, "editor.desktop", "text/plain;")
+
+	file := filepath.Join(home, "notes.foo")
+	testenv.WriteFile(t, file, "hello")
+
+	err := Open(context.Background(), file, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := waitForFile(t, output); actual != "file://"+file+"\n" {
+		t.Errorf("editor received %q, expected: %q", actual, "file://"+file+"\n")
+	}
+}
+
+func TestOpenDefault(t *testing.T) {
+	home := testenv.Setup(t)
+	createRecorder(t, home, "a-browser.desktop", "x-scheme-handler/https;")
+	output := createRecorder(t, home, "b-browser.desktop", "x-scheme-handler/https;")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.ConfigHome, "mimeapps.list"),
+		"[Default Applications]\nx-scheme-handler/https=b-browser.desktop\n",
+	)
+
+	err := Open(context.Background(), "https://example.com", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := waitForFile(t, output); actual != "https://example.com\n" {
+		t.Errorf("browser received %q", actual)
+	}
+}
+
+func TestOpenHiddenOverride(t *testing.T) {
+	home := testenv.Setup(t)
+	hiddenOutput := createRecorder(t, home, "a-browser.desktop", "x-scheme-handler/https;")
+	output := createRecorder(t, home, "b-browser.desktop", "x-scheme-handler/https;")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.ConfigHome, "mimeapps.list"),
+		"[Default Applications]\nx-scheme-handler/https=a-browser.desktop\n",
+	)
+
+	// The user deleted the system-wide a-browser.desktop
+	systemPath := filepath.Join(home, "usr/share/applications/a-browser.desktop")
+	err := os.MkdirAll(filepath.Dir(systemPath), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Rename(filepath.Join(basedir.DataHome, "applications/a-browser.desktop"), systemPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(systemPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications/a-browser.desktop"),
+		string(content)+"Hidden=true\n",
+	)
+
+	err = Open(context.Background(), "https://example.com", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := waitForFile(t, output); actual != "https://example.com\n" {
+		t.Errorf("browser received %q", actual)
+	}
+	if _, err := os.Stat(hiddenOutput); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("hidden browser was launched: %v", err)
+	}
+}
+
+func TestOpenNoHandler(t *testing.T) {
+	testenv.Setup(t)
+
+	err := Open(context.Background(), "myapp://action", Options{})
+	if !errors.Is(err, ErrNoHandler) {
+		t.Fatalf("expected ErrNoHandler, got: %v", err)
+	}
+
+	var noHandler *NoHandlerError
+	if !errors.As(err, &noHandler) || noHandler.MimeType != "x-scheme-handler/myapp" {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestOpenDBusActivatable(t *testing.T) {
+	testenv.Setup(t)
+	bus := dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		return "", nil, nil
+	})
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address)
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", "org.example.Browser.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Browser\nDBusActivatable=true\n"+
+			"MimeType=x-scheme-handler/https;\n",
+	)
+
+	err := Open(context.Background(), "https://example.com", Options{ActivationToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Received %d calls, expected: 1", len(calls))
+	}
+	if calls[0].Path != "/org/example/Browser" || calls[0].Member != "Open" {
+		t.Errorf("Call = %s %s, expected: /org/example/Browser Open", calls[0].Path, calls[0].Member)
+	}
+
+	expected := []any{
+		[]any{"https://example.com"},
+		map[string]any{
+			"desktop-startup-id": dbus.Variant{Signature: "s", Value: "token"},
+			"activation-token":   dbus.Variant{Signature: "s", Value: "token"},
+		},
+	}
+	if diff := cmp.Diff(expected, calls[0].Body); diff != "" {
+		t.Errorf("Open body mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestOpenDBusActivatableFallback(t *testing.T) {
+	home := testenv.Setup(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+filepath.Join(home, "missing-bus"))
+	output := createRecorder(t, home, "org.example.Browser.desktop", "x-scheme-handler/https;")
+	path := filepath.Join(basedir.DataHome, "applications", "org.example.Browser.desktop")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testenv.WriteFile(t, path, string(content)+"DBusActivatable=true\n")
+
+	err = Open(context.Background(), "https://example.com", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := waitForFile(t, output); actual != "https://example.com\n" {
+		t.Errorf("browser received %q", actual)
+	}
+}
+
+func TestOpenTokenProvider(t *testing.T) {
+	testenv.Setup(t)
+	bus := dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		return "", nil, nil
+	})
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address)
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", "org.example.Browser.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Browser\nDBusActivatable=true\n"+
+			"MimeType=x-scheme-handler/https;\n",
+	)
+
+	err := Open(context.Background(), "https://example.com", Options{
+		TokenProvider: func(ctx context.Context, appId string) (string, error) {
+			return "token-" + appId, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Received %d calls, expected: 1", len(calls))
+	}
+	platformData, _ := calls[0].Body[1].(map[string]any)
+	expected := dbus.Variant{Signature: "s", Value: "token-org.example.Browser"}
+	if actual := platformData["activation-token"]; actual != expected {
+		t.Errorf("activation-token = %v, expected: %v", actual, expected)
+	}
+}
@@ -0,0 +1,165 @@
+package open
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"os"
+	"strings"
+)
+
+const (
+	portalBusName   = "org.freedesktop.portal.Desktop"
+	portalPath      = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	openURIIface    = "org.freedesktop.portal.OpenURI"
+	requestIface    = "org.freedesktop.portal.Request"
+	requestPathBase = "/org/freedesktop/portal/desktop/request/"
+)
+
+// ErrCancelled is returned when the user cancelled opening the target in the dialog of the
+// portal.
+var ErrCancelled = errors.New("opening was cancelled")
+
+// Backend selects how targets are opened.
+type Backend int
+
+const (
+	// BackendAuto uses BackendPortal when running in a sandbox, as reported by
+	// basedir.Sandbox, and BackendDirect otherwise.
+	BackendAuto Backend = iota
+
+	// BackendDirect resolves the application using the mimeapps.list files and starts it.
+	BackendDirect
+
+	// BackendPortal asks the OpenURI portal to open the target. The portal picks the
+	// application, possibly asking the user. Options other than ActivationToken are ignored.
+	BackendPortal
+)
+
+func (b Backend) usePortal() bool {
+	switch b {
+	case BackendPortal:
+		return true
+	case BackendDirect:
+		return false
+	default:
+		return basedir.InSandbox()
+	}
+}
+
+// openPortal opens the target using the org.freedesktop.portal.OpenURI portal. Local files are
+// passed using OpenFile, other URIs using OpenURI.
+func openPortal(ctx context.Context, target Target, opts Options) error {
+	conn, err := dbus.SessionBus(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	token, err := handleToken()
+	if err != nil {
+		return err
+	}
+
+	// The request path is predictable, subscribing before the call prevents missing a response
+	// that is sent before the call returns.
+	sender := strings.ReplaceAll(strings.TrimPrefix(conn.UniqueName, ":"), ".", "_")
+	requestPath := dbus.ObjectPath(requestPathBase + sender + "/" + token)
+	err = conn.AddMatch(ctx, responseRule(requestPath))
+	if err != nil {
+		return err
+	}
+	signals, unsubscribe := conn.Subscribe()
+	defer unsubscribe()
+
+	options := map[string]dbus.Variant{
+		"handle_token": {Signature: "s", Value: token},
+	}
+	if opts.ActivationToken != "" {
+		options["activation_token"] = dbus.Variant{Signature: "s", Value: opts.ActivationToken}
+	}
+
+	call := &dbus.Message{
+		Path:        portalPath,
+		Interface:   openURIIface,
+		Destination: portalBusName,
+	}
+	if target.Kind == KindFile {
+		file, err := os.Open(target.Path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		call.Member = "OpenFile"
+		call.Signature = "sha{sv}"
+		call.Body = []any{"", uint32(0), options}
+		call.UnixFDs = []int{int(file.Fd())}
+	} else {
+		call.Member = "OpenURI"
+		call.Signature = "ssa{sv}"
+		call.Body = []any{"", target.URI, options}
+	}
+
+	body, err := conn.CallMessage(ctx, call)
+	if err != nil {
+		return err
+	}
+	if len(body) == 1 {
+		// Old portal versions ignore handle_token
+		if handle, ok := body[0].(dbus.ObjectPath); ok && handle != requestPath {
+			requestPath = handle
+			err = conn.AddMatch(ctx, responseRule(requestPath))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != requestPath || signal.Interface != requestIface ||
+				signal.Member != "Response" || len(signal.Body) == 0 {
+				continue
+			}
+
+			switch signal.Body[0] {
+			case uint32(0):
+				return nil
+			case uint32(1):
+				return ErrCancelled
+			default:
+				return fmt.Errorf("portal failed to open %s", target.URI)
+			}
+		case <-conn.Done():
+			return dbus.ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// responseRule returns the match rule of the Response signal of the request.
+func responseRule(requestPath dbus.ObjectPath) string {
+	return fmt.Sprintf(
+		"type='signal',interface='%s',member='Response',path='%s'",
+		requestIface,
+		requestPath,
+	)
+}
+
+// handleToken returns a random token for the handle_token option of a portal request.
+func handleToken() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "xdg_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,115 @@
+package open
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/dbus"
+	"github.com/MatthiasKunnen/xdg/internal/dbus/dbustest"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// startPortal starts a fake OpenURI portal which responds to requests with the given response
+// code.
+func startPortal(t *testing.T, response uint32) *dbustest.Bus {
+	var bus *dbustest.Bus
+	bus = dbustest.NewBus(t, func(call *dbus.Message) (string, []any, *dbus.Error) {
+		options := call.Body[2].(map[string]any)
+		token := options["handle_token"].(dbus.Variant).Value.(string)
+		sender := strings.ReplaceAll(call.Sender[1:], ".", "_")
+		handle := dbus.ObjectPath(requestPathBase + sender + "/" + token)
+
+		// The response is sent before the reply, clients must subscribe before calling
+		bus.Emit(&dbus.Message{
+			Path:      handle,
+			Interface: requestIface,
+			Member:    "Response",
+			Signature: "ua{sv}",
+			Body:      []any{response, map[string]dbus.Variant{}},
+		})
+
+		return "o", []any{handle}, nil
+	})
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.Address)
+
+	return bus
+}
+
+func TestOpenPortalURI(t *testing.T) {
+	testenv.Setup(t)
+	bus := startPortal(t, 0)
+
+	err := Open(context.Background(), "https://example.com", Options{
+		Backend:         BackendPortal,
+		ActivationToken: "token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Received %d calls, expected: 1", len(calls))
+	}
+	if calls[0].Member != "OpenURI" || calls[0].Body[1] != "https://example.com" {
+		t.Errorf(
+			"Call = %s(%v), expected: OpenURI with https://example.com",
+			calls[0].Member,
+			calls[0].Body,
+		)
+	}
+
+	options := calls[0].Body[2].(map[string]any)
+	if token := options["activation_token"]; token != (dbus.Variant{Signature: "s", Value: "token"}) {
+		t.Errorf("activation_token = %v, expected: token", token)
+	}
+}
+
+func TestOpenPortalFile(t *testing.T) {
+	home := testenv.Setup(t)
+	bus := startPortal(t, 0)
+	file := filepath.Join(home, "notes.txt")
+	testenv.WriteFile(t, file, "hello")
+
+	err := Open(context.Background(), file, Options{Backend: BackendPortal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("Received %d calls, expected: 1", len(calls))
+	}
+	if calls[0].Member != "OpenFile" || calls[0].Signature != "sha{sv}" {
+		t.Errorf("Call = %s(%s), expected: OpenFile(sha{sv})", calls[0].Member, calls[0].Signature)
+	}
+}
+
+func TestOpenPortalCancelled(t *testing.T) {
+	testenv.Setup(t)
+	startPortal(t, 1)
+
+	err := Open(context.Background(), "https://example.com", Options{Backend: BackendPortal})
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Open error = %v, expected: %v", err, ErrCancelled)
+	}
+}
+
+func TestOpenPortalAuto(t *testing.T) {
+	testenv.Setup(t)
+	bus := startPortal(t, 0)
+	t.Setenv("FLATPAK_ID", "org.example.App")
+	basedir.Reinit()
+
+	err := Open(context.Background(), "https://example.com", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bus.Calls()) != 1 {
+		t.Errorf("Portal received %d calls, expected: 1", len(bus.Calls()))
+	}
+}
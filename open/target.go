@@ -0,0 +1,87 @@
+package open
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of target to open.
+type Kind int
+
+const (
+	// KindFile is a local file, given as path or file:// URI.
+	KindFile Kind = iota
+
+	// KindURI is a URI with a scheme other than file, e.g. https://example.com.
+	KindURI
+)
+
+// schemeRegex matches the scheme of a URI as defined by RFC 3986.
+var schemeRegex = regexp.MustCompile("^([a-zA-Z][a-zA-Z0-9+.-]*):")
+
+// Target is a classified target to open.
+type Target struct {
+	Kind Kind
+
+	// Path is the absolute path of a local file. Empty for KindURI.
+	Path string
+
+	// URI of the target. For local files, this is the file:// URI.
+	URI string
+
+	// Scheme of the URI in lowercase, e.g. https.
+	Scheme string
+}
+
+// Classify determines whether target is a local file or a URI. Targets without scheme are
+// treated as paths relative to the working directory.
+func Classify(target string) (Target, error) {
+	if target == "" {
+		return Target{}, fmt.Errorf("Classify: target is empty")
+	}
+
+	match := schemeRegex.FindStringSubmatch(target)
+	// A single letter scheme is a drive letter on Windows, e.g. C:\file.txt
+	if match == nil || (len(match[1]) == 1 && filepath.Separator == '\\') {
+		path, err := filepath.Abs(target)
+		if err != nil {
+			return Target{}, fmt.Errorf("Classify: failed to make %s absolute: %w", target, err)
+		}
+
+		uri, err := fileuri.FromPath(path)
+		if err != nil {
+			return Target{}, fmt.Errorf("Classify: %w", err)
+		}
+
+		return Target{
+			Kind:   KindFile,
+			Path:   path,
+			URI:    uri,
+			Scheme: "file",
+		}, nil
+	}
+
+	scheme := strings.ToLower(match[1])
+	if scheme != "file" {
+		return Target{
+			Kind:   KindURI,
+			URI:    target,
+			Scheme: scheme,
+		}, nil
+	}
+
+	path, err := fileuri.ToPath(target)
+	if err != nil {
+		return Target{}, fmt.Errorf("Classify: %w", err)
+	}
+
+	return Target{
+		Kind:   KindFile,
+		Path:   path,
+		URI:    target,
+		Scheme: "file",
+	}, nil
+}
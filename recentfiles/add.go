@@ -0,0 +1,98 @@
+package recentfiles
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Visit describes an access of a file by an application.
+type Visit struct {
+	// URI of the file, e.g. file:///home/user/report.txt.
+	URI string
+
+	// MimeType of the file.
+	MimeType string
+
+	// AppName is the name of the application that accessed the file, e.g. gedit.
+	AppName string
+
+	// AppExec is the command line used to open the file with the application, e.g. 'gedit %u'.
+	// If empty, '<AppName> %u' is used.
+	AppExec string
+
+	// Groups to add the entry to.
+	Groups []string
+
+	// Private marks the entry as only to be shown to the applications that registered it.
+	Private bool
+}
+
+// Add records the visit in the recently-used.xbel file of the user.
+// If the file is already present, the visit is merged with the existing entry.
+// The file is updated using Update.
+func Add(visit Visit) error {
+	now := time.Now()
+	err := Update(func(entries []Entry) ([]Entry, error) {
+		return Merge(entries, visit, now)
+	})
+	if err != nil {
+		return fmt.Errorf("Add: %w", err)
+	}
+
+	return nil
+}
+
+// Merge records the visit at the given time in entries and returns the result.
+// An existing entry with the same URI has its timestamps updated, the count of the application
+// incremented, and the groups of the visit added. Otherwise, a new entry is appended.
+func Merge(entries []Entry, visit Visit, now time.Time) ([]Entry, error) {
+	if visit.URI == "" {
+		return nil, fmt.Errorf("visit has no URI")
+	}
+	if visit.AppName == "" {
+		return nil, fmt.Errorf("visit of %s has no application name", visit.URI)
+	}
+
+	exec := visit.AppExec
+	if exec == "" {
+		exec = "'" + visit.AppName + " %u'"
+	}
+
+	index := slices.IndexFunc(entries, func(e Entry) bool {
+		return e.URI == visit.URI
+	})
+	if index == -1 {
+		entries = append(entries, Entry{URI: visit.URI, Added: now})
+		index = len(entries) - 1
+	}
+
+	entry := &entries[index]
+	entry.Modified = now
+	entry.Visited = now
+	entry.Private = entry.Private || visit.Private
+	if visit.MimeType != "" {
+		entry.MimeType = visit.MimeType
+	}
+
+	for _, group := range visit.Groups {
+		if !entry.HasGroup(group) {
+			entry.Groups = append(entry.Groups, group)
+		}
+	}
+
+	appIndex := slices.IndexFunc(entry.Applications, func(a Application) bool {
+		return a.Name == visit.AppName
+	})
+	if appIndex == -1 {
+		entry.Applications = append(entry.Applications, Application{Name: visit.AppName})
+		appIndex = len(entry.Applications) - 1
+	}
+
+	app := &entry.Applications[appIndex]
+	app.Exec = exec
+	app.Modified = now
+	app.Count++
+
+	return entries, nil
+}
@@ -0,0 +1,93 @@
+package recentfiles
+
+import (
+	"bytes"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	entries, err := ParseFile("testdata/recently-used.xbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries[0].Description = `Quotes " & <tags>`
+
+	var buf bytes.Buffer
+	err = Encode(&buf, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(entries, actual); diff != "" {
+		t.Errorf("round trip mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	testenv.Setup(t)
+
+	visit := Visit{
+		URI:      "file:///home/user/a.txt",
+		MimeType: "text/plain",
+		AppName:  "editor",
+		Groups:   []string{"Development"},
+	}
+
+	err := Add(visit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visit.Groups = []string{"Development", "Notes"}
+	err = Add(visit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Add(Visit{URI: "file:///home/user/b.png", AppName: "viewer", AppExec: "'viewer %f'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.Added.After(first.Modified) || first.Modified.IsZero() {
+		t.Errorf("unexpected timestamps, added: %v, modified: %v", first.Added, first.Modified)
+	}
+
+	expectedApps := []Application{{Name: "editor", Exec: "'editor %u'", Count: 2}}
+	ignoreTime := cmp.Comparer(func(a, b time.Time) bool { return true })
+	if diff := cmp.Diff(expectedApps, first.Applications, ignoreTime); diff != "" {
+		t.Errorf("Applications mismatch (-expected +actual):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"Development", "Notes"}, first.Groups); diff != "" {
+		t.Errorf("Groups mismatch (-expected +actual):\n%s", diff)
+	}
+
+	if entries[1].Applications[0].Exec != "'viewer %f'" {
+		t.Errorf("Exec = %s, expected: 'viewer %%f'", entries[1].Applications[0].Exec)
+	}
+}
+
+func TestMergeRequiresAppName(t *testing.T) {
+	_, err := Merge(nil, Visit{URI: "file:///a"}, time.Now())
+	if err == nil {
+		t.Errorf("expected an error for a visit without application name")
+	}
+}
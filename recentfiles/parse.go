@@ -0,0 +1,203 @@
+package recentfiles
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	bookmarkNamespace = "http://www.freedesktop.org/standards/desktop-bookmarks"
+	mimeNamespace     = "http://www.freedesktop.org/standards/shared-mime-info"
+	metadataOwner     = "http://freedesktop.org"
+)
+
+// xbelDocument is a recently-used.xbel file. The elements and attributes that this package does
+// not use are kept, so that Update can write them back unchanged.
+type xbelDocument struct {
+	Attrs     []xml.Attr     `xml:",any,attr"`
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+
+	// Other contains the elements other than bookmarks, e.g. info, title, and folder.
+	Other []xbelNode `xml:",any"`
+}
+
+type xbelBookmark struct {
+	Href        string     `xml:"href,attr"`
+	Added       string     `xml:"added,attr"`
+	Modified    string     `xml:"modified,attr"`
+	Visited     string     `xml:"visited,attr"`
+	Attrs       []xml.Attr `xml:",any,attr"`
+	Title       string     `xml:"title"`
+	Description string     `xml:"desc"`
+	Info        xbelInfo   `xml:"info"`
+	Other       []xbelNode `xml:",any"`
+}
+
+type xbelInfo struct {
+	Metadata []xbelMetadata `xml:"metadata"`
+	Other    []xbelNode     `xml:",any"`
+}
+
+type xbelMetadata struct {
+	Owner    string     `xml:"owner,attr"`
+	Attrs    []xml.Attr `xml:",any,attr"`
+	MimeType struct {
+		Type string `xml:"type,attr"`
+	} `xml:"http://www.freedesktop.org/standards/shared-mime-info mime-type"`
+	Groups       []string          `xml:"http://www.freedesktop.org/standards/desktop-bookmarks groups>group"`
+	Applications []xbelApplication `xml:"http://www.freedesktop.org/standards/desktop-bookmarks applications>application"`
+	Private      *struct{}         `xml:"http://www.freedesktop.org/standards/desktop-bookmarks private"`
+
+	// Other contains the elements not listed above, e.g. bookmark:icon.
+	Other []xbelNode `xml:",any"`
+
+	// Inner is the raw content, it is written back for metadata of other owners.
+	Inner string `xml:",innerxml"`
+}
+
+// xbelNode is an element that is kept as is.
+type xbelNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+type xbelApplication struct {
+	Name      string `xml:"name,attr"`
+	Exec      string `xml:"exec,attr"`
+	Modified  string `xml:"modified,attr"`
+	Timestamp string `xml:"timestamp,attr"`
+	Count     string `xml:"count,attr"`
+}
+
+// ParseFile parses the recently-used.xbel file at path.
+func ParseFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFile: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFile: failed to parse %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// Parse parses the contents of a recently-used.xbel file.
+// Bookmarks without href are skipped.
+func Parse(reader io.Reader) ([]Entry, error) {
+	doc, err := parseDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.entries(), nil
+}
+
+func parseDocument(reader io.Reader) (*xbelDocument, error) {
+	var doc xbelDocument
+	err := xml.NewDecoder(reader).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// loadDocument parses the recently-used.xbel file at path. If the file does not exist, nil and no
+// error are returned.
+func loadDocument(path string) (*xbelDocument, error) {
+	file, err := os.Open(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	defer file.Close()
+
+	doc, err := parseDocument(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+func (d *xbelDocument) entries() []Entry {
+	result := make([]Entry, 0, len(d.Bookmarks))
+	for _, bookmark := range d.Bookmarks {
+		if bookmark.Href == "" {
+			continue
+		}
+
+		entry := Entry{
+			URI:         bookmark.Href,
+			Title:       strings.TrimSpace(bookmark.Title),
+			Description: strings.TrimSpace(bookmark.Description),
+			Added:       parseTime(bookmark.Added),
+			Modified:    parseTime(bookmark.Modified),
+			Visited:     parseTime(bookmark.Visited),
+		}
+
+		for _, metadata := range bookmark.Info.Metadata {
+			if metadata.Owner != metadataOwner {
+				continue
+			}
+
+			entry.MimeType = metadata.MimeType.Type
+			entry.Private = metadata.Private != nil
+			for _, group := range metadata.Groups {
+				if group = strings.TrimSpace(group); group != "" {
+					entry.Groups = append(entry.Groups, group)
+				}
+			}
+
+			for _, app := range metadata.Applications {
+				entry.Applications = append(entry.Applications, app.toApplication())
+			}
+		}
+
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+func (a xbelApplication) toApplication() Application {
+	result := Application{
+		Name: a.Name,
+		Exec: a.Exec,
+	}
+
+	if a.Modified != "" {
+		result.Modified = parseTime(a.Modified)
+	} else if seconds, err := strconv.ParseInt(a.Timestamp, 10, 64); err == nil {
+		// Older versions of GLib stored the time in seconds since the epoch
+		result.Modified = time.Unix(seconds, 0).UTC()
+	}
+
+	result.Count, _ = strconv.Atoi(a.Count)
+
+	return result
+}
+
+// parseTime parses an ISO 8601 timestamp as used in XBEL files. The zero time is returned for
+// empty or invalid values.
+func parseTime(value string) time.Time {
+	result, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return result
+}
@@ -0,0 +1,89 @@
+package recentfiles
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DefaultMaxAge is the default maximum age of entries used by GTK.
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+// PruneOptions determine which entries are removed by Prune.
+type PruneOptions struct {
+	// MaxItems is the maximum number of entries to keep. The most recently modified entries are
+	// kept. Zero or less means no limit.
+	MaxItems int
+
+	// MaxAge is the maximum time since the last modification of an entry. Older entries are
+	// removed. Zero or less means no limit.
+	MaxAge time.Duration
+}
+
+// Prune removes the entries exceeding the limits from the recently-used.xbel file of the user.
+// The file is updated using Update.
+func Prune(opts PruneOptions) error {
+	now := time.Now()
+	err := Update(func(entries []Entry) ([]Entry, error) {
+		return PruneEntries(entries, opts, now), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Prune: %w", err)
+	}
+
+	return nil
+}
+
+// PruneEntries returns the entries that do not exceed the limits at time now.
+// The order of the remaining entries is preserved.
+func PruneEntries(entries []Entry, opts PruneOptions, now time.Time) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if opts.MaxAge > 0 && now.Sub(entry.Modified) > opts.MaxAge {
+			continue
+		}
+		result = append(result, entry)
+	}
+
+	if opts.MaxItems <= 0 || len(result) <= opts.MaxItems {
+		return result
+	}
+
+	byRecency := slices.Clone(result)
+	slices.SortStableFunc(byRecency, func(a, b Entry) int {
+		return b.Modified.Compare(a.Modified)
+	})
+
+	keep := make(map[string]bool, opts.MaxItems)
+	for _, entry := range byRecency[:opts.MaxItems] {
+		keep[entry.URI] = true
+	}
+
+	return slices.DeleteFunc(result, func(e Entry) bool {
+		return !keep[e.URI]
+	})
+}
+
+// IsVisibleTo returns true if the entry may be shown to the application. Private entries are
+// only visible to the applications that registered them.
+func (e Entry) IsVisibleTo(appName string) bool {
+	if !e.Private {
+		return true
+	}
+
+	_, registered := e.Application(appName)
+	return registered
+}
+
+// VisibleTo returns the entries that may be shown to the application.
+// See Entry.IsVisibleTo.
+func VisibleTo(entries []Entry, appName string) []Entry {
+	var result []Entry
+	for _, entry := range entries {
+		if entry.IsVisibleTo(appName) {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
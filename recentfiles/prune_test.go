@@ -0,0 +1,89 @@
+package recentfiles
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"slices"
+	"testing"
+	"time"
+)
+
+func entryURIs(entries []Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.URI)
+	}
+
+	return result
+}
+
+func TestPruneEntries(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{URI: "a", Modified: now.Add(-40 * 24 * time.Hour)},
+		{URI: "b", Modified: now.Add(-2 * time.Hour)},
+		{URI: "c", Modified: now.Add(-1 * time.Hour)},
+		{URI: "d", Modified: now.Add(-3 * time.Hour)},
+	}
+
+	actual := entryURIs(PruneEntries(entries, PruneOptions{MaxAge: DefaultMaxAge}, now))
+	expected := []string{"b", "c", "d"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("PruneEntries(MaxAge) = %v, expected: %v", actual, expected)
+	}
+
+	actual = entryURIs(PruneEntries(entries, PruneOptions{MaxItems: 2}, now))
+	expected = []string{"b", "c"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("PruneEntries(MaxItems) = %v, expected: %v", actual, expected)
+	}
+
+	actual = entryURIs(PruneEntries(entries, PruneOptions{}, now))
+	if len(actual) != len(entries) {
+		t.Errorf("PruneEntries without limits removed entries: %v", actual)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	testenv.Setup(t)
+
+	old := time.Now().Add(-2 * DefaultMaxAge)
+	err := WriteFile(Path(), []Entry{
+		{URI: "file:///old", Added: old, Modified: old, Visited: old},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Add(Visit{URI: "file:///new", AppName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Prune(PruneOptions{MaxAge: DefaultMaxAge})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := entryURIs(entries)
+	if !slices.Equal(actual, []string{"file:///new"}) {
+		t.Errorf("entries after Prune = %v, expected: [file:///new]", actual)
+	}
+}
+
+func TestVisibleTo(t *testing.T) {
+	entries, err := ParseFile("testdata/recently-used.xbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(VisibleTo(entries, "gedit")); got != 1 {
+		t.Errorf("VisibleTo(gedit) returned %d entries, expected: 1", got)
+	}
+
+	if got := len(VisibleTo(entries, "eog")); got != 2 {
+		t.Errorf("VisibleTo(eog) returned %d entries, expected: 2", got)
+	}
+}
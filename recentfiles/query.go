@@ -0,0 +1,149 @@
+package recentfiles
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SortOrder determines the order of the entries returned by a Query.
+type SortOrder int
+
+const (
+	// SortNone keeps the order of the file.
+	SortNone SortOrder = iota
+
+	// SortModified sorts the most recently modified entries first.
+	SortModified
+
+	// SortVisited sorts the most recently visited entries first.
+	SortVisited
+
+	// SortAdded sorts the most recently added entries first.
+	SortAdded
+
+	// SortURI sorts the entries by URI.
+	SortURI
+)
+
+// Query selects recently used files. Empty fields do not filter.
+type Query struct {
+	// MimeTypes the entry must match one of. A type can end with /* to match all subtypes,
+	// e.g. image/*.
+	MimeTypes []string
+
+	// Groups of which the entry must belong to at least one.
+	Groups []string
+
+	// AppName is the name of the application that must have registered the entry.
+	AppName string
+
+	// VisibleTo removes the private entries not registered by this application.
+	// See Entry.IsVisibleTo.
+	VisibleTo string
+
+	// ExistingOnly removes entries of local files that no longer exist. Entries of non-local
+	// URIs are kept.
+	ExistingOnly bool
+
+	// Sort is the order of the result.
+	Sort SortOrder
+
+	// Limit is the maximum number of entries to return. Zero or less means no limit.
+	Limit int
+}
+
+// Find returns the entries of the recently-used.xbel file of the user that match the query.
+func Find(query Query) ([]Entry, error) {
+	entries, err := Load()
+	if err != nil {
+		return nil, fmt.Errorf("Find: %w", err)
+	}
+
+	return query.Filter(entries), nil
+}
+
+// Filter returns the entries that match the query, sorted and limited.
+func (q Query) Filter(entries []Entry) []Entry {
+	var result []Entry
+	for _, entry := range entries {
+		if q.Matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	switch q.Sort {
+	case SortModified:
+		slices.SortStableFunc(result, func(a, b Entry) int {
+			return b.Modified.Compare(a.Modified)
+		})
+	case SortVisited:
+		slices.SortStableFunc(result, func(a, b Entry) int {
+			return b.Visited.Compare(a.Visited)
+		})
+	case SortAdded:
+		slices.SortStableFunc(result, func(a, b Entry) int {
+			return b.Added.Compare(a.Added)
+		})
+	case SortURI:
+		slices.SortStableFunc(result, func(a, b Entry) int {
+			return strings.Compare(a.URI, b.URI)
+		})
+	}
+
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+
+	return result
+}
+
+// Matches returns true if the entry matches the filters of the query.
+func (q Query) Matches(entry Entry) bool {
+	if len(q.MimeTypes) > 0 && !slices.ContainsFunc(q.MimeTypes, func(pattern string) bool {
+		return matchMimeType(pattern, entry.MimeType)
+	}) {
+		return false
+	}
+
+	if len(q.Groups) > 0 && !slices.ContainsFunc(q.Groups, entry.HasGroup) {
+		return false
+	}
+
+	if q.AppName != "" {
+		if _, registered := entry.Application(q.AppName); !registered {
+			return false
+		}
+	}
+
+	if q.VisibleTo != "" && !entry.IsVisibleTo(q.VisibleTo) {
+		return false
+	}
+
+	if q.ExistingOnly && !exists(entry.URI) {
+		return false
+	}
+
+	return true
+}
+
+func matchMimeType(pattern string, mimeType string) bool {
+	if prefix, isWildcard := strings.CutSuffix(pattern, "/*"); isWildcard {
+		return strings.HasPrefix(mimeType, prefix+"/")
+	}
+
+	return pattern == mimeType
+}
+
+// exists returns false if the URI refers to a local file that does not exist.
+func exists(uri string) bool {
+	path, err := fileuri.ToPath(uri)
+	if err != nil {
+		return true
+	}
+
+	_, err = os.Stat(path)
+	return err == nil
+}
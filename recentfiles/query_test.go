@@ -0,0 +1,89 @@
+package recentfiles
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestQueryFilter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{
+			URI:          "file:///a.png",
+			MimeType:     "image/png",
+			Modified:     now.Add(-3 * time.Hour),
+			Applications: []Application{{Name: "viewer"}},
+			Groups:       []string{"Graphics"},
+		},
+		{
+			URI:          "file:///b.txt",
+			MimeType:     "text/plain",
+			Modified:     now.Add(-1 * time.Hour),
+			Applications: []Application{{Name: "editor"}},
+		},
+		{
+			URI:          "file:///c.jpg",
+			MimeType:     "image/jpeg",
+			Modified:     now.Add(-2 * time.Hour),
+			Applications: []Application{{Name: "editor"}},
+			Private:      true,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"all", Query{}, []string{"file:///a.png", "file:///b.txt", "file:///c.jpg"}},
+		{"images", Query{MimeTypes: []string{"image/*"}}, []string{"file:///a.png", "file:///c.jpg"}},
+		{"exact mime", Query{MimeTypes: []string{"text/plain"}}, []string{"file:///b.txt"}},
+		{"group", Query{Groups: []string{"Graphics"}}, []string{"file:///a.png"}},
+		{"app", Query{AppName: "editor"}, []string{"file:///b.txt", "file:///c.jpg"}},
+		{"visible", Query{VisibleTo: "viewer"}, []string{"file:///a.png", "file:///b.txt"}},
+		{
+			"sorted and limited",
+			Query{Sort: SortModified, Limit: 2},
+			[]string{"file:///b.txt", "file:///c.jpg"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := entryURIs(test.query.Filter(entries))
+			if !slices.Equal(actual, test.expected) {
+				t.Errorf("Filter = %v, expected: %v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestQueryExistingOnly(t *testing.T) {
+	home := testenv.Setup(t)
+	existing := filepath.Join(home, "exists.txt")
+	err := os.WriteFile(existing, nil, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{"file://" + existing, "file://" + home + "/gone.txt", "https://example.com/"} {
+		err = Add(Visit{URI: uri, AppName: "app"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Find(Query{ExistingOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"file://" + existing, "https://example.com/"}
+	if actual := entryURIs(entries); !slices.Equal(actual, expected) {
+		t.Errorf("Find = %v, expected: %v", actual, expected)
+	}
+}
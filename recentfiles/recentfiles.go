@@ -0,0 +1,107 @@
+// Package recentfiles implements reading and writing of the list of recently used files,
+// recently-used.xbel, as described by the desktop bookmark specification.
+// The file uses the XBEL format extended with bookmark and shared-mime-info metadata and is
+// shared by all applications of the user.
+//
+// See https://www.freedesktop.org/wiki/Specifications/desktop-bookmark-spec/.
+package recentfiles
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const fileName = "recently-used.xbel"
+
+// Entry is a recently used file.
+type Entry struct {
+	// URI of the file, e.g. file:///home/user/report.txt.
+	URI string
+
+	// Title and Description of the bookmark, usually empty.
+	Title       string
+	Description string
+
+	// MimeType of the file.
+	MimeType string
+
+	// Added is the time the entry was first added.
+	Added time.Time
+
+	// Modified is the time the entry was last modified.
+	Modified time.Time
+
+	// Visited is the time the file was last visited.
+	Visited time.Time
+
+	// Applications that registered the file.
+	Applications []Application
+
+	// Groups the entry belongs to, e.g. gedit or Graphics.
+	Groups []string
+
+	// Private is true if the entry should only be shown to the applications that registered
+	// it.
+	Private bool
+}
+
+// Application is an application that registered a recently used file.
+type Application struct {
+	// Name of the application, e.g. gedit.
+	Name string
+
+	// Exec is the command line used to open the file with the application, e.g. 'gedit %u'.
+	// %u is replaced with the URI and %f with the path of the file.
+	Exec string
+
+	// Modified is the last time the application registered the file.
+	Modified time.Time
+
+	// Count is the number of times the application registered the file.
+	Count int
+}
+
+// Path returns the location of recently-used.xbel: $XDG_DATA_HOME/recently-used.xbel.
+func Path() string {
+	return filepath.Join(basedir.DataHome, fileName)
+}
+
+// Load returns the entries of the recently-used.xbel file of the user.
+// If the file does not exist, no entries and no error are returned.
+func Load() ([]Entry, error) {
+	entries, err := ParseFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Load: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Application returns the application with the given name that registered the entry.
+func (e Entry) Application(name string) (Application, bool) {
+	for _, app := range e.Applications {
+		if app.Name == name {
+			return app, true
+		}
+	}
+
+	return Application{}, false
+}
+
+// HasGroup returns true if the entry belongs to the group.
+func (e Entry) HasGroup(group string) bool {
+	for _, g := range e.Groups {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package recentfiles
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseFile(t *testing.T) {
+	entries, err := ParseFile("testdata/recently-used.xbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Entry{
+		{
+			URI:      "file:///home/user/report%20final.txt",
+			MimeType: "text/plain",
+			Added:    time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.UTC),
+			Modified: time.Date(2024, 3, 2, 11, 0, 0, 500000000, time.UTC),
+			Visited:  time.Date(2024, 3, 2, 11, 0, 0, 0, time.UTC),
+			Applications: []Application{
+				{
+					Name:     "gedit",
+					Exec:     "'gedit %u'",
+					Modified: time.Date(2024, 3, 2, 11, 0, 0, 500000000, time.UTC),
+					Count:    3,
+				},
+				{
+					Name:     "Old App",
+					Exec:     "'oldapp %f'",
+					Modified: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+					Count:    1,
+				},
+			},
+			Groups: []string{"gedit"},
+		},
+		{
+			URI:      "file:///home/user/secret.png",
+			Title:    "Secret",
+			MimeType: "image/png",
+			Added:    time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+			Modified: time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+			Visited:  time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+			Applications: []Application{
+				{
+					Name:     "eog",
+					Exec:     "'eog %u'",
+					Modified: time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+					Count:    1,
+				},
+			},
+			Private: true,
+		},
+	}
+
+	if diff := cmp.Diff(expected, entries); diff != "" {
+		t.Errorf("ParseFile mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	testenv.Setup(t)
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Load = %v, expected no entries", entries)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	testenv.Setup(t)
+
+	data, err := os.ReadFile("testdata/recently-used.xbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(Path()), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(Path(), data, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %d", len(entries))
+	}
+
+	app, found := entries[0].Application("gedit")
+	if !found || app.Count != 3 {
+		t.Errorf("Application(gedit) = %v, %v", app, found)
+	}
+	if !entries[0].HasGroup("gedit") || entries[1].HasGroup("gedit") {
+		t.Errorf("HasGroup returned unexpected results")
+	}
+}
@@ -0,0 +1,97 @@
+package recentfiles
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"time"
+)
+
+// maxUpdateAttempts is the number of times Update retries when the file is changed by a writer
+// that does not use the lock.
+const maxUpdateAttempts = 5
+
+// ErrConcurrentModification is returned by Update when the file kept changing while the update
+// was being applied.
+var ErrConcurrentModification = errors.New("recently-used.xbel was modified concurrently")
+
+// Update applies fn to the entries of the recently-used.xbel file of the user and writes the
+// result back atomically. Elements and attributes that are not represented by Entry, such as
+// bookmark:icon and the metadata of other owners, are kept.
+//
+// Writers using this package are serialized using an advisory lock on
+// recently-used.xbel.lock. Other applications, such as GTK, do not use the lock. To not clobber
+// their changes, the file is checked for modifications before it is replaced. If it was modified,
+// the entries are read again and fn is reapplied.
+// fn may therefore be called multiple times and must not retain the entries.
+func Update(fn func(entries []Entry) ([]Entry, error)) error {
+	path := Path()
+	unlock, err := fileutil.Lock(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("Update: %w", err)
+	}
+	defer unlock()
+
+	for range maxUpdateAttempts {
+		before, err := statFile(path)
+		if err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+
+		doc, err := loadDocument(path)
+		if err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+
+		var entries []Entry
+		if doc != nil {
+			entries = doc.entries()
+		}
+
+		entries, err = fn(entries)
+		if err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+
+		after, err := statFile(path)
+		if err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+		if before != after {
+			continue
+		}
+
+		err = writeDocument(path, entries, doc)
+		if err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Update: %w", ErrConcurrentModification)
+}
+
+// fileState identifies a version of a file.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) (fileState, error) {
+	stat, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return fileState{}, nil
+	case err != nil:
+		return fileState{}, err
+	}
+
+	return fileState{
+		exists:  true,
+		size:    stat.Size(),
+		modTime: stat.ModTime(),
+	}, nil
+}
@@ -0,0 +1,141 @@
+package recentfiles
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateConcurrent(t *testing.T) {
+	testenv.Setup(t)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Add(Visit{URI: "file:///file" + strconv.Itoa(i), AppName: "app"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != writers {
+		t.Errorf("expected %d entries, got: %d", writers, len(entries))
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	testenv.Setup(t)
+
+	err := Add(Visit{URI: "file:///a", AppName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Add(Visit{URI: "file:///b"})
+	if err == nil {
+		t.Fatal("expected an error for a visit without application name")
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("failed update changed the file, entries: %v", entries)
+	}
+}
+
+func TestUpdateKeepsUnknownContent(t *testing.T) {
+	testenv.Setup(t)
+
+	content, err := os.ReadFile("testdata/gtk-recently-used.xbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(Path()), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(Path(), content, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{"file:///home/user/photo.jpg", "file:///home/user/new.txt"} {
+		err = Add(Visit{URI: uri, AppName: "viewer"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	written, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`xmlns:ex="http://example.com/ns"`,
+		`<title>Recently used files</title>`,
+		`<metadata owner="http://example.com/app"><ex:setting value="1"/></metadata>`,
+		`<folder><title>Unused folder</title></folder>`,
+		`ex:rating="5"`,
+		`<ex:note>Keep &amp; share</ex:note>`,
+		`<bookmark:icon href="file:///usr/share/icons/photo.png" type="image/png"></bookmark:icon>`,
+		`<mime:mime-type type="x-custom/owner"/>`,
+		`<ex:thumbnail path="/tmp/photo.png"/>`,
+	} {
+		if !strings.Contains(string(written), expected) {
+			t.Errorf("written file does not contain %s:\n%s", expected, written)
+		}
+	}
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %v", entries)
+	}
+
+	photo := entries[0]
+	if photo.MimeType != "image/jpeg" || !photo.Private || photo.Title != "Holiday" {
+		t.Errorf("photo entry changed: %+v", photo)
+	}
+	if _, found := photo.Application("eog"); !found {
+		t.Errorf("photo entry lost application eog: %+v", photo.Applications)
+	}
+	if _, found := photo.Application("viewer"); !found {
+		t.Errorf("photo entry has no application viewer: %+v", photo.Applications)
+	}
+
+	// A second update must not duplicate the kept content
+	err = Add(Visit{URI: "file:///home/user/new.txt", AppName: "viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(again), "<ex:note>"); count != 1 {
+		t.Errorf("<ex:note> occurs %d times, expected: 1", count)
+	}
+}
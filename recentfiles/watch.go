@@ -0,0 +1,159 @@
+package recentfiles
+
+import (
+	"context"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"github.com/MatthiasKunnen/xdg/internal/watch"
+	"os"
+	"reflect"
+	"time"
+)
+
+// DefaultDebounce is the debounce duration used by Watch if none is specified.
+const DefaultDebounce = 200 * time.Millisecond
+
+// EventType is the type of change to an entry.
+type EventType int
+
+const (
+	// EntryAdded means the entry was added to the file.
+	EntryAdded EventType = iota
+
+	// EntryRemoved means the entry was removed from the file.
+	EntryRemoved
+
+	// EntryChanged means the entry was modified, e.g. because the file was visited again.
+	EntryChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EntryAdded:
+		return "added"
+	case EntryRemoved:
+		return "removed"
+	case EntryChanged:
+		return "changed"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a change to an entry of recently-used.xbel.
+type Event struct {
+	Type EventType
+
+	// Entry is the new entry, or the old entry if it was removed.
+	Entry Entry
+}
+
+// Watch reports changes to the recently-used.xbel file of the user, including changes by other
+// applications. The file is reparsed after it has not changed for the debounce duration, after
+// which the differences with the previous version are sent as a single batch of events.
+// If debounce is zero, DefaultDebounce is used.
+//
+// The returned channel is closed when ctx is done.
+func Watch(ctx context.Context, debounce time.Duration) (<-chan []Event, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	err := os.MkdirAll(basedir.DataHome, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("Watch: failed to create %s: %w", basedir.DataHome, err)
+	}
+
+	path := Path()
+	watcher, err := watch.New([]string{basedir.DataHome})
+	if err != nil {
+		return nil, fmt.Errorf("Watch: %w", err)
+	}
+
+	entries, err := Load()
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("Watch: %w", err)
+	}
+
+	result := make(chan []Event)
+
+	go func() {
+		defer close(result)
+		defer watcher.Close()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case changed, ok := <-watcher.C:
+				if !ok {
+					return
+				}
+				if changed == path {
+					timer.Reset(debounce)
+				}
+				continue
+			case <-timer.C:
+			}
+
+			current, err := Load()
+			if err != nil {
+				logging.Logger().Warn(
+					"Failed to reload recent files",
+					logging.Path, path,
+					logging.Error, err,
+				)
+				continue
+			}
+
+			events := Diff(entries, current)
+			entries = current
+			if len(events) == 0 {
+				continue
+			}
+
+			select {
+			case result <- events:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+// Diff returns the events that transform the old entries into the new entries.
+// Entries are identified by their URI.
+func Diff(old []Entry, new []Entry) []Event {
+	oldByURI := make(map[string]Entry, len(old))
+	for _, entry := range old {
+		oldByURI[entry.URI] = entry
+	}
+
+	var result []Event
+	newURIs := make(map[string]bool, len(new))
+	for _, entry := range new {
+		newURIs[entry.URI] = true
+		oldEntry, found := oldByURI[entry.URI]
+		switch {
+		case !found:
+			result = append(result, Event{Type: EntryAdded, Entry: entry})
+		case !reflect.DeepEqual(oldEntry, entry):
+			result = append(result, Event{Type: EntryChanged, Entry: entry})
+		}
+	}
+
+	for _, entry := range old {
+		if !newURIs[entry.URI] {
+			result = append(result, Event{Type: EntryRemoved, Entry: entry})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,74 @@
+package recentfiles
+
+import (
+	"context"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := []Entry{{URI: "a"}, {URI: "b"}, {URI: "c", MimeType: "text/plain"}}
+	new := []Entry{{URI: "b"}, {URI: "c", MimeType: "text/html"}, {URI: "d"}}
+
+	events := Diff(old, new)
+	expected := []struct {
+		eventType EventType
+		uri       string
+	}{
+		{EntryChanged, "c"},
+		{EntryAdded, "d"},
+		{EntryRemoved, "a"},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Diff = %v, expected %d events", events, len(expected))
+	}
+	for i, event := range events {
+		if event.Type != expected[i].eventType || event.Entry.URI != expected[i].uri {
+			t.Errorf(
+				"event %d = %s %s, expected: %s %s",
+				i,
+				event.Type,
+				event.Entry.URI,
+				expected[i].eventType,
+				expected[i].uri,
+			)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	testenv.Setup(t)
+
+	err := Add(Visit{URI: "file:///a", AppName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := Watch(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Add(Visit{URI: "file:///b", AppName: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case batch := <-events:
+		if len(batch) != 1 || batch[0].Type != EntryAdded || batch[0].Entry.URI != "file:///b" {
+			t.Errorf("unexpected events: %v", batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no events received")
+	}
+
+	cancel()
+	for range events {
+	}
+}
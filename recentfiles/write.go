@@ -0,0 +1,282 @@
+package recentfiles
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// timeFormat is the format GLib uses to write timestamps.
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+// xmlNamespace is the namespace of the xml prefix, e.g. of xml:lang.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// Encode writes the entries in the recently-used.xbel format.
+func Encode(w io.Writer, entries []Entry) error {
+	return encodeDocument(w, entries, nil)
+}
+
+// encodeDocument writes the entries like Encode. The elements and attributes of doc that are not
+// represented by Entry are written as well, those of a bookmark are added to the entry with the
+// same URI. doc may be nil.
+func encodeDocument(w io.Writer, entries []Entry, doc *xbelDocument) error {
+	if doc == nil {
+		doc = &xbelDocument{}
+	}
+
+	names := newXMLNames(doc.Attrs)
+	bookmarks := make(map[string]*xbelBookmark, len(doc.Bookmarks))
+	for i := range doc.Bookmarks {
+		if _, found := bookmarks[doc.Bookmarks[i].Href]; !found {
+			bookmarks[doc.Bookmarks[i].Href] = &doc.Bookmarks[i]
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<xbel version="1.0"` + "\n")
+	bw.WriteString(`      xmlns:bookmark="` + bookmarkNamespace + `"` + "\n")
+	bw.WriteString(`      xmlns:mime="` + mimeNamespace + `"` + "\n")
+	for _, attr := range doc.Attrs {
+		switch {
+		case attr.Name.Space == "" && attr.Name.Local == "version":
+		case attr.Name.Space == "xmlns" &&
+			(attr.Name.Local == "bookmark" || attr.Name.Local == "mime"):
+		default:
+			bw.WriteString("      ")
+			names.writeAttr(bw, attr)
+			bw.WriteString("\n")
+		}
+	}
+	bw.WriteString(">\n")
+
+	for _, node := range doc.Other {
+		bw.WriteString("  ")
+		names.writeNode(bw, node)
+		bw.WriteString("\n")
+	}
+
+	for _, entry := range entries {
+		writeEntry(bw, entry, bookmarks[entry.URI], names)
+	}
+
+	bw.WriteString("</xbel>\n")
+
+	return bw.Flush()
+}
+
+// writeEntry writes the entry. If extra is non-nil, the elements and attributes of the bookmark
+// that are not represented by Entry are written as well.
+func writeEntry(w *bufio.Writer, entry Entry, extra *xbelBookmark, names xmlNames) {
+	if extra == nil {
+		extra = &xbelBookmark{}
+	}
+
+	w.WriteString(`  <bookmark href="` + escape(entry.URI) + `"`)
+	writeTimeAttr(w, "added", entry.Added)
+	writeTimeAttr(w, "modified", entry.Modified)
+	writeTimeAttr(w, "visited", entry.Visited)
+	for _, attr := range extra.Attrs {
+		w.WriteString(" ")
+		names.writeAttr(w, attr)
+	}
+	w.WriteString(">\n")
+
+	if entry.Title != "" {
+		w.WriteString("    <title>" + escape(entry.Title) + "</title>\n")
+	}
+	if entry.Description != "" {
+		w.WriteString("    <desc>" + escape(entry.Description) + "</desc>\n")
+	}
+
+	for _, node := range extra.Other {
+		w.WriteString("    ")
+		names.writeNode(w, node)
+		w.WriteString("\n")
+	}
+
+	// ownMetadata is the metadata of the freedesktop.org owner, which is replaced by the entry.
+	var ownMetadata xbelMetadata
+	for _, metadata := range extra.Info.Metadata {
+		if metadata.Owner == metadataOwner {
+			ownMetadata.Attrs = append(ownMetadata.Attrs, metadata.Attrs...)
+			ownMetadata.Other = append(ownMetadata.Other, metadata.Other...)
+		}
+	}
+
+	w.WriteString("    <info>\n")
+	w.WriteString(`      <metadata owner="` + metadataOwner + `"`)
+	for _, attr := range ownMetadata.Attrs {
+		w.WriteString(" ")
+		names.writeAttr(w, attr)
+	}
+	w.WriteString(">\n")
+
+	if entry.MimeType != "" {
+		w.WriteString(`        <mime:mime-type type="` + escape(entry.MimeType) + `"/>` + "\n")
+	}
+
+	if len(entry.Groups) > 0 {
+		w.WriteString("        <bookmark:groups>\n")
+		for _, group := range entry.Groups {
+			w.WriteString("          <bookmark:group>" + escape(group) + "</bookmark:group>\n")
+		}
+		w.WriteString("        </bookmark:groups>\n")
+	}
+
+	if len(entry.Applications) > 0 {
+		w.WriteString("        <bookmark:applications>\n")
+		for _, app := range entry.Applications {
+			w.WriteString(`          <bookmark:application name="` + escape(app.Name) + `"`)
+			w.WriteString(` exec="` + escape(app.Exec) + `"`)
+			writeTimeAttr(w, "modified", app.Modified)
+			w.WriteString(` count="` + strconv.Itoa(app.Count) + `"/>` + "\n")
+		}
+		w.WriteString("        </bookmark:applications>\n")
+	}
+
+	if entry.Private {
+		w.WriteString("        <bookmark:private/>\n")
+	}
+
+	for _, node := range ownMetadata.Other {
+		w.WriteString("        ")
+		names.writeNode(w, node)
+		w.WriteString("\n")
+	}
+
+	w.WriteString("      </metadata>\n")
+
+	for _, metadata := range extra.Info.Metadata {
+		if metadata.Owner == metadataOwner {
+			continue
+		}
+
+		w.WriteString(`      <metadata owner="` + escape(metadata.Owner) + `"`)
+		for _, attr := range metadata.Attrs {
+			w.WriteString(" ")
+			names.writeAttr(w, attr)
+		}
+		w.WriteString(">" + metadata.Inner + "</metadata>\n")
+	}
+
+	for _, node := range extra.Info.Other {
+		w.WriteString("      ")
+		names.writeNode(w, node)
+		w.WriteString("\n")
+	}
+
+	w.WriteString("    </info>\n")
+	w.WriteString("  </bookmark>\n")
+}
+
+// xmlNames converts the namespaces of parsed names back to the prefixes of the document.
+type xmlNames map[string]string
+
+// newXMLNames returns the prefixes of the namespaces declared on the root element.
+func newXMLNames(rootAttrs []xml.Attr) xmlNames {
+	names := xmlNames{
+		bookmarkNamespace: "bookmark",
+		mimeNamespace:     "mime",
+		xmlNamespace:      "xml",
+	}
+	for _, attr := range rootAttrs {
+		if attr.Name.Space == "xmlns" {
+			if _, found := names[attr.Value]; !found {
+				names[attr.Value] = attr.Name.Local
+			}
+		}
+	}
+
+	return names
+}
+
+// name returns the name as it is written in the document. The namespace of names whose prefix
+// was not declared is the prefix itself.
+func (n xmlNames) name(name xml.Name) string {
+	switch {
+	case name.Space == "":
+		return name.Local
+	case n[name.Space] != "":
+		return n[name.Space] + ":" + name.Local
+	default:
+		return name.Space + ":" + name.Local
+	}
+}
+
+func (n xmlNames) writeAttr(w *bufio.Writer, attr xml.Attr) {
+	if attr.Name.Space == "xmlns" {
+		w.WriteString("xmlns:" + attr.Name.Local)
+	} else {
+		w.WriteString(n.name(attr.Name))
+	}
+	w.WriteString(`="` + escape(attr.Value) + `"`)
+}
+
+// writeNode writes the element with its raw content.
+func (n xmlNames) writeNode(w *bufio.Writer, node xbelNode) {
+	name := node.XMLName.Local
+	declare := false
+	switch {
+	case node.XMLName.Space == "":
+	case n[node.XMLName.Space] != "":
+		name = n[node.XMLName.Space] + ":" + name
+	default:
+		// The namespace was declared as default namespace on the element or an ancestor
+		declare = !slices.ContainsFunc(node.Attrs, func(attr xml.Attr) bool {
+			return attr.Name.Space == "" && attr.Name.Local == "xmlns"
+		})
+	}
+
+	w.WriteString("<" + name)
+	if declare {
+		w.WriteString(` xmlns="` + escape(node.XMLName.Space) + `"`)
+	}
+	for _, attr := range node.Attrs {
+		w.WriteString(" ")
+		n.writeAttr(w, attr)
+	}
+	w.WriteString(">" + node.Inner + "</" + name + ">")
+}
+
+func writeTimeAttr(w *bufio.Writer, name string, value time.Time) {
+	if value.IsZero() {
+		return
+	}
+
+	w.WriteString(" " + name + `="` + value.UTC().Format(timeFormat) + `"`)
+}
+
+func escape(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// WriteFile atomically writes the entries to the file at path.
+func WriteFile(path string, entries []Entry) error {
+	return writeDocument(path, entries, nil)
+}
+
+// writeDocument atomically writes the entries to the file at path, see encodeDocument.
+func writeDocument(path string, entries []Entry, doc *xbelDocument) error {
+	var buf bytes.Buffer
+	err := encodeDocument(&buf, entries, doc)
+	if err != nil {
+		return fmt.Errorf("WriteFile: failed to encode entries: %w", err)
+	}
+
+	err = fileutil.WriteFileAtomic(path, buf.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package sharedmimeinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// TypeByFilename returns the MIME type of a file based on its name using the glob rules, or an
+// empty string if no glob matches.
+// Of the matching globs, the one with the highest weight wins, the longest pattern is used to
+// break ties. Globs without the cs flag match case-insensitively.
+// Patterns are matched like fnmatch(3) without flags, as update-mime-database and GLib do:
+// *, ? and bracket expressions, including negated ones such as [!a-z], are supported and a
+// backslash escapes the next character.
+func (db *Database) TypeByFilename(name string) string {
+	name = filepath.Base(name)
+	lowerName := strings.ToLower(name)
+
+	var best *glob
+	for i := range db.globs {
+		g := &db.globs[i]
+		candidate := lowerName
+		pattern := strings.ToLower(g.pattern)
+		if g.caseSensitive {
+			candidate = name
+			pattern = g.pattern
+		}
+
+		matched, err := path.Match(pattern, candidate)
+		if err != nil || !matched {
+			continue
+		}
+
+		if best == nil ||
+			g.weight > best.weight ||
+			(g.weight == best.weight && len(g.pattern) > len(best.pattern)) {
+			best = g
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.mimeType
+}
+
+// TypeByFile returns the MIME type of the file at path. Directories are inode/directory. Other
+// files are looked up by name and, if no glob matches, classified as text/plain or
+// application/octet-stream based on their contents.
+func (db *Database) TypeByFile(path string) (string, error) {
+	return db.TypeByFileContext(context.Background(), path)
+}
+
+// TypeByFileContext is like TypeByFile but returns the error of ctx if it is done before the
+// contents of the file are read.
+func (db *Database) TypeByFileContext(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("TypeByFile: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("TypeByFile: %w", err)
+	}
+
+	if stat.IsDir() {
+		return Directory, nil
+	}
+
+	if mimeType := db.TypeByFilename(path); mimeType != "" {
+		return mimeType, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("TypeByFile: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("TypeByFile: %w", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("TypeByFile: failed to read %s: %w", path, err)
+	}
+
+	if isText(head[:n]) {
+		return TextPlain, nil
+	}
+
+	return OctetStream, nil
+}
+
+// isText returns true if the data looks like text: it contains no NUL bytes and is valid UTF-8,
+// ignoring a rune that may be cut off at the end.
+func isText(data []byte) bool {
+	if bytes.IndexByte(data, 0) != -1 {
+		return false
+	}
+
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+
+	return true
+}
+
+// Canonical returns the canonical name of the MIME type if it is an alias, otherwise mimeType
+// is returned. A nil Database has no aliases.
+func (db *Database) Canonical(mimeType string) string {
+	if db == nil {
+		return mimeType
+	}
+
+	if canonical, found := db.aliases[mimeType]; found {
+		return canonical
+	}
+
+	return mimeType
+}
+
+// Parents returns the direct parents of the MIME type, including the implicit ones: every
+// text/* type is a subclass of text/plain and every type of a file's contents is a subclass of
+// application/octet-stream.
+// A nil Database has no subclasses, not even implicit ones, so the ancestors of a type can be
+// looked up without a database.
+func (db *Database) Parents(mimeType string) []string {
+	if db == nil {
+		return nil
+	}
+
+	mimeType = db.Canonical(mimeType)
+	result := append([]string{}, db.subclasses[mimeType]...)
+
+	media, _, _ := strings.Cut(mimeType, "/")
+	if media == "text" && mimeType != TextPlain && !containsString(result, TextPlain) {
+		result = append(result, TextPlain)
+	}
+
+	if media != "inode" && media != "x-scheme-handler" && mimeType != OctetStream &&
+		!containsString(result, OctetStream) && len(db.subclasses[mimeType]) == 0 {
+		result = append(result, OctetStream)
+	}
+
+	return result
+}
+
+// Icon returns the name of the icon of the MIME type, as defined in the icons file or else
+// derived from the type by replacing the slash with a dash, e.g. text-plain.
+func (db *Database) Icon(mimeType string) string {
+	mimeType = db.Canonical(mimeType)
+	if icon, found := db.icons[mimeType]; found {
+		return icon
+	}
+
+	return strings.ReplaceAll(mimeType, "/", "-")
+}
+
+// GenericIcon returns the name of the generic icon of the MIME type, used when the icon theme
+// has no icon for Icon. It is defined in the generic-icons file or else derived from the media
+// type, e.g. text-x-generic.
+func (db *Database) GenericIcon(mimeType string) string {
+	mimeType = db.Canonical(mimeType)
+	if icon, found := db.genericIcons[mimeType]; found {
+		return icon
+	}
+
+	media, _, _ := strings.Cut(mimeType, "/")
+	return media + "-x-generic"
+}
+
+// BroaderDfs returns the MIME type followed by all its ancestors, in depth-first order, without
+// duplicates. This is the order in which applications for the type should be considered.
+func (db *Database) BroaderDfs(mimeType string) []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	var visit func(string)
+	visit = func(t string) {
+		t = db.Canonical(t)
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		result = append(result, t)
+
+		for _, parent := range db.Parents(t) {
+			visit(parent)
+		}
+	}
+	visit(mimeType)
+
+	// application/octet-stream is the broadest type, consider it last
+	if i := indexOf(result, OctetStream); i != -1 && i != len(result)-1 {
+		result = append(append(result[:i], result[i+1:]...), OctetStream)
+	}
+
+	return result
+}
+
+func indexOf(list []string, value string) int {
+	for i, s := range list {
+		if s == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,234 @@
+// Package sharedmimeinfo implements lookups in the MIME database described by the
+// [Shared MIME-info Database specification]: determining the MIME type of a file by its name,
+// resolving aliases, and walking the subclass hierarchy.
+//
+// Only the generated files of the database are used: globs2, subclasses, aliases, icons, and
+// generic-icons.
+// Content sniffing using the magic file is not implemented, a simple text/binary heuristic is
+// used instead.
+//
+// [Shared MIME-info Database specification]: https://specifications.freedesktop.org/shared-mime-info-spec/0.21/
+package sharedmimeinfo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	OctetStream = "application/octet-stream"
+	TextPlain   = "text/plain"
+	Directory   = "inode/directory"
+)
+
+// Database holds the MIME information of one or more mime directories.
+type Database struct {
+	globs        []glob
+	subclasses   map[string][]string
+	aliases      map[string]string
+	icons        map[string]string
+	genericIcons map[string]string
+}
+
+type glob struct {
+	weight        int
+	mimeType      string
+	pattern       string
+	caseSensitive bool
+}
+
+// GetDirs returns the mime directories in order of precedence: $XDG_DATA_HOME/mime followed by
+// mime in each $XDG_DATA_DIRS.
+func GetDirs() []string {
+	result := []string{filepath.Join(basedir.DataHome, "mime")}
+
+	for _, dir := range basedir.DataDirs {
+		result = append(result, filepath.Join(dir, "mime"))
+	}
+
+	return result
+}
+
+// Load reads the MIME database from the given directories, in order of precedence. If dirs is
+// nil, GetDirs is used. Missing directories and files are skipped.
+func Load(dirs []string) (*Database, error) {
+	return LoadContext(context.Background(), dirs)
+}
+
+// LoadContext is like Load but stops when ctx is done, returning the error of ctx.
+func LoadContext(ctx context.Context, dirs []string) (*Database, error) {
+	if dirs == nil {
+		dirs = GetDirs()
+	}
+
+	db := &Database{
+		subclasses:   make(map[string][]string),
+		aliases:      make(map[string]string),
+		icons:        make(map[string]string),
+		genericIcons: make(map[string]string),
+	}
+
+	// Types of which the globs of directories with lower precedence are discarded using
+	// __NOGLOBS__
+	noGlobs := make(map[string]bool)
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
+		}
+
+		var dirGlobs []glob
+		err := readLines(filepath.Join(dir, "globs2"), func(line string) {
+			parts := strings.Split(line, ":")
+			if len(parts) < 3 {
+				return
+			}
+
+			weight, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return
+			}
+
+			dirGlobs = append(dirGlobs, glob{
+				weight:        weight,
+				mimeType:      parts[1],
+				pattern:       fnmatchToGo(parts[2]),
+				caseSensitive: len(parts) > 3 && strings.Contains(parts[3], "cs"),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
+		}
+
+		dirNoGlobs := make(map[string]bool)
+		for _, g := range dirGlobs {
+			if g.pattern == "__NOGLOBS__" {
+				dirNoGlobs[g.mimeType] = true
+				continue
+			}
+			if !noGlobs[g.mimeType] {
+				db.globs = append(db.globs, g)
+			}
+		}
+		for mimeType := range dirNoGlobs {
+			noGlobs[mimeType] = true
+		}
+
+		err = readLines(filepath.Join(dir, "subclasses"), func(line string) {
+			child, parent, found := strings.Cut(line, " ")
+			if found && !containsString(db.subclasses[child], parent) {
+				db.subclasses[child] = append(db.subclasses[child], parent)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
+		}
+
+		err = readLines(filepath.Join(dir, "aliases"), func(line string) {
+			alias, canonical, found := strings.Cut(line, " ")
+			if _, exists := db.aliases[alias]; found && !exists {
+				db.aliases[alias] = canonical
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Load: %w", err)
+		}
+
+		for name, icons := range map[string]map[string]string{
+			"icons":         db.icons,
+			"generic-icons": db.genericIcons,
+		} {
+			err = readLines(filepath.Join(dir, name), func(line string) {
+				mimeType, icon, found := strings.Cut(line, ":")
+				if _, exists := icons[mimeType]; found && !exists {
+					icons[mimeType] = icon
+				}
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Load: %w", err)
+			}
+		}
+	}
+
+	return db, nil
+}
+
+// fnmatchToGo converts the fnmatch(3) pattern to the syntax of path.Match, which negates bracket
+// expressions using ^ instead of !.
+func fnmatchToGo(pattern string) string {
+	var result strings.Builder
+	inBrackets := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			result.WriteByte(c)
+			i++
+			c = pattern[i]
+		case c == '[' && !inBrackets:
+			inBrackets = true
+			result.WriteByte(c)
+			if i+1 < len(pattern) && pattern[i+1] == '!' {
+				result.WriteByte('^')
+				i++
+			}
+			continue
+		case c == ']' && inBrackets:
+			inBrackets = false
+		}
+		result.WriteByte(c)
+	}
+
+	return result.String()
+}
+
+// readLines calls fn for every non-empty, non-comment line of the file at path. A missing file
+// is not an error.
+func readLines(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		logging.Logger().Warn(
+			"Skipping unreadable MIME database file",
+			logging.Path, path,
+			logging.Error, err,
+		)
+		return nil
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+
+	return false
+}
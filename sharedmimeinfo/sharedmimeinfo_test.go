@@ -0,0 +1,144 @@
+package sharedmimeinfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func loadTestDatabase(t *testing.T) *Database {
+	db, err := Load([]string{"testdata/user/mime", "testdata/system/mime", "testdata/missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestTypeByFilename(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	tests := map[string]string{
+		"main.c":             "text/x-csrc",
+		"main.C":             "text/x-c++src",
+		"MAIN.TXT":           "text/x-custom",
+		"/a/b/backup.tar.gz": "application/x-compressed-tar",
+		"file.gz":            "application/gzip",
+		"Makefile":           "text/x-makefile",
+		"image.png":          "",
+		"image.pic":          "image/png",
+		"unknown":            "",
+		"ls.1":               "text/troff",
+		"archive.a":          "",
+	}
+
+	for name, expected := range tests {
+		if actual := db.TypeByFilename(name); actual != expected {
+			t.Errorf("TypeByFilename(%s) = %s, expected: %s", name, actual, expected)
+		}
+	}
+}
+
+func TestTypeByFile(t *testing.T) {
+	db := loadTestDatabase(t)
+	dir := t.TempDir()
+
+	text := filepath.Join(dir, "README")
+	binary := filepath.Join(dir, "blob")
+	err := os.WriteFile(text, []byte("Hello, wörld\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(binary, []byte{0x89, 0x00, 0x01}, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		dir:    Directory,
+		text:   TextPlain,
+		binary: OctetStream,
+	}
+
+	for path, expected := range tests {
+		actual, err := db.TypeByFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf("TypeByFile(%s) = %s, expected: %s", path, actual, expected)
+		}
+	}
+}
+
+func TestBroaderDfs(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	tests := map[string][]string{
+		"text/x-c":               {"text/x-csrc", "text/plain", OctetStream},
+		"image/svg+xml":          {"image/svg+xml", "application/xml", "text/plain", OctetStream},
+		"text/x-makefile":        {"text/x-makefile", "text/plain", OctetStream},
+		"x-scheme-handler/https": {"x-scheme-handler/https"},
+		"inode/directory":        {"inode/directory"},
+	}
+
+	for mimeType, expected := range tests {
+		actual := db.BroaderDfs(mimeType)
+		if !slices.Equal(actual, expected) {
+			t.Errorf("BroaderDfs(%s) = %v, expected: %v", mimeType, actual, expected)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	if actual := db.Canonical("text/xml"); actual != "application/xml" {
+		t.Errorf("Canonical(text/xml) = %s, expected: application/xml", actual)
+	}
+
+	if actual := db.Canonical("image/png"); actual != "image/png" {
+		t.Errorf("Canonical(image/png) = %s, expected: image/png", actual)
+	}
+}
+
+func TestLoadContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LoadContext(ctx, []string{"testdata/user/mime"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+
+	_, err = loadTestDatabase(t).TypeByFileContext(ctx, "testdata")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestIcon(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	tests := []struct {
+		mimeType string
+		icon     string
+		generic  string
+	}{
+		{"application/x-compressed-tar", "package-x-compressed", "package-x-generic"},
+		{"text/x-c", "text-x-csrc", "text-x-script"},
+		{"image/png", "image-png", "image-x-generic"},
+	}
+
+	for _, test := range tests {
+		if actual := db.Icon(test.mimeType); actual != test.icon {
+			t.Errorf("Icon(%s) = %s, expected: %s", test.mimeType, actual, test.icon)
+		}
+		if actual := db.GenericIcon(test.mimeType); actual != test.generic {
+			t.Errorf("GenericIcon(%s) = %s, expected: %s", test.mimeType, actual, test.generic)
+		}
+	}
+}
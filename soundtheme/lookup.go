@@ -0,0 +1,206 @@
+package soundtheme
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LookupOptions configure LookupWithOptions.
+type LookupOptions struct {
+	// Theme is the ID of the theme of the user. If empty, FallbackTheme is used.
+	Theme string
+
+	// Locale, in the format lang_COUNTRY.ENCODING@MODIFIER, selects localized sounds, e.g. spoken
+	// ones. If empty, $LC_ALL, $LC_MESSAGES, or $LANG is used.
+	Locale string
+
+	// OutputProfile is the preferred output profile, e.g. 5.1. If empty or if no sound exists
+	// for it, DefaultOutputProfile is used.
+	OutputProfile string
+
+	// Dirs are the base directories. If nil, GetDirs is used.
+	Dirs []string
+}
+
+// Lookup returns the path of the sound file for the event sound with the given name in the
+// theme, using the locale of the environment.
+// See LookupWithOptions for the lookup order.
+func Lookup(name string, theme string) (string, error) {
+	return LookupWithOptions(name, LookupOptions{Theme: theme})
+}
+
+// LookupWithOptions returns the path of the sound file for the event sound with the given name,
+// e.g. message-new-instant.
+//
+// Sounds are searched in the following order, each level falling back to the next:
+//  1. The theme, the themes it inherits from, recursively, then FallbackTheme.
+//  2. The locale, its less specific variants, then the unlocalized sounds.
+//  3. The name, then the name with its last dash separated part removed, e.g.
+//     message-new-instant, message-new, message.
+//  4. The requested output profile, then DefaultOutputProfile.
+//
+// A stereo sound of the theme is therefore preferred over a sound of the requested output
+// profile in an inherited theme.
+//
+// Sound files are searched in <theme path>/<directory>[/<locale>]/<name><extension>, for each
+// directory of the theme matching the output profile and each extension in Extensions.
+// If a file with the .disabled extension is found, ErrDisabled is returned. If no sound is
+// found, ErrNotFound is returned.
+func LookupWithOptions(name string, opts LookupOptions) (string, error) {
+	dirs := opts.Dirs
+	if dirs == nil {
+		dirs = GetDirs()
+	}
+
+	themeId := opts.Theme
+	if themeId == "" {
+		themeId = FallbackTheme
+	}
+
+	locale := opts.Locale
+	if locale == "" {
+		locale = currentLocale()
+	}
+
+	profiles := []string{DefaultOutputProfile}
+	if opts.OutputProfile != "" && opts.OutputProfile != DefaultOutputProfile {
+		profiles = []string{opts.OutputProfile, DefaultOutputProfile}
+	}
+
+	themes := themeChain(themeId, dirs)
+	locales := append(localeVariants(locale), "")
+	names := nameVariants(name)
+
+	for _, theme := range themes {
+		for _, locale := range locales {
+			for _, name := range names {
+				for _, profile := range profiles {
+					path, err := findInTheme(theme, profile, locale, name)
+					if err != nil {
+						return "", fmt.Errorf("LookupWithOptions: %w", err)
+					}
+					if path != "" {
+						return path, nil
+					}
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("LookupWithOptions: %s: %w", name, ErrNotFound)
+}
+
+// themeChain returns the theme followed by the themes it inherits from, depth first, and
+// finally FallbackTheme. Missing themes are skipped.
+func themeChain(id string, dirs []string) []*Theme {
+	var result []*Theme
+	visited := make(map[string]bool)
+
+	var visit func(id string)
+	visit = func(id string) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+
+		theme, err := LoadTheme(id, dirs)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return
+		case err != nil:
+			logging.Logger().Warn("Skipping invalid sound theme", "theme", id, logging.Error, err)
+			return
+		}
+
+		result = append(result, theme)
+		for _, parent := range theme.Inherits {
+			visit(parent)
+		}
+	}
+
+	visit(id)
+	visit(FallbackTheme)
+
+	return result
+}
+
+func findInTheme(theme *Theme, profile string, locale string, name string) (string, error) {
+	for _, directory := range theme.Directories {
+		if directory.OutputProfile != profile {
+			continue
+		}
+
+		for _, themePath := range theme.Paths {
+			base := filepath.Join(themePath, directory.Name, locale, name)
+			if exists(base + ".disabled") {
+				return "", fmt.Errorf("%s: %w", name, ErrDisabled)
+			}
+
+			for _, extension := range Extensions {
+				if exists(base + extension) {
+					return base + extension, nil
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// nameVariants returns the name followed by the names obtained by repeatedly removing the last
+// dash separated part.
+func nameVariants(name string) []string {
+	result := []string{name}
+	for {
+		index := strings.LastIndexByte(name, '-')
+		if index <= 0 {
+			return result
+		}
+
+		name = name[:index]
+		result = append(result, name)
+	}
+}
+
+// localeVariants returns the variants of the locale from most to least specific, e.g.
+// de_DE@euro, de_DE, de@euro, de. The encoding is ignored. C and POSIX have no variants.
+func localeVariants(locale string) []string {
+	rest, modifier, _ := strings.Cut(locale, "@")
+	rest, _, _ = strings.Cut(rest, ".")
+	lang, country, _ := strings.Cut(rest, "_")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+
+	var result []string
+	if country != "" && modifier != "" {
+		result = append(result, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		result = append(result, lang+"_"+country)
+	}
+	if modifier != "" {
+		result = append(result, lang+"@"+modifier)
+	}
+
+	return append(result, lang)
+}
+
+func currentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,304 @@
+// Package soundtheme looks up event sounds, e.g. message-new-instant, in the installed sound
+// themes as defined by the [Sound Theme Specification] and the [Sound Naming Specification].
+//
+// [Sound Theme Specification]: https://specifications.freedesktop.org/sound-theme-spec/0.8
+// [Sound Naming Specification]: https://specifications.freedesktop.org/sound-naming-spec/0.7
+package soundtheme
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	indexFile = "index.theme"
+	mainGroup = "Sound Theme"
+
+	// FallbackTheme is the theme every theme implicitly inherits from.
+	FallbackTheme = "freedesktop"
+
+	// DefaultOutputProfile is the output profile of directories without OutputProfile key and
+	// the fallback for other profiles.
+	DefaultOutputProfile = "stereo"
+)
+
+var (
+	// ErrNotFound is returned when no theme or sound file exists for the requested name.
+	ErrNotFound = errors.New("sound not found")
+
+	// ErrDisabled is returned when the sound was disabled using a .disabled file.
+	ErrDisabled = errors.New("sound disabled")
+)
+
+// Extensions are the supported sound file extensions in order of preference. A .disabled file
+// takes precedence over all of them.
+var Extensions = []string{".oga", ".ogg", ".wav"}
+
+// Directory is a subdirectory of a theme containing sound files.
+type Directory struct {
+	// Name is the path of the directory relative to the theme directory, e.g. stereo.
+	Name string
+
+	// OutputProfile of the sounds in the directory, e.g. stereo or 5.1. Defaults to
+	// DefaultOutputProfile.
+	OutputProfile string
+
+	// Context of the sounds, if any.
+	Context string
+}
+
+// Theme is a sound theme, described by its index.theme file.
+type Theme struct {
+	// ID is the name of the theme directory, e.g. freedesktop.
+	ID string
+
+	Name    desktop.LocaleString
+	Comment desktop.LocaleString
+
+	// Inherits are the IDs of the themes to search when a sound is not found in this theme.
+	Inherits []string
+
+	// Directories of the theme that contain sounds.
+	Directories []Directory
+
+	// Hidden themes should not be shown to the user.
+	Hidden bool
+
+	// Example is the name of a sound that represents the theme.
+	Example string
+
+	// Paths are the existing directories of the theme in the base directories, in order of
+	// precedence. Sounds are searched in each of them.
+	Paths []string
+}
+
+// GetDirs returns the base directories of sound themes, $XDG_DATA_HOME/sounds followed by the
+// sounds subdirectory of each $XDG_DATA_DIRS.
+// Existence of the directories is not checked.
+func GetDirs() []string {
+	result := []string{filepath.Join(basedir.DataHome, "sounds")}
+	for _, dir := range basedir.DataDirs {
+		result = append(result, filepath.Join(dir, "sounds"))
+	}
+
+	return result
+}
+
+// Parse parses an index.theme file. The ID and Paths of the returned theme are empty.
+// The file uses the key file format of desktop entries.
+func Parse(reader io.Reader) (*Theme, error) {
+	doc, err := desktop.ParseDocument(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Parse: %w", err)
+	}
+
+	if !slices.Contains(doc.Groups(), mainGroup) {
+		return nil, fmt.Errorf("Parse: missing [%s] group", mainGroup)
+	}
+
+	theme := &Theme{}
+	for _, key := range doc.Keys(mainGroup) {
+		value, _ := doc.Get(mainGroup, key)
+		err = applyMainKey(theme, key, value)
+		if err != nil {
+			return nil, fmt.Errorf("Parse: invalid %s %s: %w", key, value, err)
+		}
+	}
+
+	for i := range theme.Directories {
+		directory := &theme.Directories[i]
+		directory.OutputProfile, err = getString(doc, directory.Name, "OutputProfile")
+		if err != nil {
+			return nil, fmt.Errorf("Parse: %w", err)
+		}
+		if directory.OutputProfile == "" {
+			directory.OutputProfile = DefaultOutputProfile
+		}
+
+		directory.Context, err = getString(doc, directory.Name, "Context")
+		if err != nil {
+			return nil, fmt.Errorf("Parse: %w", err)
+		}
+	}
+
+	return theme, nil
+}
+
+func applyMainKey(theme *Theme, key string, value string) error {
+	name, locale, _ := strings.Cut(strings.TrimSuffix(key, "]"), "[")
+	if name == "Hidden" {
+		hidden, err := desktop.DecodeBoolean(value)
+		if err != nil {
+			return err
+		}
+		theme.Hidden = hidden.(bool)
+		return nil
+	}
+
+	decoded, err := desktop.DecodeString(value)
+	if err != nil {
+		return err
+	}
+	value = decoded.(string)
+
+	switch name {
+	case "Name":
+		assignLocaleString(&theme.Name, locale, value)
+	case "Comment":
+		assignLocaleString(&theme.Comment, locale, value)
+	case "Inherits":
+		theme.Inherits = splitList(value)
+	case "Directories":
+		for _, directory := range splitList(value) {
+			theme.Directories = append(theme.Directories, Directory{Name: directory})
+		}
+	case "Example":
+		theme.Example = value
+	}
+
+	return nil
+}
+
+// getString returns the decoded value of the key in group, or an empty string if it does not
+// exist.
+func getString(doc *desktop.Document, group string, key string) (string, error) {
+	value, found := doc.Get(group, key)
+	if !found {
+		return "", nil
+	}
+
+	decoded, err := desktop.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %s: %w", key, value, err)
+	}
+
+	return decoded.(string), nil
+}
+
+func assignLocaleString(s *desktop.LocaleString, locale string, value string) {
+	if locale == "" {
+		s.Default = value
+		return
+	}
+
+	if s.Localized == nil {
+		s.Localized = make(map[string]string)
+	}
+	s.Localized[locale] = value
+}
+
+// splitList splits a list separated by commas or semicolons, as both are used in practice.
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// ParseFile parses the index.theme file at path.
+func ParseFile(path string) (*Theme, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFile: %w", err)
+	}
+	defer file.Close()
+
+	theme, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFile: %s: %w", path, err)
+	}
+
+	return theme, nil
+}
+
+// LoadTheme loads the theme with the given ID from the base directories, see GetDirs. The
+// index.theme of the first base directory that has one is used.
+// If the theme does not exist, an error matching ErrNotFound is returned.
+func LoadTheme(id string, dirs []string) (*Theme, error) {
+	var theme *Theme
+	var paths []string
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, id)
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		paths = append(paths, path)
+
+		if theme != nil {
+			continue
+		}
+
+		theme, err = ParseFile(filepath.Join(path, indexFile))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("LoadTheme: %w", err)
+		}
+	}
+
+	if theme == nil {
+		return nil, fmt.Errorf("LoadTheme: theme %s: %w", id, ErrNotFound)
+	}
+
+	theme.ID = id
+	theme.Paths = paths
+	return theme, nil
+}
+
+// List returns the themes of the base directories, ordered by ID. Themes that fail to load are
+// skipped. Hidden themes are included.
+func List(dirs []string) ([]*Theme, error) {
+	seen := make(map[string]bool)
+	var ids []string
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("List: %w", err)
+		}
+
+		for _, entry := range entries {
+			if seen[entry.Name()] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, entry.Name(), indexFile)); err != nil {
+				continue
+			}
+
+			seen[entry.Name()] = true
+			ids = append(ids, entry.Name())
+		}
+	}
+
+	slices.Sort(ids)
+	result := make([]*Theme, 0, len(ids))
+	for _, id := range ids {
+		theme, err := LoadTheme(id, dirs)
+		if err != nil {
+			logging.Logger().Warn("Skipping invalid sound theme", "theme", id, logging.Error, err)
+			continue
+		}
+		result = append(result, theme)
+	}
+
+	return result, nil
+}
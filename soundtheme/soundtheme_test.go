@@ -0,0 +1,153 @@
+package soundtheme
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	theme, err := Parse(strings.NewReader(`[Sound Theme]
+Name=Mine
+Name[de]=Meins
+Comment=My\stheme\nfor sounds
+Inherits=base,other
+Directories=stereo;5.1
+Example=bell
+
+[stereo]
+OutputProfile=stereo
+
+[5.1]
+OutputProfile=5.1
+Context=Events
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if theme.Name.Default != "Mine" || theme.Name.ToLocale("de_DE") != "Meins" {
+		t.Errorf("Name = %+v", theme.Name)
+	}
+	if theme.Comment.Default != "My theme\nfor sounds" {
+		t.Errorf("Comment = %q, expected the unescaped value", theme.Comment.Default)
+	}
+	if !slices.Equal(theme.Inherits, []string{"base", "other"}) {
+		t.Errorf("Inherits = %v, expected: [base other]", theme.Inherits)
+	}
+	expected := []Directory{
+		{Name: "stereo", OutputProfile: "stereo"},
+		{Name: "5.1", OutputProfile: "5.1", Context: "Events"},
+	}
+	if !slices.Equal(theme.Directories, expected) {
+		t.Errorf("Directories = %v, expected: %v", theme.Directories, expected)
+	}
+	if theme.Example != "bell" {
+		t.Errorf("Example = %s, expected: bell", theme.Example)
+	}
+
+	_, err = Parse(strings.NewReader("[Other]\nName=Mine\n"))
+	if err == nil {
+		t.Errorf("Parse() without [Sound Theme] group returned no error")
+	}
+
+	_, err = ParseFile(filepath.Join(t.TempDir(), indexFile))
+	if !errors.Is(err, os.ErrNotExist) || !strings.HasPrefix(err.Error(), "ParseFile: ") {
+		t.Errorf("ParseFile() error = %v, expected a ParseFile error matching os.ErrNotExist", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	testenv.Setup(t)
+	system := filepath.Join(basedir.DataDirs[0], "sounds")
+	user := filepath.Join(basedir.DataHome, "sounds")
+	testenv.WriteFile(
+		t,
+		filepath.Join(system, "freedesktop", indexFile),
+		"[Sound Theme]\nName=Default\nDirectories=stereo;5.1\n\n"+
+			"[stereo]\nOutputProfile=stereo\n\n[5.1]\nOutputProfile=5.1\n",
+	)
+	testenv.WriteFile(t, filepath.Join(system, "freedesktop/5.1/dialog-warning.oga"), "")
+	testenv.WriteFile(t, filepath.Join(system, "freedesktop/stereo/message-new-instant.oga"), "")
+	testenv.WriteFile(t, filepath.Join(system, "freedesktop/stereo/bell.oga"), "")
+	testenv.WriteFile(t, filepath.Join(system, "freedesktop/stereo/complete.oga"), "")
+	testenv.WriteFile(
+		t,
+		filepath.Join(system, "mine", indexFile),
+		"[Sound Theme]\nName=Mine\nDirectories=stereo;5.1\n\n"+
+			"[stereo]\nOutputProfile=stereo\n\n[5.1]\nOutputProfile=5.1\n",
+	)
+	testenv.WriteFile(t, filepath.Join(system, "mine/stereo/message.wav"), "")
+	testenv.WriteFile(t, filepath.Join(system, "mine/stereo/de/bell.ogg"), "")
+	testenv.WriteFile(t, filepath.Join(system, "mine/5.1/complete.oga"), "")
+	testenv.WriteFile(t, filepath.Join(system, "mine/stereo/dialog-warning.oga"), "")
+	testenv.WriteFile(t, filepath.Join(user, "mine/stereo/bell.disabled"), "")
+
+	tests := []struct {
+		name     string
+		opts     LookupOptions
+		expected string
+	}{
+		{"message-new-instant", LookupOptions{Theme: "mine"}, "mine/stereo/message.wav"},
+		{"message-new-instant", LookupOptions{}, "freedesktop/stereo/message-new-instant.oga"},
+		{"complete", LookupOptions{Theme: "mine"}, "freedesktop/stereo/complete.oga"},
+		{"complete", LookupOptions{Theme: "mine", OutputProfile: "5.1"}, "mine/5.1/complete.oga"},
+		{
+			"dialog-warning",
+			LookupOptions{Theme: "mine", OutputProfile: "5.1"},
+			"mine/stereo/dialog-warning.oga",
+		},
+		{"bell", LookupOptions{Theme: "mine", Locale: "de_DE.UTF-8"}, "mine/stereo/de/bell.ogg"},
+		{"bell", LookupOptions{Theme: "missing", Locale: "C"}, "freedesktop/stereo/bell.oga"},
+	}
+
+	for _, test := range tests {
+		actual, err := LookupWithOptions(test.name, test.opts)
+		if err != nil {
+			t.Errorf("Lookup(%s, %+v) failed: %v", test.name, test.opts, err)
+			continue
+		}
+		if expected := filepath.Join(system, test.expected); actual != expected {
+			t.Errorf("Lookup(%s, %+v) = %s, expected: %s", test.name, test.opts, actual, expected)
+		}
+	}
+
+	_, err := LookupWithOptions("bell", LookupOptions{Theme: "mine", Locale: "C"})
+	if !errors.Is(err, ErrDisabled) {
+		t.Errorf("expected ErrDisabled, got: %v", err)
+	}
+
+	_, err = Lookup("phone-incoming-call", "mine")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	testenv.Setup(t)
+	testenv.WriteFile(t, filepath.Join(basedir.DataHome, "sounds/b/index.theme"), "[Sound Theme]\nName=B\n")
+	testenv.WriteFile(t, filepath.Join(basedir.DataDirs[0], "sounds/a/index.theme"), "[Sound Theme]\nName=A\n")
+	testenv.WriteFile(t, filepath.Join(basedir.DataDirs[0], "sounds/b/index.theme"), "[Sound Theme]\nName=Old\n")
+	testenv.WriteFile(t, filepath.Join(basedir.DataDirs[0], "sounds/unthemed.oga"), "")
+
+	themes, err := List(GetDirs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.ID+"="+theme.Name.Default)
+	}
+	if !slices.Equal(names, []string{"a=A", "b=B"}) {
+		t.Errorf("themes = %v, expected: [a=A b=B]", names)
+	}
+	if len(themes) == 2 && len(themes[1].Paths) != 2 {
+		t.Errorf("Paths of b = %v, expected both base directories", themes[1].Paths)
+	}
+}
@@ -0,0 +1,251 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/mimeapps"
+	"github.com/MatthiasKunnen/xdg/open"
+	"github.com/MatthiasKunnen/xdg/sharedmimeinfo"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrNoApplication is returned when no application is associated with a MIME type.
+var ErrNoApplication = errors.New("no application found")
+
+// SystemOptions configure NewSystem.
+type SystemOptions struct {
+	// Desktop is used to include desktop specific mimeapps.list files such as
+	// gnome-mimeapps.list. If empty, the first desktop of $XDG_CURRENT_DESKTOP is used.
+	Desktop string
+
+	// Watch enables watching the directories of the desktop files, the mimeapps.list files, and
+	// the MIME database. Cached data is discarded when they change. Otherwise, use Invalidate.
+	Watch bool
+}
+
+// Application is an installed application.
+type Application struct {
+	// Id is the desktop ID, e.g. firefox.desktop.
+	Id string
+
+	// Path of the desktop file.
+	Path string
+
+	Entry *desktop.Entry
+}
+
+// System combines the packages of the module behind a single object. The desktop files, the
+// preferred applications of the mimeapps.list files, and the MIME database are loaded when first
+// needed and cached until Invalidate is called or, if enabled, a watched directory changes.
+//
+// A System is safe for concurrent use.
+type System struct {
+	desktop      string
+	stopWatching context.CancelFunc
+
+	mu           sync.Mutex
+	mimeDatabase *sharedmimeinfo.Database
+	desktopFiles desktop.IdPathMap
+	preferred    mimeapps.Associations
+}
+
+// NewSystem returns a System for the directories of basedir. Call Close when done.
+func NewSystem(opts SystemOptions) (*System, error) {
+	s := &System{desktop: opts.Desktop}
+	if s.desktop == "" {
+		s.desktop, _, _ = strings.Cut(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	}
+
+	if opts.Watch {
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := basedir.Watch(ctx, watchedDirs(s.desktop))
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("NewSystem: %w", err)
+		}
+
+		s.stopWatching = cancel
+		go func() {
+			for range changes {
+				s.Invalidate()
+			}
+		}()
+	}
+
+	return s, nil
+}
+
+func watchedDirs(currentDesktop string) []string {
+	dirs := desktop.GetDesktopFileLocations()
+	dirs = append(dirs, sharedmimeinfo.GetDirs()...)
+	for _, location := range mimeapps.GetLists(currentDesktop) {
+		dirs = append(dirs, filepath.Dir(location.Path))
+	}
+
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// Close stops watching. The System can still be used afterward.
+func (s *System) Close() error {
+	if s.stopWatching != nil {
+		s.stopWatching()
+	}
+
+	return nil
+}
+
+// Invalidate discards the cached data, it is reloaded when next needed.
+func (s *System) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mimeDatabase = nil
+	s.desktopFiles = nil
+	s.preferred = nil
+}
+
+// state returns the cached data, loading what is missing.
+func (s *System) state(
+	ctx context.Context,
+) (*sharedmimeinfo.Database, desktop.IdPathMap, mimeapps.Associations, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.mimeDatabase == nil {
+		s.mimeDatabase, err = sharedmimeinfo.LoadContext(ctx, nil)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if s.desktopFiles == nil {
+		s.desktopFiles, err = desktop.GetDesktopFilesContext(ctx, desktop.GetDesktopFileLocations())
+		if err != nil {
+			s.desktopFiles = nil
+			return nil, nil, nil, err
+		}
+	}
+
+	if s.preferred == nil {
+		s.preferred, err = mimeapps.GetPreferredApplicationsContext(
+			ctx,
+			mimeapps.GetLists(s.desktop),
+			s.desktopFiles,
+		)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return s.mimeDatabase, s.desktopFiles, s.preferred, nil
+}
+
+// DetectMime returns the MIME type of the file at path, see sharedmimeinfo.Database.TypeByFile.
+func (s *System) DetectMime(ctx context.Context, path string) (string, error) {
+	db, _, _, err := s.state(ctx)
+	if err != nil {
+		return "", fmt.Errorf("DetectMime: %w", err)
+	}
+
+	mimeType, err := db.TypeByFileContext(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("DetectMime: %w", err)
+	}
+
+	return mimeType, nil
+}
+
+// DefaultAppFor returns the preferred application of the MIME type. If no application supports
+// the type itself, its broader types are considered, e.g. text/plain for text/x-csrc.
+// If there is none, ErrNoApplication is returned.
+func (s *System) DefaultAppFor(ctx context.Context, mimeType string) (Application, error) {
+	db, desktopFiles, preferred, err := s.state(ctx)
+	if err != nil {
+		return Application{}, fmt.Errorf("DefaultAppFor: %w", err)
+	}
+
+	for _, candidateType := range db.BroaderDfs(mimeType) {
+		for _, desktopId := range preferred[candidateType] {
+			entry, path, _ := desktopFiles.LoadEffective(desktopId)
+			if entry != nil && !entry.Hidden {
+				return Application{Id: desktopId, Path: path, Entry: entry}, nil
+			}
+		}
+	}
+
+	return Application{}, fmt.Errorf("DefaultAppFor: %s: %w", mimeType, ErrNoApplication)
+}
+
+// ListApplications returns the installed applications ordered by desktop ID. Desktop files that
+// are not of type Application, are hidden, or are invalid are skipped. An application is also
+// skipped if a file with a higher precedence deletes it using Hidden=true.
+func (s *System) ListApplications(ctx context.Context) ([]Application, error) {
+	_, desktopFiles, _, err := s.state(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListApplications: %w", err)
+	}
+
+	ids := make([]string, 0, len(desktopFiles))
+	for id := range desktopFiles {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var result []Application
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ListApplications: %w", err)
+		}
+
+		entry, path, _ := desktopFiles.LoadEffective(id)
+		if entry == nil || entry.Type != desktop.TypeApplication || entry.Hidden {
+			continue
+		}
+
+		result = append(result, Application{Id: id, Path: path, Entry: entry})
+	}
+
+	return result, nil
+}
+
+// Open opens the target, a path or URI, with the preferred application, see open.Open.
+// The MimeDatabase, DesktopFiles, and Desktop options default to those of the System.
+func (s *System) Open(ctx context.Context, target string, opts open.Options) error {
+	db, desktopFiles, _, err := s.state(ctx)
+	if err != nil {
+		return fmt.Errorf("Open: %w", err)
+	}
+
+	if opts.MimeDatabase == nil {
+		opts.MimeDatabase = db
+	}
+	if opts.DesktopFiles == nil {
+		opts.DesktopFiles = desktopFiles
+	}
+	if opts.Desktop == "" {
+		opts.Desktop = s.desktop
+	}
+
+	return open.Open(ctx, target, opts)
+}
+
+// IconFor returns the names of the icons of the MIME type in order of preference: the specific
+// icon followed by the generic one, e.g. text-x-csrc and text-x-generic.
+// The names are to be looked up in the icon theme.
+func (s *System) IconFor(ctx context.Context, mimeType string) ([]string, error) {
+	db, _, _, err := s.state(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("IconFor: %w", err)
+	}
+
+	return []string{db.Icon(mimeType), db.GenericIcon(mimeType)}, nil
+}
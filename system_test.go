@@ -0,0 +1,180 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func createApp(t *testing.T, id string, mimeTypes string) {
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", id),
+		"[Desktop Entry]\nType=Application\nName="+id+"\nExec=app %f\nMimeType="+mimeTypes+"\n",
+	)
+}
+
+func applicationIds(applications []Application) []string {
+	var result []string
+	for _, application := range applications {
+		result = append(result, application.Id)
+	}
+
+	return result
+}
+
+func TestSystem(t *testing.T) {
+	home := testenv.Setup(t)
+	testenv.WriteFile(t, filepath.Join(basedir.DataHome, "mime/globs2"), "50:text/x-csrc:*.c\n")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "mime/subclasses"),
+		"text/x-csrc text/plain\n",
+	)
+	createApp(t, "editor.desktop", "text/plain;")
+	createApp(t, "viewer.desktop", "text/plain;")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.ConfigHome, "mimeapps.list"),
+		"[Default Applications]\ntext/plain=viewer.desktop\n",
+	)
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications/hidden.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Hidden\nExec=hidden\nHidden=true\n",
+	)
+	file := filepath.Join(home, "main.c")
+	testenv.WriteFile(t, file, "int main() {}\n")
+
+	system, err := NewSystem(SystemOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer system.Close()
+	ctx := context.Background()
+
+	mimeType, err := system.DetectMime(ctx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mimeType != "text/x-csrc" {
+		t.Errorf("DetectMime = %s, expected: text/x-csrc", mimeType)
+	}
+
+	app, err := system.DefaultAppFor(ctx, mimeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Id != "viewer.desktop" || app.Entry == nil {
+		t.Errorf("DefaultAppFor = %+v, expected viewer.desktop", app)
+	}
+
+	_, err = system.DefaultAppFor(ctx, "image/png")
+	if !errors.Is(err, ErrNoApplication) {
+		t.Errorf("expected ErrNoApplication, got: %v", err)
+	}
+
+	applications, err := system.ListApplications(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedIds := []string{"editor.desktop", "viewer.desktop"}
+	if ids := applicationIds(applications); !slices.Equal(ids, expectedIds) {
+		t.Errorf("ListApplications = %v, expected: %v", ids, expectedIds)
+	}
+
+	icons, err := system.IconFor(ctx, mimeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(icons, []string{"text-x-csrc", "text-x-generic"}) {
+		t.Errorf("IconFor = %v, expected: [text-x-csrc text-x-generic]", icons)
+	}
+
+	createApp(t, "ide.desktop", "text/x-csrc;")
+	app, _ = system.DefaultAppFor(ctx, mimeType)
+	if app.Id != "viewer.desktop" {
+		t.Errorf("DefaultAppFor = %s before Invalidate, expected the cached viewer.desktop", app.Id)
+	}
+
+	system.Invalidate()
+	app, _ = system.DefaultAppFor(ctx, mimeType)
+	if app.Id != "ide.desktop" {
+		t.Errorf("DefaultAppFor = %s after Invalidate, expected: ide.desktop", app.Id)
+	}
+}
+
+func TestSystemHiddenOverride(t *testing.T) {
+	home := testenv.Setup(t)
+	testenv.WriteFile(
+		t,
+		filepath.Join(home, "usr/share/applications/editor.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Editor\nExec=editor %f\nMimeType=text/plain;\n",
+	)
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications/editor.desktop"),
+		"[Desktop Entry]\nHidden=true\n",
+	)
+
+	system, err := NewSystem(SystemOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer system.Close()
+	ctx := context.Background()
+
+	applications, err := system.ListApplications(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applications) > 0 {
+		t.Errorf("ListApplications = %v, expected no applications", applicationIds(applications))
+	}
+
+	_, err = system.DefaultAppFor(ctx, "text/plain")
+	if !errors.Is(err, ErrNoApplication) {
+		t.Errorf("expected ErrNoApplication, got: %v", err)
+	}
+}
+
+func TestSystemWatch(t *testing.T) {
+	testenv.Setup(t)
+	createApp(t, "editor.desktop", "text/plain;")
+
+	system, err := NewSystem(SystemOptions{Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer system.Close()
+	ctx := context.Background()
+
+	applications, err := system.ListApplications(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applications) != 1 {
+		t.Fatalf("ListApplications returned %d applications, expected: 1", len(applications))
+	}
+
+	createApp(t, "viewer.desktop", "text/plain;")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		applications, err = system.ListApplications(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applications) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("new application was not picked up: %v", applicationIds(applications))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,345 @@
+// Package terminal discovers the installed terminal emulators and runs commands in the
+// preferred one, following the proposed [Default Terminal Execution Specification].
+//
+// Terminals are desktop entries with the TerminalEmulator category. The preference order is
+// configured in xdg-terminals.list files, XTerm is used as fallback.
+//
+// [Default Terminal Execution Specification]: https://gitlab.freedesktop.org/terminal-wg/specifications/-/merge_requests/3
+package terminal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	listName          = "xdg-terminals.list"
+	terminalCategory  = "TerminalEmulator"
+	execArgKey        = "X-TerminalArgExec"
+	defaultExecArg    = "-e"
+	fallbackTerminal  = "xterm"
+	dataSubdirectory  = "xdg-terminal-exec"
+	actionIdSeparator = ":"
+)
+
+// ErrNotFound is returned when no terminal emulator is installed.
+var ErrNotFound = errors.New("no terminal emulator found")
+
+// Terminal is an installed terminal emulator.
+type Terminal struct {
+	// ID is the desktop ID of the terminal, e.g. org.gnome.Console.desktop. It is empty for the
+	// XTerm fallback.
+	ID string
+
+	// Action is the desktop action used to start the terminal, as configured in
+	// xdg-terminals.list. If empty, the Exec key of the entry is used.
+	Action string
+
+	// Path is the path of the desktop file. It is empty for the XTerm fallback.
+	Path string
+
+	// Entry is the parsed desktop file. It is nil for the XTerm fallback.
+	Entry *desktop.Entry
+
+	// Exec is the command that starts the terminal.
+	Exec []string
+
+	// ExecArg is the argument that precedes the command to run in the terminal, taken from
+	// the X-TerminalArgExec key. It defaults to -e and is empty if the terminal takes the
+	// command directly.
+	ExecArg string
+}
+
+// Command returns the command that runs argv in the terminal. If argv is empty, the command
+// opens the terminal with its default shell.
+func (t Terminal) Command(argv []string) []string {
+	result := slices.Clone(t.Exec)
+	if len(argv) == 0 {
+		return result
+	}
+
+	if t.ExecArg != "" {
+		result = append(result, t.ExecArg)
+	}
+
+	return append(result, argv...)
+}
+
+// Options configures Discover.
+type Options struct {
+	// Desktops are the current desktop environments, used to find desktop specific lists such
+	// as gnome-xdg-terminals.list and to check OnlyShowIn and NotShowIn. If nil,
+	// $XDG_CURRENT_DESKTOP is used.
+	Desktops []string
+
+	// DesktopFiles maps desktop IDs to their files. If nil, the standard locations are scanned.
+	DesktopFiles desktop.IdPathMap
+}
+
+// GetListPaths returns the paths of the xdg-terminals.list files in order of precedence. For each
+// configuration directory followed by each data directory's xdg-terminal-exec subdirectory, the
+// desktop specific lists, e.g. gnome-xdg-terminals.list, precede xdg-terminals.list.
+// Existence of the files is not checked.
+func GetListPaths(desktops []string) []string {
+	dirs := []string{basedir.ConfigHome}
+	dirs = append(dirs, basedir.ConfigDirs...)
+	dirs = append(dirs, filepath.Join(basedir.DataHome, dataSubdirectory))
+	for _, dir := range basedir.DataDirs {
+		dirs = append(dirs, filepath.Join(dir, dataSubdirectory))
+	}
+
+	var result []string
+	for _, dir := range dirs {
+		for _, name := range desktops {
+			result = append(result, filepath.Join(dir, strings.ToLower(name)+"-"+listName))
+		}
+		result = append(result, filepath.Join(dir, listName))
+	}
+
+	return result
+}
+
+// ListEntry is an entry of an xdg-terminals.list file.
+type ListEntry struct {
+	// ID is the desktop ID of the terminal.
+	ID string
+
+	// Action is the desktop action to use, if any.
+	Action string
+}
+
+// ReadList reads the entries of the xdg-terminals.list file. Each line contains a desktop ID,
+// optionally followed by a colon and an action ID. Empty lines and comments are skipped.
+func ReadList(path string) ([]ListEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadList: %w", err)
+	}
+	defer file.Close()
+
+	var result []ListEntry
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, action, _ := strings.Cut(line, actionIdSeparator)
+		result = append(result, ListEntry{ID: id, Action: action})
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("ReadList: failed to read %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// Discover returns the installed terminal emulators in order of preference:
+//  1. The terminals listed in the xdg-terminals.list files, see GetListPaths.
+//  2. The other desktop entries with the TerminalEmulator category, ordered by desktop ID.
+//  3. XTerm, if it is in $PATH.
+//
+// Entries that are hidden, not shown in the current desktops, or of which the TryExec is not
+// executable are skipped.
+func Discover(opts Options) ([]Terminal, error) {
+	desktops := opts.Desktops
+	if desktops == nil {
+		desktops = currentDesktops()
+	}
+
+	idPathMap := opts.DesktopFiles
+	if idPathMap == nil {
+		var err error
+		idPathMap, err = desktop.GetDesktopFiles(desktop.GetDesktopFileLocations())
+		if err != nil {
+			return nil, fmt.Errorf("Discover: %w", err)
+		}
+	}
+
+	var result []Terminal
+	added := make(map[string]bool)
+
+	for _, listPath := range GetListPaths(desktops) {
+		entries, err := ReadList(listPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			logging.Logger().Warn(
+				"Skipping unreadable terminal list",
+				logging.Path, listPath,
+				logging.Error, err,
+			)
+			continue
+		}
+
+		for _, listEntry := range entries {
+			key := listEntry.ID + actionIdSeparator + listEntry.Action
+			if added[key] {
+				continue
+			}
+
+			terminal, ok := load(idPathMap, listEntry.ID, listEntry.Action, desktops, false)
+			if ok {
+				added[key] = true
+				result = append(result, terminal)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(idPathMap))
+	for id := range idPathMap {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		if added[id+actionIdSeparator] {
+			continue
+		}
+
+		terminal, ok := load(idPathMap, id, "", desktops, true)
+		if ok {
+			added[id+actionIdSeparator] = true
+			result = append(result, terminal)
+		}
+	}
+
+	if path, err := exec.LookPath(fallbackTerminal); err == nil {
+		result = append(result, Terminal{
+			Exec:    []string{path},
+			ExecArg: defaultExecArg,
+		})
+	}
+
+	return result, nil
+}
+
+// Preferred returns the most preferred terminal emulator, see Discover. If no terminal is
+// installed, ErrNotFound is returned.
+func Preferred(opts Options) (Terminal, error) {
+	terminals, err := Discover(opts)
+	if err != nil {
+		return Terminal{}, fmt.Errorf("Preferred: %w", err)
+	}
+
+	if len(terminals) == 0 {
+		return Terminal{}, fmt.Errorf("Preferred: %w", ErrNotFound)
+	}
+
+	return terminals[0], nil
+}
+
+// Command returns the command that runs argv in the preferred terminal emulator.
+func Command(argv []string) ([]string, error) {
+	terminal, err := Preferred(Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return terminal.Command(argv), nil
+}
+
+// Resolver returns a function that returns the command of the preferred terminal emulator to
+// which a command is appended, for use as desktop.LaunchOptions.ResolveTerminal.
+func Resolver(opts Options) func() ([]string, error) {
+	return func() ([]string, error) {
+		terminal, err := Preferred(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		result := slices.Clone(terminal.Exec)
+		if terminal.ExecArg != "" {
+			result = append(result, terminal.ExecArg)
+		}
+
+		return result, nil
+	}
+}
+
+// load returns the terminal with the given desktop ID and action if it is usable.
+func load(
+	idPathMap desktop.IdPathMap,
+	id string,
+	action string,
+	desktops []string,
+	requireCategory bool,
+) (Terminal, bool) {
+	entry, path, err := idPathMap.LoadById(id)
+	if err != nil || entry == nil {
+		return Terminal{}, false
+	}
+
+	switch {
+	case entry.Type != desktop.TypeApplication,
+		entry.Hidden,
+		requireCategory && !slices.Contains(entry.Categories, terminalCategory),
+		!entry.ShouldShowIn(strings.Join(desktops, ":")),
+		!entry.IsInstalled():
+		return Terminal{}, false
+	}
+
+	execValue := entry.Exec
+	if action != "" {
+		index := slices.IndexFunc(entry.Actions, func(a desktop.Action) bool {
+			return a.ID == action
+		})
+		if index == -1 {
+			return Terminal{}, false
+		}
+		execValue = entry.Actions[index].Exec
+	}
+
+	args := execValue.Expand(desktop.FieldCodeValues{
+		Icon:        entry.Icon.Default,
+		Name:        entry.Name.Default,
+		DesktopFile: path,
+	})
+	if len(args) == 0 || !isExecutable(args[0]) {
+		return Terminal{}, false
+	}
+
+	execArg, hasExecArg := entry.OtherKeys[execArgKey]
+	if !hasExecArg {
+		execArg = defaultExecArg
+	}
+
+	return Terminal{
+		ID:      id,
+		Action:  action,
+		Path:    path,
+		Entry:   entry,
+		Exec:    args,
+		ExecArg: execArg,
+	}, true
+}
+
+func currentDesktops() []string {
+	var result []string
+	for _, name := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// isExecutable returns true if the path is an executable file. Relative paths are looked up in
+// $PATH.
+func isExecutable(path string) bool {
+	_, err := exec.LookPath(path)
+	return err == nil
+}
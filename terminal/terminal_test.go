@@ -0,0 +1,164 @@
+package terminal
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// setupHome sets up the environment using testenv.Setup, $PATH only contains the bin directory
+// of the returned home.
+func setupHome(t *testing.T) string {
+	home := testenv.Setup(t)
+	t.Setenv("PATH", filepath.Join(home, "bin"))
+
+	return home
+}
+
+// createTerminal creates a terminal desktop entry and its executable.
+func createTerminal(t *testing.T, home string, id string, extra string) {
+	executable := filepath.Join(home, "bin", id)
+	testenv.WriteExecutable(t, executable, "#!/bin/sh\n")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", id+".desktop"),
+		"[Desktop Entry]\nType=Application\nName="+id+"\nExec="+id+"\n"+
+			"Categories=System;TerminalEmulator;\n"+extra,
+	)
+}
+
+func terminalIDs(terminals []Terminal) []string {
+	result := make([]string, 0, len(terminals))
+	for _, terminal := range terminals {
+		id := terminal.ID
+		if terminal.Action != "" {
+			id += ":" + terminal.Action
+		}
+		if id == "" {
+			id = filepath.Base(terminal.Exec[0])
+		}
+		result = append(result, id)
+	}
+
+	return result
+}
+
+func TestDiscover(t *testing.T) {
+	home := setupHome(t)
+	createTerminal(t, home, "alacritty", "")
+	createTerminal(t, home, "foot", "X-TerminalArgExec=\n")
+	createTerminal(
+		t,
+		home,
+		"kitty",
+		"Actions=new;\n[Desktop Action new]\nName=New\nExec=kitty --new\n",
+	)
+	createTerminal(t, home, "gnome-only", "OnlyShowIn=GNOME;\n")
+	createTerminal(t, home, "hidden", "Hidden=true\n")
+	createTerminal(t, home, "missing", "TryExec=not-installed\n")
+	testenv.WriteExecutable(t, filepath.Join(home, "bin", "xterm"), "#!/bin/sh\n")
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.DataHome, "applications", "editor.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Editor\nExec=editor\n",
+	)
+	testenv.WriteFile(
+		t,
+		filepath.Join(basedir.ConfigHome, "xdg-terminals.list"),
+		"# Preferred\nkitty.desktop:new\nunknown.desktop\n\nfoot.desktop\n",
+	)
+
+	terminals, err := Discover(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := terminalIDs(terminals)
+	expected := []string{
+		"kitty.desktop:new",
+		"foot.desktop",
+		"alacritty.desktop",
+		"kitty.desktop",
+		"xterm",
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Discover() = %v, expected: %v", actual, expected)
+	}
+
+	kitty := terminals[0]
+	if !slices.Equal(kitty.Command([]string{"top"}), []string{"kitty", "--new", "-e", "top"}) {
+		t.Errorf("Command = %v, expected: [kitty --new -e top]", kitty.Command([]string{"top"}))
+	}
+
+	foot := terminals[1]
+	if !slices.Equal(foot.Command([]string{"top"}), []string{"foot", "top"}) {
+		t.Errorf("Command = %v, expected: [foot top]", foot.Command([]string{"top"}))
+	}
+}
+
+func TestDiscoverDesktopList(t *testing.T) {
+	home := setupHome(t)
+	createTerminal(t, home, "alacritty", "")
+	createTerminal(t, home, "gnome-only", "OnlyShowIn=GNOME;\n")
+	configDir := basedir.ConfigDirs[0]
+	testenv.WriteFile(t, filepath.Join(configDir, "xdg-terminals.list"), "alacritty.desktop\n")
+	testenv.WriteFile(
+		t,
+		filepath.Join(configDir, "gnome-xdg-terminals.list"),
+		"gnome-only.desktop\n",
+	)
+
+	terminal, err := Preferred(Options{Desktops: []string{"GNOME"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terminal.ID != "gnome-only.desktop" {
+		t.Errorf("Preferred(GNOME) = %s, expected: gnome-only.desktop", terminal.ID)
+	}
+
+	terminal, err = Preferred(Options{Desktops: []string{"KDE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terminal.ID != "alacritty.desktop" {
+		t.Errorf("Preferred(KDE) = %s, expected: alacritty.desktop", terminal.ID)
+	}
+}
+
+func TestPreferredNotFound(t *testing.T) {
+	setupHome(t)
+	_, err := Preferred(Options{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Preferred() error = %v, expected: %v", err, ErrNotFound)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	home := setupHome(t)
+	createTerminal(t, home, "foot", "X-TerminalArgExec=\n")
+	createTerminal(t, home, "kitty", "")
+	testenv.WriteFile(t, filepath.Join(basedir.ConfigHome, "xdg-terminals.list"), "kitty.desktop\n")
+
+	exec, err := desktop.NewExec("top")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := &desktop.Entry{Type: desktop.TypeApplication, Exec: exec, Terminal: true}
+	cmd, err := entry.Command(desktop.LaunchOptions{ResolveTerminal: Resolver(Options{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"kitty", "-e", "top"}; !slices.Equal(cmd.Args, expected) {
+		t.Errorf("Args = %q, expected: %q", cmd.Args, expected)
+	}
+
+	_, err = Resolver(Options{DesktopFiles: desktop.IdPathMap{}})()
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolver() error = %v, expected: %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package thumbnail
+
+import "os"
+
+// isWritableDir returns true if dir is a directory with write permissions.
+func isWritableDir(dir string) bool {
+	stat, err := os.Stat(dir)
+	if err != nil || !stat.IsDir() {
+		return false
+	}
+
+	return stat.Mode().Perm()&0200 != 0
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package thumbnail
+
+import (
+	"os"
+	"syscall"
+)
+
+// isWritableDir returns true if dir is a directory the current user can write to.
+func isWritableDir(dir string) bool {
+	stat, err := os.Stat(dir)
+	if err != nil || !stat.IsDir() {
+		return false
+	}
+
+	const writeOk = 0x2
+	return syscall.Access(dir, writeOk) == nil
+}
@@ -0,0 +1,85 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FailDir returns the directory in which an application records the files it failed to
+// thumbnail, $XDG_CACHE_HOME/thumbnails/fail/$appName.
+// The name of the application should include its version, e.g. gnome-thumbnail-factory-3.0.
+func FailDir(appName string) string {
+	return filepath.Join(Dir(), "fail", appName)
+}
+
+// FailPath returns the path of the fail file of the given URI for the application.
+func FailPath(appName string, uri string) string {
+	return filepath.Join(FailDir(appName), Name(uri))
+}
+
+// RecordFailure records that the application failed to create a thumbnail for the file described
+// by info and returns the path of the fail file.
+// As required by the standard, the fail file is a PNG containing the metadata of the file.
+func RecordFailure(appName string, info Info) (string, error) {
+	if appName == "" || strings.ContainsRune(appName, filepath.Separator) {
+		return "", fmt.Errorf("RecordFailure: invalid application name %q", appName)
+	}
+
+	var buffer bytes.Buffer
+	err := Encode(&buffer, image.NewGray(image.Rect(0, 0, 1, 1)), info)
+	if err != nil {
+		return "", fmt.Errorf("RecordFailure: %w", err)
+	}
+
+	path := FailPath(appName, info.URI)
+	err = fileutil.WriteFileAtomic(path, buffer.Bytes(), 0600)
+	if err != nil {
+		return "", fmt.Errorf("RecordFailure: %w", err)
+	}
+
+	return path, nil
+}
+
+// HasFailed returns true if the application previously failed to thumbnail the file with the
+// given URI and the file has not been modified since. mtime is the current modification time of
+// the file.
+func HasFailed(appName string, uri string, mtime time.Time) bool {
+	info, err := ReadInfoFile(FailPath(appName, uri))
+	if err != nil {
+		return false
+	}
+
+	return info.URI == uri && info.MTime.Unix() == mtime.Unix()
+}
+
+// ClearFailure removes the fail file of the given URI so that thumbnailing is retried.
+// Removing a nonexistent fail file is not an error.
+func ClearFailure(appName string, uri string) error {
+	err := os.Remove(FailPath(appName, uri))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ClearFailure: %w", err)
+	}
+
+	return nil
+}
+
+// ClearFailures removes all fail files of the application.
+func ClearFailures(appName string) error {
+	if appName == "" || strings.ContainsRune(appName, filepath.Separator) {
+		return fmt.Errorf("ClearFailures: invalid application name %q", appName)
+	}
+
+	err := os.RemoveAll(FailDir(appName))
+	if err != nil {
+		return fmt.Errorf("ClearFailures: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package thumbnail
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"testing"
+	"time"
+)
+
+func TestFailures(t *testing.T) {
+	testenv.Setup(t)
+	const app = "test-thumbnailer-1.0"
+	mtime := time.Unix(1700000000, 0)
+	info := Info{URI: "file:///broken.jpg", MTime: mtime}
+
+	if HasFailed(app, info.URI, mtime) {
+		t.Errorf("HasFailed = true before recording a failure")
+	}
+
+	path, err := RecordFailure(app, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != FailPath(app, info.URI) {
+		t.Errorf("path = %s, expected: %s", path, FailPath(app, info.URI))
+	}
+
+	if !HasFailed(app, info.URI, mtime) {
+		t.Errorf("HasFailed = false after recording a failure")
+	}
+
+	if HasFailed(app, info.URI, mtime.Add(time.Minute)) {
+		t.Errorf("HasFailed = true for a modified file")
+	}
+
+	if HasFailed("other-app", info.URI, mtime) {
+		t.Errorf("HasFailed = true for another application")
+	}
+
+	if err := ClearFailure(app, info.URI); err != nil {
+		t.Fatal(err)
+	}
+
+	if HasFailed(app, info.URI, mtime) {
+		t.Errorf("HasFailed = true after clearing the failure")
+	}
+
+	if _, err := RecordFailure(app, info); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearFailures(app); err != nil {
+		t.Fatal(err)
+	}
+	if HasFailed(app, info.URI, mtime) {
+		t.Errorf("HasFailed = true after clearing all failures")
+	}
+}
+
+func TestRecordFailureInvalidAppName(t *testing.T) {
+	testenv.Setup(t)
+
+	_, err := RecordFailure("../escape", Info{URI: "file:///a", MTime: time.Now()})
+	if err == nil {
+		t.Errorf("expected an error for an application name containing a separator")
+	}
+}
@@ -0,0 +1,126 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrNoThumbnailer = errors.New("no thumbnailer available")
+
+// Invoker creates thumbnails by running thumbnailers.
+type Invoker struct {
+	// Thumbnailers to choose from, in order of preference.
+	// If nil, LoadThumbnailers is used to load the thumbnailers of the system on first use.
+	Thumbnailers []Thumbnailer
+
+	// Options determine where the created thumbnails are stored.
+	Options Options
+
+	// AppName is the name, including version, of the application used for recording failures
+	// in GetOrGenerate, e.g. my-file-manager-1.2. If empty, failures are not recorded.
+	AppName string
+
+	// Sandbox, if non-nil, is used to run the thumbnailers. Thumbnailers parse untrusted files,
+	// running them in a sandbox limits the damage a malicious file can do.
+	// See BubblewrapSandbox.
+	Sandbox Sandbox
+
+	// AllowUnsandboxed is the policy deciding which thumbnailers may run without the Sandbox.
+	// If nil, all thumbnailers are sandboxed when Sandbox is set.
+	AllowUnsandboxed func(thumbnailer Thumbnailer) bool
+
+	// loadOnce guards loaded, which holds the system thumbnailers if Thumbnailers is nil.
+	// An Invoker can therefore be shared between goroutines.
+	loadOnce sync.Once
+	loaded   []Thumbnailer
+}
+
+// Generate creates a thumbnail of the given size for the local file at path, saves it with the
+// metadata required by the standard, and returns the path of the thumbnail.
+// The first available thumbnailer that supports mimeType is used. If there is none,
+// ErrNoThumbnailer is returned.
+// Thumbnails larger than size are scaled down, see ScaleToFit.
+// The thumbnailer is killed when ctx is done.
+func (i *Invoker) Generate(ctx context.Context, path string, mimeType string, size Size) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("Generate: failed to make %s absolute: %w", path, err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("Generate: %w", err)
+	}
+
+	uri, err := URIForPath(path)
+	if err != nil {
+		return "", fmt.Errorf("Generate: %w", err)
+	}
+
+	thumbnailer, found := FindThumbnailer(i.thumbnailers(), mimeType)
+	if !found {
+		return "", fmt.Errorf("Generate: %w for %s", ErrNoThumbnailer, mimeType)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "thumbnail-")
+	if err != nil {
+		return "", fmt.Errorf("Generate: failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	output := filepath.Join(tmpDir, "thumbnail.png")
+	args, err := i.sandboxed(thumbnailer, thumbnailer.Arguments(path, uri, output, size), path, tmpDir)
+	if err != nil {
+		return "", fmt.Errorf("Generate: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf(
+			"Generate: thumbnailer %s failed for %s: %w: %s",
+			thumbnailer.ID,
+			path,
+			err,
+			strings.TrimSpace(string(out)),
+		)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		return "", fmt.Errorf("Generate: thumbnailer %s did not create a thumbnail: %w", thumbnailer.ID, err)
+	}
+	img, err := png.Decode(file)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("Generate: thumbnailer %s created an invalid PNG: %w", thumbnailer.ID, err)
+	}
+
+	// Many thumbnailers do not respect the requested size exactly
+	return SaveWithOptions(ScaleToFit(img, size), size, Info{
+		URI:      uri,
+		MTime:    stat.ModTime(),
+		Size:     stat.Size(),
+		MimeType: mimeType,
+	}, i.Options)
+}
+
+// thumbnailers returns Thumbnailers or, if nil, the thumbnailers of the system.
+func (i *Invoker) thumbnailers() []Thumbnailer {
+	if i.Thumbnailers != nil {
+		return i.Thumbnailers
+	}
+
+	i.loadOnce.Do(func() {
+		i.loaded = LoadThumbnailers(nil)
+	})
+
+	return i.loaded
+}
@@ -0,0 +1,55 @@
+package thumbnail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotFound = errors.New("thumbnail not found")
+
+// Lookup returns the path and size of the existing thumbnail that best satisfies the requested
+// dimension, in pixels, for the file with the given URI.
+// The smallest thumbnail that is at least dimension pixels is preferred. If no such thumbnail
+// exists, the largest available thumbnail is returned instead, which callers can detect by
+// comparing the returned Size with dimension.
+// Thumbnails in the legacy ~/.thumbnails directory are used when none can be found in Dir.
+// If no thumbnail exists, ErrNotFound is returned.
+func Lookup(uri string, dimension int) (string, Size, error) {
+	var fallbackPath string
+	var fallbackSize Size
+
+	for _, size := range Sizes {
+		path := findExisting(uri, size)
+		if path == "" {
+			continue
+		}
+
+		if int(size) >= dimension {
+			return path, size, nil
+		}
+
+		fallbackPath = path
+		fallbackSize = size
+	}
+
+	if fallbackPath == "" {
+		return "", 0, ErrNotFound
+	}
+
+	return fallbackPath, fallbackSize, nil
+}
+
+// findExisting returns the path of the thumbnail of the given size if it exists, either in Dir
+// or in the legacy directory. Otherwise, an empty string is returned.
+func findExisting(uri string, size Size) string {
+	for _, dir := range []string{Dir(), legacyDir()} {
+		path := filepath.Join(dir, size.DirName(), Name(uri))
+		stat, err := os.Stat(path)
+		if err == nil && stat.Mode().IsRegular() {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,97 @@
+package thumbnail
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// ReadInfo reads the metadata stored in the tEXt chunks of a thumbnail.
+// Only the chunks in front of the image data are read.
+func ReadInfo(reader io.Reader) (Info, error) {
+	r := bufio.NewReader(reader)
+	var info Info
+
+	signature := make([]byte, len(pngSignature))
+	_, err := io.ReadFull(r, signature)
+	if err != nil || !bytes.Equal(signature, pngSignature) {
+		return info, fmt.Errorf("ReadInfo: not a PNG file")
+	}
+
+	var header [8]byte
+	for {
+		_, err := io.ReadFull(r, header[:])
+		switch {
+		case errors.Is(err, io.EOF):
+			return info, nil
+		case err != nil:
+			return info, fmt.Errorf("ReadInfo: failed to read chunk: %w", err)
+		}
+
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:])
+
+		switch chunkType {
+		case "IDAT", "IEND":
+			return info, nil
+		case "tEXt":
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return info, fmt.Errorf("ReadInfo: failed to read tEXt chunk: %w", err)
+			}
+			keyword, text, found := bytes.Cut(data, []byte{0})
+			if found {
+				info.apply(string(keyword), string(text))
+			}
+			// Skip CRC
+			if _, err := r.Discard(4); err != nil {
+				return info, fmt.Errorf("ReadInfo: failed to read chunk: %w", err)
+			}
+		default:
+			if _, err := r.Discard(int(length) + 4); err != nil {
+				return info, fmt.Errorf("ReadInfo: failed to skip %s chunk: %w", chunkType, err)
+			}
+		}
+	}
+}
+
+// ReadInfoFile reads the metadata stored in the thumbnail at path.
+func ReadInfoFile(path string) (Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer file.Close()
+
+	return ReadInfo(file)
+}
+
+// apply stores the value of a known metadata key. Unknown keys and invalid values are ignored.
+func (i *Info) apply(key string, value string) {
+	switch key {
+	case keyURI:
+		i.URI = value
+	case keyMTime:
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			i.MTime = time.Unix(seconds, 0)
+		}
+	case keySize:
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			i.Size = size
+		}
+	case keyMimetype:
+		i.MimeType = value
+	case keySoftware:
+		i.Software = value
+	}
+}
@@ -0,0 +1,75 @@
+package thumbnail
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Sandbox wraps the arguments of a thumbnailer so that it runs in a sandbox.
+// args are the expanded arguments of the thumbnailer. The sandbox must give read access to
+// inputPath and write access to outputDir, the directory in which the thumbnailer creates the
+// thumbnail. The returned arguments are executed instead of args.
+type Sandbox func(args []string, inputPath string, outputDir string) ([]string, error)
+
+// BubblewrapSandbox returns a Sandbox which runs thumbnailers using bwrap, similar to how GNOME
+// runs them. The thumbnailer has read-only access to the system directories and the input
+// file, write access to the output directory only, and no network access.
+// Thumbnailers outside the system directories, e.g. in /opt or $HOME, are not available inside
+// the sandbox.
+func BubblewrapSandbox() Sandbox {
+	return func(args []string, inputPath string, outputDir string) ([]string, error) {
+		bwrap, err := exec.LookPath("bwrap")
+		if err != nil {
+			return nil, fmt.Errorf("bubblewrap sandbox is unavailable: %w", err)
+		}
+
+		result := []string{
+			bwrap,
+			"--ro-bind", "/usr", "/usr",
+			"--ro-bind-try", "/etc/ld.so.cache", "/etc/ld.so.cache",
+			"--ro-bind-try", "/etc/alternatives", "/etc/alternatives",
+			"--ro-bind-try", "/etc/fonts", "/etc/fonts",
+			"--symlink-try", "usr/bin", "/bin",
+			"--symlink-try", "usr/sbin", "/sbin",
+			"--symlink-try", "usr/lib", "/lib",
+			"--symlink-try", "usr/lib64", "/lib64",
+			"--proc", "/proc",
+			"--dev", "/dev",
+			"--tmpfs", "/tmp",
+			"--unshare-all",
+			"--die-with-parent",
+			"--new-session",
+			"--setenv", "GIO_USE_VFS", "local",
+			"--ro-bind", inputPath, inputPath,
+			"--bind", outputDir, outputDir,
+			"--chdir", "/",
+			"--",
+		}
+
+		return append(result, args...), nil
+	}
+}
+
+// sandboxed returns the arguments to execute for the thumbnailer, applying the sandbox of the
+// invoker unless the thumbnailer is allowed to run unsandboxed.
+func (i *Invoker) sandboxed(
+	thumbnailer *Thumbnailer,
+	args []string,
+	inputPath string,
+	outputDir string,
+) ([]string, error) {
+	if i.Sandbox == nil {
+		return args, nil
+	}
+
+	if i.AllowUnsandboxed != nil && i.AllowUnsandboxed(*thumbnailer) {
+		return args, nil
+	}
+
+	wrapped, err := i.Sandbox(args, inputPath, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sandbox thumbnailer %s: %w", thumbnailer.ID, err)
+	}
+
+	return wrapped, nil
+}
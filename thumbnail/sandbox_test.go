@@ -0,0 +1,42 @@
+package thumbnail
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInvokerSandboxed(t *testing.T) {
+	var sandboxCalls int
+	invoker := Invoker{
+		Sandbox: func(args []string, inputPath string, outputDir string) ([]string, error) {
+			sandboxCalls++
+			return append([]string{"sandbox", inputPath, outputDir}, args...), nil
+		},
+		AllowUnsandboxed: func(thumbnailer Thumbnailer) bool {
+			return thumbnailer.ID == "trusted"
+		},
+	}
+
+	args := []string{"thumbnailer", "/in.png", "/out/thumbnail.png"}
+
+	actual, err := invoker.sandboxed(&Thumbnailer{ID: "untrusted"}, args, "/in.png", "/out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"sandbox", "/in.png", "/out", "thumbnailer", "/in.png", "/out/thumbnail.png"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("sandboxed = %q, expected: %q", actual, expected)
+	}
+
+	actual, err = invoker.sandboxed(&Thumbnailer{ID: "trusted"}, args, "/in.png", "/out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(actual, args) {
+		t.Errorf("trusted thumbnailer was sandboxed: %q", actual)
+	}
+
+	if sandboxCalls != 1 {
+		t.Errorf("sandbox was called %d times, expected: 1", sandboxCalls)
+	}
+}
@@ -0,0 +1,56 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+)
+
+// ScaleToFit returns img scaled down, keeping its aspect ratio, so that neither its width nor
+// its height exceeds size. Images that already fit are returned as is.
+// Each pixel of the result is the average of the pixels it covers in img.
+func ScaleToFit(img image.Image, size Size) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= int(size) && height <= int(size) {
+		return img
+	}
+
+	scaledWidth, scaledHeight := int(size), int(size)
+	if width > height {
+		scaledHeight = max(1, height*int(size)/width)
+	} else {
+		scaledWidth = max(1, width*int(size)/height)
+	}
+
+	result := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		y0 := bounds.Min.Y + y*height/scaledHeight
+		y1 := bounds.Min.Y + (y+1)*height/scaledHeight
+
+		for x := 0; x < scaledWidth; x++ {
+			x0 := bounds.Min.X + x*width/scaledWidth
+			x1 := bounds.Min.X + (x+1)*width/scaledWidth
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					count++
+				}
+			}
+
+			result.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return result
+}
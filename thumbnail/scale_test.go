@@ -0,0 +1,42 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestScaleToFit(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 310, 160))
+	for y := 10; y < 160; y++ {
+		for x := 10; x < 310; x++ {
+			if x < 160 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	scaled := ScaleToFit(img, SizeNormal)
+	if expected := image.Rect(0, 0, 128, 64); scaled.Bounds() != expected {
+		t.Errorf("Bounds() = %v, expected: %v", scaled.Bounds(), expected)
+	}
+
+	if r, _, _, _ := scaled.At(0, 0).RGBA(); r != 0xffff {
+		t.Errorf("left pixel red = %#x, expected: 0xffff", r)
+	}
+	if r, _, _, _ := scaled.At(127, 63).RGBA(); r != 0 {
+		t.Errorf("right pixel red = %#x, expected: 0", r)
+	}
+
+	tall := ScaleToFit(image.NewRGBA(image.Rect(0, 0, 1, 1000)), SizeNormal)
+	if expected := image.Rect(0, 0, 1, 128); tall.Bounds() != expected {
+		t.Errorf("Bounds() = %v, expected: %v", tall.Bounds(), expected)
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, 128, 20))
+	if ScaleToFit(small, SizeNormal) != image.Image(small) {
+		t.Errorf("ScaleToFit() did not return an image that fits as is")
+	}
+}
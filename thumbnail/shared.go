@@ -0,0 +1,137 @@
+package thumbnail
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+const sharedDirName = ".sh_thumbnails"
+
+// Options configures the use of shared thumbnail repositories.
+//
+// A shared repository is a .sh_thumbnails directory next to the original files. It is used for
+// media that is accessed from multiple systems such as network shares and removable media.
+// Thumbnails in a shared repository are named after the MD5 hash of the file name instead of the
+// full URI.
+type Options struct {
+	// SharedLookup, if true, makes lookups consult the shared repository of local files before
+	// the personal repository.
+	SharedLookup bool
+
+	// SharedSave, if true, makes saving store thumbnails of local files in the shared repository
+	// when its parent directory is writable. Otherwise, the personal repository is used.
+	SharedSave bool
+}
+
+// SharedDir returns the shared repository of the file at path, .sh_thumbnails in the same
+// directory as the file.
+func SharedDir(path string) string {
+	return filepath.Join(filepath.Dir(path), sharedDirName)
+}
+
+// SharedURI returns the relative URI that identifies the file at path in its shared repository.
+// This is the percent-encoded file name.
+func SharedURI(path string) string {
+	return fileuri.EscapePath(filepath.Base(path))
+}
+
+// SharedPathFor returns the path the thumbnail of the given size has in the shared repository of
+// the file at path. Existence of the thumbnail is not checked.
+func SharedPathFor(path string, size Size) string {
+	return filepath.Join(SharedDir(path), size.DirName(), Name(SharedURI(path)))
+}
+
+// LookupWithOptions looks up the thumbnail like Lookup. If Options.SharedLookup is set and uri
+// is a file URI, the shared repository of the file is searched first.
+func LookupWithOptions(uri string, dimension int, options Options) (string, Size, error) {
+	if options.SharedLookup {
+		if path, err := fileuri.ToPath(uri); err == nil {
+			thumbPath, size, err := lookupShared(path, dimension)
+			if err == nil {
+				return thumbPath, size, nil
+			}
+		}
+	}
+
+	return Lookup(uri, dimension)
+}
+
+// lookupShared returns the thumbnail that best satisfies dimension from the shared repository of
+// the file at path. See Lookup for the selection rules.
+func lookupShared(path string, dimension int) (string, Size, error) {
+	var fallbackPath string
+	var fallbackSize Size
+
+	for _, size := range Sizes {
+		thumbPath := SharedPathFor(path, size)
+		stat, err := os.Stat(thumbPath)
+		if err != nil || !stat.Mode().IsRegular() {
+			continue
+		}
+
+		if int(size) >= dimension {
+			return thumbPath, size, nil
+		}
+
+		fallbackPath = thumbPath
+		fallbackSize = size
+	}
+
+	if fallbackPath == "" {
+		return "", 0, ErrNotFound
+	}
+
+	return fallbackPath, fallbackSize, nil
+}
+
+// SaveWithOptions stores the thumbnail like Save. If Options.SharedSave is set and info.URI is a
+// file URI of which the directory is writable, the thumbnail is stored in the shared repository
+// instead.
+//
+// As required by the standard, thumbnails in the shared repository get the permissions of the
+// original file, without execute permissions, and their Thumb::URI is the relative URI of the
+// file.
+func SaveWithOptions(img image.Image, size Size, info Info, options Options) (string, error) {
+	if !options.SharedSave {
+		return Save(img, size, info)
+	}
+
+	path, err := fileuri.ToPath(info.URI)
+	if err != nil || !isWritableDir(filepath.Dir(path)) {
+		return Save(img, size, info)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("SaveWithOptions: failed to stat %s: %w", path, err)
+	}
+
+	if size.DirName() == "" {
+		return "", fmt.Errorf("SaveWithOptions: unsupported thumbnail size %d", size)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() > int(size) || bounds.Dy() > int(size) {
+		return "", fmt.Errorf("SaveWithOptions: %w", ErrImageTooLarge)
+	}
+
+	info.URI = SharedURI(path)
+	var buffer bytes.Buffer
+	err = Encode(&buffer, img, info)
+	if err != nil {
+		return "", fmt.Errorf("SaveWithOptions: %w", err)
+	}
+
+	thumbPath := SharedPathFor(path, size)
+	err = fileutil.WriteFileAtomic(thumbPath, buffer.Bytes(), stat.Mode().Perm()&^0111)
+	if err != nil {
+		return "", fmt.Errorf("SaveWithOptions: %w", err)
+	}
+
+	return thumbPath, nil
+}
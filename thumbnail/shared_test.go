@@ -0,0 +1,69 @@
+package thumbnail
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSharedRepository(t *testing.T) {
+	home := testenv.Setup(t)
+	media := filepath.Join(home, "media")
+	original := filepath.Join(media, "my photo.jpg")
+	if err := os.MkdirAll(media, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("jpg"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	uri, err := URIForPath(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := Options{SharedLookup: true, SharedSave: true}
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	info := Info{URI: uri, MTime: time.Unix(1700000000, 0)}
+
+	path, err := SaveWithOptions(img, SizeNormal, info, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPath := filepath.Join(media, ".sh_thumbnails", "normal", Hash("my%20photo.jpg")+".png")
+	if path != expectedPath {
+		t.Errorf("path = %s, expected: %s", path, expectedPath)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0640 {
+		t.Errorf("permissions = %o, expected the permissions of the original: 640", stat.Mode().Perm())
+	}
+
+	stored, err := ReadInfoFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.URI != "my%20photo.jpg" {
+		t.Errorf("Thumb::URI = %s, expected the relative URI", stored.URI)
+	}
+
+	found, size, err := LookupWithOptions(uri, 100, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != path || size != SizeNormal {
+		t.Errorf("LookupWithOptions = %s (%d), expected: %s (%d)", found, size, path, SizeNormal)
+	}
+
+	if _, _, err := LookupWithOptions(uri, 100, Options{}); err == nil {
+		t.Errorf("shared repository was used without SharedLookup")
+	}
+}
@@ -0,0 +1,74 @@
+// Package thumbnail implements the [Thumbnail Managing Standard].
+//
+// Thumbnails are stored as PNG files in $XDG_CACHE_HOME/thumbnails/$size where $size is one of
+// normal, large, x-large, and xx-large. The name of a thumbnail is the MD5 hash of the URI of
+// the original file.
+//
+// [Thumbnail Managing Standard]: https://specifications.freedesktop.org/thumbnail-spec/0.9.0/
+package thumbnail
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"path/filepath"
+)
+
+// Size is the maximum width and height in pixels of a thumbnail.
+type Size int
+
+const (
+	SizeNormal  Size = 128
+	SizeLarge   Size = 256
+	SizeXLarge  Size = 512
+	SizeXXLarge Size = 1024
+)
+
+// Sizes contains all sizes defined by the standard, from small to large.
+var Sizes = []Size{SizeNormal, SizeLarge, SizeXLarge, SizeXXLarge}
+
+// DirName returns the name of the directory containing thumbnails of this size, e.g. large.
+// For sizes not defined by the standard, an empty string is returned.
+func (s Size) DirName() string {
+	switch s {
+	case SizeNormal:
+		return "normal"
+	case SizeLarge:
+		return "large"
+	case SizeXLarge:
+		return "x-large"
+	case SizeXXLarge:
+		return "xx-large"
+	default:
+		return ""
+	}
+}
+
+// Dir returns the directory containing the thumbnails, $XDG_CACHE_HOME/thumbnails.
+func Dir() string {
+	return filepath.Join(basedir.CacheHome, "thumbnails")
+}
+
+// legacyDir returns the directory used by older versions of the standard, ~/.thumbnails.
+// It is only read, never written.
+func legacyDir() string {
+	return filepath.Join(basedir.Home, ".thumbnails")
+}
+
+// Hash returns the MD5 hash of the URI in hexadecimal notation. This is the name of the
+// thumbnail without its .png extension.
+func Hash(uri string) string {
+	sum := md5.Sum([]byte(uri))
+	return hex.EncodeToString(sum[:])
+}
+
+// Name returns the file name of the thumbnail of the file with the given URI.
+func Name(uri string) string {
+	return Hash(uri) + ".png"
+}
+
+// PathFor returns the path the thumbnail of the given size has for the file with the given URI.
+// Existence of the thumbnail is not checked.
+func PathFor(uri string, size Size) string {
+	return filepath.Join(Dir(), size.DirName(), Name(uri))
+}
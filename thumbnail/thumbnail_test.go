@@ -0,0 +1,71 @@
+package thumbnail
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	// Example from the Thumbnail Managing Standard
+	actual := Hash("file:///home/jens/photos/me.png")
+	expected := "c6ee772d9e49320e97ec29a7eb5b1697"
+	if actual != expected {
+		t.Errorf("Hash = %s, expected: %s", actual, expected)
+	}
+}
+
+func TestURIForPath(t *testing.T) {
+	actual, err := URIForPath("/home/user/my photo#1 (ü).jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "file:///home/user/my%20photo%231%20(%C3%BC).jpg"
+	if actual != expected {
+		t.Errorf("URIForPath = %s, expected: %s", actual, expected)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	home := testenv.Setup(t)
+	uri := "file:///home/jens/photos/me.png"
+
+	_, _, err := Lookup(uri, 128)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+
+	testenv.WriteFile(t, filepath.Join(home, ".thumbnails", "normal", Name(uri)), "")
+	testenv.WriteFile(t, PathFor(uri, SizeXLarge), "")
+
+	tests := []struct {
+		dimension    int
+		expectedPath string
+		expectedSize Size
+	}{
+		{100, filepath.Join(home, ".thumbnails", "normal", Name(uri)), SizeNormal},
+		{200, PathFor(uri, SizeXLarge), SizeXLarge},
+		{512, PathFor(uri, SizeXLarge), SizeXLarge},
+		{2000, PathFor(uri, SizeXLarge), SizeXLarge},
+	}
+
+	for _, test := range tests {
+		path, size, err := Lookup(uri, test.dimension)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if path != test.expectedPath || size != test.expectedSize {
+			t.Errorf(
+				"Lookup(%d) = %s (%d), expected: %s (%d)",
+				test.dimension,
+				path,
+				size,
+				test.expectedPath,
+				test.expectedSize,
+			)
+		}
+	}
+}
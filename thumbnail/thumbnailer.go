@@ -0,0 +1,239 @@
+package thumbnail
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const thumbnailerGroup = "Thumbnailer Entry"
+
+// Thumbnailer is an external program, described by a .thumbnailer file, that creates thumbnails
+// for specific MIME types.
+type Thumbnailer struct {
+	// ID is the name of the .thumbnailer file without extension, e.g. gdk-pixbuf-thumbnailer.
+	ID string
+
+	// Path of the .thumbnailer file.
+	Path string
+
+	// TryExec is the executable that must be available for the thumbnailer to be usable.
+	TryExec string
+
+	// Exec is the command line of the thumbnailer split into arguments. Field codes in quoted
+	// arguments are expanded as well. The following field codes are supported:
+	//  - %i: path of the input file
+	//  - %u: URI of the input file
+	//  - %o: path of the output file
+	//  - %s: size of the thumbnail in pixels
+	//  - %%: a literal %
+	Exec []string
+
+	// MimeTypes that the thumbnailer supports.
+	MimeTypes []string
+}
+
+// GetThumbnailerDirs returns the directories containing .thumbnailer files in order of
+// precedence: $XDG_DATA_HOME/thumbnailers followed by thumbnailers in each $XDG_DATA_DIRS.
+func GetThumbnailerDirs() []string {
+	result := []string{filepath.Join(basedir.DataHome, "thumbnailers")}
+
+	for _, dir := range basedir.DataDirs {
+		result = append(result, filepath.Join(dir, "thumbnailers"))
+	}
+
+	return result
+}
+
+// LoadThumbnailers loads all .thumbnailer files in the given directories. If dirs is nil,
+// GetThumbnailerDirs is used.
+// A thumbnailer in a directory of higher precedence hides thumbnailers with the same ID in
+// directories of lower precedence. Files that cannot be parsed are skipped.
+func LoadThumbnailers(dirs []string) []Thumbnailer {
+	if dirs == nil {
+		dirs = GetThumbnailerDirs()
+	}
+
+	var result []Thumbnailer
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			continue
+		case err != nil:
+			logging.Logger().Warn(
+				"Failed to read thumbnailer directory",
+				logging.Path, dir,
+				logging.Error, err,
+			)
+			continue
+		}
+
+		for _, entry := range entries {
+			id, isThumbnailer := strings.CutSuffix(entry.Name(), ".thumbnailer")
+			if !isThumbnailer || entry.IsDir() || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			path := filepath.Join(dir, entry.Name())
+			thumbnailer, err := ParseThumbnailerFile(path)
+			if err != nil {
+				logging.Logger().Warn(
+					"Skipping invalid thumbnailer",
+					logging.Path, path,
+					logging.Error, err,
+				)
+				continue
+			}
+
+			result = append(result, *thumbnailer)
+		}
+	}
+
+	return result
+}
+
+// ParseThumbnailerFile parses the .thumbnailer file at path.
+func ParseThumbnailerFile(path string) (*Thumbnailer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseThumbnailerFile: %w", err)
+	}
+	defer file.Close()
+
+	thumbnailer, err := ParseThumbnailer(file)
+	if err != nil {
+		return nil, fmt.Errorf("ParseThumbnailerFile: failed to parse %s: %w", path, err)
+	}
+
+	thumbnailer.ID = strings.TrimSuffix(filepath.Base(path), ".thumbnailer")
+	thumbnailer.Path = path
+
+	return thumbnailer, nil
+}
+
+// ParseThumbnailer parses the contents of a .thumbnailer file.
+// The file uses the key file format of desktop entries. Exec follows the quoting and escaping
+// rules of the Exec key of desktop entries, see [desktop.SplitExec].
+func ParseThumbnailer(reader io.Reader) (*Thumbnailer, error) {
+	doc, err := desktop.ParseDocument(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(doc.Groups(), thumbnailerGroup) {
+		return nil, fmt.Errorf("missing [%s] group", thumbnailerGroup)
+	}
+
+	var result Thumbnailer
+	if value, found := doc.Get(thumbnailerGroup, "TryExec"); found {
+		tryExec, err := desktop.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TryExec %s: %w", value, err)
+		}
+		result.TryExec = strings.TrimSpace(tryExec.(string))
+	}
+
+	value, _ := doc.Get(thumbnailerGroup, "Exec")
+	if value == "" {
+		return nil, fmt.Errorf("Exec field is required")
+	}
+	result.Exec, err = desktop.SplitExec(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Exec %s: %w", value, err)
+	}
+
+	if value, found := doc.Get(thumbnailerGroup, "MimeType"); found {
+		mimeTypes, err := desktop.DecodeList(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MimeType %s: %w", value, err)
+		}
+		for _, mime := range mimeTypes.([]string) {
+			if mime = strings.TrimSpace(mime); mime != "" {
+				result.MimeTypes = append(result.MimeTypes, mime)
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+// Supports returns true if the thumbnailer supports the MIME type.
+func (t Thumbnailer) Supports(mimeType string) bool {
+	return slices.Contains(t.MimeTypes, mimeType)
+}
+
+// IsAvailable returns true if the executable of the thumbnailer can be found. TryExec is checked
+// if set, otherwise the first argument of Exec.
+// Relative names are looked up in $PATH.
+func (t Thumbnailer) IsAvailable() bool {
+	executable := t.TryExec
+	if executable == "" && len(t.Exec) > 0 {
+		executable = t.Exec[0]
+	}
+
+	if executable == "" {
+		return false
+	}
+
+	_, err := exec.LookPath(executable)
+	return err == nil
+}
+
+// Arguments returns the Exec arguments with the field codes expanded.
+func (t Thumbnailer) Arguments(inputPath string, inputURI string, outputPath string, size Size) []string {
+	result := make([]string, 0, len(t.Exec))
+
+	for _, arg := range t.Exec {
+		var builder strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 >= len(arg) {
+				builder.WriteByte(arg[i])
+				continue
+			}
+
+			i++
+			switch arg[i] {
+			case 'i':
+				builder.WriteString(inputPath)
+			case 'u':
+				builder.WriteString(inputURI)
+			case 'o':
+				builder.WriteString(outputPath)
+			case 's':
+				builder.WriteString(strconv.Itoa(int(size)))
+			case '%':
+				builder.WriteByte('%')
+			default:
+				builder.WriteByte('%')
+				builder.WriteByte(arg[i])
+			}
+		}
+		result = append(result, builder.String())
+	}
+
+	return result
+}
+
+// FindThumbnailer returns the first available thumbnailer that supports the MIME type.
+func FindThumbnailer(thumbnailers []Thumbnailer, mimeType string) (*Thumbnailer, bool) {
+	for i := range thumbnailers {
+		if thumbnailers[i].Supports(mimeType) && thumbnailers[i].IsAvailable() {
+			return &thumbnailers[i], true
+		}
+	}
+
+	return nil, false
+}
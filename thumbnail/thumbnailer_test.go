@@ -0,0 +1,155 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseThumbnailer(t *testing.T) {
+	result, err := ParseThumbnailer(strings.NewReader(`
+[Thumbnailer Entry]
+TryExec=/usr/bin/gdk-pixbuf-thumbnailer
+Exec=/usr/bin/gdk-pixbuf-thumbnailer -s %s "%u" "out file %%.png"
+MimeType=image/png;image/jpeg;
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedExec := []string{"/usr/bin/gdk-pixbuf-thumbnailer", "-s", "%s", "%u", "out file %%.png"}
+	if !slices.Equal(result.Exec, expectedExec) {
+		t.Errorf("Exec = %q, expected: %q", result.Exec, expectedExec)
+	}
+
+	expectedMime := []string{"image/png", "image/jpeg"}
+	if !slices.Equal(result.MimeTypes, expectedMime) {
+		t.Errorf("MimeTypes = %v, expected: %v", result.MimeTypes, expectedMime)
+	}
+
+	args := result.Arguments("/a b.png", "file:///a%20b.png", "/tmp/o.png", SizeLarge)
+	expectedArgs := []string{
+		"/usr/bin/gdk-pixbuf-thumbnailer", "-s", "256", "file:///a%20b.png", "out file %.png",
+	}
+	if !slices.Equal(args, expectedArgs) {
+		t.Errorf("Arguments = %q, expected: %q", args, expectedArgs)
+	}
+}
+
+func TestParseThumbnailerMissingGroup(t *testing.T) {
+	_, err := ParseThumbnailer(strings.NewReader("Exec=foo %i %o\n"))
+	if err == nil {
+		t.Errorf("expected an error for a missing group")
+	}
+}
+
+func TestInvokerGenerate(t *testing.T) {
+	home := testenv.Setup(t)
+
+	script := filepath.Join(home, "copy-thumbnailer")
+	err := os.WriteFile(script, []byte("#!/bin/sh\ncp \"$1\" \"$2\"\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbnailerDir := filepath.Join(home, "thumbnailers")
+	err = os.MkdirAll(thumbnailerDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(
+		filepath.Join(thumbnailerDir, "copy.thumbnailer"),
+		[]byte("[Thumbnailer Entry]\nExec="+script+" %i %o\nMimeType=image/png;\n"),
+		0600,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := filepath.Join(home, "image.png")
+	file, err := os.Create(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The thumbnailer copies the image which is larger than the requested size
+	err = png.Encode(file, image.NewRGBA(image.Rect(0, 0, 256, 128)))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoker := Invoker{Thumbnailers: LoadThumbnailers([]string{thumbnailerDir})}
+	if len(invoker.Thumbnailers) != 1 {
+		t.Fatalf("expected 1 thumbnailer, got: %d", len(invoker.Thumbnailers))
+	}
+
+	_, err = invoker.Generate(context.Background(), original, "image/jpeg", SizeNormal)
+	if !errors.Is(err, ErrNoThumbnailer) {
+		t.Errorf("expected ErrNoThumbnailer, got: %v", err)
+	}
+
+	path, err := invoker.Generate(context.Background(), original, "image/png", SizeNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uri, _ := URIForPath(original)
+	if path != PathFor(uri, SizeNormal) {
+		t.Errorf("path = %s, expected: %s", path, PathFor(uri, SizeNormal))
+	}
+
+	info, err := ReadInfoFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.URI != uri || info.MimeType != "image/png" {
+		t.Errorf("unexpected thumbnail metadata: %+v", info)
+	}
+
+	thumbnail, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer thumbnail.Close()
+	config, err := png.DecodeConfig(thumbnail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 128 || config.Height != 64 {
+		t.Errorf("thumbnail is %dx%d, expected: 128x64", config.Width, config.Height)
+	}
+}
+
+func TestInvokerConcurrentLoad(t *testing.T) {
+	home := testenv.Setup(t)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local/share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "usr/share"))
+	basedir.Reinit()
+
+	original := filepath.Join(home, "image.png")
+	testenv.WriteFile(t, original, "")
+
+	// The system thumbnailers are loaded by whichever goroutine comes first
+	var invoker Invoker
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := invoker.Generate(context.Background(), original, "image/png", SizeNormal)
+			if !errors.Is(err, ErrNoThumbnailer) {
+				t.Errorf("expected ErrNoThumbnailer, got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
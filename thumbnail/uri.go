@@ -0,0 +1,19 @@
+package thumbnail
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+)
+
+// URIForPath returns the canonical file URI of the file at path, which is used to compute the
+// thumbnail's name. The path is made absolute and cleaned, bytes outside the set allowed in URI
+// paths are percent-encoded using upper case hexadecimal digits, like GLib does.
+// E.g. /home/user/my photo.jpg becomes file:///home/user/my%20photo.jpg.
+func URIForPath(path string) (string, error) {
+	uri, err := fileuri.FromPath(path)
+	if err != nil {
+		return "", fmt.Errorf("URIForPath: %w", err)
+	}
+
+	return uri, nil
+}
@@ -0,0 +1,93 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrFailedBefore is returned by GetOrGenerate when a previous attempt to create the thumbnail
+// failed and the file has not been modified since.
+var ErrFailedBefore = errors.New("thumbnail generation failed before")
+
+// IsValid returns true if the thumbnail at thumbPath belongs to the file with the given URI and
+// is up to date with the modification time of the file.
+// Thumbnails from a shared repository, which store the relative URI of the file, are supported.
+func IsValid(thumbPath string, fileURI string, mtime time.Time) bool {
+	info, err := ReadInfoFile(thumbPath)
+	if err != nil {
+		return false
+	}
+
+	if info.MTime.Unix() != mtime.Unix() {
+		return false
+	}
+
+	if info.URI == fileURI {
+		return true
+	}
+
+	// Thumbnails in a shared repository store the relative URI
+	return !strings.Contains(info.URI, "/") &&
+		filepath.Base(filepath.Dir(filepath.Dir(thumbPath))) == sharedDirName &&
+		strings.HasSuffix(fileURI, "/"+info.URI)
+}
+
+// GetOrGenerate returns the path of a valid thumbnail of at least the given size for the local
+// file at path. An existing thumbnail is returned if it is valid, otherwise a new thumbnail is
+// generated using Generate.
+//
+// If AppName is set, failures are recorded in its fail directory and generation is not retried
+// for files that failed before and have not been modified since. In that case, ErrFailedBefore
+// is returned.
+func (i *Invoker) GetOrGenerate(
+	ctx context.Context,
+	path string,
+	mimeType string,
+	size Size,
+) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("GetOrGenerate: failed to make %s absolute: %w", path, err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("GetOrGenerate: %w", err)
+	}
+
+	uri, err := URIForPath(path)
+	if err != nil {
+		return "", fmt.Errorf("GetOrGenerate: %w", err)
+	}
+
+	thumbPath, thumbSize, err := LookupWithOptions(uri, int(size), i.Options)
+	if err == nil && thumbSize >= size && IsValid(thumbPath, uri, stat.ModTime()) {
+		return thumbPath, nil
+	}
+
+	if i.AppName != "" && HasFailed(i.AppName, uri, stat.ModTime()) {
+		return "", fmt.Errorf("GetOrGenerate: %w: %s", ErrFailedBefore, path)
+	}
+
+	thumbPath, err = i.Generate(ctx, path, mimeType, size)
+	switch {
+	case err == nil:
+		return thumbPath, nil
+	case i.AppName == "", errors.Is(err, ErrNoThumbnailer), ctx.Err() != nil:
+		return "", err
+	}
+
+	_, recordErr := RecordFailure(i.AppName, Info{
+		URI:      uri,
+		MTime:    stat.ModTime(),
+		Size:     stat.Size(),
+		MimeType: mimeType,
+	})
+
+	return "", errors.Join(err, recordErr)
+}
@@ -0,0 +1,73 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsValid(t *testing.T) {
+	testenv.Setup(t)
+	uri := "file:///home/jens/photos/me.png"
+	mtime := time.Unix(1700000000, 0)
+
+	path, err := Save(image.NewRGBA(image.Rect(0, 0, 1, 1)), SizeNormal, Info{URI: uri, MTime: mtime})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsValid(path, uri, mtime) {
+		t.Errorf("IsValid = false for matching metadata")
+	}
+
+	if IsValid(path, uri, mtime.Add(time.Second)) {
+		t.Errorf("IsValid = true for a modified file")
+	}
+
+	if IsValid(path, "file:///other.png", mtime) {
+		t.Errorf("IsValid = true for another URI")
+	}
+
+	if IsValid(filepath.Join(Dir(), "nonexistent.png"), uri, mtime) {
+		t.Errorf("IsValid = true for a nonexistent thumbnail")
+	}
+}
+
+func TestGetOrGenerateFailure(t *testing.T) {
+	home := testenv.Setup(t)
+
+	script := filepath.Join(home, "failing-thumbnailer")
+	err := os.WriteFile(script, []byte("#!/bin/sh\nexit 1\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := filepath.Join(home, "broken.png")
+	if err := os.WriteFile(original, []byte("not a png"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	invoker := Invoker{
+		Thumbnailers: []Thumbnailer{{
+			ID:        "failing",
+			Exec:      []string{script, "%i", "%o"},
+			MimeTypes: []string{"image/png"},
+		}},
+		AppName: "test-1.0",
+	}
+
+	_, err = invoker.GetOrGenerate(context.Background(), original, "image/png", SizeNormal)
+	if err == nil || errors.Is(err, ErrFailedBefore) {
+		t.Fatalf("expected the thumbnailer to fail, got: %v", err)
+	}
+
+	_, err = invoker.GetOrGenerate(context.Background(), original, "image/png", SizeNormal)
+	if !errors.Is(err, ErrFailedBefore) {
+		t.Errorf("expected ErrFailedBefore on the second attempt, got: %v", err)
+	}
+}
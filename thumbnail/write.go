@@ -0,0 +1,161 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"time"
+)
+
+// pngHeaderLength is the length of the PNG signature and the IHDR chunk which must come first.
+const pngHeaderLength = 8 + 4 + 4 + 13 + 4
+
+const (
+	keyURI      = "Thumb::URI"
+	keyMTime    = "Thumb::MTime"
+	keySize     = "Thumb::Size"
+	keyMimetype = "Thumb::Mimetype"
+	keySoftware = "Software"
+)
+
+var ErrImageTooLarge = errors.New("image is larger than the thumbnail size")
+
+// Info is the metadata that is stored in a thumbnail.
+type Info struct {
+	// URI of the original file. Required.
+	URI string
+
+	// MTime is the modification time of the original file. Required.
+	// It is stored with a precision of one second.
+	MTime time.Time
+
+	// Size of the original file in bytes. It is only stored if larger than zero.
+	Size int64
+
+	// MimeType of the original file. It is only stored if non-empty.
+	MimeType string
+
+	// Software that created the thumbnail. It is only stored if non-empty.
+	Software string
+}
+
+// textChunks returns the keyword-value pairs to store in the thumbnail.
+func (i Info) textChunks() [][2]string {
+	chunks := [][2]string{
+		{keyURI, i.URI},
+		{keyMTime, strconv.FormatInt(i.MTime.Unix(), 10)},
+	}
+
+	if i.Size > 0 {
+		chunks = append(chunks, [2]string{keySize, strconv.FormatInt(i.Size, 10)})
+	}
+
+	if i.MimeType != "" {
+		chunks = append(chunks, [2]string{keyMimetype, i.MimeType})
+	}
+
+	if i.Software != "" {
+		chunks = append(chunks, [2]string{keySoftware, i.Software})
+	}
+
+	return chunks
+}
+
+// Encode writes img as PNG including the metadata of info to w.
+func Encode(w io.Writer, img image.Image, info Info) error {
+	if info.URI == "" {
+		return fmt.Errorf("Encode: URI is required")
+	}
+
+	if info.MTime.IsZero() {
+		return fmt.Errorf("Encode: MTime is required")
+	}
+
+	var buffer bytes.Buffer
+	err := png.Encode(&buffer, img)
+	if err != nil {
+		return fmt.Errorf("Encode: failed to encode PNG: %w", err)
+	}
+	encoded := buffer.Bytes()
+
+	if _, err := w.Write(encoded[:pngHeaderLength]); err != nil {
+		return err
+	}
+
+	for _, chunk := range info.textChunks() {
+		if err := writeTextChunk(w, chunk[0], chunk[1]); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(encoded[pngHeaderLength:])
+
+	return err
+}
+
+// writeTextChunk writes a PNG tEXt chunk.
+func writeTextChunk(w io.Writer, keyword string, text string) error {
+	data := make([]byte, 0, 4+len(keyword)+1+len(text))
+	data = append(data, "tEXt"...)
+	data = append(data, keyword...)
+	data = append(data, 0)
+	data = append(data, text...)
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)-4))
+
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(data))
+
+	for _, part := range [][]byte{length[:], data, crc[:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save stores img as the thumbnail of the given size for the file described by info and returns
+// the path of the thumbnail.
+// The width and height of img must not exceed size, otherwise ErrImageTooLarge is returned. Use
+// ScaleToFit to scale larger images down.
+// As required by the standard, the thumbnail is written to a temporary file which is renamed
+// into place, and it is only readable by the user.
+func Save(img image.Image, size Size, info Info) (string, error) {
+	if size.DirName() == "" {
+		return "", fmt.Errorf("Save: unsupported thumbnail size %d", size)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() > int(size) || bounds.Dy() > int(size) {
+		return "", fmt.Errorf(
+			"Save: %w: %dx%d exceeds %d",
+			ErrImageTooLarge,
+			bounds.Dx(),
+			bounds.Dy(),
+			size,
+		)
+	}
+
+	var buffer bytes.Buffer
+	err := Encode(&buffer, img, info)
+	if err != nil {
+		return "", fmt.Errorf("Save: %w", err)
+	}
+
+	path := PathFor(info.URI, size)
+	err = fileutil.WriteFileAtomic(path, buffer.Bytes(), 0600)
+	if err != nil {
+		return "", fmt.Errorf("Save: %w", err)
+	}
+
+	return path, nil
+}
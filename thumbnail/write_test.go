@@ -0,0 +1,74 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSave(t *testing.T) {
+	testenv.Setup(t)
+	img := image.NewRGBA(image.Rect(0, 0, 128, 64))
+	info := Info{
+		URI:      "file:///home/jens/photos/me.png",
+		MTime:    time.Unix(1700000000, 0),
+		Size:     1234,
+		MimeType: "image/png",
+	}
+
+	path, err := Save(img, SizeNormal, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != PathFor(info.URI, SizeNormal) {
+		t.Errorf("path = %s, expected: %s", path, PathFor(info.URI, SizeNormal))
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %o, expected: 600", stat.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"tEXtThumb::URI\x00file:///home/jens/photos/me.png",
+		"tEXtThumb::MTime\x001700000000",
+		"tEXtThumb::Size\x001234",
+		"tEXtThumb::Mimetype\x00image/png",
+	} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("thumbnail does not contain %q", expected)
+		}
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("thumbnail is not a valid PNG: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("decoded bounds = %v, expected: %v", decoded.Bounds(), img.Bounds())
+	}
+}
+
+func TestSaveTooLarge(t *testing.T) {
+	testenv.Setup(t)
+	img := image.NewRGBA(image.Rect(0, 0, 129, 64))
+
+	_, err := Save(img, SizeNormal, Info{URI: "file:///a", MTime: time.Now()})
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got: %v", err)
+	}
+}
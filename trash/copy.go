@@ -0,0 +1,102 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// rename is os.Rename, replaceable to simulate moves across devices in tests.
+var rename = os.Rename
+
+// moveByCopy copies src to dst and removes src afterward. It is used when src cannot be renamed
+// to dst because they reside on different devices.
+// Every copied file is synced to disk before src is removed. If copying fails, the partial copy
+// at dst is removed and src is left untouched.
+func moveByCopy(src string, dst string) error {
+	err := copyTree(src, dst)
+	if err != nil {
+		cleanupErr := os.RemoveAll(dst)
+		return errors.Join(fmt.Errorf("failed to copy %s to %s: %w", src, dst, err), cleanupErr)
+	}
+
+	err = os.RemoveAll(src)
+	if err != nil {
+		return fmt.Errorf("copied %s to %s but failed to remove the original: %w", src, dst, err)
+	}
+
+	return nil
+}
+
+// copyTree copies the file, symbolic link or directory at src to dst, preserving permissions and
+// modification times. Other file types are not supported.
+func copyTree(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			err = os.Mkdir(target, mode.Perm()|0700)
+		case mode&os.ModeSymlink != 0:
+			var link string
+			link, err = os.Readlink(path)
+			if err == nil {
+				err = os.Symlink(link, target)
+			}
+			return err
+		case mode.IsRegular():
+			err = copyFile(path, target, mode.Perm())
+		default:
+			return fmt.Errorf("cannot copy %s, unsupported file type %s", path, mode.Type())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// copyFile copies the regular file src to the new file dst and syncs dst to disk.
+func copyFile(src string, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+
+	return errors.Join(err, out.Close())
+}
+
+// isCrossDevice returns true if err is caused by renaming across devices.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,66 @@
+package trash
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveByCopy(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	testenv.WriteFile(t, filepath.Join(src, "a"), "a")
+	testenv.WriteFile(t, filepath.Join(src, "nested", "b"), "b")
+	if err := os.Symlink("a", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(root, "dst")
+	if err := moveByCopy(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(src); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("source still exists after move: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, "nested", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "b" {
+		t.Errorf("nested/b contains %s, expected: b", content)
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "a" {
+		t.Errorf("link points to %s, expected: a", link)
+	}
+}
+
+func TestMoveByCopyCleanup(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	testenv.WriteFile(t, filepath.Join(src, "a"), "a")
+
+	// The destination's file already exists, making the copy fail halfway
+	dst := filepath.Join(root, "dst")
+	testenv.WriteFile(t, filepath.Join(dst, "a"), "existing")
+
+	if err := moveByCopy(src, dst); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if _, err := os.Lstat(filepath.Join(src, "a")); err != nil {
+		t.Errorf("source was modified after failed copy: %v", err)
+	}
+
+	if _, err := os.Lstat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial copy was not removed: %v", err)
+	}
+}
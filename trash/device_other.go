@@ -0,0 +1,9 @@
+//go:build !unix
+
+package trash
+
+// deviceOf returns 0 for every path as devices cannot be determined on this platform.
+// As a result, the home trash is used for every file.
+func deviceOf(path string) (uint64, error) {
+	return 0, nil
+}
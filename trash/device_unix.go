@@ -0,0 +1,24 @@
+//go:build unix
+
+package trash
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// deviceOf returns the ID of the device containing path.
+func deviceOf(path string) (uint64, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	sys, ok := stat.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("failed to determine device of %s", path)
+	}
+
+	return uint64(sys.Dev), nil
+}
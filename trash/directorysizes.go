@@ -0,0 +1,100 @@
+package trash
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"github.com/MatthiasKunnen/xdg/internal/fileutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const directorySizesName = "directorysizes"
+
+// directorySize is an entry of the directorysizes cache.
+type directorySize struct {
+	// size in bytes of the trashed directory.
+	size int64
+
+	// mtime of the trash info file of the directory at the time size was calculated, in seconds
+	// since the Unix epoch.
+	mtime int64
+}
+
+// directorySizesPath returns the path of the directorysizes cache of the trash directory.
+func (d Dir) directorySizesPath() string {
+	return filepath.Join(d.Path, directorySizesName)
+}
+
+// readDirectorySizes reads the directorysizes cache. It maps the name of a trashed directory to
+// its cached size. Invalid lines are ignored and a missing cache results in an empty map.
+func (d Dir) readDirectorySizes() map[string]directorySize {
+	result := make(map[string]directorySize)
+
+	file, err := os.Open(d.directorySizesPath())
+	if err != nil {
+		return result
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		fields := strings.SplitN(sc.Text(), " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		mtime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		name, err := fileuri.UnescapePath(fields[2])
+		if err != nil {
+			continue
+		}
+
+		result[name] = directorySize{size: size, mtime: mtime}
+	}
+
+	return result
+}
+
+// writeDirectorySizes atomically replaces the directorysizes cache.
+func (d Dir) writeDirectorySizes(sizes map[string]directorySize) error {
+	var builder strings.Builder
+	for name, entry := range sizes {
+		builder.WriteString(fmt.Sprintf(
+			"%d %d %s\n",
+			entry.size,
+			entry.mtime,
+			fileuri.EscapePath(name),
+		))
+	}
+
+	return fileutil.WriteFileAtomic(d.directorySizesPath(), []byte(builder.String()), 0600)
+}
+
+// Size returns the total size in bytes of the items in the trash directory.
+// The sizes of trashed directories are taken from the directorysizes cache when it is up to
+// date, other sizes are determined using the file system.
+func (d Dir) Size() (int64, error) {
+	items, err := d.List()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, item := range items {
+		size += item.Size
+	}
+
+	return size, nil
+}
@@ -0,0 +1,93 @@
+package trash
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirSize(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := HomeDir()
+
+	testenv.WriteFile(t, filepath.Join(home, "file"), "12345")
+	testenv.WriteFile(t, filepath.Join(home, "dir", "a"), "123")
+	testenv.WriteFile(t, filepath.Join(home, "dir", "b", "c"), "12")
+
+	for _, name := range []string{"file", "dir"} {
+		if _, err := Trash(filepath.Join(home, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size, err := dir.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 10 {
+		t.Errorf("Size() = %d, expected: 10", size)
+	}
+
+	cache, err := os.ReadFile(dir.directorySizesPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(cache), "5 ") || !strings.HasSuffix(string(cache), " dir\n") {
+		t.Errorf("unexpected directorysizes content: %q", cache)
+	}
+
+	// The cached value is used as long as the mtime of the info file matches
+	sizes := dir.readDirectorySizes()
+	entry := sizes["dir"]
+	entry.size = 100
+	sizes["dir"] = entry
+	if err := dir.writeDirectorySizes(sizes); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err = dir.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 105 {
+		t.Errorf("Size() = %d, expected the cached size to be used: 105", size)
+	}
+}
+
+func TestTrashQuota(t *testing.T) {
+	home := testenv.Setup(t)
+
+	testenv.WriteFile(t, filepath.Join(home, "first"), "12345")
+	testenv.WriteFile(t, filepath.Join(home, "second"), "1234")
+	testenv.WriteFile(t, filepath.Join(home, "huge"), "12345678901")
+
+	options := TrashOptions{MaxSize: 8}
+
+	first, err := TrashWithOptions(filepath.Join(home, "first"), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = TrashWithOptions(filepath.Join(home, "second"), options)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got: %v", err)
+	}
+
+	options.OnQuotaExceeded = QuotaPrune
+	_, err = TrashWithOptions(filepath.Join(home, "huge"), options)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded for a file larger than the quota, got: %v", err)
+	}
+
+	_, err = TrashWithOptions(filepath.Join(home, "second"), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(first.FilePath()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("first item was not pruned to make room")
+	}
+}
@@ -0,0 +1,90 @@
+package trash
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mountsFile lists the mount points of the current process.
+const mountsFile = "/proc/self/mounts"
+
+// Dirs returns all trash directories that exist for the current user.
+// The home trash is always first, followed by the trash directories found at the top
+// directories of the mounted volumes, $topdir/.Trash/$uid and $topdir/.Trash-$uid.
+// $topdir/.Trash/$uid is only included when $topdir/.Trash meets the requirements of the spec.
+func Dirs() []Dir {
+	result := []Dir{HomeDir()}
+	uid := strconv.Itoa(os.Getuid())
+
+	for _, topDir := range mountPoints() {
+		candidates := []string{filepath.Join(topDir, ".Trash-"+uid)}
+		if isValidSharedTrash(filepath.Join(topDir, ".Trash")) {
+			candidates = append([]string{filepath.Join(topDir, ".Trash", uid)}, candidates...)
+		}
+
+		for _, candidate := range candidates {
+			if candidate == result[0].Path {
+				continue
+			}
+
+			stat, err := os.Stat(candidate)
+			if err != nil || !stat.IsDir() {
+				continue
+			}
+
+			result = append(result, Dir{Path: candidate, TopDir: topDir})
+		}
+	}
+
+	return result
+}
+
+// mountPoints returns the mount points of all mounted file systems.
+// If the mount points cannot be determined, nil is returned.
+func mountPoints() []string {
+	file, err := os.Open(mountsFile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var result []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		result = append(result, unescapeMountPath(fields[1]))
+	}
+
+	return result
+}
+
+// unescapeMountPath converts the octal escapes, such as \040 for a space, used in the mounts file
+// back to their characters.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 <= len(path) {
+			code, err := strconv.ParseUint(path[i+1:i+4], 8, 8)
+			if err == nil {
+				builder.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+
+		builder.WriteByte(path[i])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,148 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EmptyOptions configures Empty.
+type EmptyOptions struct {
+	// OlderThan, if non-zero, limits deletion to items that were trashed more than OlderThan ago.
+	// Items without a valid deletion date are kept.
+	OlderThan time.Duration
+
+	// Progress, if non-nil, is called after each item has been processed.
+	// done is the amount of processed items, total is the amount of items that will be
+	// processed and err is the result of deleting item.
+	Progress func(item Item, done int, total int, err error)
+}
+
+// Delete permanently removes the item from the trash.
+// The trashed file is removed before the trash info file. If the deletion is interrupted, an
+// orphaned trash info file remains which is ignored when listing and removed by Empty.
+func Delete(item Item) error {
+	err := os.RemoveAll(item.FilePath())
+	if err != nil {
+		return fmt.Errorf("Delete: failed to remove %s: %w", item.FilePath(), err)
+	}
+
+	err = os.Remove(item.InfoFilePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Delete: failed to remove %s: %w", item.InfoFilePath(), err)
+	}
+
+	return nil
+}
+
+// Empty permanently removes the items of all trash directories returned by Dirs.
+// See Dir.Empty.
+func Empty(options EmptyOptions) error {
+	var err error
+
+	for _, dir := range Dirs() {
+		err = errors.Join(err, dir.Empty(options))
+	}
+
+	return err
+}
+
+// Empty permanently removes the items in the trash directory.
+// Deletion continues when an item cannot be removed, all errors are joined and returned.
+//
+// When OlderThan is not set, leftovers of interrupted operations are removed as well. These are
+// trashed files without a trash info file and trash info files without a trashed file that are
+// older than an hour.
+func (d Dir) Empty(options EmptyOptions) error {
+	items, err := d.List()
+	if err != nil {
+		return err
+	}
+
+	if options.OlderThan > 0 {
+		cutoff := time.Now().Add(-options.OlderThan)
+		filtered := items[:0]
+		for _, item := range items {
+			if !item.DeletionDate.IsZero() && item.DeletionDate.Before(cutoff) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	for i, item := range items {
+		deleteErr := Delete(item)
+		err = errors.Join(err, deleteErr)
+
+		if options.Progress != nil {
+			options.Progress(item, i+1, len(items), deleteErr)
+		}
+	}
+
+	if options.OlderThan == 0 {
+		err = errors.Join(err, d.removeOrphans())
+	}
+
+	return err
+}
+
+// orphanGracePeriod is how old a trash info file without a trashed file must be before it is
+// removed. Trash writes the trash info file before moving the file into the trash.
+const orphanGracePeriod = time.Hour
+
+// removeOrphans removes trashed files without a trash info file and trash info files without a
+// trashed file.
+// Files that are being trashed concurrently are kept: trash info files younger than
+// orphanGracePeriod are skipped and the trash info file of a trashed file is checked again right
+// before the file is removed.
+func (d Dir) removeOrphans() error {
+	var err error
+
+	infoEntries, readErr := os.ReadDir(d.InfoPath())
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		err = errors.Join(err, readErr)
+	}
+
+	for _, entry := range infoEntries {
+		name, isInfo := strings.CutSuffix(entry.Name(), trashInfoExt)
+		if !isInfo {
+			continue
+		}
+
+		if _, statErr := os.Lstat(filepath.Join(d.FilesPath(), name)); statErr == nil {
+			continue
+		}
+
+		info, statErr := entry.Info()
+		if statErr != nil || time.Since(info.ModTime()) < orphanGracePeriod {
+			continue
+		}
+
+		removeErr := os.Remove(filepath.Join(d.InfoPath(), entry.Name()))
+		if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			err = errors.Join(err, removeErr)
+		}
+	}
+
+	fileEntries, readErr := os.ReadDir(d.FilesPath())
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		err = errors.Join(err, readErr)
+	}
+
+	for _, entry := range fileEntries {
+		infoPath := filepath.Join(d.InfoPath(), entry.Name()+trashInfoExt)
+		if _, statErr := os.Lstat(infoPath); !errors.Is(statErr, os.ErrNotExist) {
+			continue
+		}
+
+		removeErr := os.RemoveAll(filepath.Join(d.FilesPath(), entry.Name()))
+		if removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package trash
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirEmpty(t *testing.T) {
+	home := testenv.Setup(t)
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(home, name)
+		testenv.WriteFile(t, path, name)
+		if _, err := Trash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Leftovers of an interrupted operation
+	dir := HomeDir()
+	testenv.WriteFile(t, filepath.Join(dir.FilesPath(), "no-info"), "")
+	noFile := filepath.Join(dir.InfoPath(), "no-file"+trashInfoExt)
+	testenv.WriteFile(t, noFile, "")
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	if err := os.Chtimes(noFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	var progressCalls int
+	err := dir.Empty(EmptyOptions{
+		Progress: func(item Item, done int, total int, err error) {
+			progressCalls++
+			if total != 3 {
+				t.Errorf("total = %d, expected: 3", total)
+			}
+			if done != progressCalls {
+				t.Errorf("done = %d, expected: %d", done, progressCalls)
+			}
+			if err != nil {
+				t.Errorf("failed to delete %s: %v", item.Name, err)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if progressCalls != 3 {
+		t.Errorf("progress was called %d times, expected: 3", progressCalls)
+	}
+
+	for _, path := range []string{dir.FilesPath(), dir.InfoPath()} {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) > 0 {
+			t.Errorf("%s is not empty: %v", path, entries)
+		}
+	}
+}
+
+func TestDirEmptyOlderThan(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := HomeDir()
+
+	oldPath := filepath.Join(home, "old")
+	testenv.WriteFile(t, oldPath, "")
+	oldItem, err := Trash(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldItem.DeletionDate = time.Now().Add(-40 * 24 * time.Hour)
+	testenv.WriteFile(t, oldItem.InfoFilePath(), string(oldItem.encodeInfo()))
+
+	newPath := filepath.Join(home, "new")
+	testenv.WriteFile(t, newPath, "")
+	newItem, err := Trash(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dir.Empty(EmptyOptions{OlderThan: 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(oldItem.FilePath()); !os.IsNotExist(err) {
+		t.Errorf("old item was not deleted")
+	}
+
+	if _, err := os.Lstat(newItem.FilePath()); err != nil {
+		t.Errorf("new item was deleted: %v", err)
+	}
+}
+
+func TestDirEmptyKeepsPendingTrash(t *testing.T) {
+	testenv.Setup(t)
+	dir := HomeDir()
+
+	// A trash operation that has written its trash info file but not yet moved the file
+	pending := filepath.Join(dir.InfoPath(), "pending"+trashInfoExt)
+	testenv.WriteFile(t, pending, "")
+
+	err := dir.Empty(EmptyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(pending); err != nil {
+		t.Errorf("trash info file of pending trash operation was removed: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package trash
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/fileuri"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const trashInfoHeader = "[Trash Info]"
+
+// encodeInfo returns the contents of the .trashinfo file of the item.
+// The path is percent-encoded as required by the spec. For trash directories with a TopDir, the
+// path is stored relative to it.
+func (i Item) encodeInfo() []byte {
+	path := i.OriginalPath
+	if i.Dir.TopDir != "" {
+		rel, err := filepath.Rel(i.Dir.TopDir, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(trashInfoHeader + "\n")
+	builder.WriteString("Path=" + fileuri.EscapePath(path) + "\n")
+	builder.WriteString("DeletionDate=" + i.DeletionDate.Format(deletionDateFormat) + "\n")
+
+	return []byte(builder.String())
+}
+
+// decodeInfo parses the contents of a .trashinfo file into the item.
+// Relative paths are resolved against the TopDir of the item's trash directory.
+// An invalid or missing DeletionDate is not an error, the zero time is used instead.
+func (i *Item) decodeInfo(reader io.Reader) error {
+	sc := bufio.NewScanner(reader)
+	inGroup := false
+	var path string
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			inGroup = line == trashInfoHeader
+			continue
+		case !inGroup:
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "Path":
+			unescaped, err := fileuri.UnescapePath(value)
+			if err != nil {
+				return fmt.Errorf("invalid Path %s: %w", value, err)
+			}
+			path = unescaped
+		case "DeletionDate":
+			date, err := time.ParseInLocation(deletionDateFormat, value, time.Local)
+			if err == nil {
+				i.DeletionDate = date
+			}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	if path == "" {
+		return fmt.Errorf("trash info does not contain a Path")
+	}
+
+	if !filepath.IsAbs(path) {
+		if i.Dir.TopDir == "" {
+			return fmt.Errorf("trash info of the home trash contains relative path %s", path)
+		}
+		path = filepath.Join(i.Dir.TopDir, path)
+	}
+	i.OriginalPath = path
+
+	return nil
+}
+
+// loadItem reads the .trashinfo file of the item with the given name in the trash directory.
+func (d Dir) loadItem(name string) (*Item, error) {
+	item := &Item{Name: name, Dir: d}
+
+	file, err := os.Open(item.InfoFilePath())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	err = item.decodeInfo(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", item.InfoFilePath(), err)
+	}
+
+	return item, nil
+}
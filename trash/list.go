@@ -0,0 +1,146 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// List returns the items of all trash directories returned by Dirs.
+// Trash directories that cannot be read are skipped, their errors are joined and returned
+// together with the items that could be listed.
+func List() ([]Item, error) {
+	var result []Item
+	var err error
+
+	for _, dir := range Dirs() {
+		items, dirErr := dir.List()
+		result = append(result, items...)
+		err = errors.Join(err, dirErr)
+	}
+
+	return result, err
+}
+
+// List returns the items in the trash directory ordered by name.
+// A nonexistent trash directory is treated as empty.
+// Trash info files without a trashed file, and trashed files without a trash info file, are
+// ignored.
+// The directorysizes cache is used for the sizes of trashed directories and updated when it is
+// outdated.
+func (d Dir) List() ([]Item, error) {
+	entries, err := os.ReadDir(d.InfoPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("List: failed to read trash directory %s: %w", d.InfoPath(), err)
+	}
+
+	cachedSizes := d.readDirectorySizes()
+	sizes := make(map[string]directorySize)
+	cacheChanged := false
+
+	result := make([]Item, 0, len(entries))
+	for _, entry := range entries {
+		name, isInfo := strings.CutSuffix(entry.Name(), trashInfoExt)
+		if !isInfo || entry.IsDir() {
+			continue
+		}
+
+		item, err := d.loadItem(name)
+		if err != nil {
+			logging.Logger().Warn(
+				"Skipping invalid trash item",
+				logging.Path, filepath.Join(d.InfoPath(), name+trashInfoExt),
+				logging.Error, err,
+			)
+			continue
+		}
+
+		stat, err := os.Lstat(item.FilePath())
+		if err != nil {
+			continue
+		}
+
+		if !stat.IsDir() {
+			item.Size = stat.Size()
+			result = append(result, *item)
+			continue
+		}
+
+		var infoMtime int64
+		if infoStat, err := entry.Info(); err == nil {
+			infoMtime = infoStat.ModTime().Unix()
+		}
+
+		cached, exists := cachedSizes[name]
+		if exists && cached.mtime == infoMtime {
+			item.Size = cached.size
+		} else {
+			item.Size, err = pathSize(item.FilePath())
+			if err != nil {
+				logging.Logger().Warn(
+					"Failed to determine size of trashed file",
+					logging.Path, item.FilePath(),
+					logging.Error, err,
+				)
+			}
+			cacheChanged = true
+		}
+		sizes[name] = directorySize{size: item.Size, mtime: infoMtime}
+
+		result = append(result, *item)
+	}
+
+	if cacheChanged || len(sizes) != len(cachedSizes) {
+		err := d.writeDirectorySizes(sizes)
+		if err != nil {
+			logging.Logger().Warn(
+				"Failed to update the directorysizes cache",
+				logging.Path, d.Path,
+				logging.Error, err,
+			)
+		}
+	}
+
+	return result, nil
+}
+
+// pathSize returns the size of the file at path. If the path is a directory, the total size of
+// all files in it is returned. Symbolic links are not followed.
+func pathSize(path string) (int64, error) {
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if !stat.IsDir() {
+		return stat.Size(), nil
+	}
+
+	var size int64
+	err = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,110 @@
+package trash
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirList(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "docs", "report 1.txt")
+	testenv.WriteFile(t, original, "12345")
+
+	dirPath := filepath.Join(home, "photos")
+	testenv.WriteFile(t, filepath.Join(dirPath, "a.png"), "123")
+	testenv.WriteFile(t, filepath.Join(dirPath, "nested", "b.png"), "1234")
+
+	before := time.Now().Add(-time.Second)
+	if _, err := Trash(original); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Trash(dirPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Orphaned info file, must be ignored
+	testenv.WriteFile(
+		t,
+		filepath.Join(HomeDir().InfoPath(), "orphan"+trashInfoExt),
+		"[Trash Info]\nPath=/tmp/orphan\nDeletionDate=2004-08-31T22:32:08\n",
+	)
+
+	items, err := HomeDir().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, expected: 2. Items: %v", len(items), items)
+	}
+
+	expected := map[string]int64{
+		original: 5,
+		dirPath:  7,
+	}
+	for _, item := range items {
+		size, exists := expected[item.OriginalPath]
+		if !exists {
+			t.Errorf("unexpected item with original path %s", item.OriginalPath)
+			continue
+		}
+
+		if item.Size != size {
+			t.Errorf("%s has size %d, expected: %d", item.OriginalPath, item.Size, size)
+		}
+
+		if item.DeletionDate.Before(before) {
+			t.Errorf("%s has deletion date %v, expected after %v", item.Name, item.DeletionDate, before)
+		}
+	}
+}
+
+func TestDirListRelativePath(t *testing.T) {
+	top := t.TempDir()
+	dir := Dir{Path: filepath.Join(top, ".Trash-1000"), TopDir: top}
+	testenv.WriteFile(t, filepath.Join(dir.FilesPath(), "foo"), "")
+	testenv.WriteFile(
+		t,
+		filepath.Join(dir.InfoPath(), "foo"+trashInfoExt),
+		"[Trash Info]\nPath=some%20dir/foo\nDeletionDate=2004-08-31T22:32:08\n",
+	)
+
+	items, err := dir.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("len(items) = %d, expected: 1", len(items))
+	}
+
+	expectedPath := filepath.Join(top, "some dir", "foo")
+	if items[0].OriginalPath != expectedPath {
+		t.Errorf("OriginalPath = %s, expected: %s", items[0].OriginalPath, expectedPath)
+	}
+
+	expectedDate := time.Date(2004, 8, 31, 22, 32, 8, 0, time.Local)
+	if !items[0].DeletionDate.Equal(expectedDate) {
+		t.Errorf("DeletionDate = %v, expected: %v", items[0].DeletionDate, expectedDate)
+	}
+}
+
+func TestDirListNonexistent(t *testing.T) {
+	items, err := Dir{Path: filepath.Join(t.TempDir(), "nope")}.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 0 {
+		t.Errorf("expected no items, got %v", items)
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	actual := unescapeMountPath(`/media/my\040disk`)
+	if actual != "/media/my disk" {
+		t.Errorf("unescapeMountPath = %s, expected: /media/my disk", actual)
+	}
+}
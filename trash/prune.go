@@ -0,0 +1,145 @@
+package trash
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// PruneReason states why an item was selected for deletion by Prune.
+type PruneReason int
+
+const (
+	// PruneReasonAge means the item was trashed longer ago than Policy.MaxAge.
+	PruneReasonAge PruneReason = iota + 1
+
+	// PruneReasonDirSize means the trash directory containing the item exceeded its quota in
+	// Policy.DirMaxSize.
+	PruneReasonDirSize
+
+	// PruneReasonTotalSize means the trash directories combined exceeded Policy.MaxSize.
+	PruneReasonTotalSize
+)
+
+func (r PruneReason) String() string {
+	switch r {
+	case PruneReasonAge:
+		return "age"
+	case PruneReasonDirSize:
+		return "directory size"
+	case PruneReasonTotalSize:
+		return "total size"
+	default:
+		return "unknown"
+	}
+}
+
+// Policy describes which items should be removed from the trash.
+// Zero values disable the corresponding limit.
+// When a size limit is exceeded, the oldest items are removed first.
+type Policy struct {
+	// MaxAge is the maximum duration an item is kept in the trash.
+	MaxAge time.Duration
+
+	// MaxSize is the maximum total size in bytes of all trash directories combined.
+	MaxSize int64
+
+	// DirMaxSize maps the path of a trash directory, see Dir.Path, to the maximum size in bytes
+	// of that directory. This allows setting quotas per volume.
+	DirMaxSize map[string]int64
+
+	// DryRun, if true, reports the items that would be deleted without deleting them.
+	DryRun bool
+}
+
+// PruneResult describes an item selected for deletion by Prune.
+type PruneResult struct {
+	Item   Item
+	Reason PruneReason
+
+	// Err is the error that occurred while deleting the item. Always nil for dry runs.
+	Err error
+}
+
+// Prune applies the policy to all trash directories returned by Dirs.
+// See PruneDirs.
+func Prune(policy Policy) ([]PruneResult, error) {
+	return PruneDirs(Dirs(), policy)
+}
+
+// PruneDirs applies the policy to the given trash directories and reports the items that were
+// deleted, or would be deleted when Policy.DryRun is set.
+// Items are evaluated against MaxAge first, then against DirMaxSize and finally against MaxSize.
+// The returned error joins the errors of listing the directories and of deleting the items.
+func PruneDirs(dirs []Dir, policy Policy) ([]PruneResult, error) {
+	var err error
+	var result []PruneResult
+	var remaining []Item
+
+	selectItem := func(item Item, reason PruneReason) {
+		result = append(result, PruneResult{Item: item, Reason: reason})
+	}
+
+	for _, dir := range dirs {
+		items, listErr := dir.List()
+		err = errors.Join(err, listErr)
+		sortOldestFirst(items)
+
+		var kept []Item
+		var size int64
+		for _, item := range items {
+			if policy.MaxAge > 0 && !item.DeletionDate.IsZero() &&
+				time.Since(item.DeletionDate) > policy.MaxAge {
+				selectItem(item, PruneReasonAge)
+				continue
+			}
+
+			kept = append(kept, item)
+			size += item.Size
+		}
+
+		if quota, exists := policy.DirMaxSize[dir.Path]; exists {
+			for len(kept) > 0 && size > quota {
+				selectItem(kept[0], PruneReasonDirSize)
+				size -= kept[0].Size
+				kept = kept[1:]
+			}
+		}
+
+		remaining = append(remaining, kept...)
+	}
+
+	if policy.MaxSize > 0 {
+		sortOldestFirst(remaining)
+
+		var size int64
+		for _, item := range remaining {
+			size += item.Size
+		}
+
+		for len(remaining) > 0 && size > policy.MaxSize {
+			selectItem(remaining[0], PruneReasonTotalSize)
+			size -= remaining[0].Size
+			remaining = remaining[1:]
+		}
+	}
+
+	if policy.DryRun {
+		return result, err
+	}
+
+	for i := range result {
+		result[i].Err = Delete(result[i].Item)
+		err = errors.Join(err, result[i].Err)
+	}
+
+	return result, err
+}
+
+// sortOldestFirst sorts the items by deletion date, oldest first.
+// Items without a deletion date are considered the oldest.
+func sortOldestFirst(items []Item) {
+	slices.SortStableFunc(items, func(a, b Item) int {
+		return a.DeletionDate.Compare(b.DeletionDate)
+	})
+}
@@ -0,0 +1,100 @@
+package trash
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trashWithDate trashes a new file of the given size and rewrites its deletion date.
+func trashWithDate(t *testing.T, dir Dir, name string, size int, date time.Time) *Item {
+	path := filepath.Join(filepath.Dir(dir.Path), "src", name)
+	testenv.WriteFile(t, path, strings.Repeat("x", size))
+
+	item, err := dir.Trash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item.DeletionDate = date
+	testenv.WriteFile(t, item.InfoFilePath(), string(item.encodeInfo()))
+
+	return item
+}
+
+func TestPruneDirs(t *testing.T) {
+	dir := Dir{Path: filepath.Join(t.TempDir(), "Trash")}
+	now := time.Now().Truncate(time.Second)
+
+	trashWithDate(t, dir, "ancient", 1, now.Add(-100*24*time.Hour))
+	trashWithDate(t, dir, "old", 10, now.Add(-3*time.Hour))
+	trashWithDate(t, dir, "older", 10, now.Add(-4*time.Hour))
+	recent := trashWithDate(t, dir, "recent", 10, now.Add(-time.Hour))
+
+	policy := Policy{
+		MaxAge:     30 * 24 * time.Hour,
+		DirMaxSize: map[string]int64{dir.Path: 25},
+		MaxSize:    10,
+		DryRun:     true,
+	}
+
+	results, err := PruneDirs([]Dir{dir}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name   string
+		reason PruneReason
+	}{
+		{"ancient", PruneReasonAge},
+		{"older", PruneReasonDirSize},
+		{"old", PruneReasonTotalSize},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("len(results) = %d, expected: %d. Results: %v", len(results), len(expected), results)
+	}
+
+	for i, e := range expected {
+		if results[i].Item.Name != e.name || results[i].Reason != e.reason {
+			t.Errorf(
+				"results[%d] = %s (%s), expected: %s (%s)",
+				i,
+				results[i].Item.Name,
+				results[i].Reason,
+				e.name,
+				e.reason,
+			)
+		}
+	}
+
+	items, err := dir.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Errorf("dry run deleted items, %d items remain", len(items))
+	}
+
+	policy.DryRun = false
+	_, err = PruneDirs([]Dir{dir}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err = dir.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != recent.Name {
+		t.Errorf("expected only %s to remain, got: %v", recent.Name, items)
+	}
+
+	if _, err := os.Lstat(recent.FilePath()); err != nil {
+		t.Errorf("recent item was removed: %v", err)
+	}
+}
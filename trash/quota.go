@@ -0,0 +1,65 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QuotaAction determines what happens when trashing a file would exceed TrashOptions.MaxSize.
+type QuotaAction int
+
+const (
+	// QuotaRefuse makes trashing fail with ErrQuotaExceeded.
+	QuotaRefuse QuotaAction = iota
+
+	// QuotaPrune deletes the oldest items in the trash directory until the file fits.
+	// If the file is larger than the quota by itself, trashing fails with ErrQuotaExceeded.
+	QuotaPrune
+)
+
+var ErrQuotaExceeded = errors.New("trash quota exceeded")
+
+// enforceQuota makes sure that the file at path can be added to the trash directory without
+// exceeding options.MaxSize.
+func (d Dir) enforceQuota(path string, options TrashOptions) error {
+	size, err := pathSize(path)
+	if err != nil {
+		return fmt.Errorf("failed to determine size of %s: %w", path, err)
+	}
+
+	if size > options.MaxSize {
+		return fmt.Errorf(
+			"%w: %s has size %d while the quota is %d",
+			ErrQuotaExceeded,
+			path,
+			size,
+			options.MaxSize,
+		)
+	}
+
+	trashSize, err := d.Size()
+	if err != nil {
+		return err
+	}
+
+	if trashSize+size <= options.MaxSize {
+		return nil
+	}
+
+	if options.OnQuotaExceeded != QuotaPrune {
+		return fmt.Errorf(
+			"%w: trashing %s would grow %s to %d bytes while the quota is %d",
+			ErrQuotaExceeded,
+			path,
+			d.Path,
+			trashSize+size,
+			options.MaxSize,
+		)
+	}
+
+	_, err = PruneDirs([]Dir{d}, Policy{
+		DirMaxSize: map[string]int64{d.Path: options.MaxSize - size},
+	})
+
+	return err
+}
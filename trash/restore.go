@@ -0,0 +1,181 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConflictStrategy determines what happens when a file already exists at the location an item
+// is restored to.
+type ConflictStrategy int
+
+const (
+	// ConflictFail makes the restore fail with ErrDestinationExists.
+	ConflictFail ConflictStrategy = iota
+
+	// ConflictRename restores the item next to the existing file using a new name such as
+	// "report (2).txt".
+	ConflictRename
+
+	// ConflictOverwrite replaces the existing file or directory with the item. The existing file
+	// is only removed once the item has been restored.
+	ConflictOverwrite
+)
+
+var ErrDestinationExists = errors.New("destination already exists")
+
+// Restore moves the item back to its original path and removes its trash info file.
+// If the original path resides on another device, the item is copied and removed from the trash
+// instead.
+// If the parent directory of the original path no longer exists, it is created.
+// It returns the path the item was restored to, which differs from the original path when
+// ConflictRename was used to resolve a conflict.
+func Restore(item Item, strategy ConflictStrategy) (string, error) {
+	return RestoreTo(item, item.OriginalPath, strategy)
+}
+
+// RestoreTo moves the item to the given path and removes its trash info file.
+// See Restore.
+func RestoreTo(item Item, path string, strategy ConflictStrategy) (string, error) {
+	if _, err := os.Lstat(item.FilePath()); err != nil {
+		return "", fmt.Errorf("Restore: trashed file of %s is unavailable: %w", item.Name, err)
+	}
+
+	parent := filepath.Dir(path)
+	err := os.MkdirAll(parent, 0777)
+	if err != nil {
+		return "", fmt.Errorf("Restore: failed to create directory %s: %w", parent, err)
+	}
+
+	// aside holds the existing file while it is being overwritten
+	var aside string
+	_, err = os.Lstat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", fmt.Errorf("Restore: failed to stat %s: %w", path, err)
+	default:
+		switch strategy {
+		case ConflictRename:
+			path, err = availablePath(path)
+			if err != nil {
+				return "", fmt.Errorf("Restore: %w", err)
+			}
+		case ConflictOverwrite:
+			aside, err = moveAside(path)
+			if err != nil {
+				return "", fmt.Errorf("Restore: %w", err)
+			}
+		default:
+			return "", fmt.Errorf("Restore: %w: %s", ErrDestinationExists, path)
+		}
+	}
+
+	err = rename(item.FilePath(), path)
+	if err != nil && isCrossDevice(err) {
+		// Items trashed using CopyFallback reside on another device than their original path
+		err = moveByCopy(item.FilePath(), path)
+		if err != nil && exists(path) {
+			// The copy is complete but the trashed file could not be removed entirely.
+			// The item is kept in the trash so the remainder can be deleted later.
+			return path, errors.Join(fmt.Errorf("Restore: %w", err), removeAside(aside))
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("Restore: failed to move %s to %s: %w", item.FilePath(), path, err)
+		return "", errors.Join(err, putBack(aside, path))
+	}
+
+	err = removeAside(aside)
+	if err != nil {
+		return path, fmt.Errorf("Restore: restored %s but %w", path, err)
+	}
+
+	err = os.Remove(item.InfoFilePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return path, fmt.Errorf(
+			"Restore: restored %s but failed to remove %s: %w",
+			path,
+			item.InfoFilePath(),
+			err,
+		)
+	}
+
+	return path, nil
+}
+
+// moveAside moves the file at path into a new hidden directory next to it, so it can be put
+// back if restoring over it fails. It returns the new path of the file.
+func moveAside(path string) (string, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".trash-restore-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory next to %s: %w", path, err)
+	}
+
+	aside := filepath.Join(dir, filepath.Base(path))
+	err = os.Rename(path, aside)
+	if err != nil {
+		os.Remove(dir)
+		return "", fmt.Errorf("failed to move %s aside: %w", path, err)
+	}
+
+	return aside, nil
+}
+
+// putBack moves the file that moveAside moved to aside back to path.
+// It does nothing if aside is empty.
+func putBack(aside string, path string) error {
+	if aside == "" {
+		return nil
+	}
+
+	err := os.Rename(aside, path)
+	if err != nil {
+		return fmt.Errorf("failed to move %s back to %s: %w", aside, path, err)
+	}
+
+	return os.Remove(filepath.Dir(aside))
+}
+
+// removeAside removes the file that moveAside moved to aside along with its directory.
+// It does nothing if aside is empty.
+func removeAside(aside string) error {
+	if aside == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(filepath.Dir(aside))
+	if err != nil {
+		return fmt.Errorf("failed to remove the overwritten file %s: %w", aside, err)
+	}
+
+	return nil
+}
+
+// availablePath returns a path in the same directory as path that does not exist yet.
+// E.g. for /tmp/report.txt, /tmp/report (2).txt is tried first.
+func availablePath(path string) (string, error) {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dot files such as .bashrc have no extension
+		stem, ext = base, ""
+	}
+
+	for i := 2; ; i++ {
+		candidate := filepath.Join(dir, stem+" ("+strconv.Itoa(i)+")"+ext)
+		_, err := os.Lstat(candidate)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return candidate, nil
+		case err != nil:
+			return "", fmt.Errorf("failed to stat %s: %w", candidate, err)
+		}
+	}
+}
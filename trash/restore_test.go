@@ -0,0 +1,167 @@
+package trash
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "docs", "report.txt")
+	testenv.WriteFile(t, original, "report")
+
+	item, err := Trash(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The original parent no longer exists
+	err = os.RemoveAll(filepath.Join(home, "docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Restore(*item, ConflictFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored != original {
+		t.Errorf("restored to %s, expected: %s", restored, original)
+	}
+
+	content, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "report" {
+		t.Errorf("restored content = %s, expected: report", content)
+	}
+
+	if _, err := os.Lstat(item.InfoFilePath()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("trash info file still exists after restore: %v", err)
+	}
+}
+
+func TestRestoreConflict(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "report.txt")
+
+	trashAndReplace := func() *Item {
+		testenv.WriteFile(t, original, "old")
+		item, err := Trash(original)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testenv.WriteFile(t, original, "new")
+		return item
+	}
+
+	item := trashAndReplace()
+	_, err := Restore(*item, ConflictFail)
+	if !errors.Is(err, ErrDestinationExists) {
+		t.Errorf("expected ErrDestinationExists, got: %v", err)
+	}
+
+	restored, err := Restore(*item, ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRenamed := filepath.Join(home, "report (2).txt")
+	if restored != expectedRenamed {
+		t.Errorf("restored to %s, expected: %s", restored, expectedRenamed)
+	}
+
+	item = trashAndReplace()
+	restored, err = Restore(*item, ConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "old" {
+		t.Errorf("restored content = %s, expected: old", content)
+	}
+}
+
+func TestRestoreCrossDevice(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "docs", "report.txt")
+	testenv.WriteFile(t, original, "report")
+
+	// Simulate the trash directory residing on another device than the file
+	rename = func(oldpath string, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	item, err := TrashWithOptions(original, TrashOptions{CopyFallback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Restore(*item, ConflictFail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "report" {
+		t.Errorf("restored content = %s, expected: report", content)
+	}
+
+	for _, path := range []string{item.FilePath(), item.InfoFilePath()} {
+		if _, err := os.Lstat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s still exists after restore: %v", path, err)
+		}
+	}
+}
+
+func TestRestoreOverwriteFailure(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "report.txt")
+	testenv.WriteFile(t, original, "old")
+
+	item, err := Trash(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testenv.WriteFile(t, original, "new")
+
+	rename = func(oldpath string, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	_, err = Restore(*item, ConflictOverwrite)
+	if !errors.Is(err, syscall.EACCES) {
+		t.Errorf("expected EACCES, got: %v", err)
+	}
+
+	content, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("existing content = %s, expected: new", content)
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "report.txt" && entry.Name() != ".local" {
+			t.Errorf("unexpected leftover %s", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package trash implements moving files to the trash as described by the
+// [Trash specification].
+//
+// [Trash specification]: https://specifications.freedesktop.org/trash-spec/1.0/
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	filesDirName       = "files"
+	infoDirName        = "info"
+	trashInfoExt       = ".trashinfo"
+	deletionDateFormat = "2006-01-02T15:04:05"
+)
+
+// Dir is a trash directory. It contains the files/ and info/ subdirectories.
+type Dir struct {
+	// Path of the trash directory, e.g. /home/user/.local/share/Trash.
+	Path string
+
+	// TopDir is the top directory of the volume the trash directory belongs to.
+	// For the home trash, this is empty and the paths stored in the trash info files are
+	// absolute. For other trash directories, the stored paths are relative to TopDir.
+	TopDir string
+}
+
+// Item is a file or directory that resides in the trash.
+type Item struct {
+	// Name is the name of the item in the files/ directory of the trash.
+	// It is unique within the trash directory.
+	Name string
+
+	// OriginalPath is the absolute path the item had before it was trashed.
+	OriginalPath string
+
+	// DeletionDate is the time at which the item was trashed.
+	// It is the zero time if the trash info file does not contain a valid date.
+	DeletionDate time.Time
+
+	// Size is the size in bytes of the trashed file. For directories, this is the total size of
+	// all files in the directory.
+	Size int64
+
+	// Dir is the trash directory containing the item.
+	Dir Dir
+}
+
+// TrashOptions configures trashing a file.
+type TrashOptions struct {
+	// MaxSize, if positive, is the maximum size in bytes the trash directory may have after the
+	// file has been trashed.
+	MaxSize int64
+
+	// OnQuotaExceeded determines what happens when trashing the file would make the trash
+	// directory exceed MaxSize.
+	OnQuotaExceeded QuotaAction
+
+	// CopyFallback, if true, allows trashing files that cannot be moved to the trash directory
+	// because it is on another device. This happens for files on volumes without a usable trash
+	// directory, which are then trashed to the home trash.
+	// The file is copied to the trash and synced to disk before the original is removed.
+	// If copying fails, the partial copy is removed and the original is left untouched.
+	CopyFallback bool
+}
+
+// HomeDir returns the home trash directory, $XDG_DATA_HOME/Trash.
+func HomeDir() Dir {
+	return Dir{Path: filepath.Join(basedir.DataHome, "Trash")}
+}
+
+// FilesPath returns the path of the files/ directory of the trash.
+func (d Dir) FilesPath() string {
+	return filepath.Join(d.Path, filesDirName)
+}
+
+// InfoPath returns the path of the info/ directory of the trash.
+func (d Dir) InfoPath() string {
+	return filepath.Join(d.Path, infoDirName)
+}
+
+// FilePath returns the path of the trashed file with the given name.
+func (i Item) FilePath() string {
+	return filepath.Join(i.Dir.FilesPath(), i.Name)
+}
+
+// InfoFilePath returns the path of the .trashinfo file of the item.
+func (i Item) InfoFilePath() string {
+	return filepath.Join(i.Dir.InfoPath(), i.Name+trashInfoExt)
+}
+
+// Trash moves the file or directory at path to the trash and returns the trashed item.
+// The trash directory is determined using DirFor, files on other volumes than the home
+// directory are moved to the trash directory of that volume.
+// The .trashinfo file is created before the file is moved so that a trashed file always has
+// its information available, as required by the spec.
+func Trash(path string) (*Item, error) {
+	return TrashWithOptions(path, TrashOptions{})
+}
+
+// TrashWithOptions moves the file or directory at path to the trash directory determined by
+// DirFor and returns the trashed item. See TrashOptions.
+func TrashWithOptions(path string, options TrashOptions) (*Item, error) {
+	dir, err := DirFor(path)
+	switch {
+	case errors.Is(err, ErrNoTrashDir) && options.CopyFallback:
+		dir = HomeDir()
+	case err != nil:
+		return nil, fmt.Errorf("Trash: %w", err)
+	}
+
+	return dir.TrashWithOptions(path, options)
+}
+
+// Trash moves the file or directory at path to this trash directory and returns the trashed
+// item.
+func (d Dir) Trash(path string) (*Item, error) {
+	return d.TrashWithOptions(path, TrashOptions{})
+}
+
+// TrashWithOptions moves the file or directory at path to this trash directory and returns the
+// trashed item. See TrashOptions.
+func (d Dir) TrashWithOptions(path string, options TrashOptions) (*Item, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("Trash: failed to make path %s absolute: %w", path, err)
+	}
+
+	_, err = os.Lstat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Trash: failed to stat %s: %w", path, err)
+	}
+
+	if options.MaxSize > 0 {
+		err = d.enforceQuota(path, options)
+		if err != nil {
+			return nil, fmt.Errorf("Trash: %w", err)
+		}
+	}
+
+	err = d.ensureDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{
+		OriginalPath: path,
+		DeletionDate: time.Now().Truncate(time.Second),
+		Dir:          d,
+	}
+
+	infoFile, err := d.createInfoFile(item)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = infoFile.Write(item.encodeInfo())
+	if closeErr := infoFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(item.InfoFilePath())
+		return nil, fmt.Errorf("Trash: failed to write %s: %w", item.InfoFilePath(), err)
+	}
+
+	err = rename(path, item.FilePath())
+	if err != nil && options.CopyFallback && isCrossDevice(err) {
+		err = moveByCopy(path, item.FilePath())
+		if err != nil && exists(item.FilePath()) {
+			// The copy is complete but the original could not be removed entirely.
+			// The item is kept in the trash as the original can no longer be trusted to be
+			// complete.
+			return item, fmt.Errorf("Trash: %w", err)
+		}
+	}
+	if err != nil {
+		os.Remove(item.InfoFilePath())
+		return nil, fmt.Errorf("Trash: failed to move %s to the trash: %w", path, err)
+	}
+
+	return item, nil
+}
+
+// ensureDirs creates the trash directory and its subdirectories if they do not exist.
+func (d Dir) ensureDirs() error {
+	for _, dir := range []string{d.FilesPath(), d.InfoPath()} {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return fmt.Errorf("failed to create trash directory %s: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// createInfoFile reserves a unique name for the item by exclusively creating its .trashinfo
+// file. The name is stored in item.
+//
+// Candidate names are tried in a fixed order, see candidateName. A name is only used if neither
+// files/ nor info/ contain an entry for it. The info file is created with O_EXCL, so concurrent
+// trashers that follow the same protocol can never reserve the same name.
+func (d Dir) createInfoFile(item *Item) (*os.File, error) {
+	base := filepath.Base(item.OriginalPath)
+
+	for i := 1; ; i++ {
+		name := candidateName(base, i)
+		if exists(filepath.Join(d.FilesPath(), name)) {
+			continue
+		}
+
+		item.Name = name
+		file, err := os.OpenFile(item.InfoFilePath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		switch {
+		case errors.Is(err, os.ErrExist):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to create trash info file: %w", err)
+		}
+
+		// A trasher that does not reserve the info file first may have created the file in the
+		// meantime.
+		if exists(filepath.Join(d.FilesPath(), name)) {
+			file.Close()
+			os.Remove(item.InfoFilePath())
+			continue
+		}
+
+		return file, nil
+	}
+}
+
+// candidateName returns the i-th candidate name, starting at 1, for a trashed file with the
+// given base name. The first candidate is the base name itself, the following candidates insert
+// a counter before the extension so that the type of the file can still be derived from its
+// name. E.g. report.txt, report.2.txt, report.3.txt.
+func candidateName(base string, i int) string {
+	if i <= 1 {
+		return base
+	}
+
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dot files such as .bashrc have no extension
+		stem, ext = base, ""
+	}
+
+	return stem + "." + strconv.Itoa(i) + ext
+}
+
+// exists returns true if there is a file, directory or (broken) symbolic link at path.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,183 @@
+package trash
+
+import (
+	"errors"
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTrash(t *testing.T) {
+	home := testenv.Setup(t)
+	original := filepath.Join(home, "some dir", "héllo.txt")
+	testenv.WriteFile(t, original, "hello")
+
+	item, err := Trash(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(original); !os.IsNotExist(err) {
+		t.Errorf("original file still exists after trashing: %v", err)
+	}
+
+	trashed := filepath.Join(home, ".local/share/Trash/files/héllo.txt")
+	if item.FilePath() != trashed {
+		t.Errorf("FilePath() = %s, expected: %s", item.FilePath(), trashed)
+	}
+
+	content, err := os.ReadFile(trashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("trashed content = %s, expected: hello", content)
+	}
+
+	info, err := os.ReadFile(item.InfoFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPath := "Path=" + filepath.Join(home, "some%20dir", "h%C3%A9llo.txt") + "\n"
+	if !strings.Contains(string(info), expectedPath) {
+		t.Errorf("trash info does not contain %q:\n%s", expectedPath, info)
+	}
+
+	if !strings.HasPrefix(string(info), "[Trash Info]\n") {
+		t.Errorf("trash info does not start with the header:\n%s", info)
+	}
+}
+
+func TestTrashSameName(t *testing.T) {
+	home := testenv.Setup(t)
+	first := filepath.Join(home, "a", "file")
+	second := filepath.Join(home, "b", "file")
+	testenv.WriteFile(t, first, "1")
+	testenv.WriteFile(t, second, "2")
+
+	item1, err := Trash(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item2, err := Trash(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item1.Name == item2.Name {
+		t.Errorf("both items have name %s", item1.Name)
+	}
+
+	content, err := os.ReadFile(item2.FilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "2" {
+		t.Errorf("content of second item = %s, expected: 2", content)
+	}
+}
+
+func TestTrashNonexistent(t *testing.T) {
+	home := testenv.Setup(t)
+
+	_, err := Trash(filepath.Join(home, "nope"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got: %v", err)
+	}
+
+	entries, _ := os.ReadDir(HomeDir().InfoPath())
+	if len(entries) > 0 {
+		t.Errorf("info directory is not empty after failed trash")
+	}
+}
+
+func TestCandidateName(t *testing.T) {
+	tests := []struct {
+		base     string
+		i        int
+		expected string
+	}{
+		{"report.txt", 1, "report.txt"},
+		{"report.txt", 2, "report.2.txt"},
+		{"archive.tar.gz", 3, "archive.tar.3.gz"},
+		{".bashrc", 2, ".bashrc.2"},
+		{"noext", 4, "noext.4"},
+	}
+
+	for _, test := range tests {
+		actual := candidateName(test.base, test.i)
+		if actual != test.expected {
+			t.Errorf("candidateName(%s, %d) = %s, expected: %s", test.base, test.i, actual, test.expected)
+		}
+	}
+}
+
+func TestTrashStaleFile(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := HomeDir()
+	// A file without info file, e.g. left behind by another implementation, must not be
+	// overwritten.
+	testenv.WriteFile(t, filepath.Join(dir.FilesPath(), "file.txt"), "stale")
+
+	path := filepath.Join(home, "file.txt")
+	testenv.WriteFile(t, path, "new")
+
+	item, err := Trash(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Name != "file.2.txt" {
+		t.Errorf("item.Name = %s, expected: file.2.txt", item.Name)
+	}
+}
+
+func TestTrashConcurrent(t *testing.T) {
+	home := testenv.Setup(t)
+	dir := HomeDir()
+	const amount = 20
+
+	paths := make([]string, amount)
+	for i := range paths {
+		paths[i] = filepath.Join(home, strconv.Itoa(i), "same.txt")
+		testenv.WriteFile(t, paths[i], strconv.Itoa(i))
+	}
+
+	var wg sync.WaitGroup
+	items := make([]*Item, amount)
+	errs := make([]error, amount)
+	for i := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items[i], errs[i] = dir.Trash(paths[i])
+		}()
+	}
+	wg.Wait()
+
+	names := make(map[string]bool)
+	for i, item := range items {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+
+		if names[item.Name] {
+			t.Errorf("name %s was used twice", item.Name)
+		}
+		names[item.Name] = true
+
+		content, err := os.ReadFile(item.FilePath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != strconv.Itoa(i) {
+			t.Errorf("%s contains %s, expected: %d", item.Name, content, i)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ErrNoTrashDir is returned when no trash directory can be used for a file.
+var ErrNoTrashDir = errors.New("no usable trash directory")
+
+// DirFor returns the trash directory that a file at path should be moved to.
+//
+// Files on the same volume as the home trash use the home trash. For files on other volumes,
+// the top directory of the volume is determined and the first usable directory of the following
+// is returned, creating it if needed:
+//   - $topdir/.Trash/$uid, if $topdir/.Trash is a directory with the sticky bit set and not a
+//     symbolic link.
+//   - $topdir/.Trash-$uid.
+//
+// If neither can be used, ErrNoTrashDir is returned.
+func DirFor(path string) (Dir, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Dir{}, fmt.Errorf("DirFor: failed to make path %s absolute: %w", path, err)
+	}
+
+	home := HomeDir()
+
+	fileDevice, err := deviceOf(filepath.Dir(path))
+	if err != nil {
+		return Dir{}, fmt.Errorf("DirFor: %w", err)
+	}
+
+	homeDevice, err := deviceOf(existingAncestor(home.Path))
+	if err != nil || homeDevice == fileDevice {
+		return home, nil
+	}
+
+	topDir, err := topDirOf(filepath.Dir(path), fileDevice)
+	if err != nil {
+		return Dir{}, fmt.Errorf("DirFor: failed to find top directory of %s: %w", path, err)
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+
+	if isValidSharedTrash(filepath.Join(topDir, ".Trash")) {
+		dir := Dir{Path: filepath.Join(topDir, ".Trash", uid), TopDir: topDir}
+		if dir.ensureDirs() == nil {
+			return dir, nil
+		}
+	}
+
+	dir := Dir{Path: filepath.Join(topDir, ".Trash-"+uid), TopDir: topDir}
+	err = dir.ensureDirs()
+	if err != nil {
+		return Dir{}, fmt.Errorf("DirFor: %w for %s: %w", ErrNoTrashDir, path, err)
+	}
+
+	return dir, nil
+}
+
+// isValidSharedTrash checks the requirements of the spec for an administrator created
+// $topdir/.Trash directory: it must be a directory, not a symbolic link, and have the sticky bit
+// set.
+func isValidSharedTrash(path string) bool {
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	mode := stat.Mode()
+
+	return mode.IsDir() && mode&os.ModeSymlink == 0 && mode&os.ModeSticky != 0
+}
+
+// topDirOf returns the top directory of the volume containing dir by walking up the directory
+// tree until the device changes.
+func topDirOf(dir string, device uint64) (string, error) {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+
+		parentDevice, err := deviceOf(parent)
+		if err != nil {
+			return "", err
+		}
+
+		if parentDevice != device {
+			return dir, nil
+		}
+
+		dir = parent
+	}
+}
+
+// existingAncestor returns path or its closest ancestor that exists.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,80 @@
+package trash
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/testenv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirForSameVolume(t *testing.T) {
+	home := testenv.Setup(t)
+	path := filepath.Join(home, "file")
+	testenv.WriteFile(t, path, "")
+
+	dir, err := DirFor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dir != HomeDir() {
+		t.Errorf("DirFor = %v, expected the home trash %v", dir, HomeDir())
+	}
+}
+
+func TestIsValidSharedTrash(t *testing.T) {
+	top := t.TempDir()
+	trashPath := filepath.Join(top, ".Trash")
+
+	if isValidSharedTrash(trashPath) {
+		t.Errorf("nonexistent .Trash must not be valid")
+	}
+
+	if err := os.Mkdir(trashPath, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if isValidSharedTrash(trashPath) {
+		t.Errorf(".Trash without sticky bit must not be valid")
+	}
+
+	if err := os.Chmod(trashPath, 0777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	if !isValidSharedTrash(trashPath) {
+		t.Errorf(".Trash with sticky bit must be valid")
+	}
+
+	link := filepath.Join(t.TempDir(), ".Trash")
+	if err := os.Symlink(trashPath, link); err != nil {
+		t.Fatal(err)
+	}
+	if isValidSharedTrash(link) {
+		t.Errorf("symbolic link to .Trash must not be valid")
+	}
+}
+
+func TestTopDirOf(t *testing.T) {
+	dir := t.TempDir()
+	device, err := deviceOf(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top, err := topDirOf(dir, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(dir, top) {
+		t.Errorf("top directory %s is not an ancestor of %s", top, dir)
+	}
+
+	topDevice, err := deviceOf(top)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topDevice != device {
+		t.Errorf("top directory %s is on another device", top)
+	}
+}
@@ -0,0 +1,18 @@
+// Package xdg is the root of the module, the specifications are implemented in its
+// subpackages. It configures behavior shared by all packages.
+package xdg
+
+import (
+	"github.com/MatthiasKunnen/xdg/internal/logging"
+	"log/slog"
+)
+
+// SetLogger sets the logger used by all packages of the module to report problems that do not
+// cause an error to be returned, such as invalid files that are skipped. By default, these are
+// discarded. Pass nil to discard them again.
+//
+// Records use the following attribute keys where applicable: path, desktop_id, mime, and
+// error.
+func SetLogger(logger *slog.Logger) {
+	logging.SetLogger(logger)
+}
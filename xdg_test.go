@@ -0,0 +1,39 @@
+package xdg
+
+import (
+	"bytes"
+	"github.com/MatthiasKunnen/xdg/desktop"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.desktop")
+	err := os.WriteFile(path, []byte("not a desktop file"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idPathMap := desktop.IdPathMap{"broken.desktop": {path}}
+
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	_, _, _ = idPathMap.LoadById("broken.desktop")
+	output := buf.String()
+	for _, expected := range []string{"level=WARN", "desktop_id=broken.desktop", "path=" + path} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("log output %q does not contain %q", output, expected)
+		}
+	}
+
+	buf.Reset()
+	SetLogger(nil)
+	_, _, _ = idPathMap.LoadById("broken.desktop")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output after resetting the logger, got: %s", buf.String())
+	}
+}
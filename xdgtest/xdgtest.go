@@ -0,0 +1,338 @@
+// Package xdgtest builds isolated XDG environments for tests. A Fixture describes the desktop
+// files, mimeapps.list associations, MIME types, icon themes, and other files, New writes them to
+// a temporary directory and points the XDG environment variables and basedir to it.
+//
+// As the environment variables are process wide, tests using New cannot run in parallel.
+package xdgtest
+
+import (
+	"fmt"
+	"github.com/MatthiasKunnen/xdg"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Application describes a desktop file of type Application.
+type Application struct {
+	Name string
+
+	// Exec defaults to the desktop ID without the .desktop suffix followed by %U.
+	Exec string
+
+	MimeTypes []string
+
+	// Extra are additional lines of the Desktop Entry group, e.g. "NoDisplay=true".
+	Extra []string
+}
+
+// content returns the desktop file of the application with the given desktop ID.
+func (a Application) content(desktopId string) string {
+	name := a.Name
+	if name == "" {
+		name = strings.TrimSuffix(desktopId, ".desktop")
+	}
+
+	exec := a.Exec
+	if exec == "" {
+		exec = strings.TrimSuffix(desktopId, ".desktop") + " %U"
+	}
+
+	var b strings.Builder
+	b.WriteString("[Desktop Entry]\nType=Application\n")
+	b.WriteString("Name=" + name + "\n")
+	b.WriteString("Exec=" + exec + "\n")
+	if len(a.MimeTypes) > 0 {
+		b.WriteString("MimeType=" + strings.Join(a.MimeTypes, ";") + ";\n")
+	}
+	for _, line := range a.Extra {
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// MimeType describes a type of the shared MIME-info database.
+type MimeType struct {
+	// Type, e.g. text/x-csrc.
+	Type string
+
+	// Globs are the file name patterns of the type, e.g. *.c. They have weight 50.
+	Globs []string
+
+	// Parents are the types this type is a subclass of.
+	Parents []string
+
+	// Aliases are alternative names of the type.
+	Aliases []string
+
+	Icon        string
+	GenericIcon string
+}
+
+// IconTheme describes an icon theme.
+type IconTheme struct {
+	Name     string
+	Inherits []string
+
+	// Icons maps theme subdirectories, e.g. 48x48/apps, to the names of the icons they contain.
+	// Empty PNG files are created for the icons.
+	Icons map[string][]string
+}
+
+// MimeApps describes a mimeapps.list file. The values are lists of desktop IDs.
+type MimeApps struct {
+	Default map[string][]string
+	Added   map[string][]string
+	Removed map[string][]string
+}
+
+// Fixture describes an XDG environment.
+type Fixture struct {
+	// Desktop is the value of $XDG_CURRENT_DESKTOP.
+	Desktop string
+
+	// Applications are written to $XDG_DATA_HOME/applications, keyed by desktop ID.
+	Applications map[string]Application
+
+	// SystemApplications are written to the applications directory of the first of
+	// $XDG_DATA_DIRS, keyed by desktop ID.
+	SystemApplications map[string]Application
+
+	// MimeApps is written to $XDG_CONFIG_HOME/mimeapps.list.
+	MimeApps MimeApps
+
+	// SystemMimeApps is written to the applications directory of the first of $XDG_DATA_DIRS.
+	SystemMimeApps MimeApps
+
+	// MimeTypes are written to the MIME database in $XDG_DATA_HOME/mime.
+	MimeTypes []MimeType
+
+	// IconThemes are written to $XDG_DATA_HOME/icons, keyed by theme ID.
+	IconThemes map[string]IconTheme
+
+	// Files are written relative to the root of the environment, e.g.
+	// home/.local/share/applications/foo.desktop.
+	Files map[string]string
+}
+
+// Env is an XDG environment created by New.
+type Env struct {
+	// Root is the directory containing all other directories of the environment.
+	Root string
+
+	Home       string
+	ConfigHome string
+	ConfigDirs []string
+	DataHome   string
+	DataDirs   []string
+	CacheHome  string
+	StateHome  string
+	RuntimeDir string
+}
+
+// New writes the fixture to a temporary directory and sets the XDG environment variables, $HOME,
+// and basedir to use it. Everything is restored when the test ends.
+func New(t testing.TB, fixture Fixture) *Env {
+	t.Helper()
+
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	env := &Env{
+		Root:       root,
+		Home:       home,
+		ConfigHome: filepath.Join(home, ".config"),
+		ConfigDirs: []string{filepath.Join(root, "etc/xdg")},
+		DataHome:   filepath.Join(home, ".local/share"),
+		DataDirs:   []string{filepath.Join(root, "usr/share")},
+		CacheHome:  filepath.Join(home, ".cache"),
+		StateHome:  filepath.Join(home, ".local/state"),
+		RuntimeDir: filepath.Join(root, "run/user"),
+	}
+
+	for _, dir := range []string{env.Home, env.RuntimeDir} {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("HOME", env.Home)
+	t.Setenv("XDG_CONFIG_HOME", env.ConfigHome)
+	t.Setenv("XDG_CONFIG_DIRS", strings.Join(env.ConfigDirs, ":"))
+	t.Setenv("XDG_DATA_HOME", env.DataHome)
+	t.Setenv("XDG_DATA_DIRS", strings.Join(env.DataDirs, ":"))
+	t.Setenv("XDG_CACHE_HOME", env.CacheHome)
+	t.Setenv("XDG_STATE_HOME", env.StateHome)
+	t.Setenv("XDG_RUNTIME_DIR", env.RuntimeDir)
+	t.Setenv("XDG_CURRENT_DESKTOP", fixture.Desktop)
+	t.Setenv("FLATPAK_ID", "")
+	t.Setenv("SNAP_NAME", "")
+	basedir.Reinit()
+	t.Cleanup(basedir.Reinit)
+
+	userApplications := filepath.Join(env.DataHome, "applications")
+	systemApplications := filepath.Join(env.DataDirs[0], "applications")
+	for id, application := range fixture.Applications {
+		env.WriteFile(t, filepath.Join(userApplications, id), application.content(id))
+	}
+	for id, application := range fixture.SystemApplications {
+		env.WriteFile(t, filepath.Join(systemApplications, id), application.content(id))
+	}
+
+	if content := fixture.MimeApps.content(); content != "" {
+		env.WriteFile(t, filepath.Join(env.ConfigHome, "mimeapps.list"), content)
+	}
+	if content := fixture.SystemMimeApps.content(); content != "" {
+		env.WriteFile(t, filepath.Join(systemApplications, "mimeapps.list"), content)
+	}
+
+	env.writeMimeTypes(t, fixture.MimeTypes)
+
+	for id, theme := range fixture.IconThemes {
+		env.writeIconTheme(t, id, theme)
+	}
+
+	for path, content := range fixture.Files {
+		env.WriteFile(t, filepath.Join(root, path), content)
+	}
+
+	return env
+}
+
+// WriteFile writes the file, creating its parent directories. Relative paths are relative to
+// Root.
+func (e *Env) WriteFile(t testing.TB, path string, content string) {
+	t.Helper()
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.Root, path)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// System returns an xdg.System for the environment, it is closed when the test ends.
+func (e *Env) System(t testing.TB) *xdg.System {
+	t.Helper()
+
+	system, err := xdg.NewSystem(xdg.SystemOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { system.Close() })
+
+	return system
+}
+
+func (m MimeApps) content() string {
+	var b strings.Builder
+	for _, section := range []struct {
+		name    string
+		entries map[string][]string
+	}{
+		{"Default Applications", m.Default},
+		{"Added Associations", m.Added},
+		{"Removed Associations", m.Removed},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "[%s]\n", section.name)
+		for _, mimeType := range sortedKeys(section.entries) {
+			fmt.Fprintf(&b, "%s=%s;\n", mimeType, strings.Join(section.entries[mimeType], ";"))
+		}
+	}
+
+	return b.String()
+}
+
+func (e *Env) writeMimeTypes(t testing.TB, mimeTypes []MimeType) {
+	if len(mimeTypes) == 0 {
+		return
+	}
+
+	var globs, subclasses, aliases, icons, genericIcons strings.Builder
+	for _, mimeType := range mimeTypes {
+		for _, glob := range mimeType.Globs {
+			fmt.Fprintf(&globs, "50:%s:%s\n", mimeType.Type, glob)
+		}
+		for _, parent := range mimeType.Parents {
+			fmt.Fprintf(&subclasses, "%s %s\n", mimeType.Type, parent)
+		}
+		for _, alias := range mimeType.Aliases {
+			fmt.Fprintf(&aliases, "%s %s\n", alias, mimeType.Type)
+		}
+		if mimeType.Icon != "" {
+			fmt.Fprintf(&icons, "%s:%s\n", mimeType.Type, mimeType.Icon)
+		}
+		if mimeType.GenericIcon != "" {
+			fmt.Fprintf(&genericIcons, "%s:%s\n", mimeType.Type, mimeType.GenericIcon)
+		}
+	}
+
+	dir := filepath.Join(e.DataHome, "mime")
+	e.WriteFile(t, filepath.Join(dir, "globs2"), globs.String())
+	e.WriteFile(t, filepath.Join(dir, "subclasses"), subclasses.String())
+	e.WriteFile(t, filepath.Join(dir, "aliases"), aliases.String())
+	e.WriteFile(t, filepath.Join(dir, "icons"), icons.String())
+	e.WriteFile(t, filepath.Join(dir, "generic-icons"), genericIcons.String())
+}
+
+func (e *Env) writeIconTheme(t testing.TB, id string, theme IconTheme) {
+	dir := filepath.Join(e.DataHome, "icons", id)
+	subdirs := sortedKeys(theme.Icons)
+
+	name := theme.Name
+	if name == "" {
+		name = id
+	}
+
+	var b strings.Builder
+	b.WriteString("[Icon Theme]\n")
+	b.WriteString("Name=" + name + "\n")
+	if len(theme.Inherits) > 0 {
+		b.WriteString("Inherits=" + strings.Join(theme.Inherits, ",") + "\n")
+	}
+	b.WriteString("Directories=" + strings.Join(subdirs, ",") + "\n")
+	for _, subdir := range subdirs {
+		// Directories such as 48x48/apps have a fixed size, others such as scalable/apps are
+		// scalable
+		size, _, _ := strings.Cut(subdir, "x")
+		if _, err := strconv.Atoi(size); err == nil {
+			fmt.Fprintf(&b, "\n[%s]\nSize=%s\nType=Fixed\n", subdir, size)
+		} else {
+			fmt.Fprintf(&b, "\n[%s]\nSize=48\nMinSize=8\nMaxSize=512\nType=Scalable\n", subdir)
+		}
+	}
+	e.WriteFile(t, filepath.Join(dir, "index.theme"), b.String())
+
+	for _, subdir := range subdirs {
+		for _, icon := range theme.Icons[subdir] {
+			e.WriteFile(t, filepath.Join(dir, subdir, icon+".png"), "")
+		}
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
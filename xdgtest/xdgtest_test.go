@@ -0,0 +1,67 @@
+package xdgtest
+
+import (
+	"context"
+	"github.com/MatthiasKunnen/xdg/basedir"
+	"github.com/MatthiasKunnen/xdg/mimeapps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	env := New(t, Fixture{
+		Applications: map[string]Application{
+			"editor.desktop": {MimeTypes: []string{"text/plain"}},
+		},
+		SystemApplications: map[string]Application{
+			"ide.desktop": {Name: "IDE", MimeTypes: []string{"text/x-csrc"}},
+		},
+		MimeApps: MimeApps{
+			Default: map[string][]string{"text/plain": {"editor.desktop"}},
+		},
+		MimeTypes: []MimeType{
+			{Type: "text/x-csrc", Globs: []string{"*.c"}, Parents: []string{"text/plain"}},
+		},
+		IconThemes: map[string]IconTheme{
+			"hicolor": {Icons: map[string][]string{"48x48/apps": {"editor"}}},
+		},
+		Files: map[string]string{"home/main.c": "int main() {}\n"},
+	})
+
+	if basedir.DataHome != env.DataHome || basedir.ConfigHome != env.ConfigHome {
+		t.Errorf("basedir was not updated: %s, %s", basedir.DataHome, basedir.ConfigHome)
+	}
+
+	_, err := os.Stat(filepath.Join(env.DataHome, "icons/hicolor/48x48/apps/editor.png"))
+	if err != nil {
+		t.Errorf("icon was not created: %v", err)
+	}
+
+	list, err := mimeapps.ParseFile(filepath.Join(env.ConfigHome, "mimeapps.list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults := list.Default["text/plain"]; len(defaults) != 1 || defaults[0] != "editor.desktop" {
+		t.Errorf("defaults of text/plain = %v, expected: [editor.desktop]", defaults)
+	}
+
+	system := env.System(t)
+	ctx := context.Background()
+
+	mimeType, err := system.DetectMime(ctx, filepath.Join(env.Home, "main.c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mimeType != "text/x-csrc" {
+		t.Errorf("DetectMime = %s, expected: text/x-csrc", mimeType)
+	}
+
+	app, err := system.DefaultAppFor(ctx, mimeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Id != "ide.desktop" || app.Entry.Name.Default != "IDE" {
+		t.Errorf("DefaultAppFor = %s (%s), expected: ide.desktop (IDE)", app.Id, app.Entry.Name.Default)
+	}
+}